@@ -1,17 +1,17 @@
 package apperror
 
-func NewUpdateCategoryParentRestrict() error {
+func NewCategoryMoveCycle() error {
 	return NewAppError(
 		CodeBadRequest,
-		"can't update category with parent category level 2",
+		"can't move category under itself or one of its descendants",
 		nil,
 	)
 }
 
-func NewCreateCategoryParentRestrict() error {
+func NewInvalidCategoryOrder() error {
 	return NewAppError(
 		CodeBadRequest,
-		"can't create category with parent category level 2",
+		"category order must list every sibling exactly once",
 		nil,
 	)
 }
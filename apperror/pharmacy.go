@@ -0,0 +1,27 @@
+package apperror
+
+import "fmt"
+
+func NewPharmacyClosed(name string) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("pharmacy %s isn't taking orders, it has no opening hours coming up", name),
+		nil,
+	)
+}
+
+func NewPharmacyHolidayExists(date string) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("pharmacy is already closed on %s", date),
+		nil,
+	)
+}
+
+func NewPharmacyHolidayInPast() error {
+	return NewAppError(
+		CodeBadRequest,
+		"holiday can't be in the past",
+		nil,
+	)
+}
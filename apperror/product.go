@@ -0,0 +1,94 @@
+package apperror
+
+import (
+	"fmt"
+	"strings"
+)
+
+func NewSynonymSameAsTerm() error {
+	return NewAppError(
+		CodeBadRequest,
+		"synonym must be different from its term",
+		nil,
+	)
+}
+
+func NewInvalidImageDimension(min, max int) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("image width and height must be between %d and %d pixels", min, max),
+		nil,
+	)
+}
+
+func NewProductImageLimitReached(max int) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("a product can have at most %d images", max),
+		nil,
+	)
+}
+
+func NewInvalidProductImageOrder() error {
+	return NewAppError(
+		CodeBadRequest,
+		"image order must list every image of the product exactly once",
+		nil,
+	)
+}
+
+func NewProductVariantRequired() error {
+	return NewAppError(
+		CodeBadRequest,
+		"product has variants, variant id is required",
+		nil,
+	)
+}
+
+func NewProductVariantInUse() error {
+	return NewAppError(
+		CodeBadRequest,
+		"product variant still has stocks",
+		nil,
+	)
+}
+
+func NewProductHasStockWithoutVariant() error {
+	return NewAppError(
+		CodeBadRequest,
+		"product has stocks without a variant, remove them before adding variants",
+		nil,
+	)
+}
+
+func NewSevereDrugInteraction(pairs []string) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("severe drug interaction requires a doctor's approval: %s", strings.Join(pairs, "; ")),
+		nil,
+	)
+}
+
+func NewProductDiscontinued() error {
+	return NewAppError(
+		CodeBadRequest,
+		"product is discontinued",
+		nil,
+	)
+}
+
+func NewProductNotAvailable(name string) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("product %s is not available for purchase", name),
+		nil,
+	)
+}
+
+func NewInvalidProductSchedule() error {
+	return NewAppError(
+		CodeBadRequest,
+		"publish time must be before unpublish time",
+		nil,
+	)
+}
@@ -0,0 +1,43 @@
+package apperror
+
+import "fmt"
+
+func NewPromotionExhausted(name string) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("promotion %s has reached its usage limit, please check your cart again", name),
+		nil,
+	)
+}
+
+func NewPromotionPercentageTooHigh() error {
+	return NewAppError(
+		CodeBadRequest,
+		"percentage discount can't be more than 100",
+		nil,
+	)
+}
+
+func NewPromotionEndsBeforeStart() error {
+	return NewAppError(
+		CodeBadRequest,
+		"promotion must end after it starts",
+		nil,
+	)
+}
+
+func NewPromotionScopeTarget(scope string) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("a %s promotion needs exactly its %s_id", scope, scope),
+		nil,
+	)
+}
+
+func NewPromotionLimitBelowUsage(usage int) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("usage limit can't be lower than the %d times the promotion was already used", usage),
+		nil,
+	)
+}
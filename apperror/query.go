@@ -0,0 +1,27 @@
+package apperror
+
+import "fmt"
+
+func NewInvalidSortField(field string) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("can't sort by %s", field),
+		nil,
+	)
+}
+
+func NewInvalidFilter(field string, op string) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("can't filter %s with %s", field, op),
+		nil,
+	)
+}
+
+func NewInvalidFilterValue(field string, value string) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("invalid value %q for filter %s", value, field),
+		nil,
+	)
+}
@@ -0,0 +1,9 @@
+package apperror
+
+func NewSpecializationInUse(err error) error {
+	return NewAppError(
+		CodeBadRequest,
+		"specialization is still used by doctors or pending doctor registrations",
+		err,
+	)
+}
@@ -1,5 +1,7 @@
 package apperror
 
+import "fmt"
+
 func NewStockNotEnough(err error) error {
 	return NewAppError(
 		CodeBadRequest,
@@ -31,3 +33,83 @@ func NewNotPending(err error) error {
 		err,
 	)
 }
+
+func NewStockBelowReserved(reserved int) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("stock can't be lower than the %d units reserved by orders", reserved),
+		nil,
+	)
+}
+
+func NewStockReserved(reserved int) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("stock still has %d units reserved by orders", reserved),
+		nil,
+	)
+}
+
+func NewBatchExpired(lotNumber string) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("batch %s is already expired", lotNumber),
+		nil,
+	)
+}
+
+func NewNothingToWriteOff() error {
+	return NewAppError(
+		CodeBadRequest,
+		"stock has no expired batches to write off",
+		nil,
+	)
+}
+
+func NewReorderBelowMinimum() error {
+	return NewAppError(
+		CodeBadRequest,
+		"reorder level can't be lower than the minimum stock",
+		nil,
+	)
+}
+
+func NewStockBelowBatched(batched int) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("stock can't be lower than the %d units in batches, write expired batches off instead", batched),
+		nil,
+	)
+}
+
+func NewStockImportNegative(field string) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("%s can't be negative", field),
+		nil,
+	)
+}
+
+func NewStockImportNotManaged(pharmacySlug string) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("pharmacy %s isn't managed by you", pharmacySlug),
+		nil,
+	)
+}
+
+func NewStockPriceNotInFuture() error {
+	return NewAppError(
+		CodeBadRequest,
+		"a price can only be scheduled for a time in the future",
+		nil,
+	)
+}
+
+func NewStockPriceNotScheduled() error {
+	return NewAppError(
+		CodeBadRequest,
+		"price is no longer scheduled",
+		nil,
+	)
+}
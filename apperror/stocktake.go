@@ -0,0 +1,27 @@
+package apperror
+
+import "fmt"
+
+func NewStockTakeAlreadyOpen() error {
+	return NewAppError(
+		CodeBadRequest,
+		"pharmacy already has an open stock-take",
+		nil,
+	)
+}
+
+func NewStockTakeNotOpen() error {
+	return NewAppError(
+		CodeBadRequest,
+		"stock-take is no longer open",
+		nil,
+	)
+}
+
+func NewStockTakeBelowZero(productName string) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("count of %s would leave its stock below zero", productName),
+		nil,
+	)
+}
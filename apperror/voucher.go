@@ -0,0 +1,91 @@
+package apperror
+
+import "fmt"
+
+func NewVoucherNotFound(code string) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("voucher %s doesn't exist", code),
+		nil,
+	)
+}
+
+func NewVoucherNotActive(code string) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("voucher %s isn't valid at this time", code),
+		nil,
+	)
+}
+
+func NewVoucherWrongPharmacy(code string) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("voucher %s can't be used at this pharmacy", code),
+		nil,
+	)
+}
+
+func NewVoucherMinSpend(code string, minSpend int) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("voucher %s needs a spend of at least %d", code, minSpend),
+		nil,
+	)
+}
+
+func NewVoucherExhausted(code string) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("voucher %s has reached its usage limit", code),
+		nil,
+	)
+}
+
+func NewVoucherUserLimit(code string) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("you have already used voucher %s as many times as allowed", code),
+		nil,
+	)
+}
+
+func NewVoucherCodeTaken(code string) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("voucher code %s is already taken", code),
+		nil,
+	)
+}
+
+func NewVoucherPercentageTooHigh() error {
+	return NewAppError(
+		CodeBadRequest,
+		"percentage voucher can't be more than 100",
+		nil,
+	)
+}
+
+func NewVoucherEndsBeforeStart() error {
+	return NewAppError(
+		CodeBadRequest,
+		"voucher must end after it starts",
+		nil,
+	)
+}
+
+func NewVoucherLimitBelowUsage(usage int) error {
+	return NewAppError(
+		CodeBadRequest,
+		fmt.Sprintf("usage limit can't be lower than the %d times the voucher was already used", usage),
+		nil,
+	)
+}
+
+func NewVoucherValueRequired() error {
+	return NewAppError(
+		CodeBadRequest,
+		"a percentage or fixed voucher needs a value",
+		nil,
+	)
+}
@@ -49,6 +49,9 @@ type Config struct {
 	CloudinaryName string
 	CloudinaryAPIKey string
 	CloudinaryAPISecret string
+
+	PdfcrowdUsername string
+	PdfcrowdAPIKey   string
 }
 
 func InitConfig() error {
@@ -90,6 +93,9 @@ func LoadConfig() (Config, error) {
 
 	ret.CloudinaryAPISecret = os.Getenv("CLOUDINARY_API_SECRET")
 
+	ret.PdfcrowdUsername = os.Getenv("PDFCROWD_USERNAME")
+	ret.PdfcrowdAPIKey = os.Getenv("PDFCROWD_API_KEY")
+
 	s = os.Getenv("ACCESS_TOKEN_LIFESPAN")
 	i, err := strconv.Atoi(s)
 	if err != nil {
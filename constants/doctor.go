@@ -1,9 +1,27 @@
 package constants
 
+import "time"
+
 const (
 	DoctorSortByStartWorkDate = "start_work_date"
 	DoctorSortByName          = "name"
 	DoctorSortByPrice         = "price"
+	DoctorSortByRelevance     = "relevance"
+)
+
+const (
+	// DoctorEarningJobInterval is how often rooms that ran past their end
+	// are booked to the earnings ledger.
+	DoctorEarningJobInterval = 10 * time.Minute
+)
+
+const (
+	DoctorSearchSimilarityThreshold = 0.3
+	DoctorSearchHighlightStart      = "<mark>"
+	DoctorSearchHighlightStop       = "</mark>"
+	// DoctorSearchRankDecimals is what the search rank is rounded to, so
+	// the rank a page ends on compares equal when sent back as the cursor.
+	DoctorSearchRankDecimals = 6
 )
 
 var (
@@ -11,5 +29,6 @@ var (
 		DoctorSortByStartWorkDate: true,
 		DoctorSortByName:          true,
 		DoctorSortByPrice:         true,
+		DoctorSortByRelevance:     true,
 	}
 )
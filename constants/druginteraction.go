@@ -0,0 +1,16 @@
+package constants
+
+import "time"
+
+const (
+	DrugInteractionImportMaxRows    = 20000
+	DrugInteractionInsertBatchSize  = 500
+	DrugInteractionOverrideDuration = 30 * 24 * time.Hour
+)
+
+var DrugInteractionHeader = []string{
+	"ingredient_a",
+	"ingredient_b",
+	"severity",
+	"description",
+}
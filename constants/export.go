@@ -0,0 +1,11 @@
+package constants
+
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatPDF  = "pdf"
+	ExportFormatXLSX = "xlsx"
+
+	MimeCSV  = "text/csv"
+	MimePDF  = "application/pdf"
+	MimeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
@@ -7,3 +7,10 @@ const (
 	ImagePng                = "image/png"
 	DefaultCategoryImageURL = "https://res.cloudinary.com/dzqeylpze/image/upload/v1714355863/atqc2yxfd0mdqogx4n9f.png"
 )
+
+const (
+	MinImageDimension  = 100
+	MaxImageDimension  = 4096
+	ThumbnailDimension = 240
+	MaxProductImages   = 10
+)
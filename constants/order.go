@@ -0,0 +1,10 @@
+package constants
+
+import "time"
+
+const (
+	// OrderPaymentTimeout is how long an order may wait for its payment
+	// proof before it is cancelled and its stock released.
+	OrderPaymentTimeout    = 24 * time.Hour
+	OrderExpiryJobInterval = 15 * time.Minute
+)
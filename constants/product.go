@@ -0,0 +1,33 @@
+package constants
+
+const (
+	ProductImportBatchSize = 100
+	ProductImportMaxRows   = 10000
+)
+
+var ProductCatalogHeader = []string{
+	"name",
+	"category_slug",
+	"generic_name",
+	"composition",
+	"content",
+	"manufacturer",
+	"description",
+	"product_classification",
+	"product_form",
+	"unit_in_pack",
+	"selling_unit",
+	"weight",
+	"height",
+	"length",
+	"width",
+}
+
+const (
+	ProductSearchSimilarityThreshold = 0.3
+)
+
+const (
+	ProductSuggestDefaultLimit = 5
+	ProductSuggestMaxLimit     = 20
+)
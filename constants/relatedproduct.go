@@ -0,0 +1,13 @@
+package constants
+
+import "time"
+
+const (
+	RelatedProductsDefaultLimit = 5
+	RelatedProductsMaxLimit     = 20
+
+	// AssociationMinSupport is the number of finished orders two products
+	// must share before they are recommended together.
+	AssociationMinSupport  = 2
+	AssociationJobInterval = 6 * time.Hour
+)
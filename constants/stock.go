@@ -0,0 +1,27 @@
+package constants
+
+import "time"
+
+const (
+	// StockExpiringDefaultDays is how far ahead the expiring stock report
+	// looks when no window is given.
+	StockExpiringDefaultDays = 30
+
+	ReplenishmentJobInterval = time.Hour
+	// ScheduledPriceJobInterval is how often due scheduled prices are
+	// written to their stocks. Orders already pay a due price before that.
+	ScheduledPriceJobInterval = 5 * time.Minute
+
+	StockImportMaxRows = 10000
+)
+
+var StockImportHeader = []string{
+	"pharmacy_slug",
+	"product_slug",
+}
+
+const (
+	// StockAllocationMaxSources is how many of the nearest pharmacies the
+	// allocation planner considers when covering a shortfall.
+	StockAllocationMaxSources = 10
+)
@@ -18,6 +18,18 @@ type Category struct {
 	Name     string
 	Slug     string
 	PhotoUrl *string
+	Path     string
+	Depth    int64
+	Position int64
+}
+
+// CategoryTreeNode is a category in the full tree. ProductCount includes the
+// products of every descendant, DirectProductCount only those of the node.
+type CategoryTreeNode struct {
+	Category           Category
+	ProductCount       int64
+	DirectProductCount int64
+	Children           []CategoryTreeNode
 }
 
 type CategoryWithParentName struct {
@@ -31,16 +43,15 @@ type CategoriesQuery struct {
 	Limit      int64
 	Level      int64
 	Term       string
-	SortBy     string
-	SortType   string
 	ParentSlug string
+	Sorts      []SortField
+	Filters    []Filter
 }
 
 func DefaultCategoriesQuery() CategoriesQuery {
 	return CategoriesQuery{
-		Page:     1,
-		SortBy:   CategorySortById,
-		SortType: "ASC",
+		Page:  1,
+		Sorts: []SortField{{Field: CategorySortById}},
 	}
 }
 
@@ -50,13 +61,23 @@ type CategoryRepository interface {
 	GetPageInfo(ctx context.Context, query CategoriesQuery) (PageInfo, error)
 	GetByName(ctx context.Context, name string) (Category, error)
 	GetById(ctx context.Context, id int64) (Category, error)
+	GetByIdAndLock(ctx context.Context, id int64) (Category, error)
 	GetBySlug(ctx context.Context, slug string) (Category, error)
+	GetBySlugAndLock(ctx context.Context, slug string) (Category, error)
 	GetBySlugWithParentName(ctx context.Context, slug string) (CategoryWithParentName, error)
 
 	Add(ctx context.Context, category Category) (Category, error)
 	Update(ctx context.Context, category Category) (Category, error)
 	SoftDeleteBySlug(ctx context.Context, slug string) error
 	BulkSoftDeleteBySlug(ctx context.Context, slug []string) error
+
+	GetTreeWithProductCounts(ctx context.Context) ([]CategoryTreeNode, error)
+	GetChildren(ctx context.Context, parentID *int64) ([]Category, error)
+	GetNextPosition(ctx context.Context, parentID *int64) (int64, error)
+	UpdatePath(ctx context.Context, id int64, path string) error
+	UpdatePosition(ctx context.Context, id int64, position int64) error
+	MoveSubtree(ctx context.Context, category Category, parentID *int64, path string, depth int64, position int64) error
+	SoftDeleteSubtree(ctx context.Context, path string) error
 }
 
 type CategoryService interface {
@@ -67,4 +88,7 @@ type CategoryService interface {
 	GetCategoryBySlug(ctx context.Context, slug string) (CategoryWithParentName, error)
 	DeleteCategory(ctx context.Context, slug string) error
 	UpdateCategory(ctx context.Context, category Category, file *multipart.File) (Category, error)
+	GetCategoryTree(ctx context.Context) ([]CategoryTreeNode, error)
+	MoveCategory(ctx context.Context, slug string, parentSlug *string) (Category, error)
+	ReorderCategories(ctx context.Context, parentSlug *string, slugs []string) ([]Category, error)
 }
@@ -20,12 +20,18 @@ type Room struct{
 	UserId 		int64
 	DoctorId 	int64
 	EndAt  		time.Time
+
+	// ConsultationFee is the doctor's price when the room was opened, the
+	// doctor is paid from it however the price changes later.
+	ConsultationFee int
 }
 
 
 type ChatRepository interface {
 	GetChats(ctx context.Context, roomId int64) ([]Chat, error)
 	AddChat(ctx context.Context, chat Chat) (Chat, error)
-	AddRoom(ctx context.Context, UserId int,DoctorId int,EndAt time.Time ) (Room, error)
+	AddRoom(ctx context.Context, UserId int,DoctorId int,EndAt time.Time, ConsultationFee int ) (Room, error)
+	GetRoomByID(ctx context.Context, id int64) (Room, error)
+	GetRoomByIDAndLock(ctx context.Context, id int64) (Room, error)
 }
 
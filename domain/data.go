@@ -17,6 +17,11 @@ type DataRepository interface {
 	AccountRepository() AccountRepository
 	ChatRepository() ChatRepository
 	ProductRepository() ProductRepository
+	ProductSynonymRepository() ProductSynonymRepository
+	ProductImageRepository() ProductImageRepository
+	ProductVariantRepository() ProductVariantRepository
+	DrugInteractionRepository() DrugInteractionRepository
+	RelatedProductRepository() RelatedProductRepository
 	ProductDetailsRepository() ProductDetailsRepository
 	RefreshTokenRepository() RefreshTokenRepository
 	ResetPasswordTokenRepository() ResetPasswordTokenRepository
@@ -26,6 +31,8 @@ type DataRepository interface {
 	AdminRepository() AdminRepository
 	UserRepository() UserRepository
 	DoctorRepository() DoctorRepository
+	DoctorProfileRepository() DoctorProfileRepository
+	DoctorEarningRepository() DoctorEarningRepository
 	PharmacyManagerRepository() PharmacyManagerRepository
 
 	SpecializationRepository() SpecializationRepository
@@ -33,6 +40,9 @@ type DataRepository interface {
 	ShipmentMethodRepository() ShipmentMethodRepository
 
 	StockRepository() StockRepository
+	StockTakeRepository() StockTakeRepository
+	PromotionRepository() PromotionRepository
+	VoucherRepository() VoucherRepository
 
 	PaymentRepository() PaymentRepository
 	OrderRepository() OrderRepository
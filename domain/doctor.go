@@ -6,6 +6,12 @@ import (
 	"time"
 )
 
+const (
+	DoctorVerificationPending  = "pending"
+	DoctorVerificationApproved = "approved"
+	DoctorVerificationRejected = "rejected"
+)
+
 type Doctor struct {
 	ID             int64
 	Account        Account
@@ -20,6 +26,24 @@ type Doctor struct {
 	YearExperience int
 	Price          int
 	CertificateURL string
+	Bio            string
+
+	Educations []DoctorEducation
+	Practices  []DoctorPractice
+	Languages  []string
+
+	VerificationStatus string
+	RejectionReason    *string
+
+	Search *DoctorSearchMatch
+}
+
+type DoctorSearchMatch struct {
+	Rank float64
+
+	NameHighlight           string
+	SpecializationHighlight string
+	WorkLocationHighlight   string
 }
 
 type DoctorCreateDetails struct {
@@ -45,22 +69,46 @@ type DoctorUpdateDetails struct {
 	Gender       *string
 	PhoneNumber  *string
 	Price        *int
+	Certificate  multipart.File
+	Bio          *string
+
+	Educations *[]DoctorEducation
+	Practices  *[]DoctorPractice
+	Languages  *[]string
 }
 
 type DoctorListDetails struct {
+	Query             *string
 	SpecializationID  *int64
 	Name              *string
 	Gender            *string
 	MinPrice          *int
 	MaxPrice          *int
 	MinYearExperience *int
+	Language          *string
+	Filters           []Filter
 
 	SortBy  string
 	SortAsc bool
 
-	Cursor   any
-	CursorID *int64
-	Limit    int
+	// CursorIsActive defaults to whether the doctor behind CursorID is
+	// active.
+	Cursor         any
+	CursorIsActive *bool
+	CursorID       *int64
+	Limit          int
+}
+
+type DoctorVerificationListDetails struct {
+	Status string
+
+	Page  int
+	Limit int
+}
+
+type DoctorRejectDetails struct {
+	ID     int64
+	Reason string
 }
 
 func (d *Doctor) ApplyUpdate(det DoctorUpdateDetails) {
@@ -76,6 +124,9 @@ func (d *Doctor) ApplyUpdate(det DoctorUpdateDetails) {
 	if det.Price != nil {
 		d.Price = *det.Price
 	}
+	if det.Bio != nil {
+		d.Bio = *det.Bio
+	}
 }
 
 type DoctorRepository interface {
@@ -89,8 +140,14 @@ type DoctorRepository interface {
 	GetByAccountIDAndLock(ctx context.Context, id int64) (Doctor, error)
 	IsExistByAccountID(ctx context.Context, id int64) (bool, error)
 
+	IsExistBySpecializationID(ctx context.Context, id int64) (bool, error)
+
+	GetVerificationPageInfo(ctx context.Context, dets DoctorVerificationListDetails) (PageInfo, error)
+	ListVerifications(ctx context.Context, dets DoctorVerificationListDetails) ([]Doctor, error)
+
 	Add(ctx context.Context, d Doctor) (Doctor, error)
 	Update(ctx context.Context, d Doctor) (Doctor, error)
+	UpdateVerification(ctx context.Context, d Doctor) (Doctor, error)
 }
 
 type DoctorService interface {
@@ -103,4 +160,9 @@ type DoctorService interface {
 	GetProfile(ctx context.Context) (Doctor, error)
 
 	SetActiveStatus(ctx context.Context, active bool) error
+
+	ListVerifications(ctx context.Context, dets DoctorVerificationListDetails) ([]Doctor, PageInfo, error)
+	GetVerificationByID(ctx context.Context, id int64) (Doctor, error)
+	Approve(ctx context.Context, id int64) error
+	Reject(ctx context.Context, dets DoctorRejectDetails) error
 }
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+const (
+	DoctorEarningStatusUnpaid = "unpaid"
+	DoctorEarningStatusPaid   = "paid"
+)
+
+type DoctorEarning struct {
+	ID       int64
+	DoctorID int64
+	RoomID   int64
+
+	ConsultationFee int
+	CommissionRate  float64
+	Commission      int
+	NetEarning      int
+
+	Status   string
+	PayoutID *int64
+	ClosedAt time.Time
+}
+
+type DoctorEarningStatement struct {
+	DoctorID int64
+	Month    time.Time
+	Entries  []DoctorEarning
+
+	TotalFee        int
+	TotalCommission int
+	TotalNet        int
+	PaidNet         int
+	UnpaidNet       int
+}
+
+type DoctorPayout struct {
+	ID       int64
+	DoctorID int64
+	Amount   int
+	NEntries int
+	PaidAt   time.Time
+
+	// EarningIDs are the earnings the payout pays for.
+	EarningIDs []int64
+}
+
+type DoctorPayoutCreateDetails struct {
+	DoctorID *int64
+	Until    time.Time
+}
+
+// NewDoctorEarning computes the commission and net earning of a consultation.
+// The commission rate is a percentage of the consultation fee.
+func NewDoctorEarning(doctorID, roomID int64, fee int, rate float64) DoctorEarning {
+	commission := int(math.Round(float64(fee) * rate / 100))
+
+	return DoctorEarning{
+		DoctorID:        doctorID,
+		RoomID:          roomID,
+		ConsultationFee: fee,
+		CommissionRate:  rate,
+		Commission:      commission,
+		NetEarning:      fee - commission,
+		Status:          DoctorEarningStatusUnpaid,
+	}
+}
+
+func NewDoctorEarningStatement(doctorID int64, month time.Time, entries []DoctorEarning) DoctorEarningStatement {
+	ret := DoctorEarningStatement{
+		DoctorID: doctorID,
+		Month:    month,
+		Entries:  entries,
+	}
+
+	for _, e := range entries {
+		ret.TotalFee += e.ConsultationFee
+		ret.TotalCommission += e.Commission
+		ret.TotalNet += e.NetEarning
+		if e.Status == DoctorEarningStatusPaid {
+			ret.PaidNet += e.NetEarning
+		} else {
+			ret.UnpaidNet += e.NetEarning
+		}
+	}
+
+	return ret
+}
+
+type DoctorEarningRepository interface {
+	IsExistByRoomID(ctx context.Context, roomID int64) (bool, error)
+	ListUnrecordedRoomIDs(ctx context.Context, until time.Time) ([]int64, error)
+	ListByDoctorID(ctx context.Context, doctorID int64, start, end time.Time) ([]DoctorEarning, error)
+	Add(ctx context.Context, e DoctorEarning) (DoctorEarning, error)
+
+	GetUnpaidSummaries(ctx context.Context, dets DoctorPayoutCreateDetails) ([]DoctorPayout, error)
+	AddPayout(ctx context.Context, p DoctorPayout) (DoctorPayout, error)
+	MarkPaid(ctx context.Context, payoutID int64, earningIDs []int64) (int, error)
+}
+
+type DoctorEarningService interface {
+	GetStatement(ctx context.Context, month time.Time) (DoctorEarningStatement, error)
+	ExportStatement(ctx context.Context, month time.Time, format string) (ExportFile, error)
+
+	CreatePayouts(ctx context.Context, dets DoctorPayoutCreateDetails) ([]DoctorPayout, error)
+	RecordEndedRooms(ctx context.Context) (int, error)
+}
@@ -0,0 +1,49 @@
+package domain_test
+
+import (
+	"medichat-be/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDoctorEarning(t *testing.T) {
+	t.Run("should subtract commission from the consultation fee", func(t *testing.T) {
+		// when
+		got := domain.NewDoctorEarning(1, 2, 50000, 10)
+
+		// then
+		assert.Equal(t, 5000, got.Commission)
+		assert.Equal(t, 45000, got.NetEarning)
+		assert.Equal(t, domain.DoctorEarningStatusUnpaid, got.Status)
+	})
+
+	t.Run("should round commission to the nearest rupiah", func(t *testing.T) {
+		// when
+		got := domain.NewDoctorEarning(1, 2, 12345, 12.5)
+
+		// then
+		assert.Equal(t, 1543, got.Commission)
+		assert.Equal(t, 10802, got.NetEarning)
+	})
+}
+
+func TestNewDoctorEarningStatement(t *testing.T) {
+	t.Run("should sum paid and unpaid entries separately", func(t *testing.T) {
+		// given
+		paid := domain.NewDoctorEarning(1, 1, 10000, 10)
+		paid.Status = domain.DoctorEarningStatusPaid
+		unpaid := domain.NewDoctorEarning(1, 2, 20000, 10)
+
+		// when
+		got := domain.NewDoctorEarningStatement(1, time.Now(), []domain.DoctorEarning{paid, unpaid})
+
+		// then
+		assert.Equal(t, 30000, got.TotalFee)
+		assert.Equal(t, 3000, got.TotalCommission)
+		assert.Equal(t, 27000, got.TotalNet)
+		assert.Equal(t, 9000, got.PaidNet)
+		assert.Equal(t, 18000, got.UnpaidNet)
+	})
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type DoctorEducation struct {
+	ID       int64
+	DoctorID int64
+
+	Institution string
+	Degree      string
+	StartYear   int
+	EndYear     *int
+}
+
+type DoctorPractice struct {
+	ID       int64
+	DoctorID int64
+
+	Location  string
+	StartDate time.Time
+	EndDate   *time.Time
+}
+
+type DoctorProfileRepository interface {
+	ListEducationsByDoctorID(ctx context.Context, doctorID int64) ([]DoctorEducation, error)
+	ReplaceEducations(ctx context.Context, doctorID int64, es []DoctorEducation) ([]DoctorEducation, error)
+
+	ListPracticesByDoctorID(ctx context.Context, doctorID int64) ([]DoctorPractice, error)
+	ReplacePractices(ctx context.Context, doctorID int64, ps []DoctorPractice) ([]DoctorPractice, error)
+
+	ListLanguagesByDoctorID(ctx context.Context, doctorID int64) ([]string, error)
+	ReplaceLanguages(ctx context.Context, doctorID int64, languages []string) ([]string, error)
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	DrugInteractionSeverityMinor    = "minor"
+	DrugInteractionSeverityModerate = "moderate"
+	DrugInteractionSeveritySevere   = "severe"
+)
+
+type DrugInteraction struct {
+	ID          int64
+	IngredientA string
+	IngredientB string
+	Severity    string
+	Description string
+}
+
+type DrugInteractionProduct struct {
+	ID          int64
+	Slug        string
+	Name        string
+	Ingredients []string
+}
+
+type DrugInteractionProductRef struct {
+	ID   int64
+	Slug string
+	Name string
+}
+
+type DrugInteractionWarning struct {
+	ProductA    DrugInteractionProductRef
+	ProductB    DrugInteractionProductRef
+	IngredientA string
+	IngredientB string
+	Severity    string
+	Description string
+
+	// Overridden is set when a doctor has approved this combination for the
+	// user, so a severe warning no longer blocks the order.
+	Overridden bool
+}
+
+type DrugInteractionOverride struct {
+	ID          int64
+	UserID      int64
+	DoctorID    int64
+	RoomID      int64
+	IngredientA string
+	IngredientB string
+	ExpiresAt   time.Time
+}
+
+type DrugInteractionImportRow struct {
+	Line        int
+	Interaction DrugInteraction
+}
+
+type DrugInteractionImportRowError struct {
+	Line    int
+	Message string
+}
+
+type DrugInteractionImportResult struct {
+	Imported int
+	Errors   []DrugInteractionImportRowError
+}
+
+type DrugInteractionRepository interface {
+	List(ctx context.Context) ([]DrugInteraction, error)
+	ListByIngredients(ctx context.Context, ingredients []string) ([]DrugInteraction, error)
+	ReplaceAll(ctx context.Context, interactions []DrugInteraction) error
+
+	ListActiveOverridesByUserID(ctx context.Context, userID int64) ([]DrugInteractionOverride, error)
+	AddOverride(ctx context.Context, o DrugInteractionOverride) (DrugInteractionOverride, error)
+}
+
+type DrugInteractionService interface {
+	List(ctx context.Context) ([]DrugInteraction, error)
+	Import(ctx context.Context, rows []DrugInteractionImportRow) (DrugInteractionImportResult, error)
+}
@@ -0,0 +1,7 @@
+package domain
+
+type ExportFile struct {
+	Name        string
+	ContentType string
+	Content     []byte
+}
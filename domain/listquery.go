@@ -0,0 +1,26 @@
+package domain
+
+type FilterOp string
+
+const (
+	FilterOpEq       FilterOp = "eq"
+	FilterOpIn       FilterOp = "in"
+	FilterOpRange    FilterOp = "range"
+	FilterOpContains FilterOp = "contains"
+)
+
+// SortField is one key of a multi-column sort. Field is a public sort name,
+// each repository maps it to a column of its own.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// Filter narrows a list by a public field name. Eq and contains take one
+// value, in takes one or more and range takes a lower and an upper bound,
+// either of which may be empty.
+type Filter struct {
+	Field  string
+	Op     FilterOp
+	Values []string
+}
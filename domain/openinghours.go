@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// OpeningHoursLookaheadDays is how far ahead NextOpening looks for a shift.
+const OpeningHoursLookaheadDays = 30
+
+const openingHoursDateLayout = "2006-01-02"
+
+// OpeningHours answers when a pharmacy is open, in the pharmacy's own
+// timezone. A shift whose end isn't after its start runs past midnight and
+// ends the next day. No shift starts on a holiday, but one that started the
+// evening before still runs into it.
+type OpeningHours struct {
+	loc      *time.Location
+	shifts   map[string][]PharmacyOperations
+	holidays map[string]bool
+}
+
+func NewOpeningHours(
+	timezone string,
+	operations []PharmacyOperations,
+	holidays []PharmacyHoliday,
+) (OpeningHours, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return OpeningHours{}, err
+	}
+
+	oh := OpeningHours{
+		loc:      loc,
+		shifts:   map[string][]PharmacyOperations{},
+		holidays: map[string]bool{},
+	}
+	for _, o := range operations {
+		day := strings.ToLower(o.Day)
+		oh.shifts[day] = append(oh.shifts[day], o)
+	}
+	for _, h := range holidays {
+		oh.holidays[h.Date.Format(openingHoursDateLayout)] = true
+	}
+
+	return oh, nil
+}
+
+func (oh OpeningHours) Location() *time.Location {
+	return oh.loc
+}
+
+// IsOpenAt reports whether the pharmacy is open at t.
+func (oh OpeningHours) IsOpenAt(t time.Time) bool {
+	t = t.In(oh.loc)
+	day := oh.startOfDay(t)
+
+	// Yesterday's overnight shifts may still be running.
+	for _, d := range []time.Time{day.AddDate(0, 0, -1), day} {
+		for _, s := range oh.shiftsOn(d) {
+			if !t.Before(s[0]) && t.Before(s[1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NextOpening returns t when the pharmacy is open at t, otherwise the start
+// of its next shift. It reports false when no shift starts within
+// OpeningHoursLookaheadDays.
+func (oh OpeningHours) NextOpening(t time.Time) (time.Time, bool) {
+	if oh.IsOpenAt(t) {
+		return t.In(oh.loc), true
+	}
+
+	day := oh.startOfDay(t.In(oh.loc))
+	for i := 0; i <= OpeningHoursLookaheadDays; i++ {
+		var next *time.Time
+		for _, s := range oh.shiftsOn(day.AddDate(0, 0, i)) {
+			start := s[0]
+			if start.After(t) && (next == nil || start.Before(*next)) {
+				next = &start
+			}
+		}
+		if next != nil {
+			return *next, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// shiftsOn returns the start and end of the shifts starting on the local
+// date of day.
+func (oh OpeningHours) shiftsOn(day time.Time) [][2]time.Time {
+	if oh.holidays[day.Format(openingHoursDateLayout)] {
+		return nil
+	}
+
+	var res [][2]time.Time
+	for _, o := range oh.shifts[strings.ToLower(day.Weekday().String())] {
+		start := oh.at(day, o.StartTime)
+		end := oh.at(day, o.EndTime)
+		if !end.After(start) {
+			end = oh.at(day.AddDate(0, 0, 1), o.EndTime)
+		}
+		res = append(res, [2]time.Time{start, end})
+	}
+	return res
+}
+
+// at is the clock time of clock on the local date of day.
+func (oh OpeningHours) at(day time.Time, clock time.Time) time.Time {
+	return time.Date(
+		day.Year(), day.Month(), day.Day(),
+		clock.Hour(), clock.Minute(), clock.Second(), 0,
+		oh.loc,
+	)
+}
+
+func (oh OpeningHours) startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, oh.loc)
+}
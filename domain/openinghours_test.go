@@ -0,0 +1,247 @@
+package domain_test
+
+import (
+	"medichat-be/domain"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func clock(s string) time.Time {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func shift(day, start, end string) domain.PharmacyOperations {
+	return domain.PharmacyOperations{Day: day, StartTime: clock(start), EndTime: clock(end)}
+}
+
+func holiday(date string) domain.PharmacyHoliday {
+	d, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		panic(err)
+	}
+	return domain.PharmacyHoliday{Date: d}
+}
+
+func local(tz string, value string) time.Time {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		panic(err)
+	}
+	t, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestOpeningHours_IsOpenAt(t *testing.T) {
+	// Sunday 23:30 in WIB is already Monday in WITA and WIT.
+	sundayNightWIB := time.Date(2026, 10, 18, 16, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+
+		timezone   string
+		operations []domain.PharmacyOperations
+		holidays   []domain.PharmacyHoliday
+		at         time.Time
+
+		want bool
+	}{
+		{
+			name: "should be closed in WIB before its local day starts",
+
+			timezone:   domain.PharmacyTimezoneWIB,
+			operations: []domain.PharmacyOperations{shift("Monday", "00:00", "06:00")},
+			at:         sundayNightWIB,
+
+			want: false,
+		},
+		{
+			name: "should be open in WITA once its local day started",
+
+			timezone:   domain.PharmacyTimezoneWITA,
+			operations: []domain.PharmacyOperations{shift("Monday", "00:00", "06:00")},
+			at:         sundayNightWIB,
+
+			want: true,
+		},
+		{
+			name: "should be open in WIT once its local day started",
+
+			timezone:   domain.PharmacyTimezoneWIT,
+			operations: []domain.PharmacyOperations{shift("Monday", "00:00", "06:00")},
+			at:         sundayNightWIB,
+
+			want: true,
+		},
+		{
+			name: "should be open after midnight during an overnight shift",
+
+			timezone:   domain.PharmacyTimezoneWIB,
+			operations: []domain.PharmacyOperations{shift("Friday", "20:00", "02:00")},
+			at:         local(domain.PharmacyTimezoneWIB, "2026-10-24 01:30"),
+
+			want: true,
+		},
+		{
+			name: "should be closed when an overnight shift ends",
+
+			timezone:   domain.PharmacyTimezoneWIB,
+			operations: []domain.PharmacyOperations{shift("Friday", "20:00", "02:00")},
+			at:         local(domain.PharmacyTimezoneWIB, "2026-10-24 02:00"),
+
+			want: false,
+		},
+		{
+			name: "should be closed before an overnight shift starts",
+
+			timezone:   domain.PharmacyTimezoneWIB,
+			operations: []domain.PharmacyOperations{shift("Friday", "20:00", "02:00")},
+			at:         local(domain.PharmacyTimezoneWIB, "2026-10-23 19:59"),
+
+			want: false,
+		},
+		{
+			name: "should keep an overnight shift running into a holiday",
+
+			timezone:   domain.PharmacyTimezoneWIB,
+			operations: []domain.PharmacyOperations{shift("Friday", "20:00", "02:00")},
+			holidays:   []domain.PharmacyHoliday{holiday("2026-10-24")},
+			at:         local(domain.PharmacyTimezoneWIB, "2026-10-24 01:30"),
+
+			want: true,
+		},
+		{
+			name: "should be closed on a weekday holiday",
+
+			timezone:   domain.PharmacyTimezoneWITA,
+			operations: []domain.PharmacyOperations{shift("Monday", "08:00", "17:00")},
+			holidays:   []domain.PharmacyHoliday{holiday("2026-10-19")},
+			at:         local(domain.PharmacyTimezoneWITA, "2026-10-19 10:00"),
+
+			want: false,
+		},
+		{
+			name: "should be open on the same weekday a week after the holiday",
+
+			timezone:   domain.PharmacyTimezoneWITA,
+			operations: []domain.PharmacyOperations{shift("Monday", "08:00", "17:00")},
+			holidays:   []domain.PharmacyHoliday{holiday("2026-10-19")},
+			at:         local(domain.PharmacyTimezoneWITA, "2026-10-26 10:00"),
+
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			oh, err := domain.NewOpeningHours(tt.timezone, tt.operations, tt.holidays)
+			assert.NoError(t, err)
+
+			// when
+			got := oh.IsOpenAt(tt.at)
+
+			// then
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOpeningHours_NextOpening(t *testing.T) {
+	weekdays := []domain.PharmacyOperations{
+		shift("Monday", "08:00", "17:00"),
+		shift("Tuesday", "08:00", "17:00"),
+	}
+
+	tests := []struct {
+		name string
+
+		timezone   string
+		operations []domain.PharmacyOperations
+		holidays   []domain.PharmacyHoliday
+		at         time.Time
+
+		want   time.Time
+		wantOk bool
+	}{
+		{
+			name: "should return the time itself when open",
+
+			timezone:   domain.PharmacyTimezoneWIB,
+			operations: weekdays,
+			at:         local(domain.PharmacyTimezoneWIB, "2026-10-19 09:00"),
+
+			want:   local(domain.PharmacyTimezoneWIB, "2026-10-19 09:00"),
+			wantOk: true,
+		},
+		{
+			name: "should return the start of today's shift before it opens",
+
+			timezone:   domain.PharmacyTimezoneWIT,
+			operations: weekdays,
+			at:         local(domain.PharmacyTimezoneWIT, "2026-10-19 06:00"),
+
+			want:   local(domain.PharmacyTimezoneWIT, "2026-10-19 08:00"),
+			wantOk: true,
+		},
+		{
+			name: "should skip a holiday to the next shift",
+
+			timezone:   domain.PharmacyTimezoneWITA,
+			operations: weekdays,
+			holidays:   []domain.PharmacyHoliday{holiday("2026-10-19")},
+			at:         local(domain.PharmacyTimezoneWITA, "2026-10-19 09:00"),
+
+			want:   local(domain.PharmacyTimezoneWITA, "2026-10-20 08:00"),
+			wantOk: true,
+		},
+		{
+			name: "should skip several holidays in a row",
+
+			timezone:   domain.PharmacyTimezoneWIB,
+			operations: weekdays,
+			holidays: []domain.PharmacyHoliday{
+				holiday("2026-10-19"),
+				holiday("2026-10-20"),
+			},
+			at: local(domain.PharmacyTimezoneWIB, "2026-10-18 12:00"),
+
+			want:   local(domain.PharmacyTimezoneWIB, "2026-10-26 08:00"),
+			wantOk: true,
+		},
+		{
+			name: "should report no opening without shifts",
+
+			timezone: domain.PharmacyTimezoneWIB,
+			at:       local(domain.PharmacyTimezoneWIB, "2026-10-19 09:00"),
+
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			oh, err := domain.NewOpeningHours(tt.timezone, tt.operations, tt.holidays)
+			assert.NoError(t, err)
+
+			// when
+			got, ok := oh.NextOpening(tt.at)
+
+			// then
+			assert.Equal(t, tt.wantOk, ok)
+			if tt.wantOk {
+				assert.True(t, tt.want.Equal(got), "want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -14,6 +14,12 @@ const (
 	OrderStatusCancelled           = "cancelled"
 )
 
+const (
+	OrderSortByOrderedAt = "ordered_at"
+	OrderSortByTotal     = "total"
+	OrderSortByNItems    = "n_items"
+)
+
 type Order struct {
 	ID int64
 
@@ -39,18 +45,29 @@ type Order struct {
 	Address    string
 	Coordinate Coordinate
 
-	NItems      int
-	Subtotal    int
-	ShipmentFee int
-	Total       int
+	// Total is Subtotal less Discount and VoucherDiscount plus ShipmentFee.
+	NItems          int
+	Subtotal        int
+	Discount        int
+	Voucher         *VoucherRef
+	VoucherDiscount int
+	ShipmentFee     int
+	Total           int
 
 	Status     string
 	OrderedAt  time.Time
 	FinishedAt *time.Time
 
+	// ProcessAt is set when the pharmacy was closed at checkout, the order
+	// waits for its next opening.
+	ProcessAt *time.Time
+
 	Items []OrderItem
 }
 
+// OrderItem is a product line of an order. BasePrice is the stock's price
+// when the order was placed and Discount what Promotion took off each unit,
+// Price is what each unit was sold for.
 type OrderItem struct {
 	ID int64
 
@@ -62,14 +79,22 @@ type OrderItem struct {
 		PhotoURL       string
 		Classification string
 	}
+	Variant *ProductVariantRef
+
+	BasePrice int
+	Discount  int
+	Promotion *PromotionRef
 
 	Price  int
 	Amount int
 }
 
 type Orders struct {
-	Orders []Order
-	Total  int
+	Orders          []Order
+	Discount        int
+	VoucherDiscount int
+	Total           int
+	Warnings        []DrugInteractionWarning
 }
 
 type OrderListDetails struct {
@@ -79,12 +104,16 @@ type OrderListDetails struct {
 	PharmacyManagerID *int64
 	Status            *string
 
+	Sorts   []SortField
+	Filters []Filter
+
 	Page  int
 	Limit int
 }
 
 type OrderItemCreateDetails struct {
 	ProductSlug string
+	VariantID   *int64
 	Amount      int
 }
 
@@ -96,6 +125,8 @@ type OrderCreateDetails struct {
 	Address    string
 	Coordinate Coordinate
 
+	VoucherCode *string
+
 	Items []OrderItemCreateDetails
 }
 
@@ -108,6 +139,7 @@ type OrderRepository interface {
 	Add(ctx context.Context, order Order) (Order, error)
 	UpdateStatusByID(ctx context.Context, id int64, status string) error
 	UpdateStatusByPaymentID(ctx context.Context, id int64, status string) error
+	CancelWaitingPaymentBefore(ctx context.Context, before time.Time) ([]int64, error)
 
 	ListItemsByOrderID(ctx context.Context, id int64) ([]OrderItem, error)
 	AddItem(ctx context.Context, item OrderItem) (OrderItem, error)
@@ -122,4 +154,5 @@ type OrderService interface {
 	SendOrder(ctx context.Context, id int64) error
 	FinishOrder(ctx context.Context, id int64) error
 	CancelOrder(ctx context.Context, id int64) error
+	CancelExpiredOrders(ctx context.Context) (int, error)
 }
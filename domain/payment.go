@@ -5,6 +5,11 @@ import (
 	"mime/multipart"
 )
 
+const (
+	PaymentSortByCreatedAt = "created_at"
+	PaymentSortByAmount    = "amount"
+)
+
 type Payment struct {
 	ID            int64
 	InvoiceNumber string
@@ -21,6 +26,9 @@ type PaymentListDetails struct {
 	IsConfirmed *bool
 	UserID      *int64
 
+	Sorts   []SortField
+	Filters []Filter
+
 	Page  int
 	Limit int
 }
@@ -12,6 +12,14 @@ const (
 	PharmacySortByDistance  = "distance"
 )
 
+// Pharmacies keep their opening hours in the local time of one of the
+// Indonesian timezones.
+const (
+	PharmacyTimezoneWIB  = "Asia/Jakarta"
+	PharmacyTimezoneWITA = "Asia/Makassar"
+	PharmacyTimezoneWIT  = "Asia/Jayapura"
+)
+
 type PharmacyShipmentMethods struct {
 	ID               int64
 	PharmacyID       int64
@@ -28,6 +36,25 @@ type PharmacyOperations struct {
 	EndTime   time.Time
 }
 
+// PharmacyHoliday closes a pharmacy for a whole local date.
+type PharmacyHoliday struct {
+	ID         int64
+	PharmacyID int64
+
+	Date   time.Time
+	Reason string
+}
+
+// PharmacyOpeningStatus is whether a pharmacy is open at At and, when it
+// isn't, when it opens next. NextOpening is nil when it doesn't open within
+// OpeningHoursLookaheadDays.
+type PharmacyOpeningStatus struct {
+	IsOpen      bool
+	At          time.Time
+	NextOpening *time.Time
+	Timezone    string
+}
+
 type Pharmacy struct {
 	ID                      int64
 	ManagerID               int64
@@ -38,6 +65,7 @@ type Pharmacy struct {
 	PharmacistName          string
 	PharmacistLicense       string
 	PharmacistPhone         string
+	Timezone                string
 	PharmacyOperations      []PharmacyOperations
 	PharmacyShipmentMethods []PharmacyShipmentMethods
 
@@ -54,6 +82,7 @@ type PharmacyStock struct {
 	PharmacistName          string
 	PharmacistLicense       string
 	PharmacistPhone         string
+	Timezone                string
 	PharmacyOperations      []PharmacyOperations
 	PharmacyShipmentMethods []PharmacyShipmentMethods
 	Stock                   Stock
@@ -70,6 +99,7 @@ type PharmacyCreateDetails struct {
 	PharmacistName          string
 	PharmacistLicense       string
 	PharmacistPhone         string
+	Timezone                string
 	PharmacyOperations      []PharmacyOperationCreateDetails
 	PharmacyShipmentMethods []PharmacyShipmentMethodsCreateDetails
 }
@@ -97,6 +127,7 @@ type PharmacyUpdateDetails struct {
 	PharmacistName    string
 	PharmacistLicense string
 	PharmacistPhone   string
+	Timezone          string
 }
 
 type PharmacyOperationsUpdateDetails struct {
@@ -123,14 +154,16 @@ type PharmaciesQuery struct {
 	EndTime     *string
 	ProductSlug *string
 	ProductId   *int64
+	VariantID   *int64
 	Longitude   *float64
 	Latitude    *float64
 	Name        *string
 	IsOpen      *bool
+	OpenAt      *time.Time
 	Page        int
 	Limit       int
-	SortBy      string
-	SortType    string
+	Sorts       []SortField
+	Filters     []Filter
 }
 
 type PharmacyRepository interface {
@@ -138,6 +171,7 @@ type PharmacyRepository interface {
 	GetBySlug(ctx context.Context, slug string) (Pharmacy, error)
 	GetPageInfo(ctx context.Context, query PharmaciesQuery) (PageInfo, error)
 	GetByID(ctx context.Context, id int64) (Pharmacy, error)
+	GetByIDAndLock(ctx context.Context, id int64) (Pharmacy, error)
 
 	Add(ctx context.Context, pharmacy PharmacyCreateDetails) (Pharmacy, error)
 	Update(ctx context.Context, pharmacy PharmacyUpdateDetails) (Pharmacy, error)
@@ -150,6 +184,12 @@ type PharmacyRepository interface {
 	UpdateOperation(ctx context.Context, pharmacyOperation PharmacyOperationsUpdateDetails) (PharmacyOperations, error)
 	SoftDeleteOperationByID(ctx context.Context, id int64) error
 
+	GetHolidaysByPharmacyId(ctx context.Context, id int64, from time.Time, to *time.Time) ([]PharmacyHoliday, error)
+	GetHolidayByID(ctx context.Context, id int64) (PharmacyHoliday, error)
+	IsHolidayExist(ctx context.Context, pharmacyID int64, date time.Time) (bool, error)
+	AddHoliday(ctx context.Context, holiday PharmacyHoliday) (PharmacyHoliday, error)
+	SoftDeleteHolidayByID(ctx context.Context, id int64) error
+
 	GetShipmentMethodsByPharmacyId(ctx context.Context, id int64) ([]PharmacyShipmentMethods, error)
 	GetShipmentMethodsByPharmacyIdAndLock(ctx context.Context, id int64) ([]PharmacyShipmentMethods, error)
 
@@ -169,6 +209,11 @@ type PharmacyService interface {
 	GetOperationsBySlug(ctx context.Context, slug string) ([]PharmacyOperations, error)
 	UpdateOperations(ctx context.Context, pharmacyOperation []PharmacyOperationsUpdateDetails) ([]PharmacyOperations, error)
 
+	GetOpeningStatus(ctx context.Context, slug string, at time.Time) (PharmacyOpeningStatus, error)
+	GetHolidaysBySlug(ctx context.Context, slug string) ([]PharmacyHoliday, error)
+	AddHoliday(ctx context.Context, slug string, holiday PharmacyHoliday) (PharmacyHoliday, error)
+	DeleteHoliday(ctx context.Context, slug string, id int64) error
+
 	GetShipmentMethodBySlug(ctx context.Context, slug string) ([]PharmacyShipmentMethods, error)
 	UpdateShipmentMethod(ctx context.Context, shipmentMethods []PharmacyShipmentMethodsUpdateDetails) ([]PharmacyShipmentMethods, error)
 }
@@ -7,6 +7,8 @@ import (
 
 const (
 	PharmacyManagerSortByCreatedAt     = "created_at"
+	PharmacyManagerSortByName          = "name"
+	PharmacyManagerSortByEmail         = "email"
 )
 
 type PharmacyManager struct {
@@ -24,8 +26,8 @@ type PharmacyManagerQuery struct {
 	Limit      int64
 	Level      int64
 	Term       string
-	SortBy     string
-	SortType   string
+	Sorts      []SortField
+	Filters    []Filter
 	ProfileSet *string
 }
 
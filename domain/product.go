@@ -3,9 +3,14 @@ package domain
 import (
 	"context"
 	"mime/multipart"
+	"time"
 )
 
-const ()
+const (
+	ProductStatusDraft        = "draft"
+	ProductStatusPublished    = "published"
+	ProductStatusDiscontinued = "discontinued"
+)
 
 type Product struct {
 	ID                int64
@@ -15,7 +20,34 @@ type Product struct {
 	KeyWord           string
 	ProductDetailId   int64
 	ProductCategoryId int64
-	IsActive          bool
+	Status            string
+	PublishAt         *time.Time
+	UnpublishAt       *time.Time
+
+	Images   []ProductImage
+	Variants []ProductVariant
+}
+
+// IsLive reports whether the product is published and inside its publishing
+// window at the given time. Only live products can be found and bought.
+func (p Product) IsLive(now time.Time) bool {
+	if p.Status != ProductStatusPublished {
+		return false
+	}
+	if p.PublishAt != nil && p.PublishAt.After(now) {
+		return false
+	}
+	if p.UnpublishAt != nil && !p.UnpublishAt.After(now) {
+		return false
+	}
+	return true
+}
+
+type ProductStateChange struct {
+	Slugs       []string
+	Status      string
+	PublishAt   *time.Time
+	UnpublishAt *time.Time
 }
 
 type ProductDetails struct {
@@ -38,6 +70,9 @@ type ProductDetails struct {
 type AddProductRequest struct {
 	Name              string
 	ProductCategoryId int64
+	Status            string
+	PublishAt         *time.Time
+	UnpublishAt       *time.Time
 
 	GenericName           string
 	Composition           string
@@ -78,22 +113,30 @@ type ProductsQuery struct {
 	Latitude     *float64
 	Longitude    *float64
 	Term         string
-	SortBy       string
-	SortType     string
+	Sorts        []SortField
+	Filters      []Filter
 	CategorySlug *string
 	CategoryID   *int64
+
+	// IncludeUnpublished lists products that are not live, Status then
+	// optionally narrows the list to a single stored status.
+	IncludeUnpublished bool
+	Status             string
+
+	TermSynonyms map[string][]string
 }
 
 const (
-	ProductSortById   = "id"
-	ProductSortByName = "name"
+	ProductSortById        = "id"
+	ProductSortByName      = "name"
+	ProductSortBySlug      = "slug"
+	ProductSortByRelevance = "relevance"
 )
 
 func DefaultProductsQuery() ProductsQuery {
 	return ProductsQuery{
-		Page:     1,
-		SortBy:   ProductSortById,
-		SortType: "ASC",
+		Page:  1,
+		Sorts: []SortField{{Field: ProductSortById}},
 	}
 }
 
@@ -104,11 +147,19 @@ type ProductRepository interface {
 	GetProducts(ctx context.Context, query ProductsQuery) ([]Product, error)
 	GetProductsFromArea(ctx context.Context, query ProductsQuery) ([]Product, error)
 	GetPageInfoFromArea(ctx context.Context, query ProductsQuery) (PageInfo, error)
+	GetCategoryFacets(ctx context.Context, query ProductsQuery) ([]ProductCategoryFacet, error)
+	GetCategoryFacetsFromArea(ctx context.Context, query ProductsQuery) ([]ProductCategoryFacet, error)
 	GetBySlug(ctx context.Context, slug string) (Product, error)
+	GetBySlugAndLock(ctx context.Context, slug string) (Product, error)
 	Add(ctx context.Context, product Product) (Product, error)
 	Update(ctx context.Context, product Product) (Product, error)
+	UpdatePicture(ctx context.Context, id int64, picture *string) error
+	BulkUpdateStatus(ctx context.Context, ids []int64, change ProductStateChange) ([]Product, error)
+	GetNextPublishingChange(ctx context.Context) (*time.Time, error)
 	SoftDeleteBySlug(ctx context.Context, slug string) error
 	BulkSoftDeleteBySlug(ctx context.Context, slugs []string) error
+	GetCatalog(ctx context.Context) ([]ProductCatalogEntry, error)
+	GetSuggestionSources(ctx context.Context) ([]ProductSuggestionSource, error)
 }
 
 type ProductDetailsRepository interface {
@@ -119,9 +170,35 @@ type ProductDetailsRepository interface {
 
 type ProductService interface {
 	GetProduct(ctx context.Context, slug string) (Product, ProductDetails, CategoryWithParentName, error)
-	GetProducts(ctx context.Context, query ProductsQuery) ([]Product, PageInfo, error)
-	GetProductLocation(ctx context.Context, query ProductsQuery) ([]Product, PageInfo, error)
+	GetProducts(ctx context.Context, query ProductsQuery) ([]Product, []ProductCategoryFacet, PageInfo, error)
+	GetProductLocation(ctx context.Context, query ProductsQuery) ([]Product, []ProductCategoryFacet, PageInfo, error)
 	CreateProduct(ctx context.Context, request AddProductRequest, file *multipart.File) (Product, error)
 	DeleteProducts(ctx context.Context, slug string) error
 	UpdateProduct(ctx context.Context, slug string, request UpdateProductRequest, file *multipart.File) (Product, error)
+	ImportProducts(ctx context.Context, rows []ProductImportRow) (ProductImportResult, error)
+	ExportCatalog(ctx context.Context, format string) (ExportFile, error)
+	ChangeStates(ctx context.Context, change ProductStateChange) ([]Product, error)
+}
+
+type ProductCatalogEntry struct {
+	Product      Product
+	Details      ProductDetails
+	CategorySlug string
+}
+
+type ProductImportRow struct {
+	Line         int
+	CategorySlug string
+	Product      AddProductRequest
+}
+
+type ProductImportRowError struct {
+	Line    int
+	Message string
+}
+
+type ProductImportResult struct {
+	Created int
+	Updated int
+	Errors  []ProductImportRowError
 }
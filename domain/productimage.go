@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"context"
+	"mime/multipart"
+)
+
+type ProductImage struct {
+	ID        int64
+	ProductID int64
+
+	URL               string
+	PublicID          string
+	ThumbnailURL      string
+	ThumbnailPublicID string
+	Width             int
+	Height            int
+
+	Position  int
+	IsPrimary bool
+}
+
+type ProductImageRepository interface {
+	ListByProductID(ctx context.Context, productID int64) ([]ProductImage, error)
+	GetByID(ctx context.Context, id int64) (ProductImage, error)
+	CountByProductID(ctx context.Context, productID int64) (int, error)
+
+	Add(ctx context.Context, img ProductImage) (ProductImage, error)
+	Update(ctx context.Context, img ProductImage) (ProductImage, error)
+	SoftDeleteByID(ctx context.Context, id int64) error
+}
+
+type ProductImageService interface {
+	List(ctx context.Context, slug string) ([]ProductImage, error)
+	Add(ctx context.Context, slug string, file multipart.File) (ProductImage, error)
+	Reorder(ctx context.Context, slug string, imageIDs []int64) ([]ProductImage, error)
+	SetPrimary(ctx context.Context, slug string, id int64) ([]ProductImage, error)
+	Delete(ctx context.Context, slug string, id int64) error
+}
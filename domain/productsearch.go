@@ -0,0 +1,31 @@
+package domain
+
+import "context"
+
+type ProductSynonym struct {
+	ID      int64
+	Term    string
+	Synonym string
+}
+
+type ProductCategoryFacet struct {
+	CategoryID int64
+	Name       string
+	Slug       string
+	Count      int64
+}
+
+type ProductSynonymRepository interface {
+	List(ctx context.Context) ([]ProductSynonym, error)
+	GetByWords(ctx context.Context, words []string) ([]ProductSynonym, error)
+	IsExist(ctx context.Context, term string, synonym string) (bool, error)
+
+	Add(ctx context.Context, s ProductSynonym) (ProductSynonym, error)
+	SoftDeleteByID(ctx context.Context, id int64) error
+}
+
+type ProductSynonymService interface {
+	List(ctx context.Context) ([]ProductSynonym, error)
+	Create(ctx context.Context, s ProductSynonym) (ProductSynonym, error)
+	Delete(ctx context.Context, id int64) error
+}
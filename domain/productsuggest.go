@@ -0,0 +1,26 @@
+package domain
+
+import "context"
+
+type ProductSuggestionSource struct {
+	Name        string
+	Slug        string
+	GenericName string
+}
+
+type ProductSuggestion struct {
+	Name string
+	Slug string
+}
+
+type ProductSuggestions struct {
+	Products     []ProductSuggestion
+	Categories   []ProductSuggestion
+	GenericNames []string
+}
+
+type ProductSuggestService interface {
+	Suggest(ctx context.Context, query string, limit int) (ProductSuggestions, error)
+	Rebuild(ctx context.Context) error
+	Invalidate()
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "context"
+
+type ProductVariant struct {
+	ID        int64
+	ProductID int64
+
+	Name        string
+	Strength    string
+	PackSize    int
+	SellingUnit string
+	Form        string
+}
+
+type ProductVariantRef struct {
+	ID   int64
+	Name string
+}
+
+type ProductVariantRepository interface {
+	ListByProductID(ctx context.Context, productID int64) ([]ProductVariant, error)
+	GetByID(ctx context.Context, id int64) (ProductVariant, error)
+	IsExistByProductIDAndName(ctx context.Context, productID int64, name string) (bool, error)
+
+	Add(ctx context.Context, v ProductVariant) (ProductVariant, error)
+	Update(ctx context.Context, v ProductVariant) (ProductVariant, error)
+	SoftDeleteByID(ctx context.Context, id int64) error
+}
+
+type ProductVariantService interface {
+	List(ctx context.Context, slug string) ([]ProductVariant, error)
+	Create(ctx context.Context, slug string, v ProductVariant) (ProductVariant, error)
+	Update(ctx context.Context, slug string, v ProductVariant) (ProductVariant, error)
+	Delete(ctx context.Context, slug string, id int64) error
+}
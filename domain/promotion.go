@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	PromotionDiscountPercentage = "percentage"
+	PromotionDiscountFixed      = "fixed"
+
+	PromotionScopeProduct  = "product"
+	PromotionScopeCategory = "category"
+	PromotionScopePharmacy = "pharmacy"
+)
+
+// Promotion is a discount on the unit price of the products in its scope,
+// valid from StartsAt until EndsAt. A category promotion also covers the
+// subcategories. UsageCount is the number of order items it was applied to
+// and a nil UsageLimit leaves it uncapped.
+type Promotion struct {
+	ID   int64
+	Name string
+
+	DiscountType  string
+	DiscountValue int
+
+	Scope      string
+	ProductID  *int64
+	CategoryID *int64
+	PharmacyID *int64
+
+	StartsAt time.Time
+	EndsAt   *time.Time
+
+	UsageLimit *int
+	UsageCount int
+}
+
+// Discount is what the promotion takes off a unit priced at price, never
+// more than the price itself.
+func (p Promotion) Discount(price int) int {
+	d := p.DiscountValue
+	if p.DiscountType == PromotionDiscountPercentage {
+		d = price * p.DiscountValue / 100
+	}
+	if d > price {
+		return price
+	}
+	return d
+}
+
+// IsAvailable reports whether the promotion can still be applied n more
+// times.
+func (p Promotion) IsAvailable(n int) bool {
+	return p.UsageLimit == nil || p.UsageCount+n <= *p.UsageLimit
+}
+
+type PromotionRef struct {
+	ID   int64
+	Name string
+}
+
+// PromotionTarget is what an order item is matched against.
+type PromotionTarget struct {
+	ProductID  int64
+	CategoryID int64
+	PharmacyID int64
+}
+
+type PromotionListDetails struct {
+	Scope      *string
+	ActiveOnly bool
+
+	Page  int
+	Limit int
+}
+
+type PromotionRepository interface {
+	GetPageInfo(ctx context.Context, det PromotionListDetails) (PageInfo, error)
+	List(ctx context.Context, det PromotionListDetails) ([]Promotion, error)
+	GetByID(ctx context.Context, id int64) (Promotion, error)
+	GetByIDAndLock(ctx context.Context, id int64) (Promotion, error)
+	ListApplicable(ctx context.Context, t PromotionTarget, at time.Time) ([]Promotion, error)
+
+	Add(ctx context.Context, p Promotion) (Promotion, error)
+	Update(ctx context.Context, p Promotion) (Promotion, error)
+	IncrementUsage(ctx context.Context, id int64) error
+	ReleaseUsageByOrderIDs(ctx context.Context, orderIDs []int64) error
+	SoftDeleteByID(ctx context.Context, id int64) error
+}
+
+type PromotionService interface {
+	List(ctx context.Context, det PromotionListDetails) ([]Promotion, PageInfo, error)
+	GetByID(ctx context.Context, id int64) (Promotion, error)
+
+	Create(ctx context.Context, p Promotion) (Promotion, error)
+	Update(ctx context.Context, p Promotion) (Promotion, error)
+	Delete(ctx context.Context, id int64) error
+}
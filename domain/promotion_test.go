@@ -0,0 +1,143 @@
+package domain_test
+
+import (
+	"medichat-be/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromotion_Discount(t *testing.T) {
+	tests := []struct {
+		name string
+
+		discountType  string
+		discountValue int
+		price         int
+
+		want int
+	}{
+		{
+			name: "should take the percentage off the price",
+
+			discountType:  domain.PromotionDiscountPercentage,
+			discountValue: 10,
+			price:         25000,
+
+			want: 2500,
+		},
+		{
+			name: "should round a percentage discount down",
+
+			discountType:  domain.PromotionDiscountPercentage,
+			discountValue: 15,
+			price:         999,
+
+			want: 149,
+		},
+		{
+			name: "should give nothing when the percentage rounds to zero",
+
+			discountType:  domain.PromotionDiscountPercentage,
+			discountValue: 1,
+			price:         99,
+
+			want: 0,
+		},
+		{
+			name: "should take the whole price at 100 percent",
+
+			discountType:  domain.PromotionDiscountPercentage,
+			discountValue: 100,
+			price:         12500,
+
+			want: 12500,
+		},
+		{
+			name: "should take a fixed amount off the price",
+
+			discountType:  domain.PromotionDiscountFixed,
+			discountValue: 2000,
+			price:         12500,
+
+			want: 2000,
+		},
+		{
+			name: "should cap a fixed discount at the price",
+
+			discountType:  domain.PromotionDiscountFixed,
+			discountValue: 5000,
+			price:         3000,
+
+			want: 3000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			p := domain.Promotion{DiscountType: tt.discountType, DiscountValue: tt.discountValue}
+
+			// when
+			got := p.Discount(tt.price)
+
+			// then
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPromotion_IsAvailable(t *testing.T) {
+	limit := 5
+
+	tests := []struct {
+		name string
+
+		usageLimit *int
+		usageCount int
+		n          int
+
+		want bool
+	}{
+		{
+			name: "should always be available without a limit",
+
+			usageLimit: nil,
+			usageCount: 1000,
+			n:          1,
+
+			want: true,
+		},
+		{
+			name: "should be available up to the limit",
+
+			usageLimit: &limit,
+			usageCount: 3,
+			n:          2,
+
+			want: true,
+		},
+		{
+			name: "should not be available past the limit",
+
+			usageLimit: &limit,
+			usageCount: 3,
+			n:          3,
+
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			p := domain.Promotion{UsageLimit: tt.usageLimit, UsageCount: tt.usageCount}
+
+			// when
+			got := p.IsAvailable(tt.n)
+
+			// then
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "context"
+
+type RelatedProducts struct {
+	FrequentlyBoughtTogether []Product
+	Alternatives             []Product
+	BestSellers              []Product
+}
+
+type RelatedProductsQuery struct {
+	Latitude  float64
+	Longitude float64
+	Limit     int64
+}
+
+type RelatedProductRepository interface {
+	// ReplaceAssociations recomputes every co-purchase association score
+	// from finished orders, dropping the previous scores.
+	ReplaceAssociations(ctx context.Context, minSupport int64) (int64, error)
+
+	GetFrequentlyBoughtTogether(ctx context.Context, product Product, query RelatedProductsQuery) ([]Product, error)
+	GetAlternatives(ctx context.Context, product Product, genericName string, query RelatedProductsQuery) ([]Product, error)
+	GetCategoryBestSellers(ctx context.Context, product Product, query RelatedProductsQuery) ([]Product, error)
+}
+
+type RelatedProductService interface {
+	GetRelated(ctx context.Context, slug string, query RelatedProductsQuery) (RelatedProducts, error)
+	ComputeAssociations(ctx context.Context) (int64, error)
+}
@@ -1,17 +1,70 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"mime/multipart"
+)
 
 type Specialization struct {
-	ID   int64
-	Name string
+	ID             int64
+	Name           string
+	Slug           string
+	Description    string
+	IconURL        *string
+	CommissionRate float64
+}
+
+type SpecializationWithDoctorCount struct {
+	Specialization Specialization
+	DoctorCount    int
+}
+
+type SpecializationCreateDetails struct {
+	Name           string
+	Description    string
+	CommissionRate float64
+	Icon           multipart.File
+}
+
+type SpecializationUpdateDetails struct {
+	ID             int64
+	Name           *string
+	Description    *string
+	CommissionRate *float64
+	Icon           multipart.File
+}
+
+func (s *Specialization) ApplyUpdate(det SpecializationUpdateDetails) {
+	if det.Name != nil {
+		s.Name = *det.Name
+	}
+	if det.Description != nil {
+		s.Description = *det.Description
+	}
+	if det.CommissionRate != nil {
+		s.CommissionRate = *det.CommissionRate
+	}
 }
 
 type SpecializationRepository interface {
 	GetAll(ctx context.Context) ([]Specialization, error)
+	GetAllWithDoctorCount(ctx context.Context) ([]SpecializationWithDoctorCount, error)
 	GetByID(ctx context.Context, id int64) (Specialization, error)
+	GetByIDAndLock(ctx context.Context, id int64) (Specialization, error)
+	GetBySlug(ctx context.Context, slug string) (Specialization, error)
+	IsExistBySlug(ctx context.Context, slug string) (bool, error)
+
+	Add(ctx context.Context, s Specialization) (Specialization, error)
+	Update(ctx context.Context, s Specialization) (Specialization, error)
+	SoftDeleteByID(ctx context.Context, id int64) error
 }
 
 type SpecializationService interface {
-	GetAll(ctx context.Context) ([]Specialization, error)
+	GetAll(ctx context.Context) ([]SpecializationWithDoctorCount, error)
+	GetBySlug(ctx context.Context, slug string) (Specialization, error)
+
+	Create(ctx context.Context, det SpecializationCreateDetails) (Specialization, error)
+	Update(ctx context.Context, det SpecializationUpdateDetails) (Specialization, error)
+	Delete(ctx context.Context, id int64) error
+	UpdateCommissionRate(ctx context.Context, id int64, rate float64) (Specialization, error)
 }
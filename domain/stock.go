@@ -9,6 +9,8 @@ const (
 	StockMutationAutomatic = "automatic"
 	StockMutationManual    = "manual"
 
+	StockMutationReplenishment = "replenishment"
+
 	StockMutationStatusApproved  = "approved"
 	StockMutationStatusPending   = "pending"
 	StockMutationStatusCancelled = "cancelled"
@@ -20,16 +22,259 @@ const (
 
 	StockSortBySourcePharmacyName = "source_pharmacy_name"
 	StockSortByTargetPharmacyName = "target_pharmacy_name"
+	StockSortByCreatedAt          = "created_at"
+	StockSortByAvailable          = "available"
+	StockSortByMinStock           = "min_stock"
+)
+
+const (
+	StockLedgerOpening     = "opening"
+	StockLedgerSale        = "sale"
+	StockLedgerTransferIn  = "transfer-in"
+	StockLedgerTransferOut = "transfer-out"
+	StockLedgerAdjustment  = "manual-adjustment"
+	StockLedgerWriteOff    = "write-off"
+	StockLedgerReturn      = "return"
+	StockLedgerReceipt     = "receipt"
+	StockLedgerImport      = "import"
+	StockLedgerStockTake   = "stock-take"
 )
 
+const (
+	StockImportModeSet    = "set"
+	StockImportModeAdjust = "adjust"
+)
+
+const (
+	StockPriceStatusApplied   = "applied"
+	StockPriceStatusScheduled = "scheduled"
+	StockPriceStatusCancelled = "cancelled"
+)
+
+const (
+	StockReservationStatusActive    = "active"
+	StockReservationStatusReleased  = "released"
+	StockReservationStatusCommitted = "committed"
+)
+
+// Stock is a product held by a pharmacy. Once its available quantity falls
+// below MinStock it is low and replenishment tops it back up to
+// ReorderLevel. A zero MinStock turns replenishment off.
 type Stock struct {
 	ID int64
 
 	ProductID  int64
+	VariantID  *int64
 	PharmacyID int64
 
 	Stock int
 	Price int
+
+	MinStock     int
+	ReorderLevel int
+}
+
+// StockPrice is an entry in the price history of a stock. Applied entries
+// are the prices the stock has had, a scheduled entry becomes the stock's
+// price once EffectiveAt passes.
+type StockPrice struct {
+	ID int64
+
+	StockID int64
+	Price   int
+	Status  string
+	ActorID *int64
+
+	EffectiveAt time.Time
+	CreatedAt   time.Time
+}
+
+type StockPriceScheduleDetail struct {
+	StockID     int64
+	Price       int
+	EffectiveAt time.Time
+}
+
+// StockBatch is a received lot of a stock. The stock's own amount is the
+// total on hand, quantity received before batches were tracked has no batch
+// and never expires.
+type StockBatch struct {
+	ID int64
+
+	StockID int64
+
+	LotNumber  string
+	ExpiryDate time.Time
+	CostPrice  int
+	Quantity   int
+
+	ReceivedAt time.Time
+}
+
+// IsExpired reports whether the batch can no longer be sold at now. A batch
+// is still sellable on its expiry date.
+func (b StockBatch) IsExpired(now time.Time) bool {
+	y, m, d := now.Date()
+	return b.ExpiryDate.Before(time.Date(y, m, d, 0, 0, 0, 0, b.ExpiryDate.Location()))
+}
+
+type StockBatchJoined struct {
+	StockBatch
+
+	Product struct {
+		ID   int64
+		Slug string
+		Name string
+	}
+	Variant  *ProductVariantRef
+	Pharmacy struct {
+		ID   int64
+		Slug string
+		Name string
+	}
+}
+
+type StockBatchCreateDetail struct {
+	StockID int64
+
+	LotNumber  string
+	ExpiryDate time.Time
+	CostPrice  int
+	Quantity   int
+}
+
+type ExpiringStockQuery struct {
+	Days         int
+	PharmacySlug *string
+	ManagerID    *int64
+}
+
+// StockLedgerEntry is one change to a stock's amount. Entries are only ever
+// appended. The first entry of a stock is an opening balance, so replaying
+// Change from there rebuilds every BalanceAfter. ReferenceID points at the
+// order, mutation or batch behind the change, depending on Reason.
+type StockLedgerEntry struct {
+	ID int64
+
+	StockID     int64
+	Reason      string
+	ReferenceID *int64
+	ActorID     *int64
+
+	Change       int
+	BalanceAfter int
+
+	CreatedAt time.Time
+}
+
+type StockLedgerQuery struct {
+	StockID int64
+
+	Page  int
+	Limit int
+}
+
+// StockLedgerCheck compares a stock's amount with the balance rebuilt from
+// its ledger. MismatchEntryID is the first entry whose recorded balance
+// doesn't follow from the ones before it.
+type StockLedgerCheck struct {
+	StockID int64
+
+	Balance        int
+	RebuiltBalance int
+	EntryCount     int
+
+	MismatchEntryID *int64
+}
+
+func (c StockLedgerCheck) IsConsistent() bool {
+	return c.Balance == c.RebuiltBalance && c.MismatchEntryID == nil
+}
+
+// StockImportRow sets, or with the adjust mode adds to, the quantity and
+// price of the stock of a product at a pharmacy. A nil Quantity or Price is
+// left as it is.
+type StockImportRow struct {
+	Line int
+
+	PharmacySlug string
+	ProductSlug  string
+	VariantID    *int64
+
+	Mode     string
+	Quantity *int
+	Price    *int
+}
+
+// StockAllocation is the part of a shortfall one source covers.
+type StockAllocation struct {
+	Source StockSurplus
+	Amount int
+}
+
+type StockImportRowError struct {
+	Line    int
+	Message string
+}
+
+type StockImportDetail struct {
+	FileName string
+	DryRun   bool
+	Rows     []StockImportRow
+}
+
+// StockImportChange is what a row does to its stock. StockID is nil when
+// the row creates the stock.
+type StockImportChange struct {
+	Line    int
+	StockID *int64
+
+	PharmacySlug string
+	ProductSlug  string
+	VariantID    *int64
+
+	OldStock int
+	NewStock int
+	OldPrice int
+	NewPrice int
+}
+
+// StockImport records an applied file, ledger entries of the changes it made
+// point back to it.
+type StockImport struct {
+	ID int64
+
+	ManagerID int64
+	FileName  string
+	Created   int
+	Updated   int
+
+	CreatedAt time.Time
+}
+
+// StockImportResult has a nil ImportID when nothing was written.
+type StockImportResult struct {
+	ImportID *int64
+
+	Created   int
+	Updated   int
+	Unchanged int
+
+	Changes []StockImportChange
+	Errors  []StockImportRowError
+}
+
+// StockReservation holds stock for an order item between placing the
+// order and sending it, so the same units can't be sold twice.
+type StockReservation struct {
+	ID int64
+
+	StockID     int64
+	OrderID     int64
+	OrderItemID int64
+
+	Amount int
+	Status string
 }
 
 type StockJoined struct {
@@ -40,22 +285,51 @@ type StockJoined struct {
 		Slug string
 		Name string
 	}
+	Variant  *ProductVariantRef
 	Pharmacy struct {
 		ID   int64
 		Slug string
 		Name string
 	}
 
-	Stock int
-	Price int
+	Stock    int
+	Reserved int
+	Expired  int
+	Price    int
+
+	MinStock     int
+	ReorderLevel int
+}
+
+// Available is the quantity that can still be sold, on hand minus reserved
+// and expired.
+func (s StockJoined) Available() int {
+	return s.Stock - s.Reserved - s.Expired
+}
+
+func (s StockJoined) IsLow() bool {
+	return s.Available() < s.MinStock
+}
+
+// StockSurplus is a stock that can give Surplus units away without going
+// below its own threshold, Distance meters from the pharmacy asking for them.
+type StockSurplus struct {
+	Stock Stock
+
+	Surplus  int
+	Distance float64
 }
 
 type StockCreateDetail struct {
 	ProductSlug  string
+	VariantID    *int64
 	PharmacySlug string
 
 	Stock int
 	Price int
+
+	MinStock     int
+	ReorderLevel int
 }
 
 type StockUpdateDetail struct {
@@ -63,6 +337,9 @@ type StockUpdateDetail struct {
 
 	Stock *int
 	Price *int
+
+	MinStock     *int
+	ReorderLevel *int
 }
 
 type StockMutation struct {
@@ -99,6 +376,7 @@ type StockMutationJoined struct {
 		Slug string
 		Name string
 	}
+	Variant *ProductVariantRef
 
 	Method string
 	Status string
@@ -112,18 +390,21 @@ type StockTransferRequest struct {
 	SourcePharmacySlug string
 	TargetPharmacySlug string
 	ProductSlug        string
+	VariantID          *int64
 	Amount             int
 }
 
 type StockListDetails struct {
 	ProductSlug  *string
+	VariantID    *int64
 	ProductName  *string
 	PharmacySlug *string
+	LowOnly      bool
 
 	ManagerID *int64
 
-	SortBy  string
-	SortAsc bool
+	Sorts   []SortField
+	Filters []Filter
 
 	Page  int
 	Limit int
@@ -141,8 +422,8 @@ type StockMutationListDetails struct {
 	Method *string
 	Status *string
 
-	SortBy  string
-	SortAsc bool
+	Sorts   []SortField
+	Filters []Filter
 
 	Page  int
 	Limit int
@@ -150,7 +431,9 @@ type StockMutationListDetails struct {
 
 type StockRepository interface {
 	GetByID(ctx context.Context, id int64) (Stock, error)
-	GetByPharmacyAndProduct(ctx context.Context, pharmacy_id int64, product_id int64) (Stock, error)
+	GetByPharmacyAndProduct(ctx context.Context, pharmacy_id int64, product_id int64, variant_id *int64) (Stock, error)
+	IsExistByVariantID(ctx context.Context, variantID int64) (bool, error)
+	IsExistWithoutVariantByProductID(ctx context.Context, productID int64) (bool, error)
 	GetByIDAndLock(ctx context.Context, id int64) (Stock, error)
 	GetPageInfo(ctx context.Context, det StockListDetails) (PageInfo, error)
 	List(ctx context.Context, det StockListDetails) ([]StockJoined, error)
@@ -161,6 +444,7 @@ type StockRepository interface {
 
 	GetMutationByID(ctx context.Context, id int64) (StockMutation, error)
 	GetMutationByIDAndLock(ctx context.Context, id int64) (StockMutation, error)
+	IsPendingMutationExistByTargetID(ctx context.Context, targetID int64) (bool, error)
 	GetMutationPageInfo(ctx context.Context, det StockMutationListDetails) (PageInfo, error)
 	ListMutations(ctx context.Context, det StockMutationListDetails) ([]StockMutationJoined, error)
 
@@ -168,7 +452,39 @@ type StockRepository interface {
 	UpdateMutation(ctx context.Context, s StockMutation) (StockMutation, error)
 	SoftDeleteMutationByID(ctx context.Context, id int64) error
 
-	GetNearestStockWithProduct(ctx context.Context, targetPharmacyID int64, productID int64, amount int) (Stock, error)
+	ListNearestSpareStocksAndLock(ctx context.Context, target Stock, limit int) ([]StockSurplus, error)
+	ListLowStocksToReplenish(ctx context.Context) ([]Stock, error)
+	AddImport(ctx context.Context, i StockImport) (StockImport, error)
+	GetNearestSurplusStock(ctx context.Context, target Stock) (StockSurplus, error)
+
+	GetReservedAmount(ctx context.Context, stockID int64) (int, error)
+	AddReservation(ctx context.Context, r StockReservation) (StockReservation, error)
+	UpdateReservationStatusByOrderIDs(ctx context.Context, orderIDs []int64, from string, to string) error
+
+	AddPrice(ctx context.Context, p StockPrice) (StockPrice, error)
+	ListPricesByStockID(ctx context.Context, stockID int64) ([]StockPrice, error)
+	GetPriceByIDAndLock(ctx context.Context, id int64) (StockPrice, error)
+	GetDueScheduledPrice(ctx context.Context, stockID int64, at time.Time) (StockPrice, error)
+	ListDueScheduledPricesAndLock(ctx context.Context, at time.Time) ([]StockPrice, error)
+	UpdatePriceStatus(ctx context.Context, id int64, status string) error
+
+	GetExpiredAmount(ctx context.Context, stockID int64) (int, error)
+	GetBatchedAmount(ctx context.Context, stockID int64) (int, error)
+	ListBatchesByStockID(ctx context.Context, stockID int64) ([]StockBatch, error)
+	ListSellableBatchesAndLock(ctx context.Context, stockID int64) ([]StockBatch, error)
+	ListExpiredBatchesAndLock(ctx context.Context, stockID int64) ([]StockBatch, error)
+	ListExpiringBatches(ctx context.Context, q ExpiringStockQuery) ([]StockBatchJoined, error)
+	AddBatch(ctx context.Context, b StockBatch) (StockBatch, error)
+	UpdateBatchQuantity(ctx context.Context, id int64, quantity int) error
+
+	HasLedger(ctx context.Context, stockID int64) (bool, error)
+	AddLedgerEntry(ctx context.Context, e StockLedgerEntry) (StockLedgerEntry, error)
+	GetLedgerPageInfo(ctx context.Context, q StockLedgerQuery) (PageInfo, error)
+	ListLedger(ctx context.Context, q StockLedgerQuery) ([]StockLedgerEntry, error)
+	ListAllLedgerByStockID(ctx context.Context, stockID int64) ([]StockLedgerEntry, error)
+	GetLastLedgerID(ctx context.Context) (int64, error)
+	SumLedgerChange(ctx context.Context, stockID int64, afterID int64, to time.Time) (int, error)
+	ListLedgerMismatches(ctx context.Context) ([]StockLedgerCheck, error)
 }
 
 type StockService interface {
@@ -185,4 +501,22 @@ type StockService interface {
 	RequestStockTransfer(ctx context.Context, r StockTransferRequest) (StockMutation, error)
 	ApproveStockTransfer(ctx context.Context, id int64) (StockMutation, error)
 	CancelStockTransfer(ctx context.Context, id int64) (StockMutation, error)
+
+	ReceiveBatch(ctx context.Context, det StockBatchCreateDetail) (StockBatch, error)
+	ListBatches(ctx context.Context, stockID int64) ([]StockBatch, error)
+	ListExpiringBatches(ctx context.Context, q ExpiringStockQuery) ([]StockBatchJoined, error)
+	WriteOffExpired(ctx context.Context, stockID int64) (Stock, error)
+
+	ListLedger(ctx context.Context, q StockLedgerQuery) ([]StockLedgerEntry, PageInfo, error)
+	CheckLedger(ctx context.Context, stockID int64) (StockLedgerCheck, error)
+	CheckAllLedgers(ctx context.Context) ([]StockLedgerCheck, error)
+
+	ProposeReplenishments(ctx context.Context) ([]StockMutation, error)
+
+	ListPrices(ctx context.Context, stockID int64) ([]StockPrice, error)
+	SchedulePrice(ctx context.Context, det StockPriceScheduleDetail) (StockPrice, error)
+	CancelScheduledPrice(ctx context.Context, stockID int64, priceID int64) (StockPrice, error)
+	ApplyScheduledPrices(ctx context.Context) (int, error)
+
+	ImportStocks(ctx context.Context, det StockImportDetail) (StockImportResult, error)
 }
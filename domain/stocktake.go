@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	StockTakeStatusOpen      = "open"
+	StockTakeStatusClosed    = "closed"
+	StockTakeStatusCancelled = "cancelled"
+)
+
+// StockTake is a physical count of a pharmacy's stock. Opening it takes a
+// snapshot of what every stock should hold, closing it adjusts the stocks to
+// what was counted. LastLedgerID is the last ledger entry the snapshot
+// includes.
+type StockTake struct {
+	ID int64
+
+	PharmacyID   int64
+	ManagerID    int64
+	Status       string
+	LastLedgerID int64
+
+	OpenedAt time.Time
+	ClosedAt *time.Time
+}
+
+// StockTakeItem is one stock in a stock-take. Expected is the snapshot taken
+// at opening and Movement the net change the ledger recorded between the
+// snapshot and the count, mostly sales. Variance is what the count differs
+// from the two together.
+type StockTakeItem struct {
+	ID int64
+
+	StockTakeID int64
+	StockID     int64
+
+	Expected  int
+	Counted   *int
+	CountedAt *time.Time
+
+	Movement int
+	Variance int
+}
+
+func (i StockTakeItem) ExpectedAtCount() int {
+	return i.Expected + i.Movement
+}
+
+type StockTakeItemJoined struct {
+	StockTakeItem
+
+	Product struct {
+		ID   int64
+		Slug string
+		Name string
+	}
+	Variant *ProductVariantRef
+}
+
+// StockTakeReport is a stock-take with its variances. Gain and Loss add up
+// the positive and negative variances.
+type StockTakeReport struct {
+	StockTake StockTake
+	Items     []StockTakeItemJoined
+
+	Counted   int
+	Uncounted int
+	Gain      int
+	Loss      int
+}
+
+type StockTakeCount struct {
+	StockID int64
+	Counted int
+}
+
+type StockTakeCountDetail struct {
+	StockTakeID int64
+	Counts      []StockTakeCount
+}
+
+type StockTakeRepository interface {
+	GetByID(ctx context.Context, id int64) (StockTake, error)
+	GetByIDAndLock(ctx context.Context, id int64) (StockTake, error)
+	IsOpenExistByPharmacyID(ctx context.Context, pharmacyID int64) (bool, error)
+	ListByPharmacyID(ctx context.Context, pharmacyID int64) ([]StockTake, error)
+
+	Add(ctx context.Context, t StockTake) (StockTake, error)
+	Update(ctx context.Context, t StockTake) (StockTake, error)
+
+	AddSnapshot(ctx context.Context, stockTakeID int64, pharmacyID int64) (int64, error)
+	ListItems(ctx context.Context, stockTakeID int64) ([]StockTakeItemJoined, error)
+	GetItemByStockIDAndLock(ctx context.Context, stockTakeID int64, stockID int64) (StockTakeItem, error)
+	UpdateItemCount(ctx context.Context, id int64, counted int) error
+	UpdateItemResult(ctx context.Context, i StockTakeItem) error
+}
+
+type StockTakeService interface {
+	Open(ctx context.Context, pharmacySlug string) (StockTake, error)
+	List(ctx context.Context, pharmacySlug string) ([]StockTake, error)
+	GetReport(ctx context.Context, id int64) (StockTakeReport, error)
+	SubmitCounts(ctx context.Context, det StockTakeCountDetail) (StockTakeReport, error)
+	Close(ctx context.Context, id int64) (StockTakeReport, error)
+	Cancel(ctx context.Context, id int64) (StockTake, error)
+}
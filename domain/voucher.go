@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	VoucherTypePercentage   = "percentage"
+	VoucherTypeFixed        = "fixed"
+	VoucherTypeFreeShipping = "free-shipping"
+)
+
+// Voucher is a code a user enters for an order. A percentage or fixed
+// voucher takes Value off the order's items after promotions, a free
+// shipping voucher takes off the shipment fee. It only applies from
+// MinSpend on and, with a PharmacyID, only at that pharmacy. A nil
+// UsageLimit or PerUserLimit leaves it uncapped.
+type Voucher struct {
+	ID   int64
+	Code string
+
+	Type     string
+	Value    int
+	MinSpend int
+
+	PharmacyID *int64
+
+	StartsAt time.Time
+	EndsAt   *time.Time
+
+	UsageLimit   *int
+	PerUserLimit *int
+	UsageCount   int
+}
+
+// Discount is what the voucher takes off an order that spends spend on its
+// items and pays shipmentFee for shipping.
+func (v Voucher) Discount(spend int, shipmentFee int) int {
+	d := v.Value
+	switch v.Type {
+	case VoucherTypePercentage:
+		d = spend * v.Value / 100
+	case VoucherTypeFreeShipping:
+		return shipmentFee
+	}
+	if d > spend {
+		return spend
+	}
+	return d
+}
+
+func (v Voucher) IsActive(at time.Time) bool {
+	if v.StartsAt.After(at) {
+		return false
+	}
+	return v.EndsAt == nil || v.EndsAt.After(at)
+}
+
+type VoucherRef struct {
+	ID   int64
+	Code string
+}
+
+// VoucherRedemption is a use of a voucher on an order, recorded with the
+// payment of the checkout it was used in.
+type VoucherRedemption struct {
+	ID int64
+
+	VoucherID int64
+	UserID    int64
+	PaymentID int64
+	OrderID   int64
+	Discount  int
+
+	CreatedAt time.Time
+}
+
+type VoucherListDetails struct {
+	Code       *string
+	ActiveOnly bool
+
+	Page  int
+	Limit int
+}
+
+type VoucherRepository interface {
+	GetPageInfo(ctx context.Context, det VoucherListDetails) (PageInfo, error)
+	List(ctx context.Context, det VoucherListDetails) ([]Voucher, error)
+	GetByID(ctx context.Context, id int64) (Voucher, error)
+	GetByIDAndLock(ctx context.Context, id int64) (Voucher, error)
+	GetByCode(ctx context.Context, code string) (Voucher, error)
+	IsExistByCode(ctx context.Context, code string) (bool, error)
+
+	Add(ctx context.Context, v Voucher) (Voucher, error)
+	Update(ctx context.Context, v Voucher) (Voucher, error)
+	SoftDeleteByID(ctx context.Context, id int64) error
+
+	CountRedemptionsByUserID(ctx context.Context, voucherID int64, userID int64) (int, error)
+	AddRedemption(ctx context.Context, r VoucherRedemption) (VoucherRedemption, error)
+	IncrementUsage(ctx context.Context, id int64) error
+	ReleaseByOrderIDs(ctx context.Context, orderIDs []int64) error
+}
+
+type VoucherService interface {
+	List(ctx context.Context, det VoucherListDetails) ([]Voucher, PageInfo, error)
+	GetByID(ctx context.Context, id int64) (Voucher, error)
+
+	Create(ctx context.Context, v Voucher) (Voucher, error)
+	Update(ctx context.Context, v Voucher) (Voucher, error)
+	Delete(ctx context.Context, id int64) error
+}
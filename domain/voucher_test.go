@@ -0,0 +1,156 @@
+package domain_test
+
+import (
+	"medichat-be/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVoucher_Discount(t *testing.T) {
+	tests := []struct {
+		name string
+
+		voucherType string
+		value       int
+		spend       int
+		shipmentFee int
+
+		want int
+	}{
+		{
+			name: "should take the percentage off the spend",
+
+			voucherType: domain.VoucherTypePercentage,
+			value:       10,
+			spend:       50000,
+			shipmentFee: 9000,
+
+			want: 5000,
+		},
+		{
+			name: "should round a percentage discount down",
+
+			voucherType: domain.VoucherTypePercentage,
+			value:       15,
+			spend:       12345,
+			shipmentFee: 9000,
+
+			want: 1851,
+		},
+		{
+			name: "should take a fixed amount off the spend",
+
+			voucherType: domain.VoucherTypeFixed,
+			value:       10000,
+			spend:       50000,
+			shipmentFee: 9000,
+
+			want: 10000,
+		},
+		{
+			name: "should cap a fixed discount at the spend",
+
+			voucherType: domain.VoucherTypeFixed,
+			value:       10000,
+			spend:       7500,
+			shipmentFee: 9000,
+
+			want: 7500,
+		},
+		{
+			name: "should take off the whole shipment fee for free shipping",
+
+			voucherType: domain.VoucherTypeFreeShipping,
+			value:       0,
+			spend:       50000,
+			shipmentFee: 9000,
+
+			want: 9000,
+		},
+		{
+			name: "should give nothing for free shipping without a shipment fee",
+
+			voucherType: domain.VoucherTypeFreeShipping,
+			value:       0,
+			spend:       50000,
+			shipmentFee: 0,
+
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			v := domain.Voucher{Type: tt.voucherType, Value: tt.value}
+
+			// when
+			got := v.Discount(tt.spend, tt.shipmentFee)
+
+			// then
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVoucher_IsActive(t *testing.T) {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+
+		endsAt *time.Time
+		at     time.Time
+
+		want bool
+	}{
+		{
+			name: "should not be active before it starts",
+
+			endsAt: &end,
+			at:     start.Add(-time.Second),
+
+			want: false,
+		},
+		{
+			name: "should be active from the moment it starts",
+
+			endsAt: &end,
+			at:     start,
+
+			want: true,
+		},
+		{
+			name: "should not be active from the moment it ends",
+
+			endsAt: &end,
+			at:     end,
+
+			want: false,
+		},
+		{
+			name: "should stay active without an end",
+
+			endsAt: nil,
+			at:     end.AddDate(1, 0, 0),
+
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			v := domain.Voucher{StartsAt: start, EndsAt: tt.endsAt}
+
+			// when
+			got := v.IsActive(tt.at)
+
+			// then
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"medichat-be/domain"
 	"mime/multipart"
 	"sort"
+	"strings"
 )
 
 type CreateCategoryForm struct {
@@ -25,23 +26,42 @@ type UpdateCategoryForm struct {
 type GetCategoriesQuery struct {
 	Page       int64  `form:"page" binding:"numeric,omitempty,min=1"`
 	Limit      int64  `form:"limit" binding:"numeric,omitempty,min=1"`
-	Level      int64  `form:"level" binding:"numeric,omitempty,oneof=1 2"`
+	Level      int64  `form:"level" binding:"numeric,omitempty,min=1"`
 	SortBy     string `form:"sort_by" binding:"omitempty,oneof=name level parent"`
 	SortType   string `form:"sort_type" binding:"omitempty,oneof=ASC DESC"`
 	ParentSlug string `form:"parent_slug"`
 	Term       string `form:"term"`
+	ListParams
 }
 
 type CategorySlugParams struct {
 	Slug string `uri:"slug" binding:"required"`
 }
 
+type MoveCategoryRequest struct {
+	ParentSlug *string `json:"parent_slug"`
+}
+
+type ReorderCategoriesRequest struct {
+	ParentSlug *string  `json:"parent_slug"`
+	Slugs      []string `json:"slugs" binding:"required,min=1,dive,required"`
+}
+
 type CategoryResponse struct {
 	ID       int64   `json:"id"`
 	ParentID *int64  `json:"parent_id,omitempty"`
 	Name     string  `json:"name"`
 	Slug     string  `json:"slug"`
 	PhotoUrl *string `json:"photo_url,omitempty"`
+	Depth    int64   `json:"depth"`
+	Position int64   `json:"position"`
+}
+
+type CategoryTreeResponse struct {
+	CategoryResponse
+	ProductCount       int64                  `json:"product_count"`
+	DirectProductCount int64                  `json:"direct_product_count"`
+	Children           []CategoryTreeResponse `json:"children"`
 }
 
 type CategoryWithParentNameResponse struct {
@@ -65,33 +85,30 @@ type CategoriesResponse struct {
 
 func (q *GetCategoriesQuery) ToCategoriesQuery() domain.CategoriesQuery {
 	var page int64 = q.Page
-	var sortBy string = q.SortBy
-	var sortType string = q.SortType
 	if q.Page == 0 || q.Limit == 0 {
 		page = 1
 	}
-	if q.SortBy == "" {
-		sortBy = domain.CategorySortById
-	}
-	if q.SortType == "" {
-		sortType = constants.SortAsc
-	}
-	if q.SortBy == domain.CategorySortByLevel {
-		sortBy = "parent_id"
-		if sortType == constants.SortAsc {
-			sortType = constants.SortDesc
-		} else {
-			sortType = constants.SortAsc
+
+	sorts := q.ToSorts()
+	if len(sorts) == 0 {
+		sortBy := q.SortBy
+		if sortBy == "" {
+			sortBy = domain.CategorySortById
 		}
+		sorts = []domain.SortField{{
+			Field: sortBy,
+			Desc:  strings.EqualFold(q.SortType, constants.SortDesc),
+		}}
 	}
+
 	return domain.CategoriesQuery{
 		Page:       page,
 		Limit:      q.Limit,
 		Level:      q.Level,
 		Term:       q.Term,
-		SortBy:     sortBy,
-		SortType:   sortType,
 		ParentSlug: q.ParentSlug,
+		Sorts:      sorts,
+		Filters:    q.ToFilters(),
 	}
 }
 
@@ -107,9 +124,40 @@ func NewCategoryResponse(c domain.Category) CategoryResponse {
 		Name:     c.Name,
 		Slug:     c.Slug,
 		PhotoUrl: photoUrl,
+		Depth:    c.Depth,
+		Position: c.Position,
 	}
 }
 
+func NewCategoryTreeResponse(n domain.CategoryTreeNode) CategoryTreeResponse {
+	children := make([]CategoryTreeResponse, len(n.Children))
+	for i := 0; i < len(n.Children); i++ {
+		children[i] = NewCategoryTreeResponse(n.Children[i])
+	}
+	return CategoryTreeResponse{
+		CategoryResponse:   NewCategoryResponse(n.Category),
+		ProductCount:       n.ProductCount,
+		DirectProductCount: n.DirectProductCount,
+		Children:           children,
+	}
+}
+
+func NewCategoryTreeResponses(nodes []domain.CategoryTreeNode) []CategoryTreeResponse {
+	res := make([]CategoryTreeResponse, len(nodes))
+	for i := 0; i < len(nodes); i++ {
+		res[i] = NewCategoryTreeResponse(nodes[i])
+	}
+	return res
+}
+
+func NewCategoryResponses(categories []domain.Category) []CategoryResponse {
+	res := make([]CategoryResponse, len(categories))
+	for i := 0; i < len(categories); i++ {
+		res[i] = NewCategoryResponse(categories[i])
+	}
+	return res
+}
+
 func NewCategoryWithParentNameResponse(c domain.CategoryWithParentName) CategoryWithParentNameResponse {
 	return CategoryWithParentNameResponse{
 		ID:         c.Category.ID,
@@ -140,7 +188,12 @@ func NewCategoriesHierarchyResponse(categories []domain.Category) []CategoriesRe
 		categoriesMap[cR.ID] = []CategoryResponse{}
 	}
 
+	// the hierarchy only covers the first two levels, see the tree endpoint
+	// for deeper categories
 	for i := 0; i < len(childs); i++ {
+		if _, ok := parentsMap[*childs[i].ParentID]; !ok {
+			continue
+		}
 		categoriesMap[*childs[i].ParentID] = append(categoriesMap[*childs[i].ParentID], childs[i])
 	}
 
@@ -26,6 +26,7 @@ type ChatMessage struct{
 
 type ChatPrescription struct{
 	Drugs []*ChatPrescriptionDrugs `json:"drugs"`
+	OverrideInteractions bool `json:"override_interactions"`
 }
 
 type ChatPrescriptionDrugs struct {
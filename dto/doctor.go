@@ -1,8 +1,10 @@
 package dto
 
 import (
+	"fmt"
 	"medichat-be/constants"
 	"medichat-be/domain"
+	"medichat-be/util"
 	"mime/multipart"
 	"strconv"
 	"time"
@@ -21,10 +23,77 @@ type DoctorResponse struct {
 	YearExperience int    `json:"year_experience"`
 	Price          int    `json:"price"`
 	CertificateURL string `json:"certificate_url"`
+	Bio            string `json:"bio"`
+
+	Educations []DoctorEducationResponse `json:"educations,omitempty"`
+	Practices  []DoctorPracticeResponse  `json:"practices,omitempty"`
+	Languages  []string                  `json:"languages,omitempty"`
+
+	VerificationStatus string  `json:"verification_status"`
+	RejectionReason    *string `json:"rejection_reason,omitempty"`
+
+	Search *DoctorSearchMatchResponse `json:"search,omitempty"`
+}
+
+type DoctorEducationResponse struct {
+	ID          int64  `json:"id"`
+	Institution string `json:"institution"`
+	Degree      string `json:"degree"`
+	StartYear   int    `json:"start_year"`
+	EndYear     *int   `json:"end_year"`
+}
+
+func NewDoctorEducationResponse(e domain.DoctorEducation) DoctorEducationResponse {
+	return DoctorEducationResponse{
+		ID:          e.ID,
+		Institution: e.Institution,
+		Degree:      e.Degree,
+		StartYear:   e.StartYear,
+		EndYear:     e.EndYear,
+	}
+}
+
+type DoctorPracticeResponse struct {
+	ID        int64   `json:"id"`
+	Location  string  `json:"location"`
+	StartDate string  `json:"start_date"`
+	EndDate   *string `json:"end_date"`
+}
+
+func NewDoctorPracticeResponse(p domain.DoctorPractice) DoctorPracticeResponse {
+	ret := DoctorPracticeResponse{
+		ID:        p.ID,
+		Location:  p.Location,
+		StartDate: p.StartDate.Format("2006-01-02"),
+	}
+	if p.EndDate != nil {
+		endDate := p.EndDate.Format("2006-01-02")
+		ret.EndDate = &endDate
+	}
+	return ret
+}
+
+type DoctorSearchMatchResponse struct {
+	Rank       float64 `json:"rank"`
+	Highlights struct {
+		Name           string `json:"name"`
+		Specialization string `json:"specialization"`
+		WorkLocation   string `json:"work_location"`
+	} `json:"highlights"`
+}
+
+func NewDoctorSearchMatchResponse(m domain.DoctorSearchMatch) DoctorSearchMatchResponse {
+	ret := DoctorSearchMatchResponse{
+		Rank: m.Rank,
+	}
+	ret.Highlights.Name = m.NameHighlight
+	ret.Highlights.Specialization = m.SpecializationHighlight
+	ret.Highlights.WorkLocation = m.WorkLocationHighlight
+	return ret
 }
 
 func NewDoctorResponse(d domain.Doctor) DoctorResponse {
-	return DoctorResponse{
+	ret := DoctorResponse{
 		ID:             d.ID,
 		Specialization: NewSpecializationResponse(d.Specialization),
 		STR:            d.STR,
@@ -36,46 +105,77 @@ func NewDoctorResponse(d domain.Doctor) DoctorResponse {
 		YearExperience: d.YearExperience,
 		Price:          d.Price,
 		CertificateURL: d.CertificateURL,
+		Bio:            d.Bio,
+
+		Educations: util.MapSlice(d.Educations, NewDoctorEducationResponse),
+		Practices:  util.MapSlice(d.Practices, NewDoctorPracticeResponse),
+		Languages:  d.Languages,
+
+		VerificationStatus: d.VerificationStatus,
+		RejectionReason:    d.RejectionReason,
+	}
+
+	if d.Search != nil {
+		m := NewDoctorSearchMatchResponse(*d.Search)
+		ret.Search = &m
 	}
+
+	return ret
 }
 
 type DoctorListQuery struct {
+	Query             *string `form:"q" binding:"omitempty,min=1,max=100"`
 	SpecializationID  *int64  `form:"specialization_id"`
 	Name              *string `form:"name"`
 	Gender            *string `form:"gender"`
 	MinPrice          *int    `form:"min_price" binding:"omitempty,min=0"`
 	MaxPrice          *int    `form:"max_price" binding:"omitempty,min=0"`
 	MinYearExperience *int    `form:"min_year_experience" binding:"omitempty,min=0"`
+	Language          *string `form:"language" binding:"omitempty,min=1,max=50"`
 
 	SortBy *string `form:"sort_by" binding:"omitempty,doctor_sort_by"`
 	Sort   *string `form:"sort" binding:"omitempty,sort_order"`
 
-	Cursor   *string `form:"cursor" binding:"required_with=CursorID"`
-	CursorID *int64  `form:"cursor_id" binding:"required_with=Cursor"`
-	Limit    *int    `form:"limit" binding:"omitempty,min=1"`
+	Cursor         *string `form:"cursor" binding:"required_with=CursorID"`
+	CursorIsActive *bool   `form:"cursor_is_active"`
+	CursorID       *int64  `form:"cursor_id" binding:"required_with=Cursor"`
+	Limit          *int    `form:"limit" binding:"omitempty,min=1"`
+
+	Filter []string `form:"filter"`
 }
 
 func (q *DoctorListQuery) ToDetails() (domain.DoctorListDetails, error) {
 	ret := domain.DoctorListDetails{
+		Query:             q.Query,
 		SpecializationID:  q.SpecializationID,
 		Name:              q.Name,
 		Gender:            q.Gender,
 		MinPrice:          q.MinPrice,
 		MaxPrice:          q.MaxPrice,
 		MinYearExperience: q.MinYearExperience,
+		Language:          q.Language,
+		Filters:           ListParams{Filter: q.Filter}.ToFilters(),
 
 		SortBy:  constants.DoctorSortByName,
 		SortAsc: true,
 
-		CursorID: q.CursorID,
-		Limit:    10,
+		CursorIsActive: q.CursorIsActive,
+		CursorID:       q.CursorID,
+		Limit:          10,
 	}
 
+	if q.Query != nil {
+		ret.SortBy = constants.DoctorSortByRelevance
+		ret.SortAsc = false
+	}
 	if q.SortBy != nil {
 		ret.SortBy = *q.SortBy
 	}
-	if q.Sort != nil && *q.Sort == constants.SortDesc {
-		ret.SortAsc = false
+	if ret.SortBy == constants.DoctorSortByRelevance && q.Query == nil {
+		ret.SortBy = constants.DoctorSortByName
+	}
+	if q.Sort != nil {
+		ret.SortAsc = *q.Sort != constants.SortDesc
 	}
 	if q.Limit != nil {
 		ret.Limit = *q.Limit
@@ -97,6 +197,13 @@ func (q *DoctorListQuery) ToDetails() (domain.DoctorListDetails, error) {
 			}
 			ret.Cursor = v
 
+		case constants.DoctorSortByRelevance:
+			v, err := strconv.ParseFloat(*q.Cursor, 64)
+			if err != nil {
+				return domain.DoctorListDetails{}, err
+			}
+			ret.Cursor = v
+
 		default:
 			ret.Cursor = *q.Cursor
 		}
@@ -163,7 +270,8 @@ func DoctorCreateRequestToDetails(r DoctorCreateRequest) (domain.DoctorCreateDet
 
 type DoctorUpdateRequest = MultipartForm[
 	struct {
-		Photo *multipart.FileHeader `form:"photo" binding:"omitempty,content_type=image/png"`
+		Photo       *multipart.FileHeader `form:"photo" binding:"omitempty,content_type=image/png"`
+		Certificate *multipart.FileHeader `form:"certificate" binding:"omitempty,content_type=application/pdf"`
 	},
 	struct {
 		Name         *string `json:"name" binding:"omitempty,no_leading_trailing_space"`
@@ -171,9 +279,61 @@ type DoctorUpdateRequest = MultipartForm[
 		Gender       *string `json:"gender" binding:"omitempty,no_leading_trailing_space"`
 		PhoneNumber  *string `json:"phone_number" binding:"omitempty,no_leading_trailing_space"`
 		Price        *int    `json:"price"`
+		Bio          *string `json:"bio" binding:"omitempty,max=2000"`
+
+		Educations *[]DoctorEducationRequest `json:"educations" binding:"omitempty,dive"`
+		Practices  *[]DoctorPracticeRequest  `json:"practices" binding:"omitempty,dive"`
+		Languages  *[]string                 `json:"languages" binding:"omitempty,dive,required,no_leading_trailing_space,max=50"`
 	},
 ]
 
+type DoctorEducationRequest struct {
+	Institution string `json:"institution" binding:"required,no_leading_trailing_space"`
+	Degree      string `json:"degree" binding:"required,no_leading_trailing_space"`
+	StartYear   int    `json:"start_year" binding:"required,min=1900"`
+	EndYear     *int   `json:"end_year" binding:"omitempty,gtefield=StartYear"`
+}
+
+func (r DoctorEducationRequest) ToEducation() domain.DoctorEducation {
+	return domain.DoctorEducation{
+		Institution: r.Institution,
+		Degree:      r.Degree,
+		StartYear:   r.StartYear,
+		EndYear:     r.EndYear,
+	}
+}
+
+type DoctorPracticeRequest struct {
+	Location  string  `json:"location" binding:"required,no_leading_trailing_space"`
+	StartDate string  `json:"start_date" binding:"required,datetime=2006-01-02"`
+	EndDate   *string `json:"end_date" binding:"omitempty,datetime=2006-01-02"`
+}
+
+func (r DoctorPracticeRequest) ToPractice() (domain.DoctorPractice, error) {
+	ret := domain.DoctorPractice{
+		Location: r.Location,
+	}
+
+	startDate, err := time.Parse("2006-01-02", r.StartDate)
+	if err != nil {
+		return domain.DoctorPractice{}, err
+	}
+	ret.StartDate = startDate
+
+	if r.EndDate != nil {
+		endDate, err := time.Parse("2006-01-02", *r.EndDate)
+		if err != nil {
+			return domain.DoctorPractice{}, err
+		}
+		if endDate.Before(startDate) {
+			return domain.DoctorPractice{}, fmt.Errorf("end_date must not be before start_date")
+		}
+		ret.EndDate = &endDate
+	}
+
+	return ret, nil
+}
+
 func DoctorUpdateRequestToDetails(r DoctorUpdateRequest) (domain.DoctorUpdateDetails, error) {
 	d := r.Data
 	ret := domain.DoctorUpdateDetails{
@@ -182,6 +342,25 @@ func DoctorUpdateRequestToDetails(r DoctorUpdateRequest) (domain.DoctorUpdateDet
 		Gender:       d.Gender,
 		PhoneNumber:  d.PhoneNumber,
 		Price:        d.Price,
+		Bio:          d.Bio,
+		Languages:    d.Languages,
+	}
+
+	if d.Educations != nil {
+		educations := util.MapSlice(*d.Educations, DoctorEducationRequest.ToEducation)
+		ret.Educations = &educations
+	}
+
+	if d.Practices != nil {
+		practices := make([]domain.DoctorPractice, len(*d.Practices))
+		for i, p := range *d.Practices {
+			practice, err := p.ToPractice()
+			if err != nil {
+				return domain.DoctorUpdateDetails{}, err
+			}
+			practices[i] = practice
+		}
+		ret.Practices = &practices
 	}
 
 	if r.Form.Photo != nil {
@@ -192,9 +371,55 @@ func DoctorUpdateRequestToDetails(r DoctorUpdateRequest) (domain.DoctorUpdateDet
 		ret.Photo = f
 	}
 
+	if r.Form.Certificate != nil {
+		f, err := r.Form.Certificate.Open()
+		if err != nil {
+			return domain.DoctorUpdateDetails{}, err
+		}
+		ret.Certificate = f
+	}
+
 	return ret, nil
 }
 
 type DoctorSetActiveRequest struct {
 	IsActive *bool `json:"is_active" binding:"required"`
 }
+
+type DoctorVerificationListQuery struct {
+	Status *string `form:"status" binding:"omitempty,oneof=pending approved rejected"`
+
+	Page  *int `form:"page" binding:"omitempty,min=1"`
+	Limit *int `form:"limit" binding:"omitempty,min=1"`
+}
+
+func (q DoctorVerificationListQuery) ToDetails() domain.DoctorVerificationListDetails {
+	ret := domain.DoctorVerificationListDetails{
+		Status: domain.DoctorVerificationPending,
+		Page:   1,
+		Limit:  10,
+	}
+
+	if q.Status != nil {
+		ret.Status = *q.Status
+	}
+	if q.Page != nil {
+		ret.Page = *q.Page
+	}
+	if q.Limit != nil {
+		ret.Limit = *q.Limit
+	}
+
+	return ret
+}
+
+type DoctorRejectRequest struct {
+	Reason string `json:"reason" binding:"required,no_leading_trailing_space"`
+}
+
+func (r DoctorRejectRequest) ToDetails(id int64) domain.DoctorRejectDetails {
+	return domain.DoctorRejectDetails{
+		ID:     id,
+		Reason: r.Reason,
+	}
+}
@@ -0,0 +1,114 @@
+package dto
+
+import (
+	"medichat-be/domain"
+	"medichat-be/util"
+	"time"
+)
+
+type DoctorEarningResponse struct {
+	ID     int64 `json:"id"`
+	RoomID int64 `json:"room_id"`
+
+	ConsultationFee int     `json:"consultation_fee"`
+	CommissionRate  float64 `json:"commission_rate"`
+	Commission      int     `json:"commission"`
+	NetEarning      int     `json:"net_earning"`
+
+	Status   string    `json:"status"`
+	PayoutID *int64    `json:"payout_id,omitempty"`
+	ClosedAt time.Time `json:"closed_at"`
+}
+
+func NewDoctorEarningResponse(e domain.DoctorEarning) DoctorEarningResponse {
+	return DoctorEarningResponse{
+		ID:              e.ID,
+		RoomID:          e.RoomID,
+		ConsultationFee: e.ConsultationFee,
+		CommissionRate:  e.CommissionRate,
+		Commission:      e.Commission,
+		NetEarning:      e.NetEarning,
+		Status:          e.Status,
+		PayoutID:        e.PayoutID,
+		ClosedAt:        e.ClosedAt,
+	}
+}
+
+type DoctorEarningStatementResponse struct {
+	Month   string                  `json:"month"`
+	Entries []DoctorEarningResponse `json:"entries"`
+
+	TotalFee        int `json:"total_fee"`
+	TotalCommission int `json:"total_commission"`
+	TotalNet        int `json:"total_net"`
+	PaidNet         int `json:"paid_net"`
+	UnpaidNet       int `json:"unpaid_net"`
+}
+
+func NewDoctorEarningStatementResponse(s domain.DoctorEarningStatement) DoctorEarningStatementResponse {
+	return DoctorEarningStatementResponse{
+		Month:           s.Month.Format("2006-01"),
+		Entries:         util.MapSlice(s.Entries, NewDoctorEarningResponse),
+		TotalFee:        s.TotalFee,
+		TotalCommission: s.TotalCommission,
+		TotalNet:        s.TotalNet,
+		PaidNet:         s.PaidNet,
+		UnpaidNet:       s.UnpaidNet,
+	}
+}
+
+type DoctorEarningStatementQuery struct {
+	Month *string `form:"month" binding:"omitempty,datetime=2006-01"`
+}
+
+func (q DoctorEarningStatementQuery) ToMonth() (time.Time, error) {
+	if q.Month == nil {
+		return time.Now(), nil
+	}
+	return time.ParseInLocation("2006-01", *q.Month, time.Local)
+}
+
+type DoctorEarningExportQuery struct {
+	DoctorEarningStatementQuery
+	Format string `form:"format" binding:"required,oneof=csv pdf"`
+}
+
+type DoctorPayoutResponse struct {
+	ID       int64     `json:"id"`
+	DoctorID int64     `json:"doctor_id"`
+	Amount   int       `json:"amount"`
+	NEntries int       `json:"n_entries"`
+	PaidAt   time.Time `json:"paid_at"`
+}
+
+func NewDoctorPayoutResponse(p domain.DoctorPayout) DoctorPayoutResponse {
+	return DoctorPayoutResponse{
+		ID:       p.ID,
+		DoctorID: p.DoctorID,
+		Amount:   p.Amount,
+		NEntries: p.NEntries,
+		PaidAt:   p.PaidAt,
+	}
+}
+
+type DoctorPayoutCreateRequest struct {
+	DoctorID *int64  `json:"doctor_id" binding:"omitempty,min=1"`
+	Until    *string `json:"until" binding:"omitempty,datetime=2006-01-02"`
+}
+
+func (r DoctorPayoutCreateRequest) ToDetails() (domain.DoctorPayoutCreateDetails, error) {
+	ret := domain.DoctorPayoutCreateDetails{
+		DoctorID: r.DoctorID,
+		Until:    time.Now(),
+	}
+
+	if r.Until != nil {
+		until, err := time.ParseInLocation("2006-01-02", *r.Until, time.Local)
+		if err != nil {
+			return domain.DoctorPayoutCreateDetails{}, err
+		}
+		ret.Until = until.AddDate(0, 0, 1)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,182 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"medichat-be/constants"
+	"medichat-be/domain"
+	"medichat-be/util"
+	"mime/multipart"
+	"sort"
+	"strings"
+)
+
+type DrugInteractionResponse struct {
+	ID          int64  `json:"id"`
+	IngredientA string `json:"ingredient_a"`
+	IngredientB string `json:"ingredient_b"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+}
+
+func NewDrugInteractionResponse(d domain.DrugInteraction) DrugInteractionResponse {
+	return DrugInteractionResponse{
+		ID:          d.ID,
+		IngredientA: d.IngredientA,
+		IngredientB: d.IngredientB,
+		Severity:    d.Severity,
+		Description: d.Description,
+	}
+}
+
+type DrugInteractionProductResponse struct {
+	ID   int64  `json:"id"`
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+type DrugInteractionWarningResponse struct {
+	ProductA    DrugInteractionProductResponse `json:"product_a"`
+	ProductB    DrugInteractionProductResponse `json:"product_b"`
+	IngredientA string                         `json:"ingredient_a"`
+	IngredientB string                         `json:"ingredient_b"`
+	Severity    string                         `json:"severity"`
+	Description string                         `json:"description"`
+	Overridden  bool                           `json:"overridden"`
+}
+
+func NewDrugInteractionWarningResponse(w domain.DrugInteractionWarning) DrugInteractionWarningResponse {
+	return DrugInteractionWarningResponse{
+		ProductA:    DrugInteractionProductResponse(w.ProductA),
+		ProductB:    DrugInteractionProductResponse(w.ProductB),
+		IngredientA: w.IngredientA,
+		IngredientB: w.IngredientB,
+		Severity:    w.Severity,
+		Description: w.Description,
+		Overridden:  w.Overridden,
+	}
+}
+
+type DrugInteractionImportForm struct {
+	File *multipart.FileHeader `form:"file" binding:"required"`
+}
+
+func ParseDrugInteractionImport(
+	r io.Reader,
+	format string,
+) ([]domain.DrugInteractionImportRow, []domain.DrugInteractionImportRowError, error) {
+	records, err := util.ReadSpreadsheet(r, format)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil, errors.New("file is empty")
+	}
+	if len(records)-1 > constants.DrugInteractionImportMaxRows {
+		return nil, nil, fmt.Errorf("file has more than %d rows", constants.DrugInteractionImportMaxRows)
+	}
+
+	header := map[string]int{}
+	for i, h := range records[0] {
+		header[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, h := range constants.DrugInteractionHeader {
+		if _, ok := header[h]; !ok {
+			return nil, nil, fmt.Errorf("missing column %s", h)
+		}
+	}
+
+	value := func(record []string, column string) string {
+		if idx := header[column]; idx < len(record) {
+			return strings.TrimSpace(record[idx])
+		}
+		return ""
+	}
+
+	rows := make([]domain.DrugInteractionImportRow, 0, len(records)-1)
+	rowErrors := make([]domain.DrugInteractionImportRowError, 0)
+
+	for i, record := range records[1:] {
+		line := i + 2
+		if isBlankRecord(record) {
+			continue
+		}
+
+		in := domain.DrugInteraction{
+			IngredientA: value(record, "ingredient_a"),
+			IngredientB: value(record, "ingredient_b"),
+			Severity:    strings.ToLower(value(record, "severity")),
+			Description: value(record, "description"),
+		}
+
+		msgs := []string{}
+		if in.IngredientA == "" {
+			msgs = append(msgs, "ingredient_a is required")
+		}
+		if in.IngredientB == "" {
+			msgs = append(msgs, "ingredient_b is required")
+		}
+		switch in.Severity {
+		case domain.DrugInteractionSeverityMinor,
+			domain.DrugInteractionSeverityModerate,
+			domain.DrugInteractionSeveritySevere:
+		default:
+			msgs = append(msgs, fmt.Sprintf(
+				"severity must be one of %s, %s, %s",
+				domain.DrugInteractionSeverityMinor,
+				domain.DrugInteractionSeverityModerate,
+				domain.DrugInteractionSeveritySevere,
+			))
+		}
+		if in.Description == "" {
+			msgs = append(msgs, "description is required")
+		}
+
+		if len(msgs) > 0 {
+			rowErrors = append(rowErrors, domain.DrugInteractionImportRowError{
+				Line:    line,
+				Message: strings.Join(msgs, "; "),
+			})
+			continue
+		}
+
+		rows = append(rows, domain.DrugInteractionImportRow{
+			Line:        line,
+			Interaction: in,
+		})
+	}
+
+	return rows, rowErrors, nil
+}
+
+type DrugInteractionImportRowErrorResponse struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+func NewDrugInteractionImportRowErrorResponse(e domain.DrugInteractionImportRowError) DrugInteractionImportRowErrorResponse {
+	return DrugInteractionImportRowErrorResponse(e)
+}
+
+type DrugInteractionImportResultResponse struct {
+	Imported int                                     `json:"imported"`
+	Failed   int                                     `json:"failed"`
+	Errors   []DrugInteractionImportRowErrorResponse `json:"errors"`
+}
+
+func NewDrugInteractionImportResultResponse(
+	result domain.DrugInteractionImportResult,
+	parseErrors []domain.DrugInteractionImportRowError,
+) DrugInteractionImportResultResponse {
+	errs := append(parseErrors, result.Errors...)
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Line < errs[j].Line
+	})
+
+	return DrugInteractionImportResultResponse{
+		Imported: result.Imported,
+		Failed:   len(errs),
+		Errors:   util.MapSlice(errs, NewDrugInteractionImportRowErrorResponse),
+	}
+}
@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"medichat-be/constants"
+	"medichat-be/domain"
+	"strings"
+)
+
+// ListParams are the generic sort and filter parameters of list endpoints.
+// order_by takes comma separated fields, each prefixed with - for descending
+// order. filter can be repeated and takes field:op:value, where the values of
+// in and range are comma separated, e.g. price:range:1000,5000.
+type ListParams struct {
+	OrderBy string   `form:"order_by"`
+	Filter  []string `form:"filter"`
+}
+
+func (p ListParams) ToSorts() []domain.SortField {
+	ret := []domain.SortField{}
+	for _, f := range strings.Split(p.OrderBy, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		desc := strings.HasPrefix(f, "-")
+		ret = append(ret, domain.SortField{
+			Field: strings.TrimPrefix(f, "-"),
+			Desc:  desc,
+		})
+	}
+	return ret
+}
+
+func (p ListParams) ToFilters() []domain.Filter {
+	ret := make([]domain.Filter, 0, len(p.Filter))
+	for _, f := range p.Filter {
+		parts := strings.SplitN(f, ":", 3)
+		filter := domain.Filter{Field: parts[0]}
+		if len(parts) == 3 {
+			filter.Op = domain.FilterOp(parts[1])
+			switch filter.Op {
+			case domain.FilterOpIn:
+				filter.Values = strings.Split(parts[2], ",")
+			case domain.FilterOpRange:
+				filter.Values = strings.SplitN(parts[2], ",", 2)
+			default:
+				filter.Values = []string{parts[2]}
+			}
+		}
+		ret = append(ret, filter)
+	}
+	return ret
+}
+
+// toLegacySort reads the older single field sort_by and sort parameters.
+func toLegacySort(sortBy *string, sort *string, defaultField string, defaultDesc bool) domain.SortField {
+	ret := domain.SortField{Field: defaultField, Desc: defaultDesc}
+	if sortBy != nil {
+		ret.Field = *sortBy
+	}
+	if sort != nil {
+		ret.Desc = strings.EqualFold(*sort, constants.SortDesc)
+	}
+	return ret
+}
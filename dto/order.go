@@ -3,6 +3,7 @@ package dto
 import (
 	"medichat-be/domain"
 	"medichat-be/util"
+	"strings"
 	"time"
 )
 
@@ -31,14 +32,18 @@ type OrderResponse struct {
 	Address    string        `json:"address"`
 	Coordinate CoordinateDTO `json:"coordinate"`
 
-	NItems      int `json:"n_items"`
-	Subtotal    int `json:"subtotal"`
-	ShipmentFee int `json:"shipment_fee"`
-	Total       int `json:"total"`
+	NItems          int                 `json:"n_items"`
+	Subtotal        int                 `json:"subtotal"`
+	Discount        int                 `json:"discount"`
+	Voucher         *VoucherRefResponse `json:"voucher"`
+	VoucherDiscount int                 `json:"voucher_discount"`
+	ShipmentFee     int                 `json:"shipment_fee"`
+	Total           int                 `json:"total"`
 
 	Status     string     `json:"status"`
 	OrderedAt  time.Time  `json:"ordered_at"`
 	FinishedAt *time.Time `json:"finished_at"`
+	ProcessAt  *time.Time `json:"process_at"`
 
 	Items []OrderItemResponse `json:"items,omitempty"`
 }
@@ -64,16 +69,20 @@ func NewOrderResponse(o domain.Order) OrderResponse {
 			ID   int64  "json:\"id\""
 			Name string "json:\"name\""
 		}(o.ShipmentMethod),
-		Address:     o.Address,
-		Coordinate:  CoordinateDTO(o.Coordinate),
-		NItems:      o.NItems,
-		Subtotal:    o.Subtotal,
-		ShipmentFee: o.ShipmentFee,
-		Total:       o.Total,
-		Status:      o.Status,
-		OrderedAt:   o.OrderedAt,
-		FinishedAt:  o.FinishedAt,
-		Items:       util.MapSlice(o.Items, NewOrderItemResponse),
+		Address:         o.Address,
+		Coordinate:      CoordinateDTO(o.Coordinate),
+		NItems:          o.NItems,
+		Subtotal:        o.Subtotal,
+		Discount:        o.Discount,
+		Voucher:         NewVoucherRefResponse(o.Voucher),
+		VoucherDiscount: o.VoucherDiscount,
+		ShipmentFee:     o.ShipmentFee,
+		Total:           o.Total,
+		Status:          o.Status,
+		OrderedAt:       o.OrderedAt,
+		FinishedAt:      o.FinishedAt,
+		ProcessAt:       o.ProcessAt,
+		Items:           util.MapSlice(o.Items, NewOrderItemResponse),
 	}
 }
 
@@ -87,6 +96,11 @@ type OrderItemResponse struct {
 		PhotoURL       string `json:"photo_url"`
 		Classification string `json:"classification"`
 	} `json:"product"`
+	Variant *ProductVariantRefResponse `json:"variant"`
+
+	BasePrice int                   `json:"base_price"`
+	Discount  int                   `json:"discount"`
+	Promotion *PromotionRefResponse `json:"promotion"`
 
 	Price  int `json:"price"`
 	Amount int `json:"amount"`
@@ -108,20 +122,30 @@ func NewOrderItemResponse(oi domain.OrderItem) OrderItemResponse {
 			PhotoURL:       oi.Product.PhotoURL,
 			Classification: oi.Product.Classification,
 		},
-		Price:  oi.Price,
-		Amount: oi.Amount,
+		Variant:   NewProductVariantRefResponse(oi.Variant),
+		BasePrice: oi.BasePrice,
+		Discount:  oi.Discount,
+		Promotion: NewPromotionRefResponse(oi.Promotion),
+		Price:     oi.Price,
+		Amount:    oi.Amount,
 	}
 }
 
 type OrdersResponse struct {
-	Orders []OrderResponse `json:"orders"`
-	Total  int             `json:"total"`
+	Orders          []OrderResponse                  `json:"orders"`
+	Discount        int                              `json:"discount"`
+	VoucherDiscount int                              `json:"voucher_discount"`
+	Total           int                              `json:"total"`
+	Warnings        []DrugInteractionWarningResponse `json:"warnings"`
 }
 
 func NewOrdersResponse(o domain.Orders) OrdersResponse {
 	return OrdersResponse{
-		Orders: util.MapSlice(o.Orders, NewOrderResponse),
-		Total:  o.Total,
+		Orders:          util.MapSlice(o.Orders, NewOrderResponse),
+		Discount:        o.Discount,
+		VoucherDiscount: o.VoucherDiscount,
+		Total:           o.Total,
+		Warnings:        util.MapSlice(o.Warnings, NewDrugInteractionWarningResponse),
 	}
 
 }
@@ -129,6 +153,7 @@ func NewOrdersResponse(o domain.Orders) OrdersResponse {
 type OrderListQuery struct {
 	PharmacySlug *string `form:"pharmacy_slug"`
 	Status       *string `form:"status"`
+	ListParams
 
 	Page  *int `form:"page" binding:"omitempty,min=1"`
 	Limit *int `form:"limit" binding:"omitempty,min=1"`
@@ -138,6 +163,8 @@ func (q OrderListQuery) ToDetails() domain.OrderListDetails {
 	ret := domain.OrderListDetails{
 		PharmacySlug: q.PharmacySlug,
 		Status:       q.Status,
+		Sorts:        q.ToSorts(),
+		Filters:      q.ToFilters(),
 		Page:         1,
 		Limit:        10,
 	}
@@ -154,6 +181,7 @@ func (q OrderListQuery) ToDetails() domain.OrderListDetails {
 
 type OrderItemCreateRequest struct {
 	ProductSlug string `json:"product_slug"`
+	VariantID   *int64 `json:"variant_id" binding:"omitempty,min=1"`
 	Amount      int    `json:"amount"`
 }
 
@@ -164,15 +192,23 @@ type OrderCreateRequest struct {
 	Address    string        `json:"address" binding:"required"`
 	Coordinate CoordinateDTO `json:"coordinate" binding:"required"`
 
+	VoucherCode *string `json:"voucher_code" binding:"omitempty,max=50"`
+
 	Items []OrderItemCreateRequest `json:"items" binding:"omitempty,dive,required"`
 }
 
 func (r OrderCreateRequest) ToDetails() domain.OrderCreateDetails {
+	voucherCode := r.VoucherCode
+	if voucherCode != nil && strings.TrimSpace(*voucherCode) == "" {
+		voucherCode = nil
+	}
+
 	return domain.OrderCreateDetails{
 		PharmacySlug:     r.PharmacySlug,
 		ShipmentMethodID: r.ShipmentMethodID,
 		Address:          r.Address,
 		Coordinate:       r.Coordinate.ToCoordinate(),
+		VoucherCode:      voucherCode,
 		Items: util.MapSlice(r.Items, func(oi OrderItemCreateRequest) domain.OrderItemCreateDetails {
 			return domain.OrderItemCreateDetails(oi)
 		}),
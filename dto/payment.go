@@ -23,6 +23,7 @@ func NewPaymentResponse(p domain.Payment) PaymentResponse {
 
 type PaymentListQuery struct {
 	IsConfirmed *bool `form:"is_confirmed"`
+	ListParams
 
 	Page  *int `form:"page" binding:"omitempty,min=1"`
 	Limit *int `form:"limit" binding:"omitempty,min=1"`
@@ -32,6 +33,8 @@ func (q PaymentListQuery) ToDetails() domain.PaymentListDetails {
 	ret := domain.PaymentListDetails{
 		IsConfirmed: q.IsConfirmed,
 		UserID:      nil,
+		Sorts:       q.ToSorts(),
+		Filters:     q.ToFilters(),
 		Page:        1,
 		Limit:       10,
 	}
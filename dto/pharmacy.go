@@ -16,6 +16,7 @@ type PharmacyResponse struct {
 	PharmacistName          string                           `json:"pharmacist_name"`
 	PharmacistLicense       string                           `json:"pharmacist_license"`
 	PharmacistPhone         string                           `json:"pharmacist_phone"`
+	Timezone                string                           `json:"timezone"`
 	PharmacyOperations      []PharmacyOperationResponse      `json:"pharmacy_operations"`
 	PharmacyShipmentMethods []PharmacyShipmentMethodResponse `json:"pharmacy_shipment_methods"`
 
@@ -32,6 +33,7 @@ type PharmacyResponseWithStock struct {
 	PharmacistName          string                           `json:"pharmacist_name"`
 	PharmacistLicense       string                           `json:"pharmacist_license"`
 	PharmacistPhone         string                           `json:"pharmacist_phone"`
+	Timezone                string                           `json:"timezone"`
 	PharmacyOperations      []PharmacyOperationResponse      `json:"pharmacy_operations"`
 	PharmacyShipmentMethods []PharmacyShipmentMethodResponse `json:"pharmacy_shipment_methods"`
 	StockInfo               StockResponse                    `json:"stock"`
@@ -64,6 +66,7 @@ func NewPharmacyResponse(pharmacy domain.Pharmacy) PharmacyResponse {
 		PharmacistName:          pharmacy.PharmacistName,
 		PharmacistLicense:       pharmacy.PharmacistLicense,
 		PharmacistPhone:         pharmacy.PharmacistPhone,
+		Timezone:                pharmacy.Timezone,
 		PharmacyOperations:      util.MapSlice(pharmacy.PharmacyOperations, NewPharmacyOperationResponse),
 		PharmacyShipmentMethods: util.MapSlice(pharmacy.PharmacyShipmentMethods, NewPharmacyShipmentMethodResponse),
 
@@ -82,6 +85,7 @@ func NewPharmacyWithStockResponse(pharmacy domain.PharmacyStock) PharmacyRespons
 		PharmacistName:          pharmacy.PharmacistName,
 		PharmacistLicense:       pharmacy.PharmacistLicense,
 		PharmacistPhone:         pharmacy.PharmacistLicense,
+		Timezone:                pharmacy.Timezone,
 		PharmacyOperations:      util.MapSlice(pharmacy.PharmacyOperations, NewPharmacyOperationResponse),
 		PharmacyShipmentMethods: util.MapSlice(pharmacy.PharmacyShipmentMethods, NewPharmacyShipmentMethodResponse),
 		StockInfo:               NewStockResponse(pharmacy.Stock),
@@ -213,6 +217,7 @@ type PharmacyCreateRequest struct {
 	PharmacistName          string                                `json:"pharmacist_name" binding:"required,no_leading_trailing_space"`
 	PharmacistLicense       string                                `json:"pharmacist_license" binding:"required,no_leading_trailing_space"`
 	PharmacistPhone         string                                `json:"pharmacist_phone" binding:"required,no_leading_trailing_space"`
+	Timezone                string                                `json:"timezone" binding:"omitempty,oneof=Asia/Jakarta Asia/Makassar Asia/Jayapura"`
 	PharmacyOperations      []PharmacyOperationCreateRequest      `json:"pharmacy_operations" binding:"required,min=1,dive,required"`
 	PharmacyShipmentMethods []PharmacyShipmentMethodCreateRequest `json:"pharmacy_shipment_methods" binding:"required,min=1,dive,required"`
 }
@@ -225,6 +230,7 @@ func PharmacyCreateToDetails(p PharmacyCreateRequest) domain.PharmacyCreateDetai
 		PharmacistName:    p.PharmacistName,
 		PharmacistPhone:   p.PharmacistPhone,
 		PharmacistLicense: p.PharmacistLicense,
+		Timezone:          p.Timezone,
 		PharmacyOperations: util.MapSlice(p.PharmacyOperations, func(p PharmacyOperationCreateRequest) domain.PharmacyOperationCreateDetails {
 			return p.ToEntity()
 		}),
@@ -241,6 +247,7 @@ type PharmacyUpdateRequest struct {
 	PharmacistName    string        `json:"pharmacist_name" binding:"omitempty,no_leading_trailing_space"`
 	PharmacistLicense string        `json:"pharmacist_license" binding:"omitempty,no_leading_trailing_space"`
 	PharmacistPhone   string        `json:"pharmacist_phone" binding:"omitempty,no_leading_trailing_space"`
+	Timezone          string        `json:"timezone" binding:"omitempty,oneof=Asia/Jakarta Asia/Makassar Asia/Jayapura"`
 }
 
 func PharmacyUpdateRequestToDetails(p PharmacyUpdateRequest, slug string) domain.PharmacyUpdateDetails {
@@ -252,6 +259,7 @@ func PharmacyUpdateRequestToDetails(p PharmacyUpdateRequest, slug string) domain
 		PharmacistName:    p.PharmacistName,
 		PharmacistLicense: p.PharmacistLicense,
 		PharmacistPhone:   p.PharmacistPhone,
+		Timezone:          p.Timezone,
 	}
 }
 
@@ -301,7 +309,10 @@ type PharmacyListQuery struct {
 	Limit       *int     `form:"limit"`
 	Page        *int     `form:"page"`
 	IsOpen      *bool    `form:"is_open"`
+	OpenAt      *string  `form:"open_at"`
 	ProductSlug *string  `form:"product_slug"`
+	VariantID   *int64   `form:"variant_id"`
+	ListParams
 }
 
 func (p PharmacyListQuery) ToDetails() (domain.PharmaciesQuery, error) {
@@ -314,18 +325,15 @@ func (p PharmacyListQuery) ToDetails() (domain.PharmaciesQuery, error) {
 		Latitude:    p.Latitude,
 		Limit:       10,
 		Page:        1,
-		SortBy:      "name",
-		SortType:    "asc",
+		Sorts:       p.ToSorts(),
+		Filters:     p.ToFilters(),
 		IsOpen:      p.IsOpen,
 		ProductSlug: p.ProductSlug,
+		VariantID:   p.VariantID,
 	}
 
-	if p.Sort != nil {
-		query.SortType = *p.Sort
-	}
-
-	if p.SortBy != nil {
-		query.SortBy = *p.SortBy
+	if len(query.Sorts) == 0 {
+		query.Sorts = []domain.SortField{toLegacySort(p.SortBy, p.Sort, domain.PharmacySortByName, false)}
 	}
 
 	if p.Limit != nil {
@@ -336,6 +344,15 @@ func (p PharmacyListQuery) ToDetails() (domain.PharmaciesQuery, error) {
 		query.Page = *p.Page
 	}
 
+	if p.OpenAt != nil {
+		openAt, err := time.Parse(time.RFC3339, *p.OpenAt)
+		if err != nil {
+			return domain.PharmaciesQuery{}, err
+		}
+
+		query.OpenAt = &openAt
+	}
+
 	if p.StartTime != nil {
 		_, err := time.Parse("15:04", *p.StartTime)
 		if err != nil {
@@ -356,3 +373,73 @@ func (p PharmacyListQuery) ToDetails() (domain.PharmaciesQuery, error) {
 
 	return query, nil
 }
+
+type PharmacyOpeningQuery struct {
+	At *string `form:"at" binding:"omitempty"`
+}
+
+func (q PharmacyOpeningQuery) ToTime() (time.Time, error) {
+	if q.At == nil {
+		return time.Now(), nil
+	}
+
+	return time.Parse(time.RFC3339, *q.At)
+}
+
+type PharmacyOpeningStatusResponse struct {
+	IsOpen      bool       `json:"is_open"`
+	At          time.Time  `json:"at"`
+	NextOpening *time.Time `json:"next_opening"`
+	Timezone    string     `json:"timezone"`
+	Zone        string     `json:"zone"`
+}
+
+func NewPharmacyOpeningStatusResponse(s domain.PharmacyOpeningStatus) PharmacyOpeningStatusResponse {
+	zone, _ := s.At.Zone()
+
+	return PharmacyOpeningStatusResponse{
+		IsOpen:      s.IsOpen,
+		At:          s.At,
+		NextOpening: s.NextOpening,
+		Timezone:    s.Timezone,
+		Zone:        zone,
+	}
+}
+
+type PharmacyHolidayPathRequest struct {
+	Slug string `uri:"slug" binding:"required"`
+	ID   int64  `uri:"id" binding:"required,min=1"`
+}
+
+type PharmacyHolidayCreateRequest struct {
+	Date   string `json:"date" binding:"required,datetime=2006-01-02"`
+	Reason string `json:"reason" binding:"required,no_leading_trailing_space"`
+}
+
+func (r PharmacyHolidayCreateRequest) ToEntity() (domain.PharmacyHoliday, error) {
+	date, err := time.Parse("2006-01-02", r.Date)
+	if err != nil {
+		return domain.PharmacyHoliday{}, err
+	}
+
+	return domain.PharmacyHoliday{
+		Date:   date,
+		Reason: r.Reason,
+	}, nil
+}
+
+type PharmacyHolidayResponse struct {
+	ID         int64  `json:"id"`
+	PharmacyID int64  `json:"pharmacy_id"`
+	Date       string `json:"date"`
+	Reason     string `json:"reason"`
+}
+
+func NewPharmacyHolidayResponse(h domain.PharmacyHoliday) PharmacyHolidayResponse {
+	return PharmacyHolidayResponse{
+		ID:         h.ID,
+		PharmacyID: h.PharmacyID,
+		Date:       h.Date.Format("2006-01-02"),
+		Reason:     h.Reason,
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"medichat-be/constants"
 	"medichat-be/domain"
 	"mime/multipart"
+	"strings"
 )
 
 type PharmacyManagerResponse struct {
@@ -84,33 +85,30 @@ type GetPharmacyManagerQuery struct {
 	Page       int64   `form:"page" binding:"numeric,omitempty,min=1"`
 	Limit      int64   `form:"limit" binding:"numeric,omitempty,min=1"`
 	Level      int64   `form:"level" binding:"numeric,omitempty,oneof=1 2"`
-	SortBy     string  `form:"sort_by" binding:"omitempty,oneof=created_at"`
+	SortBy     string  `form:"sort_by" binding:"omitempty,oneof=created_at name email"`
 	SortType   string  `form:"sort_type" binding:"omitempty,oneof=ASC DESC"`
 	Term       string  `form:"term"`
 	ProfileSet *string `form:"profile_set"`
+	ListParams
 }
 
 func (q *GetPharmacyManagerQuery) ToPharmacyManagerQuery() domain.PharmacyManagerQuery {
 	var page int64 = q.Page
-	var sortBy string = q.SortBy
-	var sortType string = q.SortType
 
 	if q.Page == 0 || q.Limit == 0 {
 		page = 1
 	}
-	if q.SortBy == "" {
-		sortBy = domain.PharmacyManagerSortByCreatedAt
-	}
-	if q.SortType == "" {
-		sortType = constants.SortDesc
-	}
 
-	if q.SortBy == domain.PharmacyManagerSortByCreatedAt {
-		if sortType == "ASC" {
-			sortType = constants.SortAsc
-		} else {
-			sortType = constants.SortDesc
+	sorts := q.ToSorts()
+	if len(sorts) == 0 {
+		sortBy := q.SortBy
+		if sortBy == "" {
+			sortBy = domain.PharmacyManagerSortByCreatedAt
 		}
+		sorts = []domain.SortField{{
+			Field: sortBy,
+			Desc:  q.SortType == "" || strings.EqualFold(q.SortType, constants.SortDesc),
+		}}
 	}
 
 	return domain.PharmacyManagerQuery{
@@ -118,8 +116,8 @@ func (q *GetPharmacyManagerQuery) ToPharmacyManagerQuery() domain.PharmacyManage
 		Limit:      q.Limit,
 		Level:      q.Level,
 		Term:       q.Term,
-		SortBy:     sortBy,
-		SortType:   sortType,
+		Sorts:      sorts,
+		Filters:    q.ToFilters(),
 		ProfileSet: q.ProfileSet,
 	}
 }
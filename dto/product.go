@@ -1,10 +1,12 @@
 package dto
 
 import (
-	"log"
 	"medichat-be/constants"
 	"medichat-be/domain"
+	"medichat-be/util"
 	"mime/multipart"
+	"strings"
+	"time"
 )
 
 type CreateProductForm struct {
@@ -24,6 +26,10 @@ type CreateProductForm struct {
 	Height                float64 `form:"height" binding:"required"`
 	Width                 float64 `form:"width" binding:"required"`
 
+	Status      string     `form:"status" binding:"omitempty,oneof=draft published discontinued"`
+	PublishAt   *time.Time `form:"publish_at" time_format:"2006-01-02T15:04:05Z07:00"`
+	UnpublishAt *time.Time `form:"unpublish_at" time_format:"2006-01-02T15:04:05Z07:00"`
+
 	Picture *multipart.FileHeader `form:"picture"`
 }
 
@@ -32,8 +38,25 @@ type ProductSlugParams struct {
 }
 
 type ProductsResponse struct {
-	Products []ProductResponse `json:"products"`
-	PageInfo PageInfoResponse  `json:"page_info"`
+	Products []ProductResponse              `json:"products"`
+	Facets   []ProductCategoryFacetResponse `json:"facets"`
+	PageInfo PageInfoResponse               `json:"page_info"`
+}
+
+type ProductCategoryFacetResponse struct {
+	CategoryID int64  `json:"category_id"`
+	Name       string `json:"name"`
+	Slug       string `json:"slug"`
+	Count      int64  `json:"count"`
+}
+
+func NewProductCategoryFacetResponse(f domain.ProductCategoryFacet) ProductCategoryFacetResponse {
+	return ProductCategoryFacetResponse{
+		CategoryID: f.CategoryID,
+		Name:       f.Name,
+		Slug:       f.Slug,
+		Count:      f.Count,
+	}
 }
 
 type UpdateProductForm struct {
@@ -64,18 +87,43 @@ type GetProductsQuery struct {
 	Term         string   `form:"term"`
 	Longitude    *float64 `form:"long"`
 	Latitude     *float64 `form:"lat"`
-	SortBy       string   `form:"sort_by" binding:"omitempty,oneof=name slug"`
+	SortBy       string   `form:"sort_by" binding:"omitempty,oneof=name slug relevance"`
 	SortType     string   `form:"sort_type" binding:"omitempty,oneof=ASC DESC"`
 	CategorySlug *string  `form:"category_slug"`
+	ListParams
+}
+
+type GetAdminProductsQuery struct {
+	GetProductsQuery
+	Status string `form:"status" binding:"omitempty,oneof=draft published discontinued"`
+}
+
+type ProductStateChangeRequest struct {
+	Slugs       []string   `json:"slugs" binding:"required,min=1,dive,required"`
+	Status      string     `json:"status" binding:"required,oneof=draft published discontinued"`
+	PublishAt   *time.Time `json:"publish_at"`
+	UnpublishAt *time.Time `json:"unpublish_at"`
+}
+
+func (r ProductStateChangeRequest) ToProductStateChange() domain.ProductStateChange {
+	return domain.ProductStateChange{
+		Slugs:       r.Slugs,
+		Status:      r.Status,
+		PublishAt:   r.PublishAt,
+		UnpublishAt: r.UnpublishAt,
+	}
 }
 
 type ProductResponse struct {
-	ID              int64   `json:"id"`
-	CategoryId      *int64  `json:"category_id,omitempty"`
-	ProductDetailId *int64  `json:"product_detail_id"`
-	Name            string  `json:"name"`
-	Slug            string  `json:"slug"`
-	Picture         *string `json:"photo_url,omitempty"`
+	ID              int64      `json:"id"`
+	CategoryId      *int64     `json:"category_id,omitempty"`
+	ProductDetailId *int64     `json:"product_detail_id"`
+	Name            string     `json:"name"`
+	Slug            string     `json:"slug"`
+	Picture         *string    `json:"photo_url,omitempty"`
+	Status          string     `json:"status"`
+	PublishAt       *time.Time `json:"publish_at,omitempty"`
+	UnpublishAt     *time.Time `json:"unpublish_at,omitempty"`
 }
 
 type ProductDetailResponse struct {
@@ -102,27 +150,36 @@ type ProductWithDetailResponse struct {
 	Name            string                         `json:"name"`
 	Slug            string                         `json:"slug"`
 	Picture         *string                        `json:"photo_url,omitempty"`
+	Status          string                         `json:"status"`
+	PublishAt       *time.Time                     `json:"publish_at,omitempty"`
+	UnpublishAt     *time.Time                     `json:"unpublish_at,omitempty"`
 	ProductDetail   ProductDetailResponse          `json:"product_detail"`
 	Category        CategoryWithParentNameResponse `json:"category"`
+	Images          []ProductImageResponse         `json:"images"`
+	Variants        []ProductVariantResponse       `json:"variants"`
 }
 
 func (q *GetProductsQuery) ToProductsQuery() domain.ProductsQuery {
 	var page int64 = q.Page
-	var sortBy string = q.SortBy
-	var sortType string = q.SortType
-
-	log.Println(sortBy, sortType)
-
 	if q.Page == 0 || q.Limit == 0 {
 		page = 1
 	}
 
-	if q.SortBy == "" {
-		sortBy = domain.ProductSortById
-	}
+	sorts := q.ToSorts()
+	if len(sorts) == 0 {
+		sortBy := q.SortBy
+		if sortBy == "" {
+			sortBy = domain.ProductSortById
+			if q.Term != "" {
+				sortBy = domain.ProductSortByRelevance
+			}
+		}
 
-	if q.SortType == "" {
-		sortType = constants.SortAsc
+		desc := strings.EqualFold(q.SortType, constants.SortDesc)
+		if q.SortType == "" {
+			desc = sortBy == domain.ProductSortByRelevance
+		}
+		sorts = []domain.SortField{{Field: sortBy, Desc: desc}}
 	}
 
 	return domain.ProductsQuery{
@@ -131,12 +188,19 @@ func (q *GetProductsQuery) ToProductsQuery() domain.ProductsQuery {
 		Term:         q.Term,
 		Latitude:     q.Latitude,
 		Longitude:    q.Longitude,
-		SortBy:       sortBy,
-		SortType:     sortType,
+		Sorts:        sorts,
+		Filters:      q.ToFilters(),
 		CategorySlug: q.CategorySlug,
 	}
 }
 
+func (q *GetAdminProductsQuery) ToProductsQuery() domain.ProductsQuery {
+	query := q.GetProductsQuery.ToProductsQuery()
+	query.IncludeUnpublished = true
+	query.Status = q.Status
+	return query
+}
+
 func NewProductResponse(c domain.Product) ProductResponse {
 	picture := c.Picture
 	if picture == nil {
@@ -150,6 +214,9 @@ func NewProductResponse(c domain.Product) ProductResponse {
 		CategoryId:      &c.ProductCategoryId,
 		ProductDetailId: &c.ProductDetailId,
 		Picture:         picture,
+		Status:          c.Status,
+		PublishAt:       c.PublishAt,
+		UnpublishAt:     c.UnpublishAt,
 	}
 }
 
@@ -186,18 +253,62 @@ func NewProductwithDetailResponse(p domain.Product, d domain.ProductDetails, c d
 		CategoryId:      &p.ProductCategoryId,
 		ProductDetailId: &p.ProductDetailId,
 		Picture:         picture,
+		Status:          p.Status,
+		PublishAt:       p.PublishAt,
+		UnpublishAt:     p.UnpublishAt,
 		ProductDetail:   NewProductDetail(d),
 		Category:        NewCategoryWithParentNameResponse(c),
+		Images:          util.MapSlice(p.Images, NewProductImageResponse),
+		Variants:        util.MapSlice(p.Variants, NewProductVariantResponse),
 	}
 }
 
-func NewProductsResponse(products []domain.Product, pageInfo domain.PageInfo) ProductsResponse {
+func NewProductsResponse(products []domain.Product, facets []domain.ProductCategoryFacet, pageInfo domain.PageInfo) ProductsResponse {
 	res := make([]ProductResponse, len(products))
 	for i := 0; i < len(products); i++ {
 		res[i] = NewProductResponse(products[i])
 	}
 	return ProductsResponse{
 		Products: res,
+		Facets:   util.MapSlice(facets, NewProductCategoryFacetResponse),
 		PageInfo: NewPageInfoResponse(pageInfo),
 	}
 }
+
+type ProductSuggestQuery struct {
+	Query string `form:"q" binding:"required,max=100"`
+	Limit *int   `form:"limit" binding:"omitempty,min=1,max=20"`
+}
+
+func (q ProductSuggestQuery) GetLimit() int {
+	if q.Limit == nil {
+		return constants.ProductSuggestDefaultLimit
+	}
+	return *q.Limit
+}
+
+type ProductSuggestionResponse struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+func NewProductSuggestionResponse(s domain.ProductSuggestion) ProductSuggestionResponse {
+	return ProductSuggestionResponse{
+		Name: s.Name,
+		Slug: s.Slug,
+	}
+}
+
+type ProductSuggestionsResponse struct {
+	Products     []ProductSuggestionResponse `json:"products"`
+	Categories   []ProductSuggestionResponse `json:"categories"`
+	GenericNames []string                    `json:"generic_names"`
+}
+
+func NewProductSuggestionsResponse(s domain.ProductSuggestions) ProductSuggestionsResponse {
+	return ProductSuggestionsResponse{
+		Products:     util.MapSlice(s.Products, NewProductSuggestionResponse),
+		Categories:   util.MapSlice(s.Categories, NewProductSuggestionResponse),
+		GenericNames: s.GenericNames,
+	}
+}
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"medichat-be/domain"
+	"mime/multipart"
+)
+
+type ProductImageResponse struct {
+	ID           int64  `json:"id"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	Position     int    `json:"position"`
+	IsPrimary    bool   `json:"is_primary"`
+}
+
+func NewProductImageResponse(img domain.ProductImage) ProductImageResponse {
+	return ProductImageResponse{
+		ID:           img.ID,
+		URL:          img.URL,
+		ThumbnailURL: img.ThumbnailURL,
+		Width:        img.Width,
+		Height:       img.Height,
+		Position:     img.Position,
+		IsPrimary:    img.IsPrimary,
+	}
+}
+
+type ProductImagePathRequest struct {
+	Slug string `uri:"slug" binding:"required"`
+	ID   int64  `uri:"id" binding:"required"`
+}
+
+type ProductImageForm struct {
+	Image *multipart.FileHeader `form:"image" binding:"required"`
+}
+
+type ProductImageOrderRequest struct {
+	ImageIDs []int64 `json:"image_ids" binding:"required,min=1,dive,required"`
+}
@@ -0,0 +1,231 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"medichat-be/constants"
+	"medichat-be/domain"
+	"medichat-be/util"
+	"mime/multipart"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+type ProductImportForm struct {
+	File *multipart.FileHeader `form:"file" binding:"required"`
+}
+
+type ProductExportQuery struct {
+	Format string `form:"format" binding:"required,oneof=csv xlsx"`
+}
+
+type ProductImportRowRequest struct {
+	Name                  string  `column:"name" binding:"required,no_leading_trailing_space,max=255"`
+	CategorySlug          string  `column:"category_slug" binding:"required,no_leading_trailing_space"`
+	GenericName           string  `column:"generic_name" binding:"required,no_leading_trailing_space"`
+	Composition           string  `column:"composition" binding:"required"`
+	Content               string  `column:"content" binding:"required"`
+	Manufacturer          string  `column:"manufacturer" binding:"required,no_leading_trailing_space"`
+	Description           string  `column:"description" binding:"required"`
+	ProductClassification string  `column:"product_classification" binding:"required,no_leading_trailing_space"`
+	ProductForm           string  `column:"product_form" binding:"required,no_leading_trailing_space"`
+	UnitInPack            string  `column:"unit_in_pack" binding:"required,no_leading_trailing_space"`
+	SellingUnit           string  `column:"selling_unit" binding:"required,no_leading_trailing_space"`
+	Weight                float64 `column:"weight" binding:"gt=0"`
+	Height                float64 `column:"height" binding:"gt=0"`
+	Length                float64 `column:"length" binding:"gt=0"`
+	Width                 float64 `column:"width" binding:"gt=0"`
+}
+
+func (r ProductImportRowRequest) ToRow(line int) domain.ProductImportRow {
+	return domain.ProductImportRow{
+		Line:         line,
+		CategorySlug: r.CategorySlug,
+		Product: domain.AddProductRequest{
+			Name:                  r.Name,
+			GenericName:           r.GenericName,
+			Composition:           r.Composition,
+			Content:               r.Content,
+			Manufacturer:          r.Manufacturer,
+			Description:           r.Description,
+			ProductClassification: r.ProductClassification,
+			ProductForm:           r.ProductForm,
+			UnitInPack:            r.UnitInPack,
+			SellingUnit:           r.SellingUnit,
+			Weight:                r.Weight,
+			Height:                r.Height,
+			Length:                r.Length,
+			Width:                 r.Width,
+		},
+	}
+}
+
+func ProductImportFormat(fh *multipart.FileHeader) (string, error) {
+	switch strings.ToLower(filepath.Ext(fh.Filename)) {
+	case ".csv":
+		return constants.ExportFormatCSV, nil
+	case ".xlsx":
+		return constants.ExportFormatXLSX, nil
+	default:
+		return "", fmt.Errorf("file must be a .csv or .xlsx spreadsheet")
+	}
+}
+
+func ParseProductImport(
+	r io.Reader,
+	format string,
+) ([]domain.ProductImportRow, []domain.ProductImportRowError, error) {
+	records, err := util.ReadSpreadsheet(r, format)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil, errors.New("file is empty")
+	}
+	if len(records)-1 > constants.ProductImportMaxRows {
+		return nil, nil, fmt.Errorf("file has more than %d rows", constants.ProductImportMaxRows)
+	}
+
+	header := map[string]int{}
+	for i, h := range records[0] {
+		header[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, h := range constants.ProductCatalogHeader {
+		if _, ok := header[h]; !ok {
+			return nil, nil, fmt.Errorf("missing column %s", h)
+		}
+	}
+
+	rows := make([]domain.ProductImportRow, 0, len(records)-1)
+	rowErrors := make([]domain.ProductImportRowError, 0)
+
+	for i, record := range records[1:] {
+		line := i + 2
+		if isBlankRecord(record) {
+			continue
+		}
+
+		req, err := parseProductImportRecord(record, header)
+		if err == nil {
+			err = binding.Validator.ValidateStruct(&req)
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, domain.ProductImportRowError{
+				Line:    line,
+				Message: productImportErrorMessage(err),
+			})
+			continue
+		}
+
+		rows = append(rows, req.ToRow(line))
+	}
+
+	return rows, rowErrors, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func parseProductImportRecord(
+	record []string,
+	header map[string]int,
+) (ProductImportRowRequest, error) {
+	var req ProductImportRowRequest
+
+	v := reflect.ValueOf(&req).Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		column := t.Field(i).Tag.Get("column")
+
+		value := ""
+		if idx := header[column]; idx < len(record) {
+			value = record[idx]
+		}
+
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(value)
+		case reflect.Float64:
+			if strings.TrimSpace(value) == "" {
+				continue
+			}
+			f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				return ProductImportRowRequest{}, fmt.Errorf("%s: %q is not a number", column, value)
+			}
+			field.SetFloat(f)
+		}
+	}
+
+	return req, nil
+}
+
+func productImportErrorMessage(err error) string {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return err.Error()
+	}
+
+	t := reflect.TypeOf(ProductImportRowRequest{})
+	msgs := make([]string, len(verrs))
+	for i, fe := range verrs {
+		column := fe.Field()
+		if f, ok := t.FieldByName(fe.StructField()); ok {
+			column = f.Tag.Get("column")
+		}
+		msgs[i] = fmt.Sprintf("%s: failed on '%s' validation", column, fe.Tag())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+type ProductImportRowErrorResponse struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+func NewProductImportRowErrorResponse(e domain.ProductImportRowError) ProductImportRowErrorResponse {
+	return ProductImportRowErrorResponse{
+		Line:    e.Line,
+		Message: e.Message,
+	}
+}
+
+type ProductImportResultResponse struct {
+	Created int                             `json:"created"`
+	Updated int                             `json:"updated"`
+	Failed  int                             `json:"failed"`
+	Errors  []ProductImportRowErrorResponse `json:"errors"`
+}
+
+func NewProductImportResultResponse(
+	result domain.ProductImportResult,
+	parseErrors []domain.ProductImportRowError,
+) ProductImportResultResponse {
+	errs := append(parseErrors, result.Errors...)
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Line < errs[j].Line
+	})
+
+	return ProductImportResultResponse{
+		Created: result.Created,
+		Updated: result.Updated,
+		Failed:  len(errs),
+		Errors:  util.MapSlice(errs, NewProductImportRowErrorResponse),
+	}
+}
@@ -0,0 +1,29 @@
+package dto
+
+import "medichat-be/domain"
+
+type ProductSynonymRequest struct {
+	Term    string `json:"term" binding:"required,no_leading_trailing_space,max=100"`
+	Synonym string `json:"synonym" binding:"required,no_leading_trailing_space,max=100"`
+}
+
+func (r ProductSynonymRequest) ToSynonym() domain.ProductSynonym {
+	return domain.ProductSynonym{
+		Term:    r.Term,
+		Synonym: r.Synonym,
+	}
+}
+
+type ProductSynonymResponse struct {
+	ID      int64  `json:"id"`
+	Term    string `json:"term"`
+	Synonym string `json:"synonym"`
+}
+
+func NewProductSynonymResponse(s domain.ProductSynonym) ProductSynonymResponse {
+	return ProductSynonymResponse{
+		ID:      s.ID,
+		Term:    s.Term,
+		Synonym: s.Synonym,
+	}
+}
@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"medichat-be/domain"
+	"strings"
+)
+
+type ProductVariantResponse struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Strength    string `json:"strength"`
+	PackSize    int    `json:"pack_size"`
+	SellingUnit string `json:"selling_unit"`
+	Form        string `json:"form"`
+}
+
+func NewProductVariantResponse(v domain.ProductVariant) ProductVariantResponse {
+	return ProductVariantResponse{
+		ID:          v.ID,
+		Name:        v.Name,
+		Strength:    v.Strength,
+		PackSize:    v.PackSize,
+		SellingUnit: v.SellingUnit,
+		Form:        v.Form,
+	}
+}
+
+type ProductVariantRefResponse struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func NewProductVariantRefResponse(v *domain.ProductVariantRef) *ProductVariantRefResponse {
+	if v == nil {
+		return nil
+	}
+	return &ProductVariantRefResponse{
+		ID:   v.ID,
+		Name: v.Name,
+	}
+}
+
+type ProductVariantRequest struct {
+	Name        string `json:"name" binding:"required,no_leading_trailing_space,max=100"`
+	Strength    string `json:"strength" binding:"max=50"`
+	PackSize    int    `json:"pack_size" binding:"required,min=1"`
+	SellingUnit string `json:"selling_unit" binding:"required,max=50"`
+	Form        string `json:"form" binding:"required,max=50"`
+}
+
+func (r ProductVariantRequest) ToVariant(id int64) domain.ProductVariant {
+	return domain.ProductVariant{
+		ID:          id,
+		Name:        r.Name,
+		Strength:    strings.TrimSpace(r.Strength),
+		PackSize:    r.PackSize,
+		SellingUnit: strings.TrimSpace(r.SellingUnit),
+		Form:        strings.TrimSpace(r.Form),
+	}
+}
+
+type ProductVariantPathRequest struct {
+	Slug string `uri:"slug" binding:"required"`
+	ID   int64  `uri:"id" binding:"required"`
+}
@@ -0,0 +1,103 @@
+package dto
+
+import (
+	"medichat-be/domain"
+	"time"
+)
+
+type PromotionRequest struct {
+	Name string `json:"name" binding:"required,no_leading_trailing_space,max=100"`
+
+	DiscountType  string `json:"discount_type" binding:"required,oneof=percentage fixed"`
+	DiscountValue int    `json:"discount_value" binding:"required,min=1"`
+
+	Scope      string `json:"scope" binding:"required,oneof=product category pharmacy"`
+	ProductID  *int64 `json:"product_id" binding:"omitempty,min=1"`
+	CategoryID *int64 `json:"category_id" binding:"omitempty,min=1"`
+	PharmacyID *int64 `json:"pharmacy_id" binding:"omitempty,min=1"`
+
+	StartsAt time.Time  `json:"starts_at" binding:"required"`
+	EndsAt   *time.Time `json:"ends_at"`
+
+	UsageLimit *int `json:"usage_limit" binding:"omitempty,min=1"`
+}
+
+func (r PromotionRequest) ToPromotion(id int64) domain.Promotion {
+	return domain.Promotion{
+		ID:            id,
+		Name:          r.Name,
+		DiscountType:  r.DiscountType,
+		DiscountValue: r.DiscountValue,
+		Scope:         r.Scope,
+		ProductID:     r.ProductID,
+		CategoryID:    r.CategoryID,
+		PharmacyID:    r.PharmacyID,
+		StartsAt:      r.StartsAt,
+		EndsAt:        r.EndsAt,
+		UsageLimit:    r.UsageLimit,
+	}
+}
+
+type PromotionListQuery struct {
+	Scope  *string `form:"scope" binding:"omitempty,oneof=product category pharmacy"`
+	Active bool    `form:"active"`
+
+	Page  *int `form:"page" binding:"omitempty,min=1"`
+	Limit *int `form:"limit" binding:"omitempty,min=1"`
+}
+
+func (q PromotionListQuery) ToDetails() domain.PromotionListDetails {
+	ret := domain.PromotionListDetails{
+		Scope:      q.Scope,
+		ActiveOnly: q.Active,
+		Page:       1,
+		Limit:      10,
+	}
+
+	if q.Page != nil {
+		ret.Page = *q.Page
+	}
+	if q.Limit != nil {
+		ret.Limit = *q.Limit
+	}
+
+	return ret
+}
+
+type PromotionResponse struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+
+	DiscountType  string `json:"discount_type"`
+	DiscountValue int    `json:"discount_value"`
+
+	Scope      string `json:"scope"`
+	ProductID  *int64 `json:"product_id"`
+	CategoryID *int64 `json:"category_id"`
+	PharmacyID *int64 `json:"pharmacy_id"`
+
+	StartsAt time.Time  `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+
+	UsageLimit *int `json:"usage_limit"`
+	UsageCount int  `json:"usage_count"`
+}
+
+func NewPromotionResponse(p domain.Promotion) PromotionResponse {
+	return PromotionResponse(p)
+}
+
+type PromotionRefResponse struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func NewPromotionRefResponse(p *domain.PromotionRef) *PromotionRefResponse {
+	if p == nil {
+		return nil
+	}
+	return &PromotionRefResponse{
+		ID:   p.ID,
+		Name: p.Name,
+	}
+}
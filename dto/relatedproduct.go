@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"medichat-be/domain"
+	"medichat-be/util"
+)
+
+type RelatedProductsQuery struct {
+	Latitude  *float64 `form:"lat" binding:"required,min=-90,max=90"`
+	Longitude *float64 `form:"long" binding:"required,min=-180,max=180"`
+	Limit     int64    `form:"limit" binding:"omitempty,min=1,max=20"`
+}
+
+func (q RelatedProductsQuery) ToRelatedProductsQuery() domain.RelatedProductsQuery {
+	return domain.RelatedProductsQuery{
+		Latitude:  *q.Latitude,
+		Longitude: *q.Longitude,
+		Limit:     q.Limit,
+	}
+}
+
+type RelatedProductsResponse struct {
+	FrequentlyBoughtTogether []ProductResponse `json:"frequently_bought_together"`
+	Alternatives             []ProductResponse `json:"alternatives"`
+	BestSellers              []ProductResponse `json:"best_sellers"`
+}
+
+func NewRelatedProductsResponse(r domain.RelatedProducts) RelatedProductsResponse {
+	return RelatedProductsResponse{
+		FrequentlyBoughtTogether: util.MapSlice(r.FrequentlyBoughtTogether, NewProductResponse),
+		Alternatives:             util.MapSlice(r.Alternatives, NewProductResponse),
+		BestSellers:              util.MapSlice(r.BestSellers, NewProductResponse),
+	}
+}
+
+type ComputeAssociationsResponse struct {
+	Associations int64 `json:"associations"`
+}
@@ -1,15 +1,112 @@
 package dto
 
-import "medichat-be/domain"
+import (
+	"medichat-be/domain"
+	"mime/multipart"
+)
 
 type SpecializationResponse struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
+	ID          int64   `json:"id"`
+	Name        string  `json:"name"`
+	Slug        string  `json:"slug"`
+	Description string  `json:"description,omitempty"`
+	IconURL     *string `json:"icon_url,omitempty"`
 }
 
 func NewSpecializationResponse(s domain.Specialization) SpecializationResponse {
 	return SpecializationResponse{
-		ID:   s.ID,
-		Name: s.Name,
+		ID:          s.ID,
+		Name:        s.Name,
+		Slug:        s.Slug,
+		Description: s.Description,
+		IconURL:     s.IconURL,
 	}
 }
+
+type SpecializationWithDoctorCountResponse struct {
+	SpecializationResponse
+	DoctorCount int `json:"doctor_count"`
+}
+
+func NewSpecializationWithDoctorCountResponse(
+	s domain.SpecializationWithDoctorCount,
+) SpecializationWithDoctorCountResponse {
+	return SpecializationWithDoctorCountResponse{
+		SpecializationResponse: NewSpecializationResponse(s.Specialization),
+		DoctorCount:            s.DoctorCount,
+	}
+}
+
+type SpecializationSlugRequest struct {
+	Slug string `uri:"slug" binding:"required"`
+}
+
+type SpecializationCreateRequest = MultipartForm[
+	struct {
+		Icon *multipart.FileHeader `form:"icon" binding:"omitempty,content_type=image/png"`
+	},
+	struct {
+		Name           string  `json:"name" binding:"required,no_leading_trailing_space"`
+		Description    string  `json:"description" binding:"no_leading_trailing_space"`
+		CommissionRate float64 `json:"commission_rate" binding:"min=0,max=100"`
+	},
+]
+
+func SpecializationCreateRequestToDetails(
+	r SpecializationCreateRequest,
+) (domain.SpecializationCreateDetails, error) {
+	d := r.Data
+	ret := domain.SpecializationCreateDetails{
+		Name:           d.Name,
+		Description:    d.Description,
+		CommissionRate: d.CommissionRate,
+	}
+
+	if r.Form.Icon != nil {
+		f, err := r.Form.Icon.Open()
+		if err != nil {
+			return domain.SpecializationCreateDetails{}, err
+		}
+		ret.Icon = f
+	}
+
+	return ret, nil
+}
+
+type SpecializationUpdateRequest = MultipartForm[
+	struct {
+		Icon *multipart.FileHeader `form:"icon" binding:"omitempty,content_type=image/png"`
+	},
+	struct {
+		Name           *string  `json:"name" binding:"omitempty,no_leading_trailing_space"`
+		Description    *string  `json:"description" binding:"omitempty,no_leading_trailing_space"`
+		CommissionRate *float64 `json:"commission_rate" binding:"omitempty,min=0,max=100"`
+	},
+]
+
+func SpecializationUpdateRequestToDetails(
+	id int64,
+	r SpecializationUpdateRequest,
+) (domain.SpecializationUpdateDetails, error) {
+	d := r.Data
+	ret := domain.SpecializationUpdateDetails{
+		ID:             id,
+		Name:           d.Name,
+		Description:    d.Description,
+		CommissionRate: d.CommissionRate,
+	}
+
+	if r.Form.Icon != nil {
+		f, err := r.Form.Icon.Open()
+		if err != nil {
+			return domain.SpecializationUpdateDetails{}, err
+		}
+		ret.Icon = f
+	}
+
+	return ret, nil
+}
+
+type SpecializationCommissionRequest struct {
+	CommissionRate *float64 `json:"commission_rate" binding:"required,min=0,max=100"`
+}
@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"medichat-be/constants"
 	"medichat-be/domain"
 	"time"
 )
@@ -8,11 +9,15 @@ import (
 type StockResponse struct {
 	ID int64 `json:"id"`
 
-	ProductID  int64 `json:"product_id"`
-	PharmacyID int64 `json:"pharmacy_id"`
+	ProductID  int64  `json:"product_id"`
+	VariantID  *int64 `json:"variant_id"`
+	PharmacyID int64  `json:"pharmacy_id"`
 
 	Stock int `json:"stock"`
 	Price int `json:"price"`
+
+	MinStock     int `json:"min_stock"`
+	ReorderLevel int `json:"reorder_level"`
 }
 
 func NewStockResponse(s domain.Stock) StockResponse {
@@ -27,26 +32,57 @@ type StockJoinedResponse struct {
 		Slug string `json:"slug"`
 		Name string `json:"name"`
 	} `json:"product"`
+	Variant  *ProductVariantRefResponse `json:"variant"`
 	Pharmacy struct {
 		ID   int64  `json:"id"`
 		Slug string `json:"slug"`
 		Name string `json:"name"`
 	} `json:"pharmacy"`
 
-	Stock int `json:"stock"`
-	Price int `json:"price"`
+	Stock     int `json:"stock"`
+	Reserved  int `json:"reserved"`
+	Available int `json:"available"`
+	Price     int `json:"price"`
+
+	MinStock     int  `json:"min_stock"`
+	ReorderLevel int  `json:"reorder_level"`
+	IsLow        bool `json:"is_low"`
 }
 
 func NewStockJoinedResponse(s domain.StockJoined) StockJoinedResponse {
-	return StockJoinedResponse(s)
+	return StockJoinedResponse{
+		ID: s.ID,
+		Product: struct {
+			ID   int64  "json:\"id\""
+			Slug string "json:\"slug\""
+			Name string "json:\"name\""
+		}(s.Product),
+		Variant: NewProductVariantRefResponse(s.Variant),
+		Pharmacy: struct {
+			ID   int64  "json:\"id\""
+			Slug string "json:\"slug\""
+			Name string "json:\"name\""
+		}(s.Pharmacy),
+		Stock:        s.Stock,
+		Reserved:     s.Reserved,
+		Available:    s.Available(),
+		Price:        s.Price,
+		MinStock:     s.MinStock,
+		ReorderLevel: s.ReorderLevel,
+		IsLow:        s.IsLow(),
+	}
 }
 
 type StockCreateRequest struct {
 	ProductSlug  string `json:"product_slug" binding:"required"`
+	VariantID    *int64 `json:"variant_id" binding:"omitempty,min=1"`
 	PharmacySlug string `json:"pharmacy_slug" binding:"required"`
 
 	Stock int `json:"stock" binding:"min=0"`
 	Price int `json:"price" binding:"min=0"`
+
+	MinStock     int `json:"min_stock" binding:"min=0"`
+	ReorderLevel int `json:"reorder_level" binding:"min=0,gtefield=MinStock"`
 }
 
 func (r StockCreateRequest) ToDetails() domain.StockCreateDetail {
@@ -58,6 +94,9 @@ type StockUpdateRequest struct {
 
 	Stock *int `json:"stock" binding:"omitempty,min=0"`
 	Price *int `json:"price" binding:"omitempty,min=0"`
+
+	MinStock     *int `json:"min_stock" binding:"omitempty,min=0"`
+	ReorderLevel *int `json:"reorder_level" binding:"omitempty,min=0"`
 }
 
 func (r StockUpdateRequest) ToDetails() domain.StockUpdateDetail {
@@ -102,6 +141,7 @@ type StockMutationJoinedResponse struct {
 		Slug string `json:"slug"`
 		Name string `json:"name"`
 	} `json:"product"`
+	Variant *ProductVariantRefResponse `json:"variant"`
 
 	Method string `json:"method"`
 	Status string `json:"status"`
@@ -145,6 +185,7 @@ func NewStockMutationJoinedResponse(s domain.StockMutationJoined) StockMutationJ
 			Slug: s.Product.Slug,
 			Name: s.Product.Name,
 		},
+		Variant:   NewProductVariantRefResponse(s.Variant),
 		Method:    s.Method,
 		Status:    s.Status,
 		Amount:    s.Amount,
@@ -156,6 +197,7 @@ type StockTransferRequest struct {
 	SourcePharmacySlug string `json:"source_pharmacy_slug" binding:"required"`
 	TargetPharmacySlug string `json:"target_pharmacy_slug" binding:"required"`
 	ProductSlug        string `json:"product_slug" binding:"required"`
+	VariantID          *int64 `json:"variant_id" binding:"omitempty,min=1"`
 	Amount             int    `json:"amount" binding:"min=1"`
 }
 
@@ -165,11 +207,14 @@ func (r StockTransferRequest) ToRequest() domain.StockTransferRequest {
 
 type StockListQuery struct {
 	ProductSlug  *string `form:"product_slug"`
+	VariantID    *int64  `form:"variant_id"`
 	ProductName  *string `form:"product_name"`
 	PharmacySlug *string `form:"pharmacy_slug"`
+	Low          bool    `form:"low"`
 
 	SortBy *string `form:"sort_by"`
 	Sort   *string `form:"sort"`
+	ListParams
 
 	Page  *int `form:"page"`
 	Limit *int `form:"limit"`
@@ -178,19 +223,18 @@ type StockListQuery struct {
 func (q StockListQuery) ToDetails() domain.StockListDetails {
 	ret := domain.StockListDetails{
 		ProductSlug:  q.ProductSlug,
+		VariantID:    q.VariantID,
 		ProductName:  q.ProductName,
 		PharmacySlug: q.PharmacySlug,
-		SortBy:       domain.StockSortByProductName,
-		SortAsc:      true,
+		LowOnly:      q.Low,
+		Sorts:        q.ToSorts(),
+		Filters:      q.ToFilters(),
 		Page:         1,
 		Limit:        10,
 	}
 
-	if q.SortBy != nil {
-		ret.SortBy = *q.SortBy
-	}
-	if q.Sort != nil {
-		ret.SortAsc = *q.Sort == "asc"
+	if len(ret.Sorts) == 0 {
+		ret.Sorts = []domain.SortField{toLegacySort(q.SortBy, q.Sort, domain.StockSortByProductName, false)}
 	}
 	if q.Page != nil {
 		ret.Page = *q.Page
@@ -214,6 +258,7 @@ type StockMutationListQuery struct {
 
 	SortBy *string `form:"sort_by"`
 	Sort   *string `form:"sort"`
+	ListParams
 
 	Page  *int `form:"page"`
 	Limit *int `form:"limit"`
@@ -227,18 +272,136 @@ func (q StockMutationListQuery) ToDetails() domain.StockMutationListDetails {
 		TargetPharmacySlug: q.TargetPharmacySlug,
 		Method:             q.Method,
 		Status:             q.Status,
-		SortBy:             "created_at",
-		SortAsc:            false,
+		Sorts:              q.ToSorts(),
+		Filters:            q.ToFilters(),
 		Page:               1,
 		Limit:              10,
 	}
 
-	if q.SortBy != nil {
-		ret.SortBy = *q.SortBy
+	if len(ret.Sorts) == 0 {
+		ret.Sorts = []domain.SortField{toLegacySort(q.SortBy, q.Sort, domain.StockSortByCreatedAt, true)}
+	}
+	if q.Page != nil {
+		ret.Page = *q.Page
+	}
+	if q.Limit != nil {
+		ret.Limit = *q.Limit
+	}
+
+	return ret
+}
+
+type StockBatchCreateRequest struct {
+	LotNumber  string `json:"lot_number" binding:"required,no_leading_trailing_space"`
+	ExpiryDate string `json:"expiry_date" binding:"required,datetime=2006-01-02"`
+	CostPrice  int    `json:"cost_price" binding:"min=0"`
+	Quantity   int    `json:"quantity" binding:"required,min=1"`
+}
+
+func (r StockBatchCreateRequest) ToDetails(stockID int64) (domain.StockBatchCreateDetail, error) {
+	expiryDate, err := time.Parse("2006-01-02", r.ExpiryDate)
+	if err != nil {
+		return domain.StockBatchCreateDetail{}, err
+	}
+
+	return domain.StockBatchCreateDetail{
+		StockID:    stockID,
+		LotNumber:  r.LotNumber,
+		ExpiryDate: expiryDate,
+		CostPrice:  r.CostPrice,
+		Quantity:   r.Quantity,
+	}, nil
+}
+
+type StockBatchResponse struct {
+	ID      int64 `json:"id"`
+	StockID int64 `json:"stock_id"`
+
+	LotNumber  string `json:"lot_number"`
+	ExpiryDate string `json:"expiry_date"`
+	CostPrice  int    `json:"cost_price"`
+	Quantity   int    `json:"quantity"`
+	IsExpired  bool   `json:"is_expired"`
+
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+func NewStockBatchResponse(b domain.StockBatch) StockBatchResponse {
+	return StockBatchResponse{
+		ID:         b.ID,
+		StockID:    b.StockID,
+		LotNumber:  b.LotNumber,
+		ExpiryDate: b.ExpiryDate.Format("2006-01-02"),
+		CostPrice:  b.CostPrice,
+		Quantity:   b.Quantity,
+		IsExpired:  b.IsExpired(time.Now()),
+		ReceivedAt: b.ReceivedAt,
+	}
+}
+
+type StockBatchJoinedResponse struct {
+	StockBatchResponse
+
+	Product struct {
+		ID   int64  `json:"id"`
+		Slug string `json:"slug"`
+		Name string `json:"name"`
+	} `json:"product"`
+	Variant  *ProductVariantRefResponse `json:"variant"`
+	Pharmacy struct {
+		ID   int64  `json:"id"`
+		Slug string `json:"slug"`
+		Name string `json:"name"`
+	} `json:"pharmacy"`
+}
+
+func NewStockBatchJoinedResponse(b domain.StockBatchJoined) StockBatchJoinedResponse {
+	return StockBatchJoinedResponse{
+		StockBatchResponse: NewStockBatchResponse(b.StockBatch),
+		Product: struct {
+			ID   int64  "json:\"id\""
+			Slug string "json:\"slug\""
+			Name string "json:\"name\""
+		}(b.Product),
+		Variant: NewProductVariantRefResponse(b.Variant),
+		Pharmacy: struct {
+			ID   int64  "json:\"id\""
+			Slug string "json:\"slug\""
+			Name string "json:\"name\""
+		}(b.Pharmacy),
+	}
+}
+
+type ExpiringStockQuery struct {
+	Days         *int    `form:"days" binding:"omitempty,min=0,max=365"`
+	PharmacySlug *string `form:"pharmacy_slug"`
+}
+
+func (q ExpiringStockQuery) ToDetails() domain.ExpiringStockQuery {
+	ret := domain.ExpiringStockQuery{
+		Days:         constants.StockExpiringDefaultDays,
+		PharmacySlug: q.PharmacySlug,
+	}
+
+	if q.Days != nil {
+		ret.Days = *q.Days
 	}
-	if q.Sort != nil {
-		ret.SortAsc = *q.Sort == "asc"
+
+	return ret
+}
+
+type StockLedgerQuery struct {
+	Page  *int `form:"page" binding:"omitempty,min=1"`
+	Limit *int `form:"limit" binding:"omitempty,min=1"`
+}
+
+func (q StockLedgerQuery) ToDetails(stockID int64) domain.StockLedgerQuery {
+	ret := domain.StockLedgerQuery{
+		StockID: stockID,
+		Page:    1,
+		Limit:   10,
 	}
+
 	if q.Page != nil {
 		ret.Page = *q.Page
 	}
@@ -248,3 +411,86 @@ func (q StockMutationListQuery) ToDetails() domain.StockMutationListDetails {
 
 	return ret
 }
+
+type StockLedgerEntryResponse struct {
+	ID      int64 `json:"id"`
+	StockID int64 `json:"stock_id"`
+
+	Reason      string `json:"reason"`
+	ReferenceID *int64 `json:"reference_id"`
+	ActorID     *int64 `json:"actor_id"`
+
+	Change       int `json:"change"`
+	BalanceAfter int `json:"balance_after"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewStockLedgerEntryResponse(e domain.StockLedgerEntry) StockLedgerEntryResponse {
+	return StockLedgerEntryResponse{
+		ID:           e.ID,
+		StockID:      e.StockID,
+		Reason:       e.Reason,
+		ReferenceID:  e.ReferenceID,
+		ActorID:      e.ActorID,
+		Change:       e.Change,
+		BalanceAfter: e.BalanceAfter,
+		CreatedAt:    e.CreatedAt,
+	}
+}
+
+type StockLedgerCheckResponse struct {
+	StockID int64 `json:"stock_id"`
+
+	Balance        int  `json:"balance"`
+	RebuiltBalance int  `json:"rebuilt_balance"`
+	EntryCount     int  `json:"entry_count"`
+	Consistent     bool `json:"consistent"`
+
+	MismatchEntryID *int64 `json:"mismatch_entry_id"`
+}
+
+func NewStockLedgerCheckResponse(c domain.StockLedgerCheck) StockLedgerCheckResponse {
+	return StockLedgerCheckResponse{
+		StockID:         c.StockID,
+		Balance:         c.Balance,
+		RebuiltBalance:  c.RebuiltBalance,
+		EntryCount:      c.EntryCount,
+		Consistent:      c.IsConsistent(),
+		MismatchEntryID: c.MismatchEntryID,
+	}
+}
+
+type StockPriceScheduleRequest struct {
+	Price       int       `json:"price" binding:"min=0"`
+	EffectiveAt time.Time `json:"effective_at" binding:"required"`
+}
+
+func (r StockPriceScheduleRequest) ToDetails(stockID int64) domain.StockPriceScheduleDetail {
+	return domain.StockPriceScheduleDetail{
+		StockID:     stockID,
+		Price:       r.Price,
+		EffectiveAt: r.EffectiveAt,
+	}
+}
+
+type StockPricePathRequest struct {
+	ID      int64 `uri:"id" binding:"required"`
+	PriceID int64 `uri:"price_id" binding:"required"`
+}
+
+type StockPriceResponse struct {
+	ID      int64 `json:"id"`
+	StockID int64 `json:"stock_id"`
+
+	Price   int    `json:"price"`
+	Status  string `json:"status"`
+	ActorID *int64 `json:"actor_id"`
+
+	EffectiveAt time.Time `json:"effective_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func NewStockPriceResponse(p domain.StockPrice) StockPriceResponse {
+	return StockPriceResponse(p)
+}
@@ -0,0 +1,200 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"medichat-be/constants"
+	"medichat-be/domain"
+	"medichat-be/util"
+	"mime/multipart"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type StockImportForm struct {
+	File   *multipart.FileHeader `form:"file" binding:"required"`
+	DryRun bool                  `form:"dry_run"`
+}
+
+// ParseStockImport reads a stock file. pharmacy_slug and product_slug are
+// required columns, variant_id, mode, quantity and price are optional and
+// an empty cell leaves the value as it is.
+func ParseStockImport(
+	r io.Reader,
+	format string,
+) ([]domain.StockImportRow, []domain.StockImportRowError, error) {
+	records, err := util.ReadSpreadsheet(r, format)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil, errors.New("file is empty")
+	}
+	if len(records)-1 > constants.StockImportMaxRows {
+		return nil, nil, fmt.Errorf("file has more than %d rows", constants.StockImportMaxRows)
+	}
+
+	header := map[string]int{}
+	for i, h := range records[0] {
+		header[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, h := range constants.StockImportHeader {
+		if _, ok := header[h]; !ok {
+			return nil, nil, fmt.Errorf("missing column %s", h)
+		}
+	}
+
+	value := func(record []string, column string) string {
+		if idx, ok := header[column]; ok && idx < len(record) {
+			return strings.TrimSpace(record[idx])
+		}
+		return ""
+	}
+
+	rows := make([]domain.StockImportRow, 0, len(records)-1)
+	rowErrors := make([]domain.StockImportRowError, 0)
+
+	for i, record := range records[1:] {
+		line := i + 2
+		if isBlankRecord(record) {
+			continue
+		}
+
+		row := domain.StockImportRow{
+			Line:         line,
+			PharmacySlug: value(record, "pharmacy_slug"),
+			ProductSlug:  value(record, "product_slug"),
+			Mode:         strings.ToLower(value(record, "mode")),
+		}
+
+		msgs := []string{}
+		if row.PharmacySlug == "" {
+			msgs = append(msgs, "pharmacy_slug is required")
+		}
+		if row.ProductSlug == "" {
+			msgs = append(msgs, "product_slug is required")
+		}
+
+		switch row.Mode {
+		case "":
+			row.Mode = domain.StockImportModeSet
+		case domain.StockImportModeSet, domain.StockImportModeAdjust:
+		default:
+			msgs = append(msgs, fmt.Sprintf(
+				"mode must be one of %s, %s",
+				domain.StockImportModeSet,
+				domain.StockImportModeAdjust,
+			))
+		}
+
+		if v := value(record, "variant_id"); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || id < 1 {
+				msgs = append(msgs, fmt.Sprintf("variant_id: %q is not an id", v))
+			} else {
+				row.VariantID = &id
+			}
+		}
+
+		for _, col := range []struct {
+			name string
+			dst  **int
+		}{
+			{"quantity", &row.Quantity},
+			{"price", &row.Price},
+		} {
+			v := value(record, col.name)
+			if v == "" {
+				continue
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				msgs = append(msgs, fmt.Sprintf("%s: %q is not a whole number", col.name, v))
+				continue
+			}
+			*col.dst = &n
+		}
+
+		if row.Quantity == nil && row.Price == nil {
+			msgs = append(msgs, "quantity or price is required")
+		}
+
+		if len(msgs) > 0 {
+			rowErrors = append(rowErrors, domain.StockImportRowError{
+				Line:    line,
+				Message: strings.Join(msgs, "; "),
+			})
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, rowErrors, nil
+}
+
+type StockImportRowErrorResponse struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+func NewStockImportRowErrorResponse(e domain.StockImportRowError) StockImportRowErrorResponse {
+	return StockImportRowErrorResponse(e)
+}
+
+type StockImportChangeResponse struct {
+	Line    int    `json:"line"`
+	StockID *int64 `json:"stock_id"`
+
+	PharmacySlug string `json:"pharmacy_slug"`
+	ProductSlug  string `json:"product_slug"`
+	VariantID    *int64 `json:"variant_id"`
+
+	OldStock int `json:"old_stock"`
+	NewStock int `json:"new_stock"`
+	OldPrice int `json:"old_price"`
+	NewPrice int `json:"new_price"`
+}
+
+func NewStockImportChangeResponse(c domain.StockImportChange) StockImportChangeResponse {
+	return StockImportChangeResponse(c)
+}
+
+type StockImportResultResponse struct {
+	ImportID *int64 `json:"import_id"`
+	DryRun   bool   `json:"dry_run"`
+	Applied  bool   `json:"applied"`
+
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Failed    int `json:"failed"`
+
+	Changes []StockImportChangeResponse   `json:"changes"`
+	Errors  []StockImportRowErrorResponse `json:"errors"`
+}
+
+func NewStockImportResultResponse(
+	result domain.StockImportResult,
+	parseErrors []domain.StockImportRowError,
+	dryRun bool,
+) StockImportResultResponse {
+	errs := append(parseErrors, result.Errors...)
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Line < errs[j].Line
+	})
+
+	return StockImportResultResponse{
+		ImportID:  result.ImportID,
+		DryRun:    dryRun,
+		Applied:   result.ImportID != nil,
+		Created:   result.Created,
+		Updated:   result.Updated,
+		Unchanged: result.Unchanged,
+		Failed:    len(errs),
+		Changes:   util.MapSlice(result.Changes, NewStockImportChangeResponse),
+		Errors:    util.MapSlice(errs, NewStockImportRowErrorResponse),
+	}
+}
@@ -0,0 +1,120 @@
+package dto
+
+import (
+	"medichat-be/domain"
+	"medichat-be/util"
+	"time"
+)
+
+type StockTakeOpenRequest struct {
+	PharmacySlug string `json:"pharmacy_slug" binding:"required"`
+}
+
+type StockTakeListQuery struct {
+	PharmacySlug string `form:"pharmacy_slug" binding:"required"`
+}
+
+type StockTakeCountRequest struct {
+	StockID int64 `json:"stock_id" binding:"required,min=1"`
+	Counted *int  `json:"counted" binding:"required,min=0"`
+}
+
+type StockTakeCountsRequest struct {
+	Counts []StockTakeCountRequest `json:"counts" binding:"required,min=1,dive"`
+}
+
+func (r StockTakeCountsRequest) ToDetails(stockTakeID int64) domain.StockTakeCountDetail {
+	counts := make([]domain.StockTakeCount, len(r.Counts))
+	for i, c := range r.Counts {
+		counts[i] = domain.StockTakeCount{
+			StockID: c.StockID,
+			Counted: *c.Counted,
+		}
+	}
+
+	return domain.StockTakeCountDetail{
+		StockTakeID: stockTakeID,
+		Counts:      counts,
+	}
+}
+
+type StockTakeResponse struct {
+	ID int64 `json:"id"`
+
+	PharmacyID int64  `json:"pharmacy_id"`
+	ManagerID  int64  `json:"manager_id"`
+	Status     string `json:"status"`
+
+	OpenedAt time.Time  `json:"opened_at"`
+	ClosedAt *time.Time `json:"closed_at"`
+}
+
+func NewStockTakeResponse(t domain.StockTake) StockTakeResponse {
+	return StockTakeResponse{
+		ID:         t.ID,
+		PharmacyID: t.PharmacyID,
+		ManagerID:  t.ManagerID,
+		Status:     t.Status,
+		OpenedAt:   t.OpenedAt,
+		ClosedAt:   t.ClosedAt,
+	}
+}
+
+type StockTakeItemResponse struct {
+	ID      int64 `json:"id"`
+	StockID int64 `json:"stock_id"`
+
+	Product struct {
+		ID   int64  `json:"id"`
+		Slug string `json:"slug"`
+		Name string `json:"name"`
+	} `json:"product"`
+	Variant *ProductVariantRefResponse `json:"variant"`
+
+	Expected        int        `json:"expected"`
+	Movement        int        `json:"movement"`
+	ExpectedAtCount int        `json:"expected_at_count"`
+	Counted         *int       `json:"counted"`
+	CountedAt       *time.Time `json:"counted_at"`
+	Variance        int        `json:"variance"`
+}
+
+func NewStockTakeItemResponse(i domain.StockTakeItemJoined) StockTakeItemResponse {
+	return StockTakeItemResponse{
+		ID:      i.ID,
+		StockID: i.StockID,
+		Product: struct {
+			ID   int64  "json:\"id\""
+			Slug string "json:\"slug\""
+			Name string "json:\"name\""
+		}(i.Product),
+		Variant:         NewProductVariantRefResponse(i.Variant),
+		Expected:        i.Expected,
+		Movement:        i.Movement,
+		ExpectedAtCount: i.ExpectedAtCount(),
+		Counted:         i.Counted,
+		CountedAt:       i.CountedAt,
+		Variance:        i.Variance,
+	}
+}
+
+type StockTakeReportResponse struct {
+	StockTake StockTakeResponse       `json:"stock_take"`
+	Items     []StockTakeItemResponse `json:"items"`
+
+	Counted   int `json:"counted"`
+	Uncounted int `json:"uncounted"`
+	Gain      int `json:"gain"`
+	Loss      int `json:"loss"`
+}
+
+func NewStockTakeReportResponse(r domain.StockTakeReport) StockTakeReportResponse {
+	return StockTakeReportResponse{
+		StockTake: NewStockTakeResponse(r.StockTake),
+		Items:     util.MapSlice(r.Items, NewStockTakeItemResponse),
+		Counted:   r.Counted,
+		Uncounted: r.Uncounted,
+		Gain:      r.Gain,
+		Loss:      r.Loss,
+	}
+}
@@ -0,0 +1,100 @@
+package dto
+
+import (
+	"medichat-be/domain"
+	"time"
+)
+
+type VoucherRequest struct {
+	Code string `json:"code" binding:"required,no_leading_trailing_space,max=50"`
+
+	Type     string `json:"type" binding:"required,oneof=percentage fixed free-shipping"`
+	Value    int    `json:"value" binding:"min=0"`
+	MinSpend int    `json:"min_spend" binding:"min=0"`
+
+	PharmacyID *int64 `json:"pharmacy_id" binding:"omitempty,min=1"`
+
+	StartsAt time.Time  `json:"starts_at" binding:"required"`
+	EndsAt   *time.Time `json:"ends_at"`
+
+	UsageLimit   *int `json:"usage_limit" binding:"omitempty,min=1"`
+	PerUserLimit *int `json:"per_user_limit" binding:"omitempty,min=1"`
+}
+
+func (r VoucherRequest) ToVoucher(id int64) domain.Voucher {
+	return domain.Voucher{
+		ID:           id,
+		Code:         r.Code,
+		Type:         r.Type,
+		Value:        r.Value,
+		MinSpend:     r.MinSpend,
+		PharmacyID:   r.PharmacyID,
+		StartsAt:     r.StartsAt,
+		EndsAt:       r.EndsAt,
+		UsageLimit:   r.UsageLimit,
+		PerUserLimit: r.PerUserLimit,
+	}
+}
+
+type VoucherListQuery struct {
+	Code   *string `form:"code"`
+	Active bool    `form:"active"`
+
+	Page  *int `form:"page" binding:"omitempty,min=1"`
+	Limit *int `form:"limit" binding:"omitempty,min=1"`
+}
+
+func (q VoucherListQuery) ToDetails() domain.VoucherListDetails {
+	ret := domain.VoucherListDetails{
+		Code:       q.Code,
+		ActiveOnly: q.Active,
+		Page:       1,
+		Limit:      10,
+	}
+
+	if q.Page != nil {
+		ret.Page = *q.Page
+	}
+	if q.Limit != nil {
+		ret.Limit = *q.Limit
+	}
+
+	return ret
+}
+
+type VoucherResponse struct {
+	ID   int64  `json:"id"`
+	Code string `json:"code"`
+
+	Type     string `json:"type"`
+	Value    int    `json:"value"`
+	MinSpend int    `json:"min_spend"`
+
+	PharmacyID *int64 `json:"pharmacy_id"`
+
+	StartsAt time.Time  `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+
+	UsageLimit   *int `json:"usage_limit"`
+	PerUserLimit *int `json:"per_user_limit"`
+	UsageCount   int  `json:"usage_count"`
+}
+
+func NewVoucherResponse(v domain.Voucher) VoucherResponse {
+	return VoucherResponse(v)
+}
+
+type VoucherRefResponse struct {
+	ID   int64  `json:"id"`
+	Code string `json:"code"`
+}
+
+func NewVoucherRefResponse(v *domain.VoucherRef) *VoucherRefResponse {
+	if v == nil {
+		return nil
+	}
+	return &VoucherRefResponse{
+		ID:   v.ID,
+		Code: v.Code,
+	}
+}
@@ -251,3 +251,62 @@ func (h *CategoryHandler) UpdateCategory(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, dto.ResponseOk(dto.NewCategoryResponse(category)))
 }
+
+func (h *CategoryHandler) GetCategoryTree(ctx *gin.Context) {
+	tree, err := h.categorySrv.GetCategoryTree(ctx)
+	if err != nil {
+		ctx.Error(err)
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ResponseOk(dto.NewCategoryTreeResponses(tree)))
+}
+
+func (h *CategoryHandler) MoveCategory(ctx *gin.Context) {
+	var req dto.MoveCategoryRequest
+	var params dto.CategorySlugParams
+
+	err := ctx.ShouldBindUri(&params)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	category, err := h.categorySrv.MoveCategory(ctx, params.Slug, req.ParentSlug)
+	if err != nil {
+		ctx.Error(err)
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ResponseOk(dto.NewCategoryResponse(category)))
+}
+
+func (h *CategoryHandler) ReorderCategories(ctx *gin.Context) {
+	var req dto.ReorderCategoriesRequest
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	categories, err := h.categorySrv.ReorderCategories(ctx, req.ParentSlug, req.Slugs)
+	if err != nil {
+		ctx.Error(err)
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ResponseOk(dto.NewCategoryResponses(categories)))
+}
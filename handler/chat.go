@@ -4,6 +4,7 @@ import (
 	"medichat-be/apperror"
 	"medichat-be/dto"
 	"medichat-be/service"
+	"medichat-be/util"
 	"net/http"
 	"strconv"
 	"time"
@@ -129,13 +130,16 @@ func (h*ChatHandler) CreatePrescription(ctx *gin.Context){
 	}
 	
 
-	err = h.chatService.Prescribe(&req,roomId,ctx)
+	warnings, err := h.chatService.Prescribe(&req,roomId,ctx)
 	if err!= nil {
-        ctx.Error(apperror.NewBadRequest(err))
+        ctx.Error(err)
         ctx.Abort()
         return
     }
-	ctx.JSON(http.StatusOK, gin.H{"message": "message sent"})
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "message sent",
+		"warnings": util.MapSlice(warnings, dto.NewDrugInteractionWarningResponse),
+	})
 }
 
 func (h*ChatHandler) CreateNote(ctx *gin.Context){
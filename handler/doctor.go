@@ -190,3 +190,108 @@ func (h *DoctorHandler) SetActiveStatus(ctx *gin.Context) {
 		dto.ResponseCreated(nil),
 	)
 }
+
+func (h *DoctorHandler) ListVerifications(ctx *gin.Context) {
+	var q dto.DoctorVerificationListQuery
+
+	err := ctx.ShouldBindQuery(&q)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	doctors, page, err := h.doctorSrv.ListVerifications(ctx, q.ToDetails())
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(map[string]any{
+			"page_info": dto.NewPageInfoResponse(page),
+			"doctors": util.MapSlice(doctors, func(d domain.Doctor) any {
+				return dto.NewProfileResponse(d)
+			}),
+		}),
+	)
+}
+
+func (h *DoctorHandler) GetVerificationByID(ctx *gin.Context) {
+	var req dto.IDPathRequest
+
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	doctor, err := h.doctorSrv.GetVerificationByID(ctx, req.ID)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(dto.NewProfileResponse(doctor)),
+	)
+}
+
+func (h *DoctorHandler) ApproveDoctor(ctx *gin.Context) {
+	var req dto.IDPathRequest
+
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = h.doctorSrv.Approve(ctx, req.ID)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusCreated,
+		dto.ResponseCreated(nil),
+	)
+}
+
+func (h *DoctorHandler) RejectDoctor(ctx *gin.Context) {
+	var uri dto.IDPathRequest
+	var req dto.DoctorRejectRequest
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = h.doctorSrv.Reject(ctx, req.ToDetails(uri.ID))
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusCreated,
+		dto.ResponseCreated(nil),
+	)
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"fmt"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"medichat-be/dto"
+	"medichat-be/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DoctorEarningHandler struct {
+	doctorEarningSrv domain.DoctorEarningService
+}
+
+type DoctorEarningHandlerOpts struct {
+	DoctorEarningSrv domain.DoctorEarningService
+}
+
+func NewDoctorEarningHandler(opts DoctorEarningHandlerOpts) *DoctorEarningHandler {
+	return &DoctorEarningHandler{
+		doctorEarningSrv: opts.DoctorEarningSrv,
+	}
+}
+
+func (h *DoctorEarningHandler) GetStatement(ctx *gin.Context) {
+	var q dto.DoctorEarningStatementQuery
+
+	err := ctx.ShouldBindQuery(&q)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	month, err := q.ToMonth()
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	statement, err := h.doctorEarningSrv.GetStatement(ctx, month)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(dto.NewDoctorEarningStatementResponse(statement)),
+	)
+}
+
+func (h *DoctorEarningHandler) ExportStatement(ctx *gin.Context) {
+	var q dto.DoctorEarningExportQuery
+
+	err := ctx.ShouldBindQuery(&q)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	month, err := q.ToMonth()
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	file, err := h.doctorEarningSrv.ExportStatement(ctx, month, q.Format)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Name))
+	ctx.Data(http.StatusOK, file.ContentType, file.Content)
+}
+
+func (h *DoctorEarningHandler) CreatePayouts(ctx *gin.Context) {
+	var req dto.DoctorPayoutCreateRequest
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	dets, err := req.ToDetails()
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	payouts, err := h.doctorEarningSrv.CreatePayouts(ctx, dets)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusCreated,
+		dto.ResponseCreated(util.MapSlice(payouts, dto.NewDoctorPayoutResponse)),
+	)
+}
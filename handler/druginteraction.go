@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"medichat-be/apperror"
+	"medichat-be/constants"
+	"medichat-be/domain"
+	"medichat-be/dto"
+	"medichat-be/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DrugInteractionHandler struct {
+	drugInteractionSrv domain.DrugInteractionService
+}
+
+type DrugInteractionHandlerOpts struct {
+	DrugInteractionSrv domain.DrugInteractionService
+}
+
+func NewDrugInteractionHandler(opts DrugInteractionHandlerOpts) *DrugInteractionHandler {
+	return &DrugInteractionHandler{
+		drugInteractionSrv: opts.DrugInteractionSrv,
+	}
+}
+
+func (h *DrugInteractionHandler) List(ctx *gin.Context) {
+	interactions, err := h.drugInteractionSrv.List(ctx)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(util.MapSlice(interactions, dto.NewDrugInteractionResponse)),
+	)
+}
+
+func (h *DrugInteractionHandler) Import(ctx *gin.Context) {
+	var form dto.DrugInteractionImportForm
+
+	err := util.LimitContentLength(ctx, constants.MaxFileSize)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = ctx.ShouldBind(&form)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	format, err := dto.ProductImportFormat(form.File)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	f, err := form.File.Open()
+	if err != nil {
+		ctx.Error(apperror.NewInternal(err))
+		ctx.Abort()
+		return
+	}
+	defer f.Close()
+
+	rows, rowErrors, err := dto.ParseDrugInteractionImport(f, format)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	result := domain.DrugInteractionImportResult{}
+	if len(rowErrors) == 0 {
+		result, err = h.drugInteractionSrv.Import(ctx, rows)
+		if err != nil {
+			ctx.Error(apperror.Wrap(err))
+			ctx.Abort()
+			return
+		}
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(dto.NewDrugInteractionImportResultResponse(result, rowErrors)),
+	)
+}
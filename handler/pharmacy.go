@@ -4,6 +4,7 @@ import (
 	"medichat-be/apperror"
 	"medichat-be/domain"
 	"medichat-be/dto"
+	"medichat-be/util"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -337,3 +338,127 @@ func (h *PharmacyHandler) UpdateShipmentMethodsBySlug(ctx *gin.Context) {
 		dto.ResponseOk(dto.NewPharmacyShipmentMethodsResponse(sh)),
 	)
 }
+
+func (h *PharmacyHandler) GetOpeningStatus(ctx *gin.Context) {
+	var uri dto.PharmacySlugParams
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	var q dto.PharmacyOpeningQuery
+
+	err = ctx.ShouldBindQuery(&q)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	at, err := q.ToTime()
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	status, err := h.pharmacySrv.GetOpeningStatus(ctx, uri.Slug, at)
+	if err != nil {
+		ctx.Error(err)
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(dto.NewPharmacyOpeningStatusResponse(status)),
+	)
+}
+
+func (h *PharmacyHandler) GetHolidays(ctx *gin.Context) {
+	var uri dto.PharmacySlugParams
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	holidays, err := h.pharmacySrv.GetHolidaysBySlug(ctx, uri.Slug)
+	if err != nil {
+		ctx.Error(err)
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(util.MapSlice(holidays, dto.NewPharmacyHolidayResponse)),
+	)
+}
+
+func (h *PharmacyHandler) AddHoliday(ctx *gin.Context) {
+	var uri dto.PharmacySlugParams
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	var req dto.PharmacyHolidayCreateRequest
+
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	holiday, err := req.ToEntity()
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	holiday, err = h.pharmacySrv.AddHoliday(ctx, uri.Slug, holiday)
+	if err != nil {
+		ctx.Error(err)
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusCreated,
+		dto.ResponseCreated(dto.NewPharmacyHolidayResponse(holiday)),
+	)
+}
+
+func (h *PharmacyHandler) DeleteHoliday(ctx *gin.Context) {
+	var uri dto.PharmacyHolidayPathRequest
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = h.pharmacySrv.DeleteHoliday(ctx, uri.Slug, uri.ID)
+	if err != nil {
+		ctx.Error(err)
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusNoContent,
+		nil,
+	)
+}
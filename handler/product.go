@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"fmt"
 	"medichat-be/apperror"
 	"medichat-be/constants"
 	"medichat-be/domain"
 	"medichat-be/dto"
+	"medichat-be/util"
 	"mime/multipart"
 	"net/http"
 
@@ -13,15 +15,18 @@ import (
 
 type ProductHandler struct {
 	productsrv domain.ProductService
+	suggestSrv domain.ProductSuggestService
 }
 
 type ProductHandlerOpts struct {
 	ProductSrv domain.ProductService
+	SuggestSrv domain.ProductSuggestService
 }
 
 func NewProductHandler(opts ProductHandlerOpts) *ProductHandler {
 	return &ProductHandler{
 		productsrv: opts.ProductSrv,
+		suggestSrv: opts.SuggestSrv,
 	}
 }
 
@@ -34,7 +39,7 @@ func (h *ProductHandler) GetProducts(ctx *gin.Context) {
 		ctx.Abort()
 		return
 	}
-	products, pageInfo, err := h.productsrv.GetProducts(ctx, query.ToProductsQuery())
+	products, facets, pageInfo, err := h.productsrv.GetProducts(ctx, query.ToProductsQuery())
 
 	if err != nil {
 		ctx.Error(err)
@@ -42,7 +47,47 @@ func (h *ProductHandler) GetProducts(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, dto.ResponseOk(dto.NewProductsResponse(products, pageInfo)))
+	ctx.JSON(http.StatusOK, dto.ResponseOk(dto.NewProductsResponse(products, facets, pageInfo)))
+}
+
+func (h *ProductHandler) GetAdminProducts(ctx *gin.Context) {
+	var query dto.GetAdminProductsQuery
+
+	err := ctx.ShouldBindQuery(&query)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	products, facets, pageInfo, err := h.productsrv.GetProducts(ctx, query.ToProductsQuery())
+	if err != nil {
+		ctx.Error(err)
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ResponseOk(dto.NewProductsResponse(products, facets, pageInfo)))
+}
+
+func (h *ProductHandler) ChangeStates(ctx *gin.Context) {
+	var req dto.ProductStateChangeRequest
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	products, err := h.productsrv.ChangeStates(ctx, req.ToProductStateChange())
+	if err != nil {
+		ctx.Error(err)
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ResponseOk(util.MapSlice(products, dto.NewProductResponse)))
 }
 
 func (h *ProductHandler) GetProductsFromArea(ctx *gin.Context) {
@@ -55,7 +100,7 @@ func (h *ProductHandler) GetProductsFromArea(ctx *gin.Context) {
 		return
 	}
 
-	products, pageInfo, err := h.productsrv.GetProductLocation(ctx, query.ToProductsQuery())
+	products, facets, pageInfo, err := h.productsrv.GetProductLocation(ctx, query.ToProductsQuery())
 
 	if err != nil {
 		ctx.Error(err)
@@ -63,7 +108,7 @@ func (h *ProductHandler) GetProductsFromArea(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, dto.ResponseOk(dto.NewProductsResponse(products, pageInfo)))
+	ctx.JSON(http.StatusOK, dto.ResponseOk(dto.NewProductsResponse(products, facets, pageInfo)))
 }
 
 func (h *ProductHandler) GetProductBySlug(ctx *gin.Context) {
@@ -129,6 +174,9 @@ func (h *ProductHandler) CreateProduct(ctx *gin.Context) {
 		Height:                form.Height,
 		Length:                form.Length,
 		Width:                 form.Width,
+		Status:                form.Status,
+		PublishAt:             form.PublishAt,
+		UnpublishAt:           form.UnpublishAt,
 	}, file)
 
 	if err != nil {
@@ -209,3 +257,99 @@ func (h *ProductHandler) UpdateProduct(ctx *gin.Context) {
 	}
 	ctx.JSON(http.StatusOK, dto.ResponseOk(dto.NewProductResponse(product)))
 }
+
+func (h *ProductHandler) ImportProducts(ctx *gin.Context) {
+	var form dto.ProductImportForm
+
+	err := util.LimitContentLength(ctx, constants.MaxFileSize)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = ctx.ShouldBind(&form)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	format, err := dto.ProductImportFormat(form.File)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	f, err := form.File.Open()
+	if err != nil {
+		ctx.Error(apperror.NewInternal(err))
+		ctx.Abort()
+		return
+	}
+	defer f.Close()
+
+	rows, rowErrors, err := dto.ParseProductImport(f, format)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	result, err := h.productsrv.ImportProducts(ctx, rows)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(dto.NewProductImportResultResponse(result, rowErrors)),
+	)
+}
+
+func (h *ProductHandler) ExportCatalog(ctx *gin.Context) {
+	var q dto.ProductExportQuery
+
+	err := ctx.ShouldBindQuery(&q)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	file, err := h.productsrv.ExportCatalog(ctx, q.Format)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Name))
+	ctx.Data(http.StatusOK, file.ContentType, file.Content)
+}
+
+func (h *ProductHandler) Suggest(ctx *gin.Context) {
+	var q dto.ProductSuggestQuery
+
+	err := ctx.ShouldBindQuery(&q)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	suggestions, err := h.suggestSrv.Suggest(ctx, q.Query, q.GetLimit())
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(dto.NewProductSuggestionsResponse(suggestions)),
+	)
+}
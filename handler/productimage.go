@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"medichat-be/apperror"
+	"medichat-be/constants"
+	"medichat-be/domain"
+	"medichat-be/dto"
+	"medichat-be/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductImageHandler struct {
+	productImageSrv domain.ProductImageService
+}
+
+type ProductImageHandlerOpts struct {
+	ProductImageSrv domain.ProductImageService
+}
+
+func NewProductImageHandler(opts ProductImageHandlerOpts) *ProductImageHandler {
+	return &ProductImageHandler{
+		productImageSrv: opts.ProductImageSrv,
+	}
+}
+
+func (h *ProductImageHandler) List(ctx *gin.Context) {
+	var uri dto.ProductSlugParams
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	images, err := h.productImageSrv.List(ctx, uri.Slug)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(util.MapSlice(images, dto.NewProductImageResponse)),
+	)
+}
+
+func (h *ProductImageHandler) Add(ctx *gin.Context) {
+	var uri dto.ProductSlugParams
+	var form dto.ProductImageForm
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = util.LimitContentLength(ctx, constants.MaxFileSize)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = ctx.ShouldBind(&form)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	file, err := form.Image.Open()
+	if err != nil {
+		ctx.Error(apperror.NewInternal(err))
+		ctx.Abort()
+		return
+	}
+	defer file.Close()
+
+	img, err := h.productImageSrv.Add(ctx, uri.Slug, file)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusCreated,
+		dto.ResponseCreated(dto.NewProductImageResponse(img)),
+	)
+}
+
+func (h *ProductImageHandler) Reorder(ctx *gin.Context) {
+	var uri dto.ProductSlugParams
+	var req dto.ProductImageOrderRequest
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	images, err := h.productImageSrv.Reorder(ctx, uri.Slug, req.ImageIDs)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(util.MapSlice(images, dto.NewProductImageResponse)),
+	)
+}
+
+func (h *ProductImageHandler) SetPrimary(ctx *gin.Context) {
+	var uri dto.ProductImagePathRequest
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	images, err := h.productImageSrv.SetPrimary(ctx, uri.Slug, uri.ID)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(util.MapSlice(images, dto.NewProductImageResponse)),
+	)
+}
+
+func (h *ProductImageHandler) Delete(ctx *gin.Context) {
+	var uri dto.ProductImagePathRequest
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = h.productImageSrv.Delete(ctx, uri.Slug, uri.ID)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(nil),
+	)
+}
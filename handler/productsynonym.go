@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"medichat-be/dto"
+	"medichat-be/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductSynonymHandler struct {
+	productSynonymSrv domain.ProductSynonymService
+}
+
+type ProductSynonymHandlerOpts struct {
+	ProductSynonymSrv domain.ProductSynonymService
+}
+
+func NewProductSynonymHandler(opts ProductSynonymHandlerOpts) *ProductSynonymHandler {
+	return &ProductSynonymHandler{
+		productSynonymSrv: opts.ProductSynonymSrv,
+	}
+}
+
+func (h *ProductSynonymHandler) List(ctx *gin.Context) {
+	synonyms, err := h.productSynonymSrv.List(ctx)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(util.MapSlice(synonyms, dto.NewProductSynonymResponse)),
+	)
+}
+
+func (h *ProductSynonymHandler) Create(ctx *gin.Context) {
+	var req dto.ProductSynonymRequest
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	synonym, err := h.productSynonymSrv.Create(ctx, req.ToSynonym())
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusCreated,
+		dto.ResponseCreated(dto.NewProductSynonymResponse(synonym)),
+	)
+}
+
+func (h *ProductSynonymHandler) Delete(ctx *gin.Context) {
+	var uri dto.IDPathRequest
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = h.productSynonymSrv.Delete(ctx, uri.ID)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(nil),
+	)
+}
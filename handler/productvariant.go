@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"medichat-be/dto"
+	"medichat-be/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductVariantHandler struct {
+	productVariantSrv domain.ProductVariantService
+}
+
+type ProductVariantHandlerOpts struct {
+	ProductVariantSrv domain.ProductVariantService
+}
+
+func NewProductVariantHandler(opts ProductVariantHandlerOpts) *ProductVariantHandler {
+	return &ProductVariantHandler{
+		productVariantSrv: opts.ProductVariantSrv,
+	}
+}
+
+func (h *ProductVariantHandler) List(ctx *gin.Context) {
+	var uri dto.ProductSlugParams
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	variants, err := h.productVariantSrv.List(ctx, uri.Slug)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(util.MapSlice(variants, dto.NewProductVariantResponse)),
+	)
+}
+
+func (h *ProductVariantHandler) Create(ctx *gin.Context) {
+	var uri dto.ProductSlugParams
+	var req dto.ProductVariantRequest
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	variant, err := h.productVariantSrv.Create(ctx, uri.Slug, req.ToVariant(0))
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusCreated,
+		dto.ResponseCreated(dto.NewProductVariantResponse(variant)),
+	)
+}
+
+func (h *ProductVariantHandler) Update(ctx *gin.Context) {
+	var uri dto.ProductVariantPathRequest
+	var req dto.ProductVariantRequest
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	variant, err := h.productVariantSrv.Update(ctx, uri.Slug, req.ToVariant(uri.ID))
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(dto.NewProductVariantResponse(variant)),
+	)
+}
+
+func (h *ProductVariantHandler) Delete(ctx *gin.Context) {
+	var uri dto.ProductVariantPathRequest
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = h.productVariantSrv.Delete(ctx, uri.Slug, uri.ID)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(nil),
+	)
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"medichat-be/dto"
+	"medichat-be/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromotionHandler struct {
+	promotionSrv domain.PromotionService
+}
+
+type PromotionHandlerOpts struct {
+	PromotionSrv domain.PromotionService
+}
+
+func NewPromotionHandler(opts PromotionHandlerOpts) *PromotionHandler {
+	return &PromotionHandler{
+		promotionSrv: opts.PromotionSrv,
+	}
+}
+
+func (h *PromotionHandler) List(ctx *gin.Context) {
+	var q dto.PromotionListQuery
+
+	err := ctx.ShouldBindQuery(&q)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	promotions, page, err := h.promotionSrv.List(ctx, q.ToDetails())
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(map[string]any{
+			"page_info":  dto.NewPageInfoResponse(page),
+			"promotions": util.MapSlice(promotions, dto.NewPromotionResponse),
+		}),
+	)
+}
+
+func (h *PromotionHandler) GetByID(ctx *gin.Context) {
+	var req dto.IDPathRequest
+
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	promotion, err := h.promotionSrv.GetByID(ctx, req.ID)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(dto.NewPromotionResponse(promotion)),
+	)
+}
+
+func (h *PromotionHandler) Create(ctx *gin.Context) {
+	var req dto.PromotionRequest
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	promotion, err := h.promotionSrv.Create(ctx, req.ToPromotion(0))
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusCreated,
+		dto.ResponseCreated(dto.NewPromotionResponse(promotion)),
+	)
+}
+
+func (h *PromotionHandler) Update(ctx *gin.Context) {
+	var uri dto.IDPathRequest
+	var req dto.PromotionRequest
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	promotion, err := h.promotionSrv.Update(ctx, req.ToPromotion(uri.ID))
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(dto.NewPromotionResponse(promotion)),
+	)
+}
+
+func (h *PromotionHandler) Delete(ctx *gin.Context) {
+	var uri dto.IDPathRequest
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = h.promotionSrv.Delete(ctx, uri.ID)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(nil),
+	)
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"medichat-be/dto"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RelatedProductHandler struct {
+	relatedProductSrv domain.RelatedProductService
+}
+
+type RelatedProductHandlerOpts struct {
+	RelatedProductSrv domain.RelatedProductService
+}
+
+func NewRelatedProductHandler(opts RelatedProductHandlerOpts) *RelatedProductHandler {
+	return &RelatedProductHandler{
+		relatedProductSrv: opts.RelatedProductSrv,
+	}
+}
+
+func (h *RelatedProductHandler) GetRelated(ctx *gin.Context) {
+	var params dto.ProductSlugParams
+	var query dto.RelatedProductsQuery
+
+	err := ctx.ShouldBindUri(&params)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = ctx.ShouldBindQuery(&query)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	related, err := h.relatedProductSrv.GetRelated(ctx, params.Slug, query.ToRelatedProductsQuery())
+	if err != nil {
+		ctx.Error(err)
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ResponseOk(dto.NewRelatedProductsResponse(related)))
+}
+
+func (h *RelatedProductHandler) ComputeAssociations(ctx *gin.Context) {
+	n, err := h.relatedProductSrv.ComputeAssociations(ctx)
+	if err != nil {
+		ctx.Error(err)
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ResponseOk(dto.ComputeAssociationsResponse{
+		Associations: n,
+	}))
+}
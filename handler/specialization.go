@@ -2,6 +2,7 @@ package handler
 
 import (
 	"medichat-be/apperror"
+	"medichat-be/constants"
 	"medichat-be/domain"
 	"medichat-be/dto"
 	"medichat-be/util"
@@ -36,7 +37,166 @@ func (h *SpecializationHandler) GetAll(ctx *gin.Context) {
 		http.StatusOK,
 		dto.ResponseOk(util.MapSlice(
 			specializations,
-			dto.NewSpecializationResponse,
+			dto.NewSpecializationWithDoctorCountResponse,
 		)),
 	)
 }
+
+func (h *SpecializationHandler) GetBySlug(ctx *gin.Context) {
+	var uri dto.SpecializationSlugRequest
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	specialization, err := h.specializationSrv.GetBySlug(ctx, uri.Slug)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(dto.NewSpecializationResponse(specialization)),
+	)
+}
+
+func (h *SpecializationHandler) Create(ctx *gin.Context) {
+	var req dto.SpecializationCreateRequest
+
+	err := util.LimitContentLength(ctx, constants.MaxFileSize)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = dto.ShouldBindMultipart(ctx, &req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	dets, err := dto.SpecializationCreateRequestToDetails(req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	specialization, err := h.specializationSrv.Create(ctx, dets)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusCreated,
+		dto.ResponseCreated(dto.NewSpecializationResponse(specialization)),
+	)
+}
+
+func (h *SpecializationHandler) Update(ctx *gin.Context) {
+	var uri dto.IDPathRequest
+	var req dto.SpecializationUpdateRequest
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = util.LimitContentLength(ctx, constants.MaxFileSize)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = dto.ShouldBindMultipart(ctx, &req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	dets, err := dto.SpecializationUpdateRequestToDetails(uri.ID, req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	specialization, err := h.specializationSrv.Update(ctx, dets)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(dto.NewSpecializationResponse(specialization)),
+	)
+}
+
+func (h *SpecializationHandler) Delete(ctx *gin.Context) {
+	var uri dto.IDPathRequest
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = h.specializationSrv.Delete(ctx, uri.ID)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(nil),
+	)
+}
+
+func (h *SpecializationHandler) UpdateCommissionRate(ctx *gin.Context) {
+	var uri dto.IDPathRequest
+	var req dto.SpecializationCommissionRequest
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	_, err = h.specializationSrv.UpdateCommissionRate(ctx, uri.ID, *req.CommissionRate)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(nil),
+	)
+}
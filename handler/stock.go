@@ -2,6 +2,7 @@ package handler
 
 import (
 	"medichat-be/apperror"
+	"medichat-be/constants"
 	"medichat-be/domain"
 	"medichat-be/dto"
 	"medichat-be/util"
@@ -269,3 +270,316 @@ func (h *StockHandler) CancelTransfer(ctx *gin.Context) {
 		dto.ResponseCreated(dto.NewStockMutationResponse(mut)),
 	)
 }
+
+func (h *StockHandler) ReceiveBatch(ctx *gin.Context) {
+	var uri dto.IDPathRequest
+	var req dto.StockBatchCreateRequest
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	det, err := req.ToDetails(uri.ID)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	batch, err := h.stockSrv.ReceiveBatch(ctx, det)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusCreated,
+		dto.ResponseCreated(dto.NewStockBatchResponse(batch)),
+	)
+}
+
+func (h *StockHandler) ListBatches(ctx *gin.Context) {
+	var req dto.IDPathRequest
+
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	batches, err := h.stockSrv.ListBatches(ctx, req.ID)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(util.MapSlice(batches, dto.NewStockBatchResponse)),
+	)
+}
+
+func (h *StockHandler) ListExpiringBatches(ctx *gin.Context) {
+	var q dto.ExpiringStockQuery
+
+	err := ctx.ShouldBindQuery(&q)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	batches, err := h.stockSrv.ListExpiringBatches(ctx, q.ToDetails())
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(util.MapSlice(batches, dto.NewStockBatchJoinedResponse)),
+	)
+}
+
+func (h *StockHandler) WriteOffExpired(ctx *gin.Context) {
+	var req dto.IDPathRequest
+
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	stock, err := h.stockSrv.WriteOffExpired(ctx, req.ID)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(dto.NewStockResponse(stock)),
+	)
+}
+
+func (h *StockHandler) ListLedger(ctx *gin.Context) {
+	var req dto.IDPathRequest
+	var q dto.StockLedgerQuery
+
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = ctx.ShouldBindQuery(&q)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	entries, page, err := h.stockSrv.ListLedger(ctx, q.ToDetails(req.ID))
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(map[string]any{
+			"page_info": dto.NewPageInfoResponse(page),
+			"entries":   util.MapSlice(entries, dto.NewStockLedgerEntryResponse),
+		}),
+	)
+}
+
+func (h *StockHandler) CheckLedger(ctx *gin.Context) {
+	var req dto.IDPathRequest
+
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	check, err := h.stockSrv.CheckLedger(ctx, req.ID)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(dto.NewStockLedgerCheckResponse(check)),
+	)
+}
+
+func (h *StockHandler) CheckAllLedgers(ctx *gin.Context) {
+	checks, err := h.stockSrv.CheckAllLedgers(ctx)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(util.MapSlice(checks, dto.NewStockLedgerCheckResponse)),
+	)
+}
+
+func (h *StockHandler) ImportStocks(ctx *gin.Context) {
+	var form dto.StockImportForm
+
+	err := util.LimitContentLength(ctx, constants.MaxFileSize)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = ctx.ShouldBind(&form)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	format, err := dto.ProductImportFormat(form.File)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	f, err := form.File.Open()
+	if err != nil {
+		ctx.Error(apperror.NewInternal(err))
+		ctx.Abort()
+		return
+	}
+	defer f.Close()
+
+	rows, rowErrors, err := dto.ParseStockImport(f, format)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	// Rows that parsed are still checked so every problem in the file is
+	// reported at once, but nothing is written.
+	result, err := h.stockSrv.ImportStocks(ctx, domain.StockImportDetail{
+		FileName: form.File.Filename,
+		DryRun:   form.DryRun || len(rowErrors) > 0,
+		Rows:     rows,
+	})
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(dto.NewStockImportResultResponse(result, rowErrors, form.DryRun)),
+	)
+}
+
+func (h *StockHandler) ListPrices(ctx *gin.Context) {
+	var req dto.IDPathRequest
+
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	prices, err := h.stockSrv.ListPrices(ctx, req.ID)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(util.MapSlice(prices, dto.NewStockPriceResponse)),
+	)
+}
+
+func (h *StockHandler) SchedulePrice(ctx *gin.Context) {
+	var req dto.IDPathRequest
+	var body dto.StockPriceScheduleRequest
+
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = ctx.ShouldBindJSON(&body)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	price, err := h.stockSrv.SchedulePrice(ctx, body.ToDetails(req.ID))
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusCreated,
+		dto.ResponseCreated(dto.NewStockPriceResponse(price)),
+	)
+}
+
+func (h *StockHandler) CancelScheduledPrice(ctx *gin.Context) {
+	var req dto.StockPricePathRequest
+
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	price, err := h.stockSrv.CancelScheduledPrice(ctx, req.ID, req.PriceID)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(dto.NewStockPriceResponse(price)),
+	)
+}
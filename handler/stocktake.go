@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"medichat-be/dto"
+	"medichat-be/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StockTakeHandler struct {
+	stockTakeSrv domain.StockTakeService
+}
+
+type StockTakeHandlerOpts struct {
+	StockTakeSrv domain.StockTakeService
+}
+
+func NewStockTakeHandler(opts StockTakeHandlerOpts) *StockTakeHandler {
+	return &StockTakeHandler{
+		stockTakeSrv: opts.StockTakeSrv,
+	}
+}
+
+func (h *StockTakeHandler) Open(ctx *gin.Context) {
+	var req dto.StockTakeOpenRequest
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	take, err := h.stockTakeSrv.Open(ctx, req.PharmacySlug)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusCreated,
+		dto.ResponseCreated(dto.NewStockTakeResponse(take)),
+	)
+}
+
+func (h *StockTakeHandler) List(ctx *gin.Context) {
+	var q dto.StockTakeListQuery
+
+	err := ctx.ShouldBindQuery(&q)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	takes, err := h.stockTakeSrv.List(ctx, q.PharmacySlug)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(util.MapSlice(takes, dto.NewStockTakeResponse)),
+	)
+}
+
+func (h *StockTakeHandler) GetReport(ctx *gin.Context) {
+	var req dto.IDPathRequest
+
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	report, err := h.stockTakeSrv.GetReport(ctx, req.ID)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(dto.NewStockTakeReportResponse(report)),
+	)
+}
+
+func (h *StockTakeHandler) SubmitCounts(ctx *gin.Context) {
+	var uri dto.IDPathRequest
+	var req dto.StockTakeCountsRequest
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	report, err := h.stockTakeSrv.SubmitCounts(ctx, req.ToDetails(uri.ID))
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(dto.NewStockTakeReportResponse(report)),
+	)
+}
+
+func (h *StockTakeHandler) Close(ctx *gin.Context) {
+	var req dto.IDPathRequest
+
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	report, err := h.stockTakeSrv.Close(ctx, req.ID)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(dto.NewStockTakeReportResponse(report)),
+	)
+}
+
+func (h *StockTakeHandler) Cancel(ctx *gin.Context) {
+	var req dto.IDPathRequest
+
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	take, err := h.stockTakeSrv.Cancel(ctx, req.ID)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(dto.NewStockTakeResponse(take)),
+	)
+}
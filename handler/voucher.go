@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"medichat-be/dto"
+	"medichat-be/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VoucherHandler struct {
+	voucherSrv domain.VoucherService
+}
+
+type VoucherHandlerOpts struct {
+	VoucherSrv domain.VoucherService
+}
+
+func NewVoucherHandler(opts VoucherHandlerOpts) *VoucherHandler {
+	return &VoucherHandler{
+		voucherSrv: opts.VoucherSrv,
+	}
+}
+
+func (h *VoucherHandler) List(ctx *gin.Context) {
+	var q dto.VoucherListQuery
+
+	err := ctx.ShouldBindQuery(&q)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	vouchers, page, err := h.voucherSrv.List(ctx, q.ToDetails())
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(map[string]any{
+			"page_info": dto.NewPageInfoResponse(page),
+			"vouchers":  util.MapSlice(vouchers, dto.NewVoucherResponse),
+		}),
+	)
+}
+
+func (h *VoucherHandler) GetByID(ctx *gin.Context) {
+	var req dto.IDPathRequest
+
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	voucher, err := h.voucherSrv.GetByID(ctx, req.ID)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(dto.NewVoucherResponse(voucher)),
+	)
+}
+
+func (h *VoucherHandler) Create(ctx *gin.Context) {
+	var req dto.VoucherRequest
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	voucher, err := h.voucherSrv.Create(ctx, req.ToVoucher(0))
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusCreated,
+		dto.ResponseCreated(dto.NewVoucherResponse(voucher)),
+	)
+}
+
+func (h *VoucherHandler) Update(ctx *gin.Context) {
+	var uri dto.IDPathRequest
+	var req dto.VoucherRequest
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	voucher, err := h.voucherSrv.Update(ctx, req.ToVoucher(uri.ID))
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(dto.NewVoucherResponse(voucher)),
+	)
+}
+
+func (h *VoucherHandler) Delete(ctx *gin.Context) {
+	var uri dto.IDPathRequest
+
+	err := ctx.ShouldBindUri(&uri)
+	if err != nil {
+		ctx.Error(apperror.NewBadRequest(err))
+		ctx.Abort()
+		return
+	}
+
+	err = h.voucherSrv.Delete(ctx, uri.ID)
+	if err != nil {
+		ctx.Error(apperror.Wrap(err))
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		dto.ResponseOk(nil),
+	)
+}
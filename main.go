@@ -21,6 +21,7 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
 	firebase "firebase.google.com/go/v4"
 	"github.com/gin-gonic/gin"
@@ -193,9 +194,16 @@ func main() {
 		EmailProvider:                 emailProvider,
 	})
 
+	productSuggestService := service.NewProductSuggestService(service.ProductSuggestServiceOpts{
+		DataRepository: dataRepository,
+		Logger:         log,
+	})
+	productSuggestService.Invalidate()
+
 	categoryService := service.NewCategoryService(service.CategoryServiceOpts{
 		DataRepository: dataRepository,
 		Cloud:          cld,
+		SuggestSrv:     productSuggestService,
 	})
 
 	googleAuthService := service.NewOAuth2Service(service.OAuth2ServiceOpts{
@@ -214,15 +222,55 @@ func main() {
 	doctorService := service.NewDoctorService(service.DoctorServiceOpts{
 		DataRepository: dataRepository,
 		CloudProvider:  cld,
+		AppEmail:       appEmail,
+		EmailProvider:  emailProvider,
+		Logger:         log,
+	})
+
+	pdfProvider := util.NewPdfcrowdProvider(util.PDFProviderOpts{
+		Username: conf.PdfcrowdUsername,
+		APIKey:   conf.PdfcrowdAPIKey,
+	})
+
+	doctorEarningService, err := service.NewDoctorEarningService(service.DoctorEarningServiceOpts{
+		DataRepository: dataRepository,
+		PDFProvider:    pdfProvider,
+		Logger:         log,
 	})
+	if err != nil {
+		log.Fatalf("Error creating doctor earning service: %v", err)
+	}
 
 	specializationService := service.NewSpecializationService(service.SpecializationServiceOpts{
 		DataRepository: dataRepository,
+		CloudProvider:  cld,
 	})
 
 	productService := service.NewProductService(service.ProductServiceOpts{
 		DataRepository: dataRepository,
 		Cloud:          cld,
+		SuggestSrv:     productSuggestService,
+	})
+
+	productImageService := service.NewProductImageService(service.ProductImageServiceOpts{
+		DataRepository: dataRepository,
+		Cloud:          cld,
+	})
+
+	productVariantService := service.NewProductVariantService(service.ProductVariantServiceOpts{
+		DataRepository: dataRepository,
+	})
+
+	drugInteractionService := service.NewDrugInteractionService(service.DrugInteractionServiceOpts{
+		DataRepository: dataRepository,
+	})
+
+	relatedProductService := service.NewRelatedProductService(service.RelatedProductServiceOpts{
+		DataRepository: dataRepository,
+	})
+
+	productSynonymService := service.NewProductSynonymService(service.ProductSynonymServiceOpts{
+		DataRepository: dataRepository,
 	})
 
 	pharmacyService := service.NewPharmacyService(service.PharmacyServiceOpts{
@@ -236,6 +284,19 @@ func main() {
 
 	stockService := service.NewStockService(service.StockServiceOpts{
 		DataRepository: dataRepository,
+		Logger:         log,
+	})
+
+	stockTakeService := service.NewStockTakeService(service.StockTakeServiceOpts{
+		DataRepository: dataRepository,
+	})
+
+	promotionService := service.NewPromotionService(service.PromotionServiceOpts{
+		DataRepository: dataRepository,
+	})
+
+	voucherService := service.NewVoucherService(service.VoucherServiceOpts{
+		DataRepository: dataRepository,
 	})
 
 	paymentService := service.NewPaymentService(service.PaymentServiceOpts{
@@ -273,12 +334,36 @@ func main() {
 		DoctorSrv: doctorService,
 	})
 
+	doctorEarningHandler := handler.NewDoctorEarningHandler(handler.DoctorEarningHandlerOpts{
+		DoctorEarningSrv: doctorEarningService,
+	})
+
 	specializationHandler := handler.NewSpecializationHandler(handler.SpecializationHandlerOpts{
 		SpecializationSrv: specializationService,
 	})
 
 	productHandler := handler.NewProductHandler(handler.ProductHandlerOpts{
 		ProductSrv: productService,
+		SuggestSrv: productSuggestService,
+	})
+
+	productImageHandler := handler.NewProductImageHandler(handler.ProductImageHandlerOpts{
+		ProductImageSrv: productImageService,
+	})
+
+	productVariantHandler := handler.NewProductVariantHandler(handler.ProductVariantHandlerOpts{
+		ProductVariantSrv: productVariantService,
+	})
+
+	relatedProductHandler := handler.NewRelatedProductHandler(handler.RelatedProductHandlerOpts{
+		RelatedProductSrv: relatedProductService,
+	})
+	drugInteractionHandler := handler.NewDrugInteractionHandler(handler.DrugInteractionHandlerOpts{
+		DrugInteractionSrv: drugInteractionService,
+	})
+
+	productSynonymHandler := handler.NewProductSynonymHandler(handler.ProductSynonymHandlerOpts{
+		ProductSynonymSrv: productSynonymService,
 	})
 
 	pharmacyHandler := handler.NewPharmacyHandler(handler.PharmacyHandlerOpts{
@@ -294,6 +379,18 @@ func main() {
 		StockSrv: stockService,
 	})
 
+	stockTakeHandler := handler.NewStockTakeHandler(handler.StockTakeHandlerOpts{
+		StockTakeSrv: stockTakeService,
+	})
+
+	promotionHandler := handler.NewPromotionHandler(handler.PromotionHandlerOpts{
+		PromotionSrv: promotionService,
+	})
+
+	voucherHandler := handler.NewVoucherHandler(handler.VoucherHandlerOpts{
+		VoucherSrv: voucherService,
+	})
+
 	paymentHandler := handler.NewPaymentHandler(handler.PaymentHandlerOpts{
 		PaymentSrv: paymentService,
 	})
@@ -328,12 +425,21 @@ func main() {
 		GoogleHandler:          googleHandler,
 		UserHandler:            userHandler,
 		DoctorHandler:          doctorHandler,
+		DoctorEarningHandler:   doctorEarningHandler,
 		SpecializationHandler:  specializationHandler,
 		CategoryHandler:        categoryHandler,
 		ProductHandler:         productHandler,
+		ProductImageHandler:    productImageHandler,
+		ProductVariantHandler:  productVariantHandler,
+		ProductSynonymHandler:  productSynonymHandler,
+		DrugInteractionHandler: drugInteractionHandler,
+		RelatedProductHandler:  relatedProductHandler,
 		PharmacyHandler:        pharmacyHandler,
 		PharmacyManagerHandler: pharmacyManagerHandler,
 		StockHandler:           stockHandler,
+		StockTakeHandler:       stockTakeHandler,
+		PromotionHandler:       promotionHandler,
+		VoucherHandler:         voucherHandler,
 		PaymentHandler:         paymentHandler,
 		OrderHandler:           orderHandler,
 
@@ -363,6 +469,34 @@ func main() {
 		Handler: router,
 	}
 
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	go service.RunPeriodically(jobCtx, log, "product associations", constants.AssociationJobInterval, func(ctx context.Context) error {
+		_, err := relatedProductService.ComputeAssociations(ctx)
+		return err
+	})
+
+	go service.RunPeriodically(jobCtx, log, "expired orders", constants.OrderExpiryJobInterval, func(ctx context.Context) error {
+		_, err := orderService.CancelExpiredOrders(ctx)
+		return err
+	})
+
+	go service.RunPeriodically(jobCtx, log, "stock replenishment", constants.ReplenishmentJobInterval, func(ctx context.Context) error {
+		_, err := stockService.ProposeReplenishments(ctx)
+		return err
+	})
+
+	go service.RunPeriodically(jobCtx, log, "scheduled prices", constants.ScheduledPriceJobInterval, func(ctx context.Context) error {
+		_, err := stockService.ApplyScheduledPrices(ctx)
+		return err
+	})
+
+	go service.RunPeriodically(jobCtx, log, "doctor earnings", constants.DoctorEarningJobInterval, func(ctx context.Context) error {
+		_, err := doctorEarningService.RecordEndedRooms(ctx)
+		return err
+	})
+
 	log.Info("Starting Server...")
 
 	go func() {
@@ -378,6 +512,7 @@ func main() {
 	<-quit
 
 	log.Info("Shutting down server...")
+	stopJobs()
 
 	shCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -35,6 +35,29 @@ func (_m *AccountRepository) Add(ctx context.Context, creds domain.AccountWithCr
 	return r0, r1
 }
 
+// GetAllPharmacyManager provides a mock function with given fields: ctx, query
+func (_m *AccountRepository) GetAllPharmacyManager(ctx context.Context, query domain.PharmacyManagerQuery) ([]domain.Account, error) {
+	ret := _m.Called(ctx, query)
+
+	var r0 []domain.Account
+	if rf, ok := ret.Get(0).(func(context.Context, domain.PharmacyManagerQuery) []domain.Account); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Account)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.PharmacyManagerQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByEmail provides a mock function with given fields: ctx, email
 func (_m *AccountRepository) GetByEmail(ctx context.Context, email string) (domain.Account, error) {
 	ret := _m.Called(ctx, email)
@@ -119,6 +142,27 @@ func (_m *AccountRepository) GetByIDAndLock(ctx context.Context, id int64) (doma
 	return r0, r1
 }
 
+// GetPageInfo provides a mock function with given fields: ctx, query
+func (_m *AccountRepository) GetPageInfo(ctx context.Context, query domain.PharmacyManagerQuery) (domain.PageInfo, error) {
+	ret := _m.Called(ctx, query)
+
+	var r0 domain.PageInfo
+	if rf, ok := ret.Get(0).(func(context.Context, domain.PharmacyManagerQuery) domain.PageInfo); ok {
+		r0 = rf(ctx, query)
+	} else {
+		r0 = ret.Get(0).(domain.PageInfo)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.PharmacyManagerQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetWithCredentialsByEmail provides a mock function with given fields: ctx, email
 func (_m *AccountRepository) GetWithCredentialsByEmail(ctx context.Context, email string) (domain.AccountWithCredentials, error) {
 	ret := _m.Called(ctx, email)
@@ -217,6 +261,20 @@ func (_m *AccountRepository) ProfileSetByID(ctx context.Context, id int64) error
 	return r0
 }
 
+// SoftDeleteById provides a mock function with given fields: ctx, id
+func (_m *AccountRepository) SoftDeleteById(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, a
 func (_m *AccountRepository) Update(ctx context.Context, a domain.Account) (domain.Account, error) {
 	ret := _m.Called(ctx, a)
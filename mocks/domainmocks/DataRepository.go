@@ -87,6 +87,54 @@ func (_m *DataRepository) CategoryRepository() domain.CategoryRepository {
 	return r0
 }
 
+// ChatRepository provides a mock function with given fields:
+func (_m *DataRepository) ChatRepository() domain.ChatRepository {
+	ret := _m.Called()
+
+	var r0 domain.ChatRepository
+	if rf, ok := ret.Get(0).(func() domain.ChatRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(domain.ChatRepository)
+		}
+	}
+
+	return r0
+}
+
+// DoctorEarningRepository provides a mock function with given fields:
+func (_m *DataRepository) DoctorEarningRepository() domain.DoctorEarningRepository {
+	ret := _m.Called()
+
+	var r0 domain.DoctorEarningRepository
+	if rf, ok := ret.Get(0).(func() domain.DoctorEarningRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(domain.DoctorEarningRepository)
+		}
+	}
+
+	return r0
+}
+
+// DoctorProfileRepository provides a mock function with given fields:
+func (_m *DataRepository) DoctorProfileRepository() domain.DoctorProfileRepository {
+	ret := _m.Called()
+
+	var r0 domain.DoctorProfileRepository
+	if rf, ok := ret.Get(0).(func() domain.DoctorProfileRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(domain.DoctorProfileRepository)
+		}
+	}
+
+	return r0
+}
+
 // DoctorRepository provides a mock function with given fields:
 func (_m *DataRepository) DoctorRepository() domain.DoctorRepository {
 	ret := _m.Called()
@@ -103,6 +151,75 @@ func (_m *DataRepository) DoctorRepository() domain.DoctorRepository {
 	return r0
 }
 
+// DrugInteractionRepository provides a mock function with given fields:
+func (_m *DataRepository) DrugInteractionRepository() domain.DrugInteractionRepository {
+	ret := _m.Called()
+
+	var r0 domain.DrugInteractionRepository
+	if rf, ok := ret.Get(0).(func() domain.DrugInteractionRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(domain.DrugInteractionRepository)
+		}
+	}
+
+	return r0
+}
+
+// GetDistance provides a mock function with given fields: ctx, a, b
+func (_m *DataRepository) GetDistance(ctx context.Context, a domain.Coordinate, b domain.Coordinate) (float64, error) {
+	ret := _m.Called(ctx, a, b)
+
+	var r0 float64
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Coordinate, domain.Coordinate) float64); ok {
+		r0 = rf(ctx, a, b)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.Coordinate, domain.Coordinate) error); ok {
+		r1 = rf(ctx, a, b)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OrderRepository provides a mock function with given fields:
+func (_m *DataRepository) OrderRepository() domain.OrderRepository {
+	ret := _m.Called()
+
+	var r0 domain.OrderRepository
+	if rf, ok := ret.Get(0).(func() domain.OrderRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(domain.OrderRepository)
+		}
+	}
+
+	return r0
+}
+
+// PaymentRepository provides a mock function with given fields:
+func (_m *DataRepository) PaymentRepository() domain.PaymentRepository {
+	ret := _m.Called()
+
+	var r0 domain.PaymentRepository
+	if rf, ok := ret.Get(0).(func() domain.PaymentRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(domain.PaymentRepository)
+		}
+	}
+
+	return r0
+}
+
 // PharmacyManagerRepository provides a mock function with given fields:
 func (_m *DataRepository) PharmacyManagerRepository() domain.PharmacyManagerRepository {
 	ret := _m.Called()
@@ -119,6 +236,118 @@ func (_m *DataRepository) PharmacyManagerRepository() domain.PharmacyManagerRepo
 	return r0
 }
 
+// PharmacyRepository provides a mock function with given fields:
+func (_m *DataRepository) PharmacyRepository() domain.PharmacyRepository {
+	ret := _m.Called()
+
+	var r0 domain.PharmacyRepository
+	if rf, ok := ret.Get(0).(func() domain.PharmacyRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(domain.PharmacyRepository)
+		}
+	}
+
+	return r0
+}
+
+// ProductDetailsRepository provides a mock function with given fields:
+func (_m *DataRepository) ProductDetailsRepository() domain.ProductDetailsRepository {
+	ret := _m.Called()
+
+	var r0 domain.ProductDetailsRepository
+	if rf, ok := ret.Get(0).(func() domain.ProductDetailsRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(domain.ProductDetailsRepository)
+		}
+	}
+
+	return r0
+}
+
+// ProductImageRepository provides a mock function with given fields:
+func (_m *DataRepository) ProductImageRepository() domain.ProductImageRepository {
+	ret := _m.Called()
+
+	var r0 domain.ProductImageRepository
+	if rf, ok := ret.Get(0).(func() domain.ProductImageRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(domain.ProductImageRepository)
+		}
+	}
+
+	return r0
+}
+
+// ProductRepository provides a mock function with given fields:
+func (_m *DataRepository) ProductRepository() domain.ProductRepository {
+	ret := _m.Called()
+
+	var r0 domain.ProductRepository
+	if rf, ok := ret.Get(0).(func() domain.ProductRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(domain.ProductRepository)
+		}
+	}
+
+	return r0
+}
+
+// ProductSynonymRepository provides a mock function with given fields:
+func (_m *DataRepository) ProductSynonymRepository() domain.ProductSynonymRepository {
+	ret := _m.Called()
+
+	var r0 domain.ProductSynonymRepository
+	if rf, ok := ret.Get(0).(func() domain.ProductSynonymRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(domain.ProductSynonymRepository)
+		}
+	}
+
+	return r0
+}
+
+// ProductVariantRepository provides a mock function with given fields:
+func (_m *DataRepository) ProductVariantRepository() domain.ProductVariantRepository {
+	ret := _m.Called()
+
+	var r0 domain.ProductVariantRepository
+	if rf, ok := ret.Get(0).(func() domain.ProductVariantRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(domain.ProductVariantRepository)
+		}
+	}
+
+	return r0
+}
+
+// PromotionRepository provides a mock function with given fields:
+func (_m *DataRepository) PromotionRepository() domain.PromotionRepository {
+	ret := _m.Called()
+
+	var r0 domain.PromotionRepository
+	if rf, ok := ret.Get(0).(func() domain.PromotionRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(domain.PromotionRepository)
+		}
+	}
+
+	return r0
+}
+
 // RefreshTokenRepository provides a mock function with given fields:
 func (_m *DataRepository) RefreshTokenRepository() domain.RefreshTokenRepository {
 	ret := _m.Called()
@@ -135,6 +364,22 @@ func (_m *DataRepository) RefreshTokenRepository() domain.RefreshTokenRepository
 	return r0
 }
 
+// RelatedProductRepository provides a mock function with given fields:
+func (_m *DataRepository) RelatedProductRepository() domain.RelatedProductRepository {
+	ret := _m.Called()
+
+	var r0 domain.RelatedProductRepository
+	if rf, ok := ret.Get(0).(func() domain.RelatedProductRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(domain.RelatedProductRepository)
+		}
+	}
+
+	return r0
+}
+
 // ResetPasswordTokenRepository provides a mock function with given fields:
 func (_m *DataRepository) ResetPasswordTokenRepository() domain.ResetPasswordTokenRepository {
 	ret := _m.Called()
@@ -151,6 +396,22 @@ func (_m *DataRepository) ResetPasswordTokenRepository() domain.ResetPasswordTok
 	return r0
 }
 
+// ShipmentMethodRepository provides a mock function with given fields:
+func (_m *DataRepository) ShipmentMethodRepository() domain.ShipmentMethodRepository {
+	ret := _m.Called()
+
+	var r0 domain.ShipmentMethodRepository
+	if rf, ok := ret.Get(0).(func() domain.ShipmentMethodRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(domain.ShipmentMethodRepository)
+		}
+	}
+
+	return r0
+}
+
 // Sleep provides a mock function with given fields: ctx, duration
 func (_m *DataRepository) Sleep(ctx context.Context, duration time.Duration) error {
 	ret := _m.Called(ctx, duration)
@@ -197,6 +458,22 @@ func (_m *DataRepository) StockRepository() domain.StockRepository {
 	return r0
 }
 
+// StockTakeRepository provides a mock function with given fields:
+func (_m *DataRepository) StockTakeRepository() domain.StockTakeRepository {
+	ret := _m.Called()
+
+	var r0 domain.StockTakeRepository
+	if rf, ok := ret.Get(0).(func() domain.StockTakeRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(domain.StockTakeRepository)
+		}
+	}
+
+	return r0
+}
+
 // UserRepository provides a mock function with given fields:
 func (_m *DataRepository) UserRepository() domain.UserRepository {
 	ret := _m.Called()
@@ -228,3 +505,19 @@ func (_m *DataRepository) VerifyEmailTokenRepository() domain.VerifyEmailTokenRe
 
 	return r0
 }
+
+// VoucherRepository provides a mock function with given fields:
+func (_m *DataRepository) VoucherRepository() domain.VoucherRepository {
+	ret := _m.Called()
+
+	var r0 domain.VoucherRepository
+	if rf, ok := ret.Get(0).(func() domain.VoucherRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(domain.VoucherRepository)
+		}
+	}
+
+	return r0
+}
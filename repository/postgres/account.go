@@ -2,7 +2,6 @@ package postgres
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"medichat-be/apperror"
 	"medichat-be/domain"
@@ -15,6 +14,22 @@ type accountRepository struct {
 	querier Querier
 }
 
+var pharmacyManagerListSpec = listSpec{
+	sorts: map[string]string{
+		domain.PharmacyManagerSortByCreatedAt: "created_at",
+		domain.PharmacyManagerSortByName:      "name",
+		domain.PharmacyManagerSortByEmail:     "email",
+	},
+	filters: map[string]filterColumn{
+		"name":           {expr: "name", kind: filterString},
+		"email":          {expr: "email", kind: filterString},
+		"email_verified": {expr: "email_verified", kind: filterBool, ops: []domain.FilterOp{domain.FilterOpEq}},
+		"created_at":     {expr: "created_at", kind: filterTime, ops: []domain.FilterOp{domain.FilterOpRange}},
+	},
+	defaultSort: []domain.SortField{{Field: domain.PharmacyManagerSortByCreatedAt, Desc: true}},
+	tiebreak:    "id ASC",
+}
+
 func (r *accountRepository) GetByEmail(
 	ctx context.Context,
 	email string,
@@ -96,7 +111,6 @@ func (r *accountRepository) GetAllPharmacyManager(
 ) ([]domain.Account, error) {
 	sb := strings.Builder{}
 	args := pgx.NamedArgs{}
-	offset := (query.Page - 1) * query.Limit
 
 	sb.WriteString(`
 	SELECT ` + accountColumns + `
@@ -113,14 +127,18 @@ func (r *accountRepository) GetAllPharmacyManager(
 		args["profileSet"] = *query.ProfileSet
 	}
 
-	if query.SortBy != domain.CategorySortByParent {
-		fmt.Fprintf(&sb, " ORDER BY %s %s, id asc", query.SortBy, query.SortType)
+	err := pharmacyManagerListSpec.writeFilters(&sb, namedArg(args), query.Filters)
+	if err != nil {
+		return nil, err
 	}
 
-	if query.Limit != 0 {
-		fmt.Fprintf(&sb, " OFFSET %d LIMIT %d ", offset, query.Limit)
+	err = pharmacyManagerListSpec.writeOrderBy(&sb, query.Sorts)
+	if err != nil {
+		return nil, err
 	}
 
+	writePage(&sb, query.Page, query.Limit)
+
 	return queryFull(
 		r.querier, ctx, sb.String(),
 		scanAccountPharmacy,
@@ -150,6 +168,11 @@ func (r *accountRepository) GetPageInfo(
 		args["profileSet"] = *query.ProfileSet
 	}
 
+	err := pharmacyManagerListSpec.writeFilters(&sb, namedArg(args), query.Filters)
+	if err != nil {
+		return domain.PageInfo{}, err
+	}
+
 	var totalData int64
 	row := r.querier.QueryRowContext(ctx, sb.String(), args)
 	row.Scan(&totalData)
@@ -13,10 +13,44 @@ type categoryRepository struct {
 	querier Querier
 }
 
+var categoryWithParentNameListSpec = listSpec{
+	sorts: map[string]string{
+		domain.CategorySortById:     "c.id",
+		domain.CategorySortByName:   "c.name",
+		domain.CategorySortByLevel:  "c.depth",
+		domain.CategorySortByParent: "c2.name",
+	},
+	filters: map[string]filterColumn{
+		"name":        {expr: "c.name", kind: filterString},
+		"slug":        {expr: "c.slug", kind: filterString},
+		"level":       {expr: "c.depth", kind: filterInt},
+		"parent_id":   {expr: "c.parent_id", kind: filterInt},
+		"parent_slug": {expr: "c2.slug", kind: filterString},
+	},
+	defaultSort: []domain.SortField{{Field: domain.CategorySortById}},
+	tiebreak:    "c.id ASC",
+}
+
+var categoryListSpec = listSpec{
+	sorts: map[string]string{
+		domain.CategorySortById:     "id",
+		domain.CategorySortByName:   "name",
+		domain.CategorySortByLevel:  "depth",
+		domain.CategorySortByParent: "parent_id",
+	},
+	filters: map[string]filterColumn{
+		"name":      {expr: "name", kind: filterString},
+		"slug":      {expr: "slug", kind: filterString},
+		"level":     {expr: "depth", kind: filterInt},
+		"parent_id": {expr: "parent_id", kind: filterInt},
+	},
+	defaultSort: []domain.SortField{{Field: domain.CategorySortById}},
+	tiebreak:    "id ASC",
+}
+
 func (r *categoryRepository) GetCategoriesWithParentName(ctx context.Context, query domain.CategoriesQuery) ([]domain.CategoryWithParentName, error) {
 	sb := strings.Builder{}
 	args := pgx.NamedArgs{}
-	offset := (query.Page - 1) * query.Limit
 
 	sb.WriteString(`
 		SELECT ` + categoryWithParentNameColumns + `
@@ -36,11 +70,8 @@ func (r *categoryRepository) GetCategoriesWithParentName(ctx context.Context, qu
 	}
 
 	if query.Level != 0 {
-		var key string
-		if query.Level == 2 {
-			key = "NOT"
-		}
-		fmt.Fprintf(&sb, `AND c.parent_id IS %s NULL `, key)
+		sb.WriteString(` AND c.depth = @level `)
+		args["level"] = query.Level
 	}
 
 	if query.ParentId != nil {
@@ -48,14 +79,18 @@ func (r *categoryRepository) GetCategoriesWithParentName(ctx context.Context, qu
 		args["parentId"] = *query.ParentId
 	}
 
-	if query.SortBy != domain.CategorySortByParent {
-		fmt.Fprintf(&sb, " ORDER BY %s %s", query.SortBy, query.SortType)
+	err := categoryWithParentNameListSpec.writeFilters(&sb, namedArg(args), query.Filters)
+	if err != nil {
+		return nil, err
 	}
 
-	if query.Limit != 0 {
-		fmt.Fprintf(&sb, " OFFSET %d LIMIT %d ", offset, query.Limit)
+	err = categoryWithParentNameListSpec.writeOrderBy(&sb, query.Sorts)
+	if err != nil {
+		return nil, err
 	}
 
+	writePage(&sb, query.Page, query.Limit)
+
 	return queryFull(
 		r.querier, ctx, sb.String(),
 		scanCategoryWithParentName,
@@ -66,7 +101,6 @@ func (r *categoryRepository) GetCategoriesWithParentName(ctx context.Context, qu
 func (r *categoryRepository) GetCategories(ctx context.Context, query domain.CategoriesQuery) ([]domain.Category, error) {
 	sb := strings.Builder{}
 	args := pgx.NamedArgs{}
-	offset := (query.Page - 1) * query.Limit
 
 	sb.WriteString(`
 		SELECT ` + categoryColumns + `
@@ -78,11 +112,8 @@ func (r *categoryRepository) GetCategories(ctx context.Context, query domain.Cat
 	args["name"] = query.Term
 
 	if query.Level != 0 {
-		var key string
-		if query.Level == 2 {
-			key = "NOT"
-		}
-		fmt.Fprintf(&sb, `AND parent_id IS %s NULL `, key)
+		sb.WriteString(` AND depth = @level `)
+		args["level"] = query.Level
 	}
 
 	if query.ParentId != nil {
@@ -90,14 +121,18 @@ func (r *categoryRepository) GetCategories(ctx context.Context, query domain.Cat
 		args["parentId"] = *query.ParentId
 	}
 
-	if query.SortBy != domain.CategorySortByParent {
-		fmt.Fprintf(&sb, " ORDER BY %s %s", query.SortBy, query.SortType)
+	err := categoryListSpec.writeFilters(&sb, namedArg(args), query.Filters)
+	if err != nil {
+		return nil, err
 	}
 
-	if query.Limit != 0 {
-		fmt.Fprintf(&sb, " OFFSET %d LIMIT %d ", offset, query.Limit)
+	err = categoryListSpec.writeOrderBy(&sb, query.Sorts)
+	if err != nil {
+		return nil, err
 	}
 
+	writePage(&sb, query.Page, query.Limit)
+
 	return queryFull(
 		r.querier, ctx, sb.String(),
 		scanCategory,
@@ -119,6 +154,21 @@ func (r *categoryRepository) GetBySlug(ctx context.Context, slug string) (domain
 	)
 }
 
+func (r *categoryRepository) GetBySlugAndLock(ctx context.Context, slug string) (domain.Category, error) {
+	q := `
+		SELECT ` + categoryColumns + `
+		FROM categories
+		WHERE deleted_at IS NULL AND slug = $1
+		FOR UPDATE
+	`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanCategory,
+		slug,
+	)
+}
+
 func (r *categoryRepository) GetBySlugWithParentName(ctx context.Context, slug string) (domain.CategoryWithParentName, error) {
 	q := `
 		SELECT ` + categoryWithParentNameColumns + `
@@ -156,11 +206,8 @@ func (r *categoryRepository) GetPageInfo(ctx context.Context, query domain.Categ
 	}
 
 	if query.Level != 0 {
-		var key string
-		if query.Level == 2 {
-			key = "NOT"
-		}
-		fmt.Fprintf(&sb, `AND c.parent_id IS %s NULL `, key)
+		sb.WriteString(` AND c.depth = @level `)
+		args["level"] = query.Level
 	}
 
 	if query.ParentId != nil {
@@ -168,9 +215,14 @@ func (r *categoryRepository) GetPageInfo(ctx context.Context, query domain.Categ
 		args["parentId"] = *query.ParentId
 	}
 
+	err := categoryWithParentNameListSpec.writeFilters(&sb, namedArg(args), query.Filters)
+	if err != nil {
+		return domain.PageInfo{}, err
+	}
+
 	var totalData int64
 	row := r.querier.QueryRowContext(ctx, sb.String(), args)
-	err := row.Scan(&totalData)
+	err = row.Scan(&totalData)
 
 	if err != nil {
 		return domain.PageInfo{}, nil
@@ -210,17 +262,33 @@ func (r *categoryRepository) GetById(ctx context.Context, id int64) (domain.Cate
 	)
 }
 
+func (r *categoryRepository) GetByIdAndLock(ctx context.Context, id int64) (domain.Category, error) {
+	q := `
+		SELECT ` + categoryColumns + `
+		FROM categories
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+		`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanCategory,
+		id,
+	)
+}
+
 func (r *categoryRepository) Add(ctx context.Context, category domain.Category) (domain.Category, error) {
 	q := `
-		INSERT INTO categories(parent_id, name, slug, photo_url)
+		INSERT INTO categories(parent_id, name, slug, photo_url, path, depth, position)
 		VALUES
-		($1, $2, $3, $4)
+		($1, $2, $3, $4, $5, $6, $7)
 		RETURNING ` + categoryColumns
 
 	return queryOneFull(
 		r.querier, ctx, q,
 		scanCategory,
 		category.ParentID, category.Name, category.Slug, category.PhotoUrl,
+		category.Path, category.Depth, category.Position,
 	)
 }
 
@@ -228,15 +296,14 @@ func (r *categoryRepository) Update(ctx context.Context, category domain.Categor
 	q := `
 		UPDATE categories
 		SET name = $1, 
-			parent_id = $2,
-			slug = $3,
-			photo_url = $4
-		WHERE id = $5 RETURNING ` + categoryColumns
+			slug = $2,
+			photo_url = $3
+		WHERE id = $4 RETURNING ` + categoryColumns
 
 	return queryOneFull(
 		r.querier, ctx, q,
 		scanCategory,
-		category.Name, category.ParentID, category.Slug, category.PhotoUrl, category.ID,
+		category.Name, category.Slug, category.PhotoUrl, category.ID,
 	)
 }
 
@@ -275,3 +342,108 @@ func (r *categoryRepository) BulkSoftDeleteBySlug(ctx context.Context, slugs []s
 		params...,
 	)
 }
+
+func (r *categoryRepository) GetTreeWithProductCounts(ctx context.Context) ([]domain.CategoryTreeNode, error) {
+	q := `
+		SELECT c.id, c.parent_id, c.name, c.slug, c.photo_url, c.path, c.depth, c.position,
+			COUNT(p.id) AS product_count,
+			COUNT(p.id) FILTER (WHERE p.category_id = c.id) AS direct_product_count
+		FROM categories c
+			LEFT JOIN categories d
+				ON d.deleted_at IS NULL AND d.path LIKE c.path || '%'
+			LEFT JOIN products p
+				ON p.category_id = d.id AND p.deleted_at IS NULL
+		WHERE c.deleted_at IS NULL
+		GROUP BY c.id
+		ORDER BY c.depth, c.position, c.id
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanCategoryTreeNode,
+	)
+}
+
+func (r *categoryRepository) GetChildren(ctx context.Context, parentID *int64) ([]domain.Category, error) {
+	q := `
+		SELECT ` + categoryColumns + `
+		FROM categories
+		WHERE deleted_at IS NULL AND parent_id IS NOT DISTINCT FROM $1
+		ORDER BY position, id
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanCategory,
+		parentID,
+	)
+}
+
+func (r *categoryRepository) GetNextPosition(ctx context.Context, parentID *int64) (int64, error) {
+	q := `
+		SELECT COALESCE(MAX(position), 0) + 1
+		FROM categories
+		WHERE deleted_at IS NULL AND parent_id IS NOT DISTINCT FROM $1
+	`
+
+	return queryOne(
+		r.querier, ctx, q,
+		int64ScanDest,
+		parentID,
+	)
+}
+
+func (r *categoryRepository) UpdatePath(ctx context.Context, id int64, path string) error {
+	q := `
+		UPDATE categories
+		SET path = $1
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+
+	return execOne(
+		r.querier, ctx, q,
+		path, id,
+	)
+}
+
+func (r *categoryRepository) UpdatePosition(ctx context.Context, id int64, position int64) error {
+	q := `
+		UPDATE categories
+		SET position = $1
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+
+	return execOne(
+		r.querier, ctx, q,
+		position, id,
+	)
+}
+
+func (r *categoryRepository) MoveSubtree(ctx context.Context, category domain.Category, parentID *int64, path string, depth int64, position int64) error {
+	q := `
+		UPDATE categories
+		SET parent_id = CASE WHEN id = $1 THEN $2 ELSE parent_id END,
+			position = CASE WHEN id = $1 THEN $3 ELSE position END,
+			path = $4 || substr(path, length($5) + 1),
+			depth = depth + $6
+		WHERE deleted_at IS NULL AND path LIKE $5 || '%'
+	`
+
+	return exec(
+		r.querier, ctx, q,
+		category.ID, parentID, position, path, category.Path, depth-category.Depth,
+	)
+}
+
+func (r *categoryRepository) SoftDeleteSubtree(ctx context.Context, path string) error {
+	q := `
+		UPDATE categories
+		SET deleted_at = now()
+		WHERE deleted_at IS NULL AND path LIKE $1 || '%'
+	`
+
+	return exec(
+		r.querier, ctx, q,
+		path,
+	)
+}
@@ -44,16 +44,45 @@ func (r *chatRepository) AddChat(ctx context.Context, chat domain.Chat) (domain.
 	)
 }
 
-func (r *chatRepository) AddRoom(ctx context.Context, UserId int,DoctorId int,EndAt time.Time ) (domain.Room, error) {
+func (r *chatRepository) AddRoom(ctx context.Context, UserId int,DoctorId int,EndAt time.Time, ConsultationFee int ) (domain.Room, error) {
 	q := `
 		INSERT INTO chat_rooms(`+roomsColumns+`)
 		VALUES
-		($1, $2, $3)
-		RETURNING id, user_id, doctor_id, end_at
-		`
+		($1, $2, $3, $4)
+		RETURNING id, `+roomsColumns
 	return queryOneFull(
 		r.querier, ctx, q,
 		scanRooms,
-		UserId, DoctorId, EndAt,
+		UserId, DoctorId, EndAt, ConsultationFee,
 	)
-}
\ No newline at end of file
+}
+func (r *chatRepository) GetRoomByID(ctx context.Context, id int64) (domain.Room, error) {
+	q := `
+		SELECT id, ` + roomsColumns + `
+		FROM chat_rooms
+		WHERE id = $1
+			AND deleted_at IS NULL
+	`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanRooms,
+		id,
+	)
+}
+
+func (r *chatRepository) GetRoomByIDAndLock(ctx context.Context, id int64) (domain.Room, error) {
+	q := `
+		SELECT id, ` + roomsColumns + `
+		FROM chat_rooms
+		WHERE id = $1
+			AND deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanRooms,
+		id,
+	)
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"database/sql"
+	"medichat-be/domain"
 	"time"
 )
 
@@ -35,6 +36,22 @@ func toInt64Ptr(ni sql.NullInt64) *int64 {
 	return nil
 }
 
+func fromIntPtr(i *int) sql.NullInt64 {
+	var ret sql.NullInt64
+	if i != nil {
+		ret.Valid, ret.Int64 = true, int64(*i)
+	}
+	return ret
+}
+
+func toIntPtr(ni sql.NullInt64) *int {
+	if ni.Valid {
+		i := int(ni.Int64)
+		return &i
+	}
+	return nil
+}
+
 func fromTimePtr(t *time.Time) sql.NullTime {
 	var ret sql.NullTime
 	if t != nil {
@@ -49,3 +66,24 @@ func toTimePtr(nt sql.NullTime) *time.Time {
 	}
 	return nil
 }
+
+func toVariantRefPtr(id sql.NullInt64, name sql.NullString) *domain.ProductVariantRef {
+	if id.Valid {
+		return &domain.ProductVariantRef{ID: id.Int64, Name: name.String}
+	}
+	return nil
+}
+
+func toPromotionRefPtr(id sql.NullInt64, name sql.NullString) *domain.PromotionRef {
+	if id.Valid {
+		return &domain.PromotionRef{ID: id.Int64, Name: name.String}
+	}
+	return nil
+}
+
+func toVoucherRefPtr(id sql.NullInt64, code sql.NullString) *domain.VoucherRef {
+	if id.Valid {
+		return &domain.VoucherRef{ID: id.Int64, Code: code.String}
+	}
+	return nil
+}
@@ -79,6 +79,36 @@ func (r *dataRepository) ProductRepository() domain.ProductRepository {
 	}
 }
 
+func (r *dataRepository) ProductSynonymRepository() domain.ProductSynonymRepository {
+	return &productSynonymRepository{
+		querier: r.querier,
+	}
+}
+
+func (r *dataRepository) ProductImageRepository() domain.ProductImageRepository {
+	return &productImageRepository{
+		querier: r.querier,
+	}
+}
+
+func (r *dataRepository) ProductVariantRepository() domain.ProductVariantRepository {
+	return &productVariantRepository{
+		querier: r.querier,
+	}
+}
+
+func (r *dataRepository) DrugInteractionRepository() domain.DrugInteractionRepository {
+	return &drugInteractionRepository{
+		querier: r.querier,
+	}
+}
+
+func (r *dataRepository) RelatedProductRepository() domain.RelatedProductRepository {
+	return &relatedProductRepository{
+		querier: r.querier,
+	}
+}
+
 func (r *dataRepository) ProductDetailsRepository() domain.ProductDetailsRepository {
 	return &productDetailRepository{
 		querier: r.querier,
@@ -138,6 +168,18 @@ func (r *dataRepository) DoctorRepository() domain.DoctorRepository {
 	}
 }
 
+func (r *dataRepository) DoctorProfileRepository() domain.DoctorProfileRepository {
+	return &doctorProfileRepository{
+		querier: r.querier,
+	}
+}
+
+func (r *dataRepository) DoctorEarningRepository() domain.DoctorEarningRepository {
+	return &doctorEarningRepository{
+		querier: r.querier,
+	}
+}
+
 func (r *dataRepository) PharmacyManagerRepository() domain.PharmacyManagerRepository {
 	return &pharmacyManagerRepository{
 		querier: r.querier,
@@ -168,6 +210,24 @@ func (r *dataRepository) StockRepository() domain.StockRepository {
 	}
 }
 
+func (r *dataRepository) StockTakeRepository() domain.StockTakeRepository {
+	return &stockTakeRepository{
+		querier: r.querier,
+	}
+}
+
+func (r *dataRepository) PromotionRepository() domain.PromotionRepository {
+	return &promotionRepository{
+		querier: r.querier,
+	}
+}
+
+func (r *dataRepository) VoucherRepository() domain.VoucherRepository {
+	return &voucherRepository{
+		querier: r.querier,
+	}
+}
+
 func (r *dataRepository) PaymentRepository() domain.PaymentRepository {
 	return &paymentRepository{
 		querier: r.querier,
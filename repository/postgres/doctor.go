@@ -7,12 +7,31 @@ import (
 	"medichat-be/constants"
 	"medichat-be/domain"
 	"strings"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type doctorRepository struct {
 	querier Querier
 }
 
+// doctorListSpec has no default sort, doctors are cursor paginated on a single
+// sort key that is always given.
+var doctorListSpec = listSpec{
+	sorts: map[string]string{
+		constants.DoctorSortByName:          "a.name",
+		constants.DoctorSortByPrice:         "d.price",
+		constants.DoctorSortByStartWorkDate: "d.start_work_date",
+	},
+	filters: map[string]filterColumn{
+		"name":              {expr: "a.name", kind: filterString},
+		"gender":            {expr: "d.gender", kind: filterString, ops: []domain.FilterOp{domain.FilterOpEq, domain.FilterOpIn}},
+		"specialization_id": {expr: "d.specialization_id", kind: filterInt},
+		"price":             {expr: "d.price", kind: filterInt},
+		"work_location":     {expr: "d.work_location", kind: filterString},
+	},
+}
+
 func (r *doctorRepository) List(
 	ctx context.Context,
 	det domain.DoctorListDetails,
@@ -21,13 +40,72 @@ func (r *doctorRepository) List(
 	var args = make([]any, 0)
 	var idx = 1
 
+	args = append(args, domain.DoctorVerificationApproved)
+	idx++
+
+	selectCols := doctorJoinedColumns
+	scanFunc := scanDoctorJoined
+	matchExpr := ""
+	rankExpr := ""
+
+	if det.Query != nil && strings.TrimSpace(*det.Query) != "" {
+		simExpr := fmt.Sprintf(`word_similarity($%d, %s)`, idx, doctorSearchDocument)
+		idx++
+		args = append(args, *det.Query)
+
+		// The highlights are HTML, so the text around the marks is escaped.
+		headline := func(col string) string {
+			return htmlEscapeExpr(col)
+		}
+
+		tsq := toPrefixTsQuery(*det.Query)
+		if tsq != "" {
+			tsqExpr := fmt.Sprintf(`to_tsquery('simple', $%d)`, idx)
+			idx++
+			args = append(args, tsq)
+
+			matchExpr = fmt.Sprintf(
+				`(to_tsvector('simple', %s) @@ %s OR %s >= %v)`,
+				doctorSearchDocument, tsqExpr, simExpr, constants.DoctorSearchSimilarityThreshold,
+			)
+			rankExpr = fmt.Sprintf(
+				`round((ts_rank(to_tsvector('simple', %s), %s)::float8 + %s::float8)::numeric, %d)::float8`,
+				doctorSearchDocument, tsqExpr, simExpr, constants.DoctorSearchRankDecimals,
+			)
+			headline = func(col string) string {
+				return fmt.Sprintf(
+					`ts_headline('simple', %s, %s, 'StartSel=%s, StopSel=%s, HighlightAll=true')`,
+					htmlEscapeExpr(col), tsqExpr,
+					constants.DoctorSearchHighlightStart, constants.DoctorSearchHighlightStop,
+				)
+			}
+		} else {
+			matchExpr = fmt.Sprintf(`%s >= %v`, simExpr, constants.DoctorSearchSimilarityThreshold)
+			rankExpr = fmt.Sprintf(
+				`round(%s::numeric, %d)::float8`,
+				simExpr, constants.DoctorSearchRankDecimals,
+			)
+		}
+
+		selectCols += fmt.Sprintf(
+			`, %s, %s, %s, %s`,
+			rankExpr, headline("a.name"), headline("s.name"), headline("d.work_location"),
+		)
+		scanFunc = scanDoctorJoinedWithSearch
+	}
+
 	sb.WriteString(`
-		SELECT ` + doctorJoinedColumns + `
+		SELECT ` + selectCols + `
 		FROM doctors d JOIN accounts a ON d.account_id = a.id
 			JOIN specializations s ON d.specialization_id = s.id
 		WHERE d.deleted_at IS NULL
+			AND d.verification_status = $1
 	`)
 
+	if matchExpr != "" {
+		fmt.Fprintf(&sb, ` AND %s 
+		`, matchExpr)
+	}
 	if det.SpecializationID != nil {
 		fmt.Fprintf(&sb, ` AND d.specialization_id = $%d 
 		`, idx)
@@ -63,31 +141,59 @@ func (r *doctorRepository) List(
 		idx++
 		args = append(args, *det.MinYearExperience*365)
 	}
+	if det.Language != nil {
+		fmt.Fprintf(&sb, ` AND EXISTS (
+			SELECT id
+			FROM doctor_languages dl
+			WHERE dl.doctor_id = d.id
+				AND lower(dl.language) = lower($%d)
+				AND dl.deleted_at IS NULL
+		) `, idx)
+		idx++
+		args = append(args, *det.Language)
+	}
+
+	err := doctorListSpec.writeFilters(&sb, positionalArg(&args), det.Filters)
+	if err != nil {
+		return nil, err
+	}
+	idx = len(args) + 1
 
-	sortCol := "a.name"
+	if rankExpr == "" {
+		rankExpr = "a.name"
+	}
 	sortAsc := det.SortAsc
-	switch det.SortBy {
-	case constants.DoctorSortByName:
-		sortCol = "a.name"
-	case constants.DoctorSortByPrice:
-		sortCol = "d.price"
-	case constants.DoctorSortByStartWorkDate:
-		sortCol = "d.start_work_date"
+	sortCol, err := doctorListSpec.
+		withSort(constants.DoctorSortByRelevance, rankExpr).
+		sortExpr(det.SortBy)
+	if err != nil {
+		return nil, err
 	}
 
+	// Active doctors always come first, so the cursor continues within the
+	// group the last page ended in or moves on to the inactive ones.
 	if det.CursorID != nil && det.Cursor != nil {
+		cursorActive := fmt.Sprintf(`(SELECT is_active FROM doctors WHERE id = $%d)`, idx+1)
+		if det.CursorIsActive != nil {
+			cursorActive = fmt.Sprintf(`$%d`, idx+2)
+		}
+
 		fmt.Fprintf(
 			&sb,
-			` AND (%s, d.id) %s ($%d, $%d) `,
-			sortCol, getSortCursorCmp(sortAsc), idx, idx+1,
+			` AND (d.is_active < %s OR (d.is_active = %s AND (%s, d.id) %s ($%d, $%d))) `,
+			cursorActive, cursorActive, sortCol, getSortCursorCmp(sortAsc), idx, idx+1,
 		)
 		idx += 2
 		args = append(args, det.Cursor, *det.CursorID)
+		if det.CursorIsActive != nil {
+			idx++
+			args = append(args, *det.CursorIsActive)
+		}
 	}
 
 	fmt.Fprintf(
 		&sb,
-		` ORDER BY is_active desc, %s %s, d.id %s`,
+		` ORDER BY d.is_active desc, %s %s, d.id %s`,
 		sortCol,
 		getSortOrder(sortAsc),
 		getSortOrder(det.SortAsc),
@@ -99,7 +205,7 @@ func (r *doctorRepository) List(
 
 	return queryFull(
 		r.querier, ctx, sb.String(),
-		scanDoctorJoined,
+		scanFunc,
 		args...,
 	)
 }
@@ -222,6 +328,94 @@ func (r *doctorRepository) IsExistByAccountID(
 	)
 }
 
+// IsExistBySpecializationID reports whether an approved doctor or a pending
+// registration has the specialization. Rejected registrations don't count.
+func (r *doctorRepository) IsExistBySpecializationID(
+	ctx context.Context,
+	id int64,
+) (bool, error) {
+	q := `
+		SELECT EXISTS (
+			SELECT id
+			FROM doctors
+			WHERE specialization_id = $1
+				AND verification_status <> $2
+				AND deleted_at IS NULL
+		)
+	`
+
+	return queryOne(
+		r.querier, ctx, q,
+		boolScanDest,
+		id, domain.DoctorVerificationRejected,
+	)
+}
+
+func (r *doctorRepository) buildVerificationListQuery(
+	sel string,
+	dets domain.DoctorVerificationListDetails,
+) (*strings.Builder, pgx.NamedArgs) {
+	var sb strings.Builder
+	args := pgx.NamedArgs{}
+
+	sb.WriteString(sel)
+	sb.WriteString(`
+		FROM doctors d JOIN accounts a ON d.account_id = a.id
+			JOIN specializations s ON d.specialization_id = s.id
+		WHERE d.deleted_at IS NULL
+			AND d.verification_status = @status
+	`)
+	args["status"] = dets.Status
+
+	return &sb, args
+}
+
+func (r *doctorRepository) GetVerificationPageInfo(
+	ctx context.Context,
+	dets domain.DoctorVerificationListDetails,
+) (domain.PageInfo, error) {
+	sb, args := r.buildVerificationListQuery(`SELECT COUNT(d.id)`, dets)
+
+	count, err := queryOne(
+		r.querier, ctx, sb.String(),
+		int64ScanDest,
+		args,
+	)
+	if err != nil {
+		return domain.PageInfo{}, apperror.Wrap(err)
+	}
+
+	return domain.PageInfo{
+		CurrentPage:  dets.Page,
+		ItemsPerPage: dets.Limit,
+		ItemCount:    count,
+		PageCount:    int((count - 1 + int64(dets.Limit)) / int64(dets.Limit)),
+	}, nil
+}
+
+func (r *doctorRepository) ListVerifications(
+	ctx context.Context,
+	dets domain.DoctorVerificationListDetails,
+) ([]domain.Doctor, error) {
+	sb, args := r.buildVerificationListQuery(`SELECT `+doctorJoinedColumns, dets)
+	offset := (dets.Page - 1) * dets.Limit
+
+	sb.WriteString(` ORDER BY d.created_at ASC, d.id ASC`)
+
+	fmt.Fprintf(
+		sb,
+		` OFFSET %d LIMIT %d `,
+		offset,
+		dets.Limit,
+	)
+
+	return queryFull(
+		r.querier, ctx, sb.String(),
+		scanDoctorJoined,
+		args,
+	)
+}
+
 func (r *doctorRepository) Add(
 	ctx context.Context,
 	d domain.Doctor,
@@ -229,10 +423,11 @@ func (r *doctorRepository) Add(
 	q := `
 		INSERT INTO doctors(
 			account_id, specialization_id, str, work_location, gender,
-			phone_number, is_active, start_work_date, price, certificate_url
+			phone_number, is_active, start_work_date, price, certificate_url,
+			bio, verification_status
 		)		
 		VALUES
-		($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING ` + doctorColumns
 
 	return queryOneFull(
@@ -240,6 +435,7 @@ func (r *doctorRepository) Add(
 		scanDoctor,
 		d.Account.ID, d.Specialization.ID, d.STR, d.WorkLocation, d.Gender,
 		d.PhoneNumber, d.IsActive, d.StartWorkDate, d.Price, d.CertificateURL,
+		d.Bio, d.VerificationStatus,
 	)
 }
 
@@ -254,6 +450,8 @@ func (r *doctorRepository) Update(
 			phone_number = $4,
 			price = $5,
 			is_active = $6,
+			certificate_url = $7,
+			bio = $8,
 			updated_at = now()
 		WHERE id = $1
 			AND deleted_at IS NULL
@@ -262,6 +460,31 @@ func (r *doctorRepository) Update(
 	err := execOne(
 		r.querier, ctx, q,
 		d.ID, d.WorkLocation, d.Gender, d.PhoneNumber, d.Price, d.IsActive,
+		d.CertificateURL, d.Bio,
+	)
+	if err != nil {
+		return domain.Doctor{}, apperror.Wrap(err)
+	}
+
+	return d, nil
+}
+
+func (r *doctorRepository) UpdateVerification(
+	ctx context.Context,
+	d domain.Doctor,
+) (domain.Doctor, error) {
+	q := `
+		UPDATE doctors
+		SET verification_status = $2,
+			rejection_reason = $3,
+			updated_at = now()
+		WHERE id = $1
+			AND deleted_at IS NULL
+	`
+
+	err := execOne(
+		r.querier, ctx, q,
+		d.ID, d.VerificationStatus, fromStringPtr(d.RejectionReason),
 	)
 	if err != nil {
 		return domain.Doctor{}, apperror.Wrap(err)
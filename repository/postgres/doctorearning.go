@@ -0,0 +1,223 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type doctorEarningRepository struct {
+	querier Querier
+}
+
+func (r *doctorEarningRepository) IsExistByRoomID(
+	ctx context.Context,
+	roomID int64,
+) (bool, error) {
+	q := `
+		SELECT EXISTS (
+			SELECT id
+			FROM doctor_earnings
+			WHERE room_id = $1
+				AND deleted_at IS NULL
+		)
+	`
+
+	return queryOne(
+		r.querier, ctx, q,
+		boolScanDest,
+		roomID,
+	)
+}
+
+// ListUnrecordedRoomIDs lists the rooms that ended before until without a
+// ledger entry.
+func (r *doctorEarningRepository) ListUnrecordedRoomIDs(
+	ctx context.Context,
+	until time.Time,
+) ([]int64, error) {
+	q := `
+		SELECT r.id
+		FROM chat_rooms r
+		WHERE r.end_at < $1
+			AND r.deleted_at IS NULL
+			AND NOT EXISTS (
+				SELECT e.id
+				FROM doctor_earnings e
+				WHERE e.room_id = r.id
+					AND e.deleted_at IS NULL
+			)
+		ORDER BY r.id ASC
+	`
+
+	return query(
+		r.querier, ctx, q,
+		int64ScanDest,
+		until,
+	)
+}
+
+func (r *doctorEarningRepository) ListByDoctorID(
+	ctx context.Context,
+	doctorID int64,
+	start, end time.Time,
+) ([]domain.DoctorEarning, error) {
+	q := `
+		SELECT ` + doctorEarningColumns + `
+		FROM doctor_earnings
+		WHERE doctor_id = $1
+			AND closed_at >= $2
+			AND closed_at < $3
+			AND deleted_at IS NULL
+		ORDER BY closed_at ASC, id ASC
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanDoctorEarning,
+		doctorID, start, end,
+	)
+}
+
+func (r *doctorEarningRepository) Add(
+	ctx context.Context,
+	e domain.DoctorEarning,
+) (domain.DoctorEarning, error) {
+	q := `
+		INSERT INTO doctor_earnings(
+			doctor_id, room_id, consultation_fee, commission_rate,
+			commission, net_earning, status, closed_at
+		)
+		VALUES
+		($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING ` + doctorEarningColumns
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanDoctorEarning,
+		e.DoctorID, e.RoomID, e.ConsultationFee, e.CommissionRate,
+		e.Commission, e.NetEarning, e.Status, e.ClosedAt,
+	)
+}
+
+// GetUnpaidSummaries locks the unpaid earnings closed before dets.Until and
+// sums them up per doctor, with the IDs of the earnings it locked.
+func (r *doctorEarningRepository) GetUnpaidSummaries(
+	ctx context.Context,
+	dets domain.DoctorPayoutCreateDetails,
+) ([]domain.DoctorPayout, error) {
+	var sb strings.Builder
+	args := pgx.NamedArgs{
+		"status": domain.DoctorEarningStatusUnpaid,
+		"until":  dets.Until,
+	}
+
+	sb.WriteString(`
+		SELECT id, doctor_id, net_earning
+		FROM doctor_earnings
+		WHERE status = @status
+			AND closed_at < @until
+			AND deleted_at IS NULL
+	`)
+
+	if dets.DoctorID != nil {
+		sb.WriteString(`
+			AND doctor_id = @doctorID
+		`)
+		args["doctorID"] = *dets.DoctorID
+	}
+
+	sb.WriteString(`
+		ORDER BY doctor_id ASC, id ASC
+		FOR UPDATE
+	`)
+
+	earnings, err := queryFull(
+		r.querier, ctx, sb.String(),
+		scanDoctorUnpaidEarning,
+		args,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := []domain.DoctorPayout{}
+	for _, e := range earnings {
+		if len(summaries) == 0 || summaries[len(summaries)-1].DoctorID != e.DoctorID {
+			summaries = append(summaries, domain.DoctorPayout{DoctorID: e.DoctorID})
+		}
+		s := &summaries[len(summaries)-1]
+		s.Amount += e.NetEarning
+		s.NEntries++
+		s.EarningIDs = append(s.EarningIDs, e.ID)
+	}
+
+	return summaries, nil
+}
+
+func (r *doctorEarningRepository) AddPayout(
+	ctx context.Context,
+	p domain.DoctorPayout,
+) (domain.DoctorPayout, error) {
+	q := `
+		INSERT INTO doctor_payouts(doctor_id, amount, n_entries, paid_at)
+		VALUES
+		($1, $2, $3, now())
+		RETURNING ` + doctorPayoutColumns
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanDoctorPayout,
+		p.DoctorID, p.Amount, p.NEntries,
+	)
+}
+
+// MarkPaid marks the unpaid earnings among earningIDs as paid by the payout
+// and returns how many it marked.
+func (r *doctorEarningRepository) MarkPaid(
+	ctx context.Context,
+	payoutID int64,
+	earningIDs []int64,
+) (int, error) {
+	if len(earningIDs) == 0 {
+		return 0, nil
+	}
+
+	var sb strings.Builder
+	args := []any{domain.DoctorEarningStatusPaid, payoutID, domain.DoctorEarningStatusUnpaid}
+
+	sb.WriteString(`
+		UPDATE doctor_earnings
+		SET status = $1,
+			payout_id = $2,
+			updated_at = now()
+		WHERE status = $3
+			AND deleted_at IS NULL
+			AND id IN (`)
+
+	for i, id := range earningIDs {
+		args = append(args, id)
+		fmt.Fprintf(&sb, "$%d", len(args))
+		if i != len(earningIDs)-1 {
+			sb.WriteString(", ")
+		}
+	}
+	sb.WriteString(`)`)
+
+	res, err := r.querier.ExecContext(ctx, sb.String(), args...)
+	if err != nil {
+		return 0, apperror.Wrap(err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, apperror.Wrap(err)
+	}
+
+	return int(n), nil
+}
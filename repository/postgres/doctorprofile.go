@@ -0,0 +1,214 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"medichat-be/domain"
+	"strings"
+)
+
+type doctorProfileRepository struct {
+	querier Querier
+}
+
+func (r *doctorProfileRepository) ListEducationsByDoctorID(
+	ctx context.Context,
+	doctorID int64,
+) ([]domain.DoctorEducation, error) {
+	q := `
+		SELECT ` + doctorEducationColumns + `
+		FROM doctor_educations
+		WHERE doctor_id = $1
+			AND deleted_at IS NULL
+		ORDER BY start_year DESC, id ASC
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanDoctorEducation,
+		doctorID,
+	)
+}
+
+func (r *doctorProfileRepository) ReplaceEducations(
+	ctx context.Context,
+	doctorID int64,
+	es []domain.DoctorEducation,
+) ([]domain.DoctorEducation, error) {
+	q := `
+		UPDATE doctor_educations
+		SET deleted_at = now()
+		WHERE doctor_id = $1
+			AND deleted_at IS NULL
+	`
+
+	err := exec(
+		r.querier, ctx, q,
+		doctorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(es) == 0 {
+		return []domain.DoctorEducation{}, nil
+	}
+
+	var sb strings.Builder
+	args := []any{doctorID}
+	idx := 2
+
+	sb.WriteString(`
+		INSERT INTO doctor_educations(doctor_id, institution, degree, start_year, end_year)
+		VALUES
+	`)
+	for i, e := range es {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "($1, $%d, $%d, $%d, $%d)", idx, idx+1, idx+2, idx+3)
+		idx += 4
+		args = append(args, e.Institution, e.Degree, e.StartYear, fromIntPtr(e.EndYear))
+	}
+	sb.WriteString(` RETURNING ` + doctorEducationColumns)
+
+	return queryFull(
+		r.querier, ctx, sb.String(),
+		scanDoctorEducation,
+		args...,
+	)
+}
+
+func (r *doctorProfileRepository) ListPracticesByDoctorID(
+	ctx context.Context,
+	doctorID int64,
+) ([]domain.DoctorPractice, error) {
+	q := `
+		SELECT ` + doctorPracticeColumns + `
+		FROM doctor_practices
+		WHERE doctor_id = $1
+			AND deleted_at IS NULL
+		ORDER BY start_date DESC, id ASC
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanDoctorPractice,
+		doctorID,
+	)
+}
+
+func (r *doctorProfileRepository) ReplacePractices(
+	ctx context.Context,
+	doctorID int64,
+	ps []domain.DoctorPractice,
+) ([]domain.DoctorPractice, error) {
+	q := `
+		UPDATE doctor_practices
+		SET deleted_at = now()
+		WHERE doctor_id = $1
+			AND deleted_at IS NULL
+	`
+
+	err := exec(
+		r.querier, ctx, q,
+		doctorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ps) == 0 {
+		return []domain.DoctorPractice{}, nil
+	}
+
+	var sb strings.Builder
+	args := []any{doctorID}
+	idx := 2
+
+	sb.WriteString(`
+		INSERT INTO doctor_practices(doctor_id, location, start_date, end_date)
+		VALUES
+	`)
+	for i, p := range ps {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "($1, $%d, $%d, $%d)", idx, idx+1, idx+2)
+		idx += 3
+		args = append(args, p.Location, p.StartDate, fromTimePtr(p.EndDate))
+	}
+	sb.WriteString(` RETURNING ` + doctorPracticeColumns)
+
+	return queryFull(
+		r.querier, ctx, sb.String(),
+		scanDoctorPractice,
+		args...,
+	)
+}
+
+func (r *doctorProfileRepository) ListLanguagesByDoctorID(
+	ctx context.Context,
+	doctorID int64,
+) ([]string, error) {
+	q := `
+		SELECT language
+		FROM doctor_languages
+		WHERE doctor_id = $1
+			AND deleted_at IS NULL
+		ORDER BY id ASC
+	`
+
+	return query(
+		r.querier, ctx, q,
+		stringScanDest,
+		doctorID,
+	)
+}
+
+func (r *doctorProfileRepository) ReplaceLanguages(
+	ctx context.Context,
+	doctorID int64,
+	languages []string,
+) ([]string, error) {
+	q := `
+		UPDATE doctor_languages
+		SET deleted_at = now()
+		WHERE doctor_id = $1
+			AND deleted_at IS NULL
+	`
+
+	err := exec(
+		r.querier, ctx, q,
+		doctorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(languages) == 0 {
+		return []string{}, nil
+	}
+
+	var sb strings.Builder
+	args := []any{doctorID}
+
+	sb.WriteString(`
+		INSERT INTO doctor_languages(doctor_id, language)
+		VALUES
+	`)
+	for i, l := range languages {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "($1, $%d)", i+2)
+		args = append(args, l)
+	}
+	sb.WriteString(` RETURNING language`)
+
+	return query(
+		r.querier, ctx, sb.String(),
+		stringScanDest,
+		args...,
+	)
+}
@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"medichat-be/constants"
+	"medichat-be/domain"
+	"strings"
+)
+
+type drugInteractionRepository struct {
+	querier Querier
+}
+
+func (r *drugInteractionRepository) List(
+	ctx context.Context,
+) ([]domain.DrugInteraction, error) {
+	q := `
+		SELECT ` + drugInteractionColumns + `
+		FROM drug_interactions
+		WHERE deleted_at IS NULL
+		ORDER BY ingredient_a ASC, ingredient_b ASC
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanDrugInteraction,
+	)
+}
+
+func (r *drugInteractionRepository) ListByIngredients(
+	ctx context.Context,
+	ingredients []string,
+) ([]domain.DrugInteraction, error) {
+	if len(ingredients) == 0 {
+		return []domain.DrugInteraction{}, nil
+	}
+
+	var sb strings.Builder
+	args := make([]any, len(ingredients))
+	placeholders := make([]string, len(ingredients))
+	for i, ing := range ingredients {
+		args[i] = ing
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	in := strings.Join(placeholders, ", ")
+
+	fmt.Fprintf(&sb, `
+		SELECT `+drugInteractionColumns+`
+		FROM drug_interactions
+		WHERE deleted_at IS NULL
+			AND ingredient_a IN (%s)
+			AND ingredient_b IN (%s)
+	`, in, in)
+
+	return queryFull(
+		r.querier, ctx, sb.String(),
+		scanDrugInteraction,
+		args...,
+	)
+}
+
+func (r *drugInteractionRepository) ReplaceAll(
+	ctx context.Context,
+	interactions []domain.DrugInteraction,
+) error {
+	q := `
+		UPDATE drug_interactions
+		SET deleted_at = now(),
+			updated_at = now()
+		WHERE deleted_at IS NULL
+	`
+
+	err := exec(
+		r.querier, ctx, q,
+	)
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(interactions); start += constants.DrugInteractionInsertBatchSize {
+		end := start + constants.DrugInteractionInsertBatchSize
+		if end > len(interactions) {
+			end = len(interactions)
+		}
+
+		var sb strings.Builder
+		args := []any{}
+
+		sb.WriteString(`
+			INSERT INTO drug_interactions(ingredient_a, ingredient_b, severity, description)
+			VALUES
+		`)
+		for i, in := range interactions[start:end] {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			n := len(args)
+			fmt.Fprintf(&sb, "($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4)
+			args = append(args, in.IngredientA, in.IngredientB, in.Severity, in.Description)
+		}
+
+		err = exec(
+			r.querier, ctx, sb.String(),
+			args...,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *drugInteractionRepository) ListActiveOverridesByUserID(
+	ctx context.Context,
+	userID int64,
+) ([]domain.DrugInteractionOverride, error) {
+	q := `
+		SELECT ` + drugInteractionOverrideColumns + `
+		FROM drug_interaction_overrides
+		WHERE user_id = $1
+			AND expires_at > now()
+			AND deleted_at IS NULL
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanDrugInteractionOverride,
+		userID,
+	)
+}
+
+func (r *drugInteractionRepository) AddOverride(
+	ctx context.Context,
+	o domain.DrugInteractionOverride,
+) (domain.DrugInteractionOverride, error) {
+	q := `
+		INSERT INTO drug_interaction_overrides(
+			user_id, doctor_id, room_id, ingredient_a, ingredient_b, expires_at
+		)
+		VALUES
+		($1, $2, $3, $4, $5, $6)
+		RETURNING ` + drugInteractionOverrideColumns
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanDrugInteractionOverride,
+		o.UserID, o.DoctorID, o.RoomID, o.IngredientA, o.IngredientB, o.ExpiresAt,
+	)
+}
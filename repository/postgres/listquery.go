@@ -0,0 +1,215 @@
+package postgres
+
+import (
+	"fmt"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type filterKind int
+
+const (
+	filterString filterKind = iota
+	filterInt
+	filterFloat
+	filterTime
+	filterBool
+)
+
+// filterColumn is a filterable SQL expression. When ops is empty every
+// operator that fits the kind is allowed.
+type filterColumn struct {
+	expr string
+	kind filterKind
+	ops  []domain.FilterOp
+}
+
+func (c filterColumn) allows(op domain.FilterOp) bool {
+	switch op {
+	case domain.FilterOpEq, domain.FilterOpIn, domain.FilterOpRange:
+	case domain.FilterOpContains:
+		if c.kind != filterString {
+			return false
+		}
+	default:
+		return false
+	}
+
+	if len(c.ops) == 0 {
+		return true
+	}
+	for _, o := range c.ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (c filterColumn) parse(field string, v string) (any, error) {
+	var ret any
+	var err error
+
+	switch c.kind {
+	case filterInt:
+		ret, err = strconv.ParseInt(v, 10, 64)
+	case filterFloat:
+		ret, err = strconv.ParseFloat(v, 64)
+	case filterTime:
+		ret, err = time.Parse(time.RFC3339, v)
+	case filterBool:
+		ret, err = strconv.ParseBool(v)
+	default:
+		ret = v
+	}
+
+	if err != nil {
+		return nil, apperror.NewInvalidFilterValue(field, v)
+	}
+	return ret, nil
+}
+
+// listSpec whitelists the public sort and filter names of a list query.
+// Names coming from requests are only ever looked up here, so they never
+// reach the SQL text themselves.
+type listSpec struct {
+	sorts       map[string]string
+	filters     map[string]filterColumn
+	defaultSort []domain.SortField
+	// tiebreak keeps pages stable when the sort keys are equal.
+	tiebreak string
+}
+
+// withSort returns a copy of the spec with an extra sort expression, for
+// sorts that depend on the request such as search rank or distance.
+func (s listSpec) withSort(name string, expr string) listSpec {
+	sorts := make(map[string]string, len(s.sorts)+1)
+	for k, v := range s.sorts {
+		sorts[k] = v
+	}
+	sorts[name] = expr
+	s.sorts = sorts
+	return s
+}
+
+func (s listSpec) sortExpr(field string) (string, error) {
+	expr, ok := s.sorts[field]
+	if !ok {
+		return "", apperror.NewInvalidSortField(field)
+	}
+	return expr, nil
+}
+
+// writeOrderBy writes an ORDER BY clause for the given sorts, falling back to
+// the spec's default sort when none are given.
+func (s listSpec) writeOrderBy(sb *strings.Builder, sorts []domain.SortField) error {
+	if len(sorts) == 0 {
+		sorts = s.defaultSort
+	}
+
+	keys := make([]string, 0, len(sorts)+1)
+	for _, sf := range sorts {
+		expr, err := s.sortExpr(sf.Field)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, expr+" "+getSortOrder(!sf.Desc))
+	}
+	if s.tiebreak != "" {
+		keys = append(keys, s.tiebreak)
+	}
+
+	if len(keys) > 0 {
+		sb.WriteString(" ORDER BY " + strings.Join(keys, ", ") + " ")
+	}
+	return nil
+}
+
+// writeFilters appends one AND condition per filter. arg registers a value
+// and returns its placeholder, see namedArg and positionalArg.
+func (s listSpec) writeFilters(sb *strings.Builder, arg func(any) string, filters []domain.Filter) error {
+	for _, f := range filters {
+		col, ok := s.filters[f.Field]
+		if !ok || !col.allows(f.Op) {
+			return apperror.NewInvalidFilter(f.Field, string(f.Op))
+		}
+
+		switch f.Op {
+		case domain.FilterOpEq, domain.FilterOpContains:
+			if len(f.Values) != 1 {
+				return apperror.NewInvalidFilter(f.Field, string(f.Op))
+			}
+			v, err := col.parse(f.Field, f.Values[0])
+			if err != nil {
+				return err
+			}
+			if f.Op == domain.FilterOpEq {
+				fmt.Fprintf(sb, ` AND %s = %s `, col.expr, arg(v))
+			} else {
+				fmt.Fprintf(sb, ` AND %s ILIKE '%%' || %s || '%%' ESCAPE '\' `, col.expr, arg(escapeLike(f.Values[0])))
+			}
+
+		case domain.FilterOpIn:
+			if len(f.Values) == 0 {
+				return apperror.NewInvalidFilter(f.Field, string(f.Op))
+			}
+			placeholders := make([]string, len(f.Values))
+			for i, raw := range f.Values {
+				v, err := col.parse(f.Field, raw)
+				if err != nil {
+					return err
+				}
+				placeholders[i] = arg(v)
+			}
+			fmt.Fprintf(sb, ` AND %s IN (%s) `, col.expr, strings.Join(placeholders, ", "))
+
+		case domain.FilterOpRange:
+			if len(f.Values) != 2 {
+				return apperror.NewInvalidFilter(f.Field, string(f.Op))
+			}
+			for i, cmp := range []string{">=", "<="} {
+				if f.Values[i] == "" {
+					continue
+				}
+				v, err := col.parse(f.Field, f.Values[i])
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(sb, ` AND %s %s %s `, col.expr, cmp, arg(v))
+			}
+		}
+	}
+	return nil
+}
+
+func writePage(sb *strings.Builder, page int64, limit int64) {
+	if limit != 0 {
+		fmt.Fprintf(sb, " OFFSET %d LIMIT %d ", (page-1)*limit, limit)
+	}
+}
+
+// namedArg registers values in args under generated names, for queries built
+// with pgx.NamedArgs.
+func namedArg(args pgx.NamedArgs) func(any) string {
+	n := 0
+	return func(v any) string {
+		n++
+		name := fmt.Sprintf("listArg%d", n)
+		args[name] = v
+		return "@" + name
+	}
+}
+
+// positionalArg appends values to args, for queries built with $n
+// placeholders.
+func positionalArg(args *[]any) func(any) string {
+	return func(v any) string {
+		*args = append(*args, v)
+		return fmt.Sprintf("$%d", len(*args))
+	}
+}
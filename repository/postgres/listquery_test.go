@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"medichat-be/domain"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_listSpec_writeFilters_contains(t *testing.T) {
+	spec := listSpec{
+		filters: map[string]filterColumn{
+			"name": {expr: "p.name", kind: filterString},
+		},
+	}
+
+	tests := []struct {
+		name string
+
+		value string
+
+		wantArg string
+	}{
+		{
+			name: "should pass plain text through",
+
+			value: "panadol",
+
+			wantArg: "panadol",
+		},
+		{
+			name: "should escape percent and underscore",
+
+			value: "50%_off",
+
+			wantArg: `50\%\_off`,
+		},
+		{
+			name: "should escape the escape character",
+
+			value: `a\b`,
+
+			wantArg: `a\\b`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			var sb strings.Builder
+			var args []any
+			filters := []domain.Filter{{Field: "name", Op: domain.FilterOpContains, Values: []string{tt.value}}}
+
+			// when
+			err := spec.writeFilters(&sb, positionalArg(&args), filters)
+
+			// then
+			assert.NoError(t, err)
+			assert.Contains(t, sb.String(), `p.name ILIKE '%' || $1 || '%' ESCAPE '\'`)
+			assert.Equal(t, []any{tt.wantArg}, args)
+		})
+	}
+}
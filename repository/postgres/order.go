@@ -2,11 +2,11 @@ package postgres
 
 import (
 	"context"
-	"fmt"
 	"medichat-be/apperror"
 	"medichat-be/domain"
 	"medichat-be/repository/postgis"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 )
@@ -15,7 +15,24 @@ type orderRepository struct {
 	querier Querier
 }
 
-func (r *orderRepository) buildListQuery(sel string, dets domain.OrderListDetails) (*strings.Builder, pgx.NamedArgs) {
+var orderListSpec = listSpec{
+	sorts: map[string]string{
+		domain.OrderSortByOrderedAt: "o.ordered_at",
+		domain.OrderSortByTotal:     "o.total",
+		domain.OrderSortByNItems:    "o.n_items",
+	},
+	filters: map[string]filterColumn{
+		"status":        {expr: "o.status", kind: filterString, ops: []domain.FilterOp{domain.FilterOpEq, domain.FilterOpIn}},
+		"pharmacy_slug": {expr: "ph.slug", kind: filterString},
+		"total":         {expr: "o.total", kind: filterInt},
+		"ordered_at":    {expr: "o.ordered_at", kind: filterTime, ops: []domain.FilterOp{domain.FilterOpRange}},
+		"finished_at":   {expr: "o.finished_at", kind: filterTime, ops: []domain.FilterOp{domain.FilterOpRange}},
+	},
+	defaultSort: []domain.SortField{{Field: domain.OrderSortByOrderedAt, Desc: true}},
+	tiebreak:    "o.id ASC",
+}
+
+func (r *orderRepository) buildListQuery(sel string, dets domain.OrderListDetails) (*strings.Builder, pgx.NamedArgs, error) {
 	sb := strings.Builder{}
 	args := pgx.NamedArgs{}
 
@@ -56,11 +73,19 @@ func (r *orderRepository) buildListQuery(sel string, dets domain.OrderListDetail
 		args["status"] = *dets.Status
 	}
 
-	return &sb, args
+	err := orderListSpec.writeFilters(&sb, namedArg(args), dets.Filters)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &sb, args, nil
 }
 
 func (r *orderRepository) GetPageInfo(ctx context.Context, dets domain.OrderListDetails) (domain.PageInfo, error) {
-	sb, args := r.buildListQuery(countOrderJoined, dets)
+	sb, args, err := r.buildListQuery(countOrderJoined, dets)
+	if err != nil {
+		return domain.PageInfo{}, err
+	}
 
 	count, err := queryOne(
 		r.querier, ctx, sb.String(),
@@ -80,19 +105,17 @@ func (r *orderRepository) GetPageInfo(ctx context.Context, dets domain.OrderList
 }
 
 func (r *orderRepository) List(ctx context.Context, dets domain.OrderListDetails) ([]domain.Order, error) {
-	sb, args := r.buildListQuery(selectOrderJoined, dets)
-	offset := (dets.Page - 1) * dets.Limit
+	sb, args, err := r.buildListQuery(selectOrderJoined, dets)
+	if err != nil {
+		return nil, err
+	}
 
-	sb.WriteString(`
-		ORDER BY o.ordered_at DESC
-	`)
+	err = orderListSpec.writeOrderBy(sb, dets.Sorts)
+	if err != nil {
+		return nil, err
+	}
 
-	fmt.Fprintf(
-		sb,
-		` OFFSET %d LIMIT %d `,
-		offset,
-		dets.Limit,
-	)
+	writePage(sb, int64(dets.Page), int64(dets.Limit))
 
 	return queryFull(
 		r.querier, ctx, sb.String(),
@@ -133,25 +156,30 @@ func (r *orderRepository) Add(ctx context.Context, o domain.Order) (domain.Order
 		INSERT INTO orders(
 			user_id, pharmacy_id, payment_id, shipment_method_id,
 			address, coordinate,
-			n_items, subtotal, shipment_fee, total,
-			status, ordered_at, finished_at
+			n_items, subtotal, discount, voucher_id, voucher_discount, shipment_fee, total,
+			status, ordered_at, finished_at, process_at
 		)
 		VALUES
 		(
 			$1, $2, $3, $4,
 			$5, $6,
-			$7, $8, $9, $10,
-			$11, $12, $13
+			$7, $8, $9, $10, $11, $12, $13,
+			$14, $15, $16, $17
 		)
 		RETURNING ` + orderColumns
 
+	var voucherID *int64
+	if o.Voucher != nil {
+		voucherID = &o.Voucher.ID
+	}
+
 	return queryOneFull(
 		r.querier, ctx, q,
 		scanOrder,
 		o.User.ID, o.Pharmacy.ID, o.Payment.ID, o.ShipmentMethod.ID,
 		o.Address, postgis.NewPointFromCoordinate(o.Coordinate),
-		o.NItems, o.Subtotal, o.ShipmentFee, o.Total,
-		o.Status, o.OrderedAt, fromTimePtr(o.FinishedAt),
+		o.NItems, o.Subtotal, o.Discount, fromInt64Ptr(voucherID), o.VoucherDiscount, o.ShipmentFee, o.Total,
+		o.Status, o.OrderedAt, fromTimePtr(o.FinishedAt), fromTimePtr(o.ProcessAt),
 	)
 }
 
@@ -183,6 +211,24 @@ func (r *orderRepository) UpdateStatusByPaymentID(ctx context.Context, id int64,
 	)
 }
 
+func (r *orderRepository) CancelWaitingPaymentBefore(ctx context.Context, before time.Time) ([]int64, error) {
+	q := `
+		UPDATE orders
+		SET status = $1,
+			updated_at = now()
+		WHERE status = $2
+			AND ordered_at < $3
+			AND deleted_at IS NULL
+		RETURNING id
+	`
+
+	return query(
+		r.querier, ctx, q,
+		int64ScanDest,
+		domain.OrderStatusCancelled, domain.OrderStatusWaitingPayment, before,
+	)
+}
+
 func (r *orderRepository) ListItemsByOrderID(ctx context.Context, id int64) ([]domain.OrderItem, error) {
 	q := selectOrderItemJoined + `
 		WHERE oi.order_id = $1
@@ -198,14 +244,26 @@ func (r *orderRepository) ListItemsByOrderID(ctx context.Context, id int64) ([]d
 
 func (r *orderRepository) AddItem(ctx context.Context, item domain.OrderItem) (domain.OrderItem, error) {
 	q := `
-		INSERT INTO order_items(order_id, product_id, price, amount)
+		INSERT INTO order_items(
+			order_id, product_id, product_variant_id,
+			base_price, discount, promotion_id, price, amount
+		)
 		VALUES
-		($1, $2, $3, $4)
+		($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING ` + orderItemColumns
 
+	var variantID, promotionID *int64
+	if item.Variant != nil {
+		variantID = &item.Variant.ID
+	}
+	if item.Promotion != nil {
+		promotionID = &item.Promotion.ID
+	}
+
 	return queryOneFull(
 		r.querier, ctx, q,
 		scanOrderItem,
-		item.OrderID, item.Product.ID, item.Price, item.Amount,
+		item.OrderID, item.Product.ID, fromInt64Ptr(variantID),
+		item.BasePrice, item.Discount, fromInt64Ptr(promotionID), item.Price, item.Amount,
 	)
 }
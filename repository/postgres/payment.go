@@ -2,7 +2,6 @@ package postgres
 
 import (
 	"context"
-	"fmt"
 	"medichat-be/apperror"
 	"medichat-be/domain"
 	"strings"
@@ -14,7 +13,21 @@ type paymentRepository struct {
 	querier Querier
 }
 
-func (r *paymentRepository) buildListQuery(sel string, dets domain.PaymentListDetails) (*strings.Builder, pgx.NamedArgs) {
+var paymentListSpec = listSpec{
+	sorts: map[string]string{
+		domain.PaymentSortByCreatedAt: "p.created_at",
+		domain.PaymentSortByAmount:    "p.amount",
+	},
+	filters: map[string]filterColumn{
+		"invoice_number": {expr: "p.invoice_number", kind: filterString},
+		"amount":         {expr: "p.amount", kind: filterInt},
+		"created_at":     {expr: "p.created_at", kind: filterTime, ops: []domain.FilterOp{domain.FilterOpRange}},
+	},
+	defaultSort: []domain.SortField{{Field: domain.PaymentSortByCreatedAt, Desc: true}},
+	tiebreak:    "p.id ASC",
+}
+
+func (r *paymentRepository) buildListQuery(sel string, dets domain.PaymentListDetails) (*strings.Builder, pgx.NamedArgs, error) {
 	var sb strings.Builder
 	args := pgx.NamedArgs{}
 
@@ -36,11 +49,19 @@ func (r *paymentRepository) buildListQuery(sel string, dets domain.PaymentListDe
 		args["userID"] = *dets.UserID
 	}
 
-	return &sb, args
+	err := paymentListSpec.writeFilters(&sb, namedArg(args), dets.Filters)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &sb, args, nil
 }
 
 func (r *paymentRepository) GetPageInfo(ctx context.Context, dets domain.PaymentListDetails) (domain.PageInfo, error) {
-	sb, args := r.buildListQuery(countPaymentJoined, dets)
+	sb, args, err := r.buildListQuery(countPaymentJoined, dets)
+	if err != nil {
+		return domain.PageInfo{}, err
+	}
 
 	count, err := queryOne(
 		r.querier, ctx, sb.String(),
@@ -60,17 +81,17 @@ func (r *paymentRepository) GetPageInfo(ctx context.Context, dets domain.Payment
 }
 
 func (r *paymentRepository) List(ctx context.Context, dets domain.PaymentListDetails) ([]domain.Payment, error) {
-	sb, args := r.buildListQuery(selectPaymentJoined, dets)
-	offset := (dets.Page - 1) * dets.Limit
+	sb, args, err := r.buildListQuery(selectPaymentJoined, dets)
+	if err != nil {
+		return nil, err
+	}
 
-	sb.WriteString(` ORDER BY p.created_at DESC, p.id ASC`)
+	err = paymentListSpec.writeOrderBy(sb, dets.Sorts)
+	if err != nil {
+		return nil, err
+	}
 
-	fmt.Fprintf(
-		sb,
-		` OFFSET %d LIMIT %d `,
-		offset,
-		dets.Limit,
-	)
+	writePage(sb, int64(dets.Page), int64(dets.Limit))
 
 	return queryFull(
 		r.querier, ctx, sb.String(),
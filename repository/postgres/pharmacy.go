@@ -13,11 +13,61 @@ type pharmacyRepository struct {
 	querier Querier
 }
 
+// Holiday dates are passed as plain dates so the session timezone can't
+// shift them.
+const pharmacyHolidayDateLayout = "2006-01-02"
+
+// pharmacyOpenCondition matches the pharmacies open at the timestamp in
+// parameter param, read in each pharmacy's own timezone. The shifts that
+// started yesterday are checked too, a shift whose end isn't after its start
+// runs past midnight. No shift starts on a holiday.
+func pharmacyOpenCondition(param int) string {
+	return fmt.Sprintf(`
+		AND EXISTS (
+			SELECT o.id
+			FROM pharmacy_operations o,
+				LATERAL (SELECT $%d::timestamptz AT TIME ZONE p.timezone AS t) l,
+				LATERAL (VALUES (l.t::date - 1), (l.t::date)) d(day)
+			WHERE o.pharmacy_id = p.id
+				AND o.deleted_at IS NULL
+				AND lower(o.day) = lower(to_char(d.day, 'FMDay'))
+				AND l.t >= d.day + o.start_time::time
+				AND l.t < d.day + o.end_time::time + CASE
+					WHEN o.end_time::time <= o.start_time::time THEN INTERVAL '1 day'
+					ELSE INTERVAL '0 days'
+				END
+				AND NOT EXISTS (
+					SELECT h.id
+					FROM pharmacy_holidays h
+					WHERE h.pharmacy_id = p.id
+						AND h.date = d.day
+						AND h.deleted_at IS NULL
+				)
+		)
+	`, param)
+}
+
+var pharmacyListSpec = listSpec{
+	sorts: map[string]string{
+		domain.PharmacySortById:        "p.id",
+		domain.PharmacySortByName:      "p.name",
+		domain.PharmacySortByManagerId: "p.manager_id",
+	},
+	filters: map[string]filterColumn{
+		"name":            {expr: "p.name", kind: filterString},
+		"slug":            {expr: "p.slug", kind: filterString},
+		"address":         {expr: "p.address", kind: filterString},
+		"manager_id":      {expr: "p.manager_id", kind: filterInt},
+		"pharmacist_name": {expr: "p.pharmacist_name", kind: filterString},
+	},
+	defaultSort: []domain.SortField{{Field: domain.PharmacySortByName}},
+	tiebreak:    "p.id ASC",
+}
+
 func (r *pharmacyRepository) GetPharmacies(ctx context.Context, query domain.PharmaciesQuery) ([]domain.Pharmacy, error) {
 	sb := strings.Builder{}
 	var args = make([]any, 0)
 	var idx = 1
-	offset := (query.Page - 1) * query.Limit
 
 	if query.Longitude != nil && query.Latitude != nil {
 		sb.WriteString(`
@@ -40,6 +90,12 @@ func (r *pharmacyRepository) GetPharmacies(ctx context.Context, query domain.Pha
 		`, idx)
 		idx++
 		args = append(args, *query.ProductId)
+
+		fmt.Fprintf(&sb, ` AND s.product_variant_id IS NOT DISTINCT FROM $%d
+		AND s.deleted_at IS NULL
+		`, idx)
+		idx++
+		args = append(args, fromInt64Ptr(query.VariantID))
 	}
 
 	if query.Name != nil && query.Term == nil {
@@ -69,20 +125,18 @@ func (r *pharmacyRepository) GetPharmacies(ctx context.Context, query domain.Pha
 		args = append(args, *query.Longitude, *query.Latitude)
 	}
 
-	if query.IsOpen != nil && *query.IsOpen {
-		time := time.Now()
-
-		fmt.Fprintf(&sb, `
-		AND p.id IN (
-		SELECT o.pharmacy_id
-		FROM pharmacy_operations o
-		WHERE o.deleted_at IS NULL
-		AND o.day = '%s'
-		AND o.start_time <= '0001-01-01 %s:00')
-		`, time.Format("Monday"), time.Format("15:04"))
+	if openAt := query.OpenAt; openAt != nil || (query.IsOpen != nil && *query.IsOpen) {
+		at := time.Now()
+		if openAt != nil {
+			at = *openAt
+		}
+
+		sb.WriteString(pharmacyOpenCondition(idx))
+		idx++
+		args = append(args, at)
 	}
 
-	if (query.Day != nil || query.StartTime != nil || query.EndTime != nil) && query.IsOpen == nil {
+	if (query.Day != nil || query.StartTime != nil || query.EndTime != nil) && query.IsOpen == nil && query.OpenAt == nil {
 		sb.WriteString(`
 			AND p.id IN (
 			SELECT o.pharmacy_id
@@ -110,19 +164,26 @@ func (r *pharmacyRepository) GetPharmacies(ctx context.Context, query domain.Pha
 		sb.WriteString(`)`)
 	}
 
+	err := pharmacyListSpec.writeFilters(&sb, positionalArg(&args), query.Filters)
+	if err != nil {
+		return nil, err
+	}
+
 	sb.WriteString(`GROUP BY p.id`)
 
-	if query.SortBy == domain.PharmacySortByName {
-		fmt.Fprintf(&sb, " ORDER BY %s %s", query.SortBy, query.SortType)
+	// Without a location there is no distance to sort by, so it falls
+	// through to the tiebreak.
+	spec := pharmacyListSpec.withSort(domain.PharmacySortByDistance, "p.id")
+	if query.Longitude != nil && query.Latitude != nil {
+		spec = pharmacyListSpec.withSort(domain.PharmacySortByDistance, "p.coordinate <-> $1")
 	}
 
-	if query.SortBy == domain.PharmacySortByDistance && query.Latitude != nil && query.Longitude != nil {
-		fmt.Fprintf(&sb, " ORDER BY p.coordinate <-> ST_MakePoint(%f, %f)::geometry", *query.Longitude, *query.Latitude)
+	err = spec.writeOrderBy(&sb, query.Sorts)
+	if err != nil {
+		return nil, err
 	}
 
-	if query.Limit != 0 {
-		fmt.Fprintf(&sb, " OFFSET %d LIMIT %d ", offset, query.Limit)
-	}
+	writePage(&sb, int64(query.Page), int64(query.Limit))
 
 	scanner := scanPharmacy
 	if query.Longitude != nil && query.Latitude != nil {
@@ -168,20 +229,18 @@ func (r *pharmacyRepository) GetPageInfo(ctx context.Context, query domain.Pharm
 		args = append(args, *query.Longitude, *query.Latitude)
 	}
 
-	if query.IsOpen != nil && *query.IsOpen {
-		time := time.Now()
-
-		fmt.Fprintf(&sb, `
-		AND p.id IN (
-		SELECT o.pharmacy_id
-		FROM pharmacy_operations o
-		WHERE o.deleted_at IS NULL
-		AND o.day = '%s'
-		AND o.start_time <= '0001-01-01 %s:00:00')
-		`, time.Format("Monday"), time.Format("15"))
+	if openAt := query.OpenAt; openAt != nil || (query.IsOpen != nil && *query.IsOpen) {
+		at := time.Now()
+		if openAt != nil {
+			at = *openAt
+		}
+
+		sb.WriteString(pharmacyOpenCondition(idx))
+		idx++
+		args = append(args, at)
 	}
 
-	if (query.Day != nil || query.StartTime != nil || query.EndTime != nil) && query.IsOpen == nil {
+	if (query.Day != nil || query.StartTime != nil || query.EndTime != nil) && query.IsOpen == nil && query.OpenAt == nil {
 		sb.WriteString(`
 			AND p.id IN (
 			SELECT o.pharmacy_id
@@ -209,9 +268,14 @@ func (r *pharmacyRepository) GetPageInfo(ctx context.Context, query domain.Pharm
 		sb.WriteString(`)`)
 	}
 
+	err := pharmacyListSpec.writeFilters(&sb, positionalArg(&args), query.Filters)
+	if err != nil {
+		return domain.PageInfo{}, err
+	}
+
 	var totalData int64
 	row := r.querier.QueryRowContext(ctx, sb.String(), args...)
-	err := row.Scan(&totalData)
+	err = row.Scan(&totalData)
 
 	if err != nil {
 		return domain.PageInfo{}, err
@@ -247,12 +311,25 @@ func (r *pharmacyRepository) GetByID(ctx context.Context, id int64) (domain.Phar
 	)
 }
 
+func (r *pharmacyRepository) GetByIDAndLock(ctx context.Context, id int64) (domain.Pharmacy, error) {
+	q := `
+		SELECT ` + pharmacyColumns + `FROM pharmacies
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanPharmacy, id,
+	)
+}
+
 func (r *pharmacyRepository) Add(ctx context.Context, pharmacy domain.PharmacyCreateDetails) (domain.Pharmacy, error) {
 	q := `
 		INSERT INTO pharmacies(name, manager_id, address, coordinate, 
-		pharmacist_name, pharmacist_license, pharmacist_phone, slug)
+		pharmacist_name, pharmacist_license, pharmacist_phone, slug, timezone)
 		VALUES
-		($1, $2, $3, $4, $5, $6, $7, $8)
+		($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING
 	` + pharmacyColumns
 
@@ -262,7 +339,7 @@ func (r *pharmacyRepository) Add(ctx context.Context, pharmacy domain.PharmacyCr
 		pharmacy.Name, pharmacy.ManagerID, pharmacy.Address,
 		postgis.NewPointFromCoordinate(pharmacy.Coordinate),
 		pharmacy.PharmacistName, pharmacy.PharmacistLicense,
-		pharmacy.PharmacistPhone, pharmacy.Slug,
+		pharmacy.PharmacistPhone, pharmacy.Slug, pharmacy.Timezone,
 	)
 }
 
@@ -275,6 +352,7 @@ func (r *pharmacyRepository) Update(ctx context.Context, pharmacy domain.Pharmac
 			pharmacist_name = $4,
 			pharmacist_license = $5,
 			pharmacist_phone = $6,
+			timezone = COALESCE(NULLIF($8, ''), timezone),
 			updated_at = now()
 		WHERE slug = $7 RETURNING
 	` + pharmacyColumns
@@ -284,7 +362,7 @@ func (r *pharmacyRepository) Update(ctx context.Context, pharmacy domain.Pharmac
 		scanPharmacy,
 		pharmacy.Name, pharmacy.Address, postgis.NewPointFromCoordinate(pharmacy.Coordinate),
 		pharmacy.PharmacistName, pharmacy.PharmacistLicense, pharmacy.PharmacistPhone,
-		pharmacy.Slug,
+		pharmacy.Slug, pharmacy.Timezone,
 	)
 }
 
@@ -377,6 +455,94 @@ func (r *pharmacyRepository) GetPharmacyOperationsByPharmacyIdAndLock(ctx contex
 	)
 }
 
+func (r *pharmacyRepository) GetHolidaysByPharmacyId(
+	ctx context.Context,
+	id int64,
+	from time.Time,
+	to *time.Time,
+) ([]domain.PharmacyHoliday, error) {
+	q := `
+		SELECT ` + pharmacyHolidayColumns + `
+		FROM pharmacy_holidays
+		WHERE pharmacy_id = $1
+			AND date >= $2::date
+			AND ($3::date IS NULL OR date <= $3::date)
+			AND deleted_at IS NULL
+		ORDER BY date ASC
+	`
+
+	var until *string
+	if to != nil {
+		d := to.Format(pharmacyHolidayDateLayout)
+		until = &d
+	}
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanPharmacyHoliday,
+		id, from.Format(pharmacyHolidayDateLayout), until,
+	)
+}
+
+func (r *pharmacyRepository) GetHolidayByID(ctx context.Context, id int64) (domain.PharmacyHoliday, error) {
+	q := `
+		SELECT ` + pharmacyHolidayColumns + `
+		FROM pharmacy_holidays
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanPharmacyHoliday, id,
+	)
+}
+
+func (r *pharmacyRepository) IsHolidayExist(ctx context.Context, pharmacyID int64, date time.Time) (bool, error) {
+	q := `
+		SELECT EXISTS(
+			SELECT id
+			FROM pharmacy_holidays
+			WHERE pharmacy_id = $1
+				AND date = $2::date
+				AND deleted_at IS NULL
+		)
+	`
+
+	return queryOne(
+		r.querier, ctx, q,
+		boolScanDest,
+		pharmacyID, date.Format(pharmacyHolidayDateLayout),
+	)
+}
+
+func (r *pharmacyRepository) AddHoliday(ctx context.Context, holiday domain.PharmacyHoliday) (domain.PharmacyHoliday, error) {
+	q := `
+		INSERT INTO pharmacy_holidays(pharmacy_id, date, reason)
+		VALUES ($1, $2::date, $3)
+		RETURNING
+	` + pharmacyHolidayColumns
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanPharmacyHoliday,
+		holiday.PharmacyID, holiday.Date.Format(pharmacyHolidayDateLayout), holiday.Reason,
+	)
+}
+
+func (r *pharmacyRepository) SoftDeleteHolidayByID(ctx context.Context, id int64) error {
+	q := `
+		UPDATE pharmacy_holidays
+		SET deleted_at = now(),
+			updated_at = now()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	return execOne(
+		r.querier, ctx, q,
+		id,
+	)
+}
+
 func (r *pharmacyRepository) GetShipmentMethodsByPharmacyId(ctx context.Context, id int64) ([]domain.PharmacyShipmentMethods, error) {
 	q := `
 		SELECT ` + PharmacyShipmentMethodColumns + `
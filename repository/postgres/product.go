@@ -3,9 +3,10 @@ package postgres
 import (
 	"context"
 	"fmt"
-	"medichat-be/apperror"
+	"medichat-be/constants"
 	"medichat-be/domain"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 )
@@ -14,96 +15,168 @@ type productRepository struct {
 	querier Querier
 }
 
-func (r *productRepository) GetProductsFromArea(ctx context.Context, query domain.ProductsQuery) ([]domain.Product, error) {
-	sb := strings.Builder{}
-	args := make([]any, 0)
-	var idx = 1
-	offset := (query.Page - 1) * query.Limit
-
-	_, err := fmt.Fprintf(&sb, ` SELECT DISTINCT p.id, p.name, p.slug, p.product_detail_id, p.category_id, p.picture, p.is_active from products p inner join
-		(select pharma.id as pharmacy_id,pharma.name, stock.product_id as product_id from
-			(SELECT id, name, address, coordinate FROM pharmacies
-			WHERE deleted_at IS null AND ST_DWithin(coordinate, ST_MakePoint($%d, $%d)::geography, 25000)) as pharma
-			inner join (select s.id,s.product_id,s.pharmacy_id from stocks s where s.deleted_at is null and stock >=0) as stock
-		on pharma.id = stock.pharmacy_id) as pp
-		on p.id = pp.product_id
-	`, idx, idx+1)
-	idx += 2
-	args = append(args, *query.Longitude, *query.Latitude)
-	if err != nil {
-		return []domain.Product{}, apperror.Wrap(err)
+var productListSpec = listSpec{
+	sorts: map[string]string{
+		domain.ProductSortById:   "p.id",
+		domain.ProductSortByName: "p.name",
+		domain.ProductSortBySlug: "p.slug",
+	},
+	filters: map[string]filterColumn{
+		"name":         {expr: "p.name", kind: filterString},
+		"slug":         {expr: "p.slug", kind: filterString},
+		"category_id":  {expr: "p.category_id", kind: filterInt},
+		"status":       {expr: "p.status", kind: filterString, ops: []domain.FilterOp{domain.FilterOpEq, domain.FilterOpIn}},
+		"manufacturer": {expr: "pd.manufacturer", kind: filterString},
+		"generic_name": {expr: "pd.generic_name", kind: filterString},
+		"publish_at":   {expr: "p.publish_at", kind: filterTime, ops: []domain.FilterOp{domain.FilterOpRange}},
+	},
+	defaultSort: []domain.SortField{{Field: domain.ProductSortById}},
+	tiebreak:    "p.id ASC",
+}
+
+func (r *productRepository) writeSearchFilter(
+	sb *strings.Builder,
+	args pgx.NamedArgs,
+	query domain.ProductsQuery,
+	withCategory bool,
+) (string, error) {
+	rankExpr := ""
+
+	if !query.IncludeUnpublished {
+		sb.WriteString(` AND ` + productLiveCondition)
+	}
+
+	if query.Status != "" {
+		sb.WriteString(` AND p.status = @status `)
+		args["status"] = query.Status
+	}
+
+	if strings.TrimSpace(query.Term) != "" {
+		args["term"] = query.Term
+		simExpr := `word_similarity(@term, ` + productSearchDocument + `)`
+
+		tsq := toSynonymTsQuery(query.Term, query.TermSynonyms)
+		if tsq != "" {
+			args["tsquery"] = tsq
+			fmt.Fprintf(
+				sb,
+				` AND (to_tsvector('simple', %s) @@ to_tsquery('simple', @tsquery) OR %s >= %v) `,
+				productSearchDocument, simExpr, constants.ProductSearchSimilarityThreshold,
+			)
+			rankExpr = fmt.Sprintf(
+				`(ts_rank(to_tsvector('simple', %s), to_tsquery('simple', @tsquery))::float8 + %s::float8)`,
+				productSearchDocument, simExpr,
+			)
+		} else {
+			fmt.Fprintf(sb, ` AND %s >= %v `, simExpr, constants.ProductSearchSimilarityThreshold)
+			rankExpr = simExpr + `::float8`
+		}
 	}
 
-	if query.Term != "" {
+	if withCategory && query.CategoryID != nil {
+		sb.WriteString(`
+			AND p.category_id IN (
+				SELECT d.id
+				FROM categories a
+					JOIN categories d ON d.path LIKE a.path || '%'
+				WHERE a.id = @categoryID AND d.deleted_at IS NULL
+			)
+		`)
+		args["categoryID"] = *query.CategoryID
+	}
+
+	err := productListSpec.writeFilters(sb, namedArg(args), query.Filters)
+	if err != nil {
+		return "", err
+	}
 
-		fmt.Fprintf(&sb, `AND c.keyword ILIKE $%d`, idx)
-		args = append(args, query.Term)
-		idx += 1
+	return rankExpr, nil
+}
 
+func (r *productRepository) writeOrderAndPage(
+	sb *strings.Builder,
+	query domain.ProductsQuery,
+	rankExpr string,
+) error {
+	// Without a search term every product ranks the same, so relevance
+	// falls through to the tiebreak.
+	if rankExpr == "" {
+		rankExpr = "p.id"
 	}
+	spec := productListSpec.withSort(domain.ProductSortByRelevance, rankExpr)
 
-	if query.CategoryID != nil {
-		fmt.Fprintf(&sb, ` AND p.category_id = $%d `, idx)
-		idx += 1
-		args = append(args, *query.CategoryID)
+	err := spec.writeOrderBy(sb, query.Sorts)
+	if err != nil {
+		return err
 	}
 
-	if query.SortBy != domain.CategorySortByParent {
-		query.SortBy = "p." + query.SortBy
-		fmt.Fprintf(&sb, " ORDER BY %v %v", query.SortBy, query.SortType)
+	writePage(sb, query.Page, query.Limit)
+	return nil
+}
+
+func (r *productRepository) writeAreaFilter(
+	sb *strings.Builder,
+	args pgx.NamedArgs,
+	query domain.ProductsQuery,
+) {
+	sb.WriteString(` AND ` + productInAreaCondition)
+	args["long"] = *query.Longitude
+	args["lat"] = *query.Latitude
+}
+
+func (r *productRepository) GetProductsFromArea(ctx context.Context, query domain.ProductsQuery) ([]domain.Product, error) {
+	sb := strings.Builder{}
+	args := pgx.NamedArgs{}
+
+	sb.WriteString(`
+		SELECT ` + productJoinedColumns + `
+		FROM products p
+			JOIN product_details pd ON p.product_detail_id = pd.id
+		WHERE p.deleted_at IS NULL
+	`)
 
+	r.writeAreaFilter(&sb, args, query)
+	rankExpr, err := r.writeSearchFilter(&sb, args, query, true)
+	if err != nil {
+		return nil, err
 	}
 
-	if query.Limit != 0 {
-		fmt.Fprintf(&sb, " OFFSET $%d LIMIT $%d ", idx, idx+1)
-		args = append(args, offset, query.Limit)
-		idx += 2
+	err = r.writeOrderAndPage(&sb, query, rankExpr)
+	if err != nil {
+		return nil, err
 	}
 
 	return queryFull(
 		r.querier, ctx, sb.String(),
 		scanProduct,
-		args...,
+		args,
 	)
 }
 
 func (r *productRepository) GetPageInfoFromArea(ctx context.Context, query domain.ProductsQuery) (domain.PageInfo, error) {
 	sb := strings.Builder{}
-	args := make([]any, 0)
-	var idx = 1
-
-	_, err := fmt.Fprintf(&sb, ` SELECT COUNT(DISTINCT p.id) as total_data from products p inner join
-		(select pharma.id as pharmacy_id,pharma.name, stock.product_id as product_id from
-			(SELECT id, name, address, coordinate FROM pharmacies
-			WHERE deleted_at IS null AND ST_DWithin(coordinate, ST_MakePoint($%d, $%d)::geography, 25000)) as pharma
-			inner join (select s.id,s.product_id,s.pharmacy_id from stocks s where s.deleted_at is null and stock >=0) as stock
-		on pharma.id = stock.pharmacy_id) as pp
-		on p.id = pp.product_id
-	`, idx, idx+1)
-	idx += 2
-	args = append(args, *query.Longitude, *query.Latitude)
-	if err != nil {
-		return domain.PageInfo{}, apperror.Wrap(err)
-	}
+	args := pgx.NamedArgs{}
 
-	if query.Term != "" {
-		fmt.Fprintf(&sb, `AND p.name ILIKE $%d`, idx)
-		args = append(args, "%"+query.Term+"%")
-		idx += 1
-	}
+	sb.WriteString(`
+		SELECT COUNT(p.id) as total_data
+		FROM products p
+			JOIN product_details pd ON p.product_detail_id = pd.id
+		WHERE p.deleted_at IS NULL
+	`)
 
-	if query.CategoryID != nil {
-		fmt.Fprintf(&sb, ` AND p.category_id = $%d `, idx)
-		idx += 1
-		args = append(args, *query.CategoryID)
+	r.writeAreaFilter(&sb, args, query)
+	_, err := r.writeSearchFilter(&sb, args, query, true)
+	if err != nil {
+		return domain.PageInfo{}, err
 	}
 
-	var totalData int64
-	row := r.querier.QueryRowContext(ctx, sb.String(), args...)
-	err = row.Scan(&totalData)
-
+	totalData, err := queryOne(
+		r.querier, ctx, sb.String(),
+		int64ScanDest,
+		args,
+	)
 	if err != nil {
-		return domain.PageInfo{}, nil
+		return domain.PageInfo{}, err
 	}
 
 	return domain.PageInfo{
@@ -112,34 +185,51 @@ func (r *productRepository) GetPageInfoFromArea(ctx context.Context, query domai
 	}, nil
 }
 
-func (r *productRepository) GetProducts(ctx context.Context, query domain.ProductsQuery) ([]domain.Product, error) {
+func (r *productRepository) GetCategoryFacetsFromArea(ctx context.Context, query domain.ProductsQuery) ([]domain.ProductCategoryFacet, error) {
 	sb := strings.Builder{}
 	args := pgx.NamedArgs{}
-	offset := (query.Page - 1) * query.Limit
 
 	sb.WriteString(`
-		SELECT ` + productColumns + `
+		SELECT c.id, c.name, c.slug, COUNT(p.id)
 		FROM products p
+			JOIN product_details pd ON p.product_detail_id = pd.id
+			JOIN categories c ON p.category_id = c.id
 		WHERE p.deleted_at IS NULL
 	`)
 
-	if query.Term != "" {
-		sb.WriteString(` AND (p.keyword ILIKE '%' || @name || '%' `)
-		sb.WriteString(` OR p.name ILIKE '%' || @name || '%') `)
-		args["name"] = query.Term
+	r.writeAreaFilter(&sb, args, query)
+	_, err := r.writeSearchFilter(&sb, args, query, false)
+	if err != nil {
+		return nil, err
 	}
+	sb.WriteString(` GROUP BY c.id ORDER BY COUNT(p.id) DESC, c.name ASC `)
 
-	if query.CategoryID != nil {
-		sb.WriteString(` AND p.category_id = @categoryID `)
-		args["categoryID"] = *query.CategoryID
-	}
+	return queryFull(
+		r.querier, ctx, sb.String(),
+		scanProductCategoryFacet,
+		args,
+	)
+}
+
+func (r *productRepository) GetProducts(ctx context.Context, query domain.ProductsQuery) ([]domain.Product, error) {
+	sb := strings.Builder{}
+	args := pgx.NamedArgs{}
 
-	if query.SortBy != domain.CategorySortByParent {
-		fmt.Fprintf(&sb, " ORDER BY %s %s", query.SortBy, query.SortType)
+	sb.WriteString(`
+		SELECT ` + productJoinedColumns + `
+		FROM products p
+			JOIN product_details pd ON p.product_detail_id = pd.id
+		WHERE p.deleted_at IS NULL
+	`)
+
+	rankExpr, err := r.writeSearchFilter(&sb, args, query, true)
+	if err != nil {
+		return nil, err
 	}
 
-	if query.Limit != 0 {
-		fmt.Fprintf(&sb, " OFFSET %d LIMIT %d ", offset, query.Limit)
+	err = r.writeOrderAndPage(&sb, query, rankExpr)
+	if err != nil {
+		return nil, err
 	}
 
 	return queryFull(
@@ -149,6 +239,31 @@ func (r *productRepository) GetProducts(ctx context.Context, query domain.Produc
 	)
 }
 
+func (r *productRepository) GetCategoryFacets(ctx context.Context, query domain.ProductsQuery) ([]domain.ProductCategoryFacet, error) {
+	sb := strings.Builder{}
+	args := pgx.NamedArgs{}
+
+	sb.WriteString(`
+		SELECT c.id, c.name, c.slug, COUNT(p.id)
+		FROM products p
+			JOIN product_details pd ON p.product_detail_id = pd.id
+			JOIN categories c ON p.category_id = c.id
+		WHERE p.deleted_at IS NULL
+	`)
+
+	_, err := r.writeSearchFilter(&sb, args, query, false)
+	if err != nil {
+		return nil, err
+	}
+	sb.WriteString(` GROUP BY c.id ORDER BY COUNT(p.id) DESC, c.name ASC `)
+
+	return queryFull(
+		r.querier, ctx, sb.String(),
+		scanProductCategoryFacet,
+		args,
+	)
+}
+
 func (r *productRepository) GetBySlug(ctx context.Context, slug string) (domain.Product, error) {
 	q := `
 		SELECT ` + productColumns + `
@@ -165,6 +280,23 @@ func (r *productRepository) GetBySlug(ctx context.Context, slug string) (domain.
 	)
 }
 
+func (r *productRepository) GetBySlugAndLock(ctx context.Context, slug string) (domain.Product, error) {
+	q := `
+		SELECT ` + productColumns + `
+		FROM products
+		WHERE slug = $1
+		AND deleted_at IS NULL
+		LIMIT 1
+		FOR UPDATE
+		`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanProduct,
+		slug,
+	)
+}
+
 func (r *productRepository) GetByName(ctx context.Context, name string) (domain.Product, error) {
 	q := `
 		SELECT ` + productColumns + `
@@ -200,28 +332,24 @@ func (r *productRepository) GetPageInfo(ctx context.Context, query domain.Produc
 	args := pgx.NamedArgs{}
 
 	sb.WriteString(`
-		SELECT COUNT(*) as total_data
-		FROM products c
-		WHERE c.deleted_at IS NULL
+		SELECT COUNT(p.id) as total_data
+		FROM products p
+			JOIN product_details pd ON p.product_detail_id = pd.id
+		WHERE p.deleted_at IS NULL
 	`)
 
-	if query.Term != "" {
-		sb.WriteString(` AND (c.keyword ILIKE '%' || @name || '%' `)
-		sb.WriteString(` OR c.name ILIKE '%' || @name || '%') `)
-		args["name"] = query.Term
-	}
-
-	if query.CategoryID != nil {
-		sb.WriteString(` AND c.category_id = @categoryID `)
-		args["categoryID"] = *query.CategoryID
+	_, err := r.writeSearchFilter(&sb, args, query, true)
+	if err != nil {
+		return domain.PageInfo{}, err
 	}
 
-	var totalData int64
-	row := r.querier.QueryRowContext(ctx, sb.String(), args)
-	err := row.Scan(&totalData)
-
+	totalData, err := queryOne(
+		r.querier, ctx, sb.String(),
+		int64ScanDest,
+		args,
+	)
 	if err != nil {
-		return domain.PageInfo{}, nil
+		return domain.PageInfo{}, err
 	}
 
 	return domain.PageInfo{
@@ -236,15 +364,16 @@ func (r *productRepository) Add(ctx context.Context, product domain.Product) (do
 		picture = *product.Picture
 	}
 	q := `
-		INSERT INTO products(name,category_id, product_detail_id, picture, slug, is_active, keyword)
+		INSERT INTO products(name,category_id, product_detail_id, picture, slug, status, publish_at, unpublish_at, keyword)
 		VALUES
-		($1, $2, $3, $4, $5, $6, $7)
+		($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING ` + productColumns
 
 	return queryOneFull(
 		r.querier, ctx, q,
 		scanProduct,
-		product.Name, product.ProductCategoryId, product.ProductDetailId, picture, product.Slug, product.IsActive, product.KeyWord,
+		product.Name, product.ProductCategoryId, product.ProductDetailId, picture, product.Slug,
+		product.Status, fromTimePtr(product.PublishAt), fromTimePtr(product.UnpublishAt), product.KeyWord,
 	)
 }
 
@@ -256,14 +385,17 @@ func (r *productRepository) Update(ctx context.Context, product domain.Product)
 			product_detail_id = $3,
 			picture = $4,
 			slug = $5,
-			is_active = $6,
-			keyword = $7
-		WHERE id = $8 RETURNING ` + productColumns
+			status = $6,
+			publish_at = $7,
+			unpublish_at = $8,
+			keyword = $9
+		WHERE id = $10 RETURNING ` + productColumns
 
 	return queryOneFull(
 		r.querier, ctx, q,
 		scanProduct,
-		product.Name, product.ProductCategoryId, product.ProductDetailId, product.Picture, product.Slug, product.IsActive, product.KeyWord, product.ID,
+		product.Name, product.ProductCategoryId, product.ProductDetailId, product.Picture, product.Slug,
+		product.Status, fromTimePtr(product.PublishAt), fromTimePtr(product.UnpublishAt), product.KeyWord, product.ID,
 	)
 }
 
@@ -302,3 +434,105 @@ func (r *productRepository) BulkSoftDeleteBySlug(ctx context.Context, slugs []st
 		params...,
 	)
 }
+
+func (r *productRepository) GetCatalog(ctx context.Context) ([]domain.ProductCatalogEntry, error) {
+	q := `
+		SELECT ` + productCatalogColumns + `
+		FROM products p
+			JOIN product_details pd ON p.product_detail_id = pd.id
+			JOIN categories c ON p.category_id = c.id
+		WHERE p.deleted_at IS NULL
+		ORDER BY p.id ASC
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanProductCatalogEntry,
+	)
+}
+
+func (r *productRepository) GetSuggestionSources(ctx context.Context) ([]domain.ProductSuggestionSource, error) {
+	q := `
+		SELECT p.name, p.slug, pd.generic_name
+		FROM products p
+			JOIN product_details pd ON p.product_detail_id = pd.id
+		WHERE p.deleted_at IS NULL
+			AND ` + productLiveCondition
+
+	return query(
+		r.querier, ctx, q,
+		productSuggestionSourceScanDests,
+	)
+}
+
+func (r *productRepository) UpdatePicture(ctx context.Context, id int64, picture *string) error {
+	q := `
+		UPDATE products
+		SET picture = $2
+		WHERE id = $1
+			AND deleted_at IS NULL
+	`
+
+	return execOne(
+		r.querier, ctx, q,
+		id, fromStringPtr(picture),
+	)
+}
+
+func (r *productRepository) BulkUpdateStatus(ctx context.Context, ids []int64, change domain.ProductStateChange) ([]domain.Product, error) {
+	sb := strings.Builder{}
+	params := make([]interface{}, len(ids)+3)
+	params[0] = change.Status
+	params[1] = fromTimePtr(change.PublishAt)
+	params[2] = fromTimePtr(change.UnpublishAt)
+	sb.WriteString(`
+		UPDATE products
+		SET status = $1,
+			publish_at = $2,
+			unpublish_at = $3
+		WHERE deleted_at IS NULL AND id IN (`)
+
+	for i := 0; i < len(ids); i++ {
+		params[i+3] = ids[i]
+		sb.WriteString(fmt.Sprintf("$%d", i+4))
+		if i != len(ids)-1 {
+			sb.WriteString(", ")
+		}
+	}
+	sb.WriteString(") RETURNING " + productColumns)
+
+	return queryFull(
+		r.querier, ctx, sb.String(),
+		scanProduct,
+		params...,
+	)
+}
+
+func (r *productRepository) GetNextPublishingChange(ctx context.Context) (*time.Time, error) {
+	q := `
+		SELECT MIN(t)
+		FROM (
+			SELECT publish_at AS t
+			FROM products
+			WHERE deleted_at IS NULL
+				AND status = 'published'
+				AND publish_at > now()
+			UNION ALL
+			SELECT unpublish_at AS t
+			FROM products
+			WHERE deleted_at IS NULL
+				AND status = 'published'
+				AND unpublish_at > now()
+		) changes
+	`
+
+	next, err := queryOne(
+		r.querier, ctx, q,
+		nullTimeScanDest,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return toTimePtr(next), nil
+}
@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+)
+
+type productImageRepository struct {
+	querier Querier
+}
+
+func (r *productImageRepository) ListByProductID(
+	ctx context.Context,
+	productID int64,
+) ([]domain.ProductImage, error) {
+	q := `
+		SELECT ` + productImageColumns + `
+		FROM product_images
+		WHERE product_id = $1
+			AND deleted_at IS NULL
+		ORDER BY position ASC, id ASC
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanProductImage,
+		productID,
+	)
+}
+
+func (r *productImageRepository) GetByID(
+	ctx context.Context,
+	id int64,
+) (domain.ProductImage, error) {
+	q := `
+		SELECT ` + productImageColumns + `
+		FROM product_images
+		WHERE id = $1
+			AND deleted_at IS NULL
+	`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanProductImage,
+		id,
+	)
+}
+
+func (r *productImageRepository) CountByProductID(
+	ctx context.Context,
+	productID int64,
+) (int, error) {
+	q := `
+		SELECT COUNT(id)
+		FROM product_images
+		WHERE product_id = $1
+			AND deleted_at IS NULL
+	`
+
+	count, err := queryOne(
+		r.querier, ctx, q,
+		int64ScanDest,
+		productID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+func (r *productImageRepository) Add(
+	ctx context.Context,
+	img domain.ProductImage,
+) (domain.ProductImage, error) {
+	q := `
+		INSERT INTO product_images(
+			product_id, url, public_id, thumbnail_url, thumbnail_public_id,
+			width, height, position, is_primary
+		)
+		VALUES
+		($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING ` + productImageColumns
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanProductImage,
+		img.ProductID, img.URL, img.PublicID, img.ThumbnailURL, img.ThumbnailPublicID,
+		img.Width, img.Height, img.Position, img.IsPrimary,
+	)
+}
+
+func (r *productImageRepository) Update(
+	ctx context.Context,
+	img domain.ProductImage,
+) (domain.ProductImage, error) {
+	q := `
+		UPDATE product_images
+		SET position = $2,
+			is_primary = $3,
+			updated_at = now()
+		WHERE id = $1
+			AND deleted_at IS NULL
+	`
+
+	err := execOne(
+		r.querier, ctx, q,
+		img.ID, img.Position, img.IsPrimary,
+	)
+	if err != nil {
+		return domain.ProductImage{}, apperror.Wrap(err)
+	}
+
+	return img, nil
+}
+
+func (r *productImageRepository) SoftDeleteByID(
+	ctx context.Context,
+	id int64,
+) error {
+	q := `
+		UPDATE product_images
+		SET deleted_at = now()
+		WHERE id = $1
+			AND deleted_at IS NULL
+	`
+
+	return execOne(
+		r.querier, ctx, q,
+		id,
+	)
+}
@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"medichat-be/domain"
+	"strings"
+)
+
+type productSynonymRepository struct {
+	querier Querier
+}
+
+func (r *productSynonymRepository) List(
+	ctx context.Context,
+) ([]domain.ProductSynonym, error) {
+	q := `
+		SELECT ` + productSynonymColumns + `
+		FROM product_synonyms
+		WHERE deleted_at IS NULL
+		ORDER BY term ASC, synonym ASC
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanProductSynonym,
+	)
+}
+
+func (r *productSynonymRepository) GetByWords(
+	ctx context.Context,
+	words []string,
+) ([]domain.ProductSynonym, error) {
+	if len(words) == 0 {
+		return []domain.ProductSynonym{}, nil
+	}
+
+	var sb strings.Builder
+	args := make([]any, len(words))
+	placeholders := make([]string, len(words))
+	for i, w := range words {
+		args[i] = w
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	in := strings.Join(placeholders, ", ")
+
+	fmt.Fprintf(&sb, `
+		SELECT `+productSynonymColumns+`
+		FROM product_synonyms
+		WHERE deleted_at IS NULL
+			AND (term IN (%s) OR synonym IN (%s))
+	`, in, in)
+
+	return queryFull(
+		r.querier, ctx, sb.String(),
+		scanProductSynonym,
+		args...,
+	)
+}
+
+func (r *productSynonymRepository) IsExist(
+	ctx context.Context,
+	term string,
+	synonym string,
+) (bool, error) {
+	q := `
+		SELECT EXISTS (
+			SELECT id
+			FROM product_synonyms
+			WHERE ((term = $1 AND synonym = $2) OR (term = $2 AND synonym = $1))
+				AND deleted_at IS NULL
+		)
+	`
+
+	return queryOne(
+		r.querier, ctx, q,
+		boolScanDest,
+		term, synonym,
+	)
+}
+
+func (r *productSynonymRepository) Add(
+	ctx context.Context,
+	s domain.ProductSynonym,
+) (domain.ProductSynonym, error) {
+	q := `
+		INSERT INTO product_synonyms(term, synonym)
+		VALUES
+		($1, $2)
+		RETURNING ` + productSynonymColumns
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanProductSynonym,
+		s.Term, s.Synonym,
+	)
+}
+
+func (r *productSynonymRepository) SoftDeleteByID(
+	ctx context.Context,
+	id int64,
+) error {
+	q := `
+		UPDATE product_synonyms
+		SET deleted_at = now()
+		WHERE id = $1
+			AND deleted_at IS NULL
+	`
+
+	return execOne(
+		r.querier, ctx, q,
+		id,
+	)
+}
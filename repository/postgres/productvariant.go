@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+)
+
+type productVariantRepository struct {
+	querier Querier
+}
+
+func (r *productVariantRepository) ListByProductID(
+	ctx context.Context,
+	productID int64,
+) ([]domain.ProductVariant, error) {
+	q := `
+		SELECT ` + productVariantColumns + `
+		FROM product_variants
+		WHERE product_id = $1
+			AND deleted_at IS NULL
+		ORDER BY pack_size ASC, name ASC, id ASC
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanProductVariant,
+		productID,
+	)
+}
+
+func (r *productVariantRepository) GetByID(
+	ctx context.Context,
+	id int64,
+) (domain.ProductVariant, error) {
+	q := `
+		SELECT ` + productVariantColumns + `
+		FROM product_variants
+		WHERE id = $1
+			AND deleted_at IS NULL
+	`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanProductVariant,
+		id,
+	)
+}
+
+func (r *productVariantRepository) IsExistByProductIDAndName(
+	ctx context.Context,
+	productID int64,
+	name string,
+) (bool, error) {
+	q := `
+		SELECT EXISTS (
+			SELECT id
+			FROM product_variants
+			WHERE product_id = $1
+				AND lower(name) = lower($2)
+				AND deleted_at IS NULL
+		)
+	`
+
+	return queryOne(
+		r.querier, ctx, q,
+		boolScanDest,
+		productID, name,
+	)
+}
+
+func (r *productVariantRepository) Add(
+	ctx context.Context,
+	v domain.ProductVariant,
+) (domain.ProductVariant, error) {
+	q := `
+		INSERT INTO product_variants(
+			product_id, name, strength, pack_size, selling_unit, form
+		)
+		VALUES
+		($1, $2, $3, $4, $5, $6)
+		RETURNING ` + productVariantColumns
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanProductVariant,
+		v.ProductID, v.Name, v.Strength, v.PackSize, v.SellingUnit, v.Form,
+	)
+}
+
+func (r *productVariantRepository) Update(
+	ctx context.Context,
+	v domain.ProductVariant,
+) (domain.ProductVariant, error) {
+	q := `
+		UPDATE product_variants
+		SET name = $2,
+			strength = $3,
+			pack_size = $4,
+			selling_unit = $5,
+			form = $6,
+			updated_at = now()
+		WHERE id = $1
+			AND deleted_at IS NULL
+	`
+
+	err := execOne(
+		r.querier, ctx, q,
+		v.ID, v.Name, v.Strength, v.PackSize, v.SellingUnit, v.Form,
+	)
+	if err != nil {
+		return domain.ProductVariant{}, apperror.Wrap(err)
+	}
+
+	return v, nil
+}
+
+func (r *productVariantRepository) SoftDeleteByID(
+	ctx context.Context,
+	id int64,
+) error {
+	q := `
+		UPDATE product_variants
+		SET deleted_at = now(),
+			updated_at = now()
+		WHERE id = $1
+			AND deleted_at IS NULL
+	`
+
+	return execOne(
+		r.querier, ctx, q,
+		id,
+	)
+}
@@ -0,0 +1,270 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type promotionRepository struct {
+	querier Querier
+}
+
+func (r *promotionRepository) buildListQuery(sel string, det domain.PromotionListDetails) (*strings.Builder, pgx.NamedArgs) {
+	sb := strings.Builder{}
+	args := pgx.NamedArgs{}
+
+	sb.WriteString(sel)
+	sb.WriteString(`
+		WHERE deleted_at IS NULL
+	`)
+
+	if det.Scope != nil {
+		sb.WriteString(`
+			AND scope = @scope
+		`)
+		args["scope"] = *det.Scope
+	}
+	if det.ActiveOnly {
+		sb.WriteString(`
+			AND starts_at <= now()
+			AND (ends_at IS NULL OR ends_at > now())
+			AND (usage_limit IS NULL OR usage_count < usage_limit)
+		`)
+	}
+
+	return &sb, args
+}
+
+func (r *promotionRepository) GetPageInfo(ctx context.Context, det domain.PromotionListDetails) (domain.PageInfo, error) {
+	sb, args := r.buildListQuery(`
+		SELECT COUNT(id)
+		FROM promotions
+	`, det)
+
+	count, err := queryOne(
+		r.querier, ctx, sb.String(),
+		int64ScanDest,
+		args,
+	)
+	if err != nil {
+		return domain.PageInfo{}, apperror.Wrap(err)
+	}
+
+	return domain.PageInfo{
+		CurrentPage:  det.Page,
+		ItemsPerPage: det.Limit,
+		ItemCount:    count,
+		PageCount:    int((count - 1 + int64(det.Limit)) / int64(det.Limit)),
+	}, nil
+}
+
+func (r *promotionRepository) List(ctx context.Context, det domain.PromotionListDetails) ([]domain.Promotion, error) {
+	sb, args := r.buildListQuery(`
+		SELECT `+promotionColumns+`
+		FROM promotions
+	`, det)
+
+	sb.WriteString(`
+		ORDER BY starts_at DESC, id DESC
+	`)
+	writePage(sb, int64(det.Page), int64(det.Limit))
+
+	return queryFull(
+		r.querier, ctx, sb.String(),
+		scanPromotion,
+		args,
+	)
+}
+
+func (r *promotionRepository) GetByID(ctx context.Context, id int64) (domain.Promotion, error) {
+	q := `
+		SELECT ` + promotionColumns + `
+		FROM promotions
+		WHERE id = $1
+			AND deleted_at IS NULL
+	`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanPromotion,
+		id,
+	)
+}
+
+func (r *promotionRepository) GetByIDAndLock(ctx context.Context, id int64) (domain.Promotion, error) {
+	q := `
+		SELECT ` + promotionColumns + `
+		FROM promotions
+		WHERE id = $1
+			AND deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanPromotion,
+		id,
+	)
+}
+
+// ListApplicable returns the promotions running at at whose scope covers
+// the target. A category promotion covers every category under it.
+func (r *promotionRepository) ListApplicable(ctx context.Context, t domain.PromotionTarget, at time.Time) ([]domain.Promotion, error) {
+	q := `
+		SELECT ` + promotionColumns + `
+		FROM promotions pr
+		WHERE pr.deleted_at IS NULL
+			AND pr.starts_at <= $4
+			AND (pr.ends_at IS NULL OR pr.ends_at > $4)
+			AND (pr.usage_limit IS NULL OR pr.usage_count < pr.usage_limit)
+			AND (
+				(pr.scope = $5 AND pr.product_id = $1)
+				OR (pr.scope = $7 AND pr.pharmacy_id = $3)
+				OR (pr.scope = $6 AND EXISTS(
+					SELECT pc.id
+					FROM categories pc
+						JOIN categories c ON c.path LIKE pc.path || '%'
+					WHERE pc.id = pr.category_id
+						AND c.id = $2
+						AND pc.deleted_at IS NULL
+				))
+			)
+		ORDER BY pr.id
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanPromotion,
+		t.ProductID, t.CategoryID, t.PharmacyID, at,
+		domain.PromotionScopeProduct, domain.PromotionScopeCategory, domain.PromotionScopePharmacy,
+	)
+}
+
+func (r *promotionRepository) Add(ctx context.Context, p domain.Promotion) (domain.Promotion, error) {
+	q := `
+		INSERT INTO promotions (
+			name, discount_type, discount_value,
+			scope, product_id, category_id, pharmacy_id,
+			starts_at, ends_at, usage_limit
+		)
+		VALUES
+		($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING ` + promotionColumns
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanPromotion,
+		p.Name, p.DiscountType, p.DiscountValue,
+		p.Scope, fromInt64Ptr(p.ProductID), fromInt64Ptr(p.CategoryID), fromInt64Ptr(p.PharmacyID),
+		p.StartsAt, fromTimePtr(p.EndsAt), fromIntPtr(p.UsageLimit),
+	)
+}
+
+func (r *promotionRepository) Update(ctx context.Context, p domain.Promotion) (domain.Promotion, error) {
+	q := `
+		UPDATE promotions
+		SET name = $2,
+			discount_type = $3,
+			discount_value = $4,
+			scope = $5,
+			product_id = $6,
+			category_id = $7,
+			pharmacy_id = $8,
+			starts_at = $9,
+			ends_at = $10,
+			usage_limit = $11,
+			updated_at = now()
+		WHERE id = $1
+			AND deleted_at IS NULL
+		RETURNING ` + promotionColumns
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanPromotion,
+		p.ID, p.Name, p.DiscountType, p.DiscountValue,
+		p.Scope, fromInt64Ptr(p.ProductID), fromInt64Ptr(p.CategoryID), fromInt64Ptr(p.PharmacyID),
+		p.StartsAt, fromTimePtr(p.EndsAt), fromIntPtr(p.UsageLimit),
+	)
+}
+
+// IncrementUsage counts one more use of the promotion. It fails with not
+// found once the usage limit is reached, so concurrent orders can't go past
+// it.
+func (r *promotionRepository) IncrementUsage(ctx context.Context, id int64) error {
+	q := `
+		UPDATE promotions
+		SET usage_count = usage_count + 1,
+			updated_at = now()
+		WHERE id = $1
+			AND deleted_at IS NULL
+			AND (usage_limit IS NULL OR usage_count < usage_limit)
+		RETURNING id
+	`
+
+	_, err := queryOne(
+		r.querier, ctx, q,
+		int64ScanDest,
+		id,
+	)
+	return err
+}
+
+// ReleaseUsageByOrderIDs gives back the uses the items of the orders took.
+func (r *promotionRepository) ReleaseUsageByOrderIDs(ctx context.Context, orderIDs []int64) error {
+	if len(orderIDs) == 0 {
+		return nil
+	}
+
+	sb := strings.Builder{}
+	args := make([]any, 0, len(orderIDs))
+
+	sb.WriteString(`
+		UPDATE promotions pr
+		SET usage_count = GREATEST(pr.usage_count - u.n, 0),
+			updated_at = now()
+		FROM (
+			SELECT promotion_id, COUNT(id) AS n
+			FROM order_items
+			WHERE promotion_id IS NOT NULL
+				AND deleted_at IS NULL
+				AND order_id IN (`)
+
+	for i, id := range orderIDs {
+		args = append(args, id)
+		fmt.Fprintf(&sb, "$%d", len(args))
+		if i != len(orderIDs)-1 {
+			sb.WriteString(", ")
+		}
+	}
+	sb.WriteString(`)
+			GROUP BY promotion_id
+		) u
+		WHERE pr.id = u.promotion_id
+	`)
+
+	return exec(
+		r.querier, ctx, sb.String(),
+		args...,
+	)
+}
+
+func (r *promotionRepository) SoftDeleteByID(ctx context.Context, id int64) error {
+	q := `
+		UPDATE promotions
+		SET deleted_at = now(),
+			updated_at = now()
+		WHERE id = $1
+			AND deleted_at IS NULL
+	`
+
+	return execOne(
+		r.querier, ctx, q,
+		id,
+	)
+}
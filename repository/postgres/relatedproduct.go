@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"medichat-be/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type relatedProductRepository struct {
+	querier Querier
+}
+
+var relatedProductFilter = `
+	p.deleted_at IS NULL
+	AND p.id <> @productID
+	AND ` + productLiveCondition + `
+	AND ` + productInAreaCondition
+
+func relatedProductArgs(product domain.Product, query domain.RelatedProductsQuery) pgx.NamedArgs {
+	return pgx.NamedArgs{
+		"productID": product.ID,
+		"long":      query.Longitude,
+		"lat":       query.Latitude,
+		"limit":     query.Limit,
+	}
+}
+
+func (r *relatedProductRepository) ReplaceAssociations(ctx context.Context, minSupport int64) (int64, error) {
+	err := exec(
+		r.querier, ctx,
+		`DELETE FROM product_associations`,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	q := `
+		WITH baskets AS (
+			SELECT DISTINCT oi.order_id, oi.product_id
+			FROM order_items oi
+				JOIN orders o ON oi.order_id = o.id
+			WHERE o.deleted_at IS NULL
+				AND oi.deleted_at IS NULL
+				AND o.status = @finished
+		), baskets_per_product AS (
+			SELECT product_id, COUNT(*) AS n_orders
+			FROM baskets
+			GROUP BY product_id
+		), pairs AS (
+			SELECT a.product_id, b.product_id AS related_product_id, COUNT(*) AS support
+			FROM baskets a
+				JOIN baskets b ON a.order_id = b.order_id AND a.product_id <> b.product_id
+			GROUP BY a.product_id, b.product_id
+		), inserted AS (
+			INSERT INTO product_associations(product_id, related_product_id, support, score)
+			SELECT pr.product_id, pr.related_product_id, pr.support,
+				pr.support::float8 / bp.n_orders
+			FROM pairs pr
+				JOIN baskets_per_product bp ON pr.product_id = bp.product_id
+			WHERE pr.support >= @minSupport
+			RETURNING product_id
+		)
+		SELECT COUNT(*) FROM inserted
+	`
+
+	return queryOne(
+		r.querier, ctx, q,
+		int64ScanDest,
+		pgx.NamedArgs{
+			"finished":   domain.OrderStatusFinished,
+			"minSupport": minSupport,
+		},
+	)
+}
+
+func (r *relatedProductRepository) GetFrequentlyBoughtTogether(ctx context.Context, product domain.Product, query domain.RelatedProductsQuery) ([]domain.Product, error) {
+	q := `
+		SELECT ` + productJoinedColumns + `
+		FROM product_associations pa
+			JOIN products p ON pa.related_product_id = p.id
+		WHERE pa.product_id = @productID
+			AND ` + relatedProductFilter + `
+		ORDER BY pa.score DESC, pa.support DESC, p.id ASC
+		LIMIT @limit
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanProduct,
+		relatedProductArgs(product, query),
+	)
+}
+
+func (r *relatedProductRepository) GetAlternatives(ctx context.Context, product domain.Product, genericName string, query domain.RelatedProductsQuery) ([]domain.Product, error) {
+	q := `
+		SELECT ` + productJoinedColumns + `
+		FROM products p
+			JOIN product_details pd ON p.product_detail_id = pd.id
+		WHERE lower(trim(pd.generic_name)) = lower(trim(@genericName))
+			AND ` + relatedProductFilter + `
+		ORDER BY p.name ASC, p.id ASC
+		LIMIT @limit
+	`
+
+	args := relatedProductArgs(product, query)
+	args["genericName"] = genericName
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanProduct,
+		args,
+	)
+}
+
+func (r *relatedProductRepository) GetCategoryBestSellers(ctx context.Context, product domain.Product, query domain.RelatedProductsQuery) ([]domain.Product, error) {
+	q := `
+		SELECT ` + productJoinedColumns + `
+		FROM products p
+			LEFT JOIN (
+				SELECT oi.product_id, SUM(oi.amount) AS sold
+				FROM order_items oi
+					JOIN orders o ON oi.order_id = o.id
+				WHERE o.deleted_at IS NULL
+					AND oi.deleted_at IS NULL
+					AND o.status = @finished
+				GROUP BY oi.product_id
+			) sales ON sales.product_id = p.id
+		WHERE p.category_id = @categoryID
+			AND ` + relatedProductFilter + `
+		ORDER BY COALESCE(sales.sold, 0) DESC, p.id ASC
+		LIMIT @limit
+	`
+
+	args := relatedProductArgs(product, query)
+	args["categoryID"] = product.ProductCategoryId
+	args["finished"] = domain.OrderStatusFinished
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanProduct,
+		args,
+	)
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"medichat-be/apperror"
 	"medichat-be/domain"
 )
 
@@ -25,6 +26,27 @@ func (r *specializationRepository) GetAll(
 	)
 }
 
+func (r *specializationRepository) GetAllWithDoctorCount(
+	ctx context.Context,
+) ([]domain.SpecializationWithDoctorCount, error) {
+	q := `
+		SELECT ` + specializationJoinedColumns + `, COUNT(d.id)
+		FROM specializations s
+			LEFT JOIN doctors d ON d.specialization_id = s.id
+				AND d.verification_status = $1
+				AND d.deleted_at IS NULL
+		WHERE s.deleted_at IS NULL
+		GROUP BY s.id
+		ORDER BY s.id ASC
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanSpecializationWithDoctorCount,
+		domain.DoctorVerificationApproved,
+	)
+}
+
 func (r *specializationRepository) GetByID(
 	ctx context.Context,
 	id int64,
@@ -42,3 +64,121 @@ func (r *specializationRepository) GetByID(
 		id,
 	)
 }
+
+func (r *specializationRepository) GetByIDAndLock(
+	ctx context.Context,
+	id int64,
+) (domain.Specialization, error) {
+	q := `
+		SELECT ` + specializationColumns + `
+		FROM specializations
+		WHERE id = $1
+			AND deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanSpecialization,
+		id,
+	)
+}
+
+func (r *specializationRepository) GetBySlug(
+	ctx context.Context,
+	slug string,
+) (domain.Specialization, error) {
+	q := `
+		SELECT ` + specializationColumns + `
+		FROM specializations
+		WHERE slug = $1
+			AND deleted_at IS NULL
+	`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanSpecialization,
+		slug,
+	)
+}
+
+func (r *specializationRepository) IsExistBySlug(
+	ctx context.Context,
+	slug string,
+) (bool, error) {
+	q := `
+		SELECT EXISTS (
+			SELECT id
+			FROM specializations
+			WHERE slug = $1
+				AND deleted_at IS NULL
+		)
+	`
+
+	return queryOne(
+		r.querier, ctx, q,
+		boolScanDest,
+		slug,
+	)
+}
+
+func (r *specializationRepository) Add(
+	ctx context.Context,
+	s domain.Specialization,
+) (domain.Specialization, error) {
+	q := `
+		INSERT INTO specializations(name, slug, description, icon_url, commission_rate)
+		VALUES
+		($1, $2, $3, $4, $5)
+		RETURNING ` + specializationColumns
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanSpecialization,
+		s.Name, s.Slug, s.Description, fromStringPtr(s.IconURL), s.CommissionRate,
+	)
+}
+
+func (r *specializationRepository) Update(
+	ctx context.Context,
+	s domain.Specialization,
+) (domain.Specialization, error) {
+	q := `
+		UPDATE specializations
+		SET name = $2,
+			slug = $3,
+			description = $4,
+			icon_url = $5,
+			commission_rate = $6,
+			updated_at = now()
+		WHERE id = $1
+			AND deleted_at IS NULL
+	`
+
+	err := execOne(
+		r.querier, ctx, q,
+		s.ID, s.Name, s.Slug, s.Description, fromStringPtr(s.IconURL), s.CommissionRate,
+	)
+	if err != nil {
+		return domain.Specialization{}, apperror.Wrap(err)
+	}
+
+	return s, nil
+}
+
+func (r *specializationRepository) SoftDeleteByID(
+	ctx context.Context,
+	id int64,
+) error {
+	q := `
+		UPDATE specializations
+		SET deleted_at = now()
+		WHERE id = $1
+			AND deleted_at IS NULL
+	`
+
+	return execOne(
+		r.querier, ctx, q,
+		id,
+	)
+}
@@ -6,6 +6,7 @@ import (
 	"medichat-be/apperror"
 	"medichat-be/domain"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 )
@@ -14,6 +15,51 @@ type stockRepository struct {
 	querier Querier
 }
 
+var stockListSpec = listSpec{
+	sorts: map[string]string{
+		domain.StockSortByProductName:  "pd.name",
+		domain.StockSortByPharmacyName: "ph.name",
+		domain.StockSortByPrice:        "st.price",
+		domain.StockSortByAmount:       "st.stock",
+		domain.StockSortByAvailable:    "(st.stock - " + stockReservedExpr + " - " + stockExpiredExpr + ")",
+		domain.StockSortByMinStock:     "st.min_stock",
+	},
+	filters: map[string]filterColumn{
+		"product_name":  {expr: "pd.name", kind: filterString},
+		"product_slug":  {expr: "pd.slug", kind: filterString},
+		"pharmacy_name": {expr: "ph.name", kind: filterString},
+		"pharmacy_slug": {expr: "ph.slug", kind: filterString},
+		"price":         {expr: "st.price", kind: filterInt},
+		"amount":        {expr: "st.stock", kind: filterInt},
+		"available":     {expr: "(st.stock - " + stockReservedExpr + " - " + stockExpiredExpr + ")", kind: filterInt},
+		"min_stock":     {expr: "st.min_stock", kind: filterInt},
+		"reorder_level": {expr: "st.reorder_level", kind: filterInt},
+	},
+	defaultSort: []domain.SortField{{Field: domain.StockSortByProductName}},
+	tiebreak:    "st.id ASC",
+}
+
+var stockMutationListSpec = listSpec{
+	sorts: map[string]string{
+		domain.StockSortByCreatedAt:          "sm.created_at",
+		domain.StockSortByProductName:        "pd.name",
+		domain.StockSortBySourcePharmacyName: "ph1.name",
+		domain.StockSortByTargetPharmacyName: "ph2.name",
+		domain.StockSortByAmount:             "sm.amount",
+	},
+	filters: map[string]filterColumn{
+		"product_name":         {expr: "pd.name", kind: filterString},
+		"source_pharmacy_slug": {expr: "ph1.slug", kind: filterString},
+		"target_pharmacy_slug": {expr: "ph2.slug", kind: filterString},
+		"method":               {expr: "sm.method", kind: filterString, ops: []domain.FilterOp{domain.FilterOpEq, domain.FilterOpIn}},
+		"status":               {expr: "sm.status", kind: filterString, ops: []domain.FilterOp{domain.FilterOpEq, domain.FilterOpIn}},
+		"amount":               {expr: "sm.amount", kind: filterInt},
+		"created_at":           {expr: "sm.created_at", kind: filterTime, ops: []domain.FilterOp{domain.FilterOpRange}},
+	},
+	defaultSort: []domain.SortField{{Field: domain.StockSortByCreatedAt, Desc: true}},
+	tiebreak:    "sm.id ASC",
+}
+
 func (r *stockRepository) GetByID(ctx context.Context, id int64) (domain.Stock, error) {
 	q := `
 		SELECT ` + stockColumns + `
@@ -29,19 +75,55 @@ func (r *stockRepository) GetByID(ctx context.Context, id int64) (domain.Stock,
 	)
 }
 
-func (r *stockRepository) GetByPharmacyAndProduct(ctx context.Context, pharmacy_id int64, product_id int64) (domain.Stock, error) {
+func (r *stockRepository) GetByPharmacyAndProduct(ctx context.Context, pharmacy_id int64, product_id int64, variant_id *int64) (domain.Stock, error) {
 	q := `
 		SELECT ` + stockColumns + `
 		FROM stocks
 		WHERE pharmacy_id = $1
 			AND product_id = $2
+			AND product_variant_id IS NOT DISTINCT FROM $3
 			AND deleted_at IS NULL
 	`
 
 	return queryOneFull(
 		r.querier, ctx, q,
 		scanStock,
-		pharmacy_id, product_id,
+		pharmacy_id, product_id, fromInt64Ptr(variant_id),
+	)
+}
+
+func (r *stockRepository) IsExistByVariantID(ctx context.Context, variantID int64) (bool, error) {
+	q := `
+		SELECT EXISTS (
+			SELECT id
+			FROM stocks
+			WHERE product_variant_id = $1
+				AND deleted_at IS NULL
+		)
+	`
+
+	return queryOne(
+		r.querier, ctx, q,
+		boolScanDest,
+		variantID,
+	)
+}
+
+func (r *stockRepository) IsExistWithoutVariantByProductID(ctx context.Context, productID int64) (bool, error) {
+	q := `
+		SELECT EXISTS (
+			SELECT id
+			FROM stocks
+			WHERE product_id = $1
+				AND product_variant_id IS NULL
+				AND deleted_at IS NULL
+		)
+	`
+
+	return queryOne(
+		r.querier, ctx, q,
+		boolScanDest,
+		productID,
 	)
 }
 
@@ -61,7 +143,7 @@ func (r *stockRepository) GetByIDAndLock(ctx context.Context, id int64) (domain.
 	)
 }
 
-func (r *stockRepository) buildListQuery(sel string, det domain.StockListDetails) (*strings.Builder, pgx.NamedArgs) {
+func (r *stockRepository) buildListQuery(sel string, det domain.StockListDetails) (*strings.Builder, pgx.NamedArgs, error) {
 	var sb strings.Builder
 	args := pgx.NamedArgs{}
 
@@ -82,11 +164,17 @@ func (r *stockRepository) buildListQuery(sel string, det domain.StockListDetails
 		`)
 		args["productSlug"] = *det.ProductSlug
 	}
+	if det.VariantID != nil {
+		sb.WriteString(`
+			AND st.product_variant_id = @variantID
+		`)
+		args["variantID"] = *det.VariantID
+	}
 	if det.ProductName != nil {
 		sb.WriteString(`
-			AND pd.name ILIKE '%' || @productName || '%'
+			AND pd.name ILIKE '%' || @productName || '%' ESCAPE '\'
 		`)
-		args["productName"] = *det.ProductName
+		args["productName"] = escapeLike(*det.ProductName)
 	}
 	if det.ManagerID != nil {
 		sb.WriteString(`
@@ -94,12 +182,25 @@ func (r *stockRepository) buildListQuery(sel string, det domain.StockListDetails
 		`)
 		args["managerID"] = *det.ManagerID
 	}
+	if det.LowOnly {
+		sb.WriteString(`
+			AND st.stock - ` + stockReservedExpr + ` - ` + stockExpiredExpr + ` < st.min_stock
+		`)
+	}
+
+	err := stockListSpec.writeFilters(&sb, namedArg(args), det.Filters)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	return &sb, args
+	return &sb, args, nil
 }
 
 func (r *stockRepository) GetPageInfo(ctx context.Context, det domain.StockListDetails) (domain.PageInfo, error) {
-	sb, args := r.buildListQuery(countStockJoined, det)
+	sb, args, err := r.buildListQuery(countStockJoined, det)
+	if err != nil {
+		return domain.PageInfo{}, err
+	}
 
 	return getPageInfo(
 		r.querier, ctx, sb.String(),
@@ -109,36 +210,17 @@ func (r *stockRepository) GetPageInfo(ctx context.Context, det domain.StockListD
 }
 
 func (r *stockRepository) List(ctx context.Context, det domain.StockListDetails) ([]domain.StockJoined, error) {
-	sb, args := r.buildListQuery(selectStockJoined, det)
-	offset := (det.Page - 1) * det.Limit
-
-	sortCol := "pd.name"
-
-	switch det.SortBy {
-	case domain.StockSortByProductName:
-		sortCol = "pd.name"
-	case domain.StockSortByPharmacyName:
-		sortCol = "ph.name"
-	case domain.StockSortByPrice:
-		sortCol = "st.price"
-	case domain.StockSortByAmount:
-		sortCol = "st.stock"
+	sb, args, err := r.buildListQuery(selectStockJoined, det)
+	if err != nil {
+		return nil, err
 	}
 
-	fmt.Fprintf(
-		sb,
-		` ORDER BY %s %s, st.id %s `,
-		sortCol,
-		getSortOrder(det.SortAsc),
-		getSortOrder(det.SortAsc),
-	)
+	err = stockListSpec.writeOrderBy(sb, det.Sorts)
+	if err != nil {
+		return nil, err
+	}
 
-	fmt.Fprintf(
-		sb,
-		` OFFSET %d LIMIT %d `,
-		offset,
-		det.Limit,
-	)
+	writePage(sb, int64(det.Page), int64(det.Limit))
 
 	return queryFull(
 		r.querier, ctx, sb.String(),
@@ -149,15 +231,15 @@ func (r *stockRepository) List(ctx context.Context, det domain.StockListDetails)
 
 func (r *stockRepository) Add(ctx context.Context, s domain.Stock) (domain.Stock, error) {
 	q := `
-		INSERT INTO stocks (product_id, pharmacy_id, stock, price)
+		INSERT INTO stocks (product_id, product_variant_id, pharmacy_id, stock, price, min_stock, reorder_level)
 		VALUES
-		($1, $2, $3, $4)
+		($1, $2, $3, $4, $5, $6, $7)
 		RETURNING ` + stockColumns
 
 	return queryOneFull(
 		r.querier, ctx, q,
 		scanStock,
-		s.ProductID, s.PharmacyID, s.Stock, s.Price,
+		s.ProductID, fromInt64Ptr(s.VariantID), s.PharmacyID, s.Stock, s.Price, s.MinStock, s.ReorderLevel,
 	)
 }
 
@@ -166,13 +248,15 @@ func (r *stockRepository) Update(ctx context.Context, s domain.Stock) (domain.St
 		UPDATE stocks
 		SET stock = $2,
 			price = $3,
+			min_stock = $4,
+			reorder_level = $5,
 			updated_at = now()
 		WHERE id = $1
 	`
 
 	err := execOne(
 		r.querier, ctx, q,
-		s.ID, s.Stock, s.Price,
+		s.ID, s.Stock, s.Price, s.MinStock, s.ReorderLevel,
 	)
 
 	if err != nil {
@@ -227,7 +311,25 @@ func (r *stockRepository) GetMutationByIDAndLock(ctx context.Context, id int64)
 	)
 }
 
-func (r *stockRepository) buildListMutationQuery(sel string, det domain.StockMutationListDetails) (*strings.Builder, pgx.NamedArgs) {
+func (r *stockRepository) IsPendingMutationExistByTargetID(ctx context.Context, targetID int64) (bool, error) {
+	q := `
+		SELECT EXISTS(
+			SELECT id
+			FROM stock_mutations
+			WHERE target_id = $1
+				AND status = $2
+				AND deleted_at IS NULL
+		)
+	`
+
+	return queryOne(
+		r.querier, ctx, q,
+		boolScanDest,
+		targetID, domain.StockMutationStatusPending,
+	)
+}
+
+func (r *stockRepository) buildListMutationQuery(sel string, det domain.StockMutationListDetails) (*strings.Builder, pgx.NamedArgs, error) {
 	var sb strings.Builder
 	args := pgx.NamedArgs{}
 
@@ -256,9 +358,9 @@ func (r *stockRepository) buildListMutationQuery(sel string, det domain.StockMut
 	}
 	if det.ProductName != nil {
 		sb.WriteString(`
-			AND pd.name ILIKE '%' || @productName || '%'
+			AND pd.name ILIKE '%' || @productName || '%' ESCAPE '\'
 		`)
-		args["productName"] = *det.ProductName
+		args["productName"] = escapeLike(*det.ProductName)
 	}
 	if det.Method != nil {
 		sb.WriteString(`
@@ -279,11 +381,19 @@ func (r *stockRepository) buildListMutationQuery(sel string, det domain.StockMut
 		args["managerID"] = *det.ManagerID
 	}
 
-	return &sb, args
+	err := stockMutationListSpec.writeFilters(&sb, namedArg(args), det.Filters)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &sb, args, nil
 }
 
 func (r *stockRepository) GetMutationPageInfo(ctx context.Context, det domain.StockMutationListDetails) (domain.PageInfo, error) {
-	sb, args := r.buildListMutationQuery(countStockMutationJoined, det)
+	sb, args, err := r.buildListMutationQuery(countStockMutationJoined, det)
+	if err != nil {
+		return domain.PageInfo{}, err
+	}
 
 	return getPageInfo(
 		r.querier, ctx, sb.String(),
@@ -293,36 +403,17 @@ func (r *stockRepository) GetMutationPageInfo(ctx context.Context, det domain.St
 }
 
 func (r *stockRepository) ListMutations(ctx context.Context, det domain.StockMutationListDetails) ([]domain.StockMutationJoined, error) {
-	sb, args := r.buildListMutationQuery(selectStockMutationJoined, det)
-	offset := (det.Page - 1) * det.Limit
-
-	sortCol := "sm.created_at"
-
-	switch det.SortBy {
-	case domain.StockSortByProductName:
-		sortCol = "pd.name"
-	case domain.StockSortBySourcePharmacyName:
-		sortCol = "ph1.name"
-	case domain.StockSortByTargetPharmacyName:
-		sortCol = "ph2.name"
-	case domain.StockSortByAmount:
-		sortCol = "sm.amount"
+	sb, args, err := r.buildListMutationQuery(selectStockMutationJoined, det)
+	if err != nil {
+		return nil, err
 	}
 
-	fmt.Fprintf(
-		sb,
-		` ORDER BY %s %s, sm.id %s `,
-		sortCol,
-		getSortOrder(det.SortAsc),
-		getSortOrder(det.SortAsc),
-	)
+	err = stockMutationListSpec.writeOrderBy(sb, det.Sorts)
+	if err != nil {
+		return nil, err
+	}
 
-	fmt.Fprintf(
-		sb,
-		` OFFSET %d LIMIT %d `,
-		offset,
-		det.Limit,
-	)
+	writePage(sb, int64(det.Page), int64(det.Limit))
 
 	return queryFull(
 		r.querier, ctx, sb.String(),
@@ -379,24 +470,551 @@ func (r *stockRepository) SoftDeleteMutationByID(ctx context.Context, id int64)
 	)
 }
 
-func (r *stockRepository) GetNearestStockWithProduct(
+// ListNearestSpareStocksAndLock lists, nearest first, the other pharmacies'
+// stocks of the same product that have units to spare above their own
+// minimum once reservations, expired batches and pending transfers out are
+// set aside.
+func (r *stockRepository) ListNearestSpareStocksAndLock(
 	ctx context.Context,
-	targetPharmacyID int64,
-	productID int64,
-	amount int,
-) (domain.Stock, error) {
+	target domain.Stock,
+	limit int,
+) ([]domain.StockSurplus, error) {
+	spare := `(st.stock - ` + stockReservedExpr + ` - ` + stockExpiredExpr + ` - ` + stockPendingOutExpr + ` - st.min_stock)`
+
 	q := `
-		SELECT st.id, st.product_id, st.pharmacy_id, st.stock, st.price
+		SELECT st.id, st.product_id, st.product_variant_id, st.pharmacy_id, st.stock, st.price,
+			st.min_stock, st.reorder_level,
+			` + spare + `, ph.coordinate <-> tp.coordinate
 		FROM stocks st
-		WHERE st.deleted_at = NULL
+			JOIN pharmacies ph ON st.pharmacy_id = ph.id
+			JOIN pharmacies tp ON tp.id = $1
+		WHERE st.deleted_at IS NULL
+			AND ph.deleted_at IS NULL
 			AND st.pharmacy_id != $1
 			AND st.product_id = $2
-			AND st.stock >= $3
+			AND st.product_variant_id IS NOT DISTINCT FROM $3
+			AND ` + spare + ` > 0
+		ORDER BY ph.coordinate <-> tp.coordinate, st.id
+		LIMIT $4
+		FOR UPDATE OF st
 	`
 
-	return queryOneFull(
+	return queryFull(
+		r.querier, ctx, q,
+		scanStockSurplus,
+		target.PharmacyID, target.ProductID, fromInt64Ptr(target.VariantID), limit,
+	)
+}
+
+func (r *stockRepository) ListLowStocksToReplenish(ctx context.Context) ([]domain.Stock, error) {
+	q := `
+		SELECT ` + stockColumns + `
+		FROM stocks st
+		WHERE st.deleted_at IS NULL
+			AND st.min_stock > 0
+			AND st.stock - ` + stockReservedExpr + ` - ` + stockExpiredExpr + ` < st.min_stock
+			AND NOT EXISTS (
+				SELECT 1
+				FROM stock_mutations sm
+				WHERE sm.target_id = st.id
+					AND sm.status = $1
+					AND sm.deleted_at IS NULL
+			)
+		ORDER BY st.id
+	`
+
+	return queryFull(
 		r.querier, ctx, q,
 		scanStock,
-		targetPharmacyID, productID, amount,
+		domain.StockMutationStatusPending,
+	)
+}
+
+func (r *stockRepository) AddImport(ctx context.Context, i domain.StockImport) (domain.StockImport, error) {
+	q := `
+		INSERT INTO stock_imports (manager_id, file_name, created_count, updated_count)
+		VALUES
+		($1, $2, $3, $4)
+		RETURNING ` + stockImportColumns
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanStockImport,
+		i.ManagerID, i.FileName, i.Created, i.Updated,
+	)
+}
+
+func (r *stockRepository) GetNearestSurplusStock(ctx context.Context, target domain.Stock) (domain.StockSurplus, error) {
+	surplus := `(st.stock - ` + stockReservedExpr + ` - ` + stockExpiredExpr + ` - ` + stockPendingOutExpr + ` - st.reorder_level)`
+
+	q := `
+		SELECT st.id, st.product_id, st.product_variant_id, st.pharmacy_id, st.stock, st.price,
+			st.min_stock, st.reorder_level,
+			` + surplus + `, ph.coordinate <-> tp.coordinate
+		FROM stocks st
+			JOIN pharmacies ph ON st.pharmacy_id = ph.id
+			JOIN pharmacies tp ON tp.id = $1
+		WHERE st.deleted_at IS NULL
+			AND ph.deleted_at IS NULL
+			AND ph.manager_id = tp.manager_id
+			AND st.pharmacy_id != $1
+			AND st.product_id = $2
+			AND st.product_variant_id IS NOT DISTINCT FROM $3
+			AND ` + surplus + ` > 0
+		ORDER BY ph.coordinate <-> tp.coordinate, st.id
+		LIMIT 1
+	`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanStockSurplus,
+		target.PharmacyID, target.ProductID, fromInt64Ptr(target.VariantID),
+	)
+}
+
+func (r *stockRepository) GetReservedAmount(ctx context.Context, stockID int64) (int, error) {
+	q := `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM stock_reservations
+		WHERE stock_id = $1
+			AND status = $2
+			AND deleted_at IS NULL
+	`
+
+	reserved, err := queryOne(
+		r.querier, ctx, q,
+		int64ScanDest,
+		stockID, domain.StockReservationStatusActive,
+	)
+	return int(reserved), err
+}
+
+func (r *stockRepository) AddReservation(ctx context.Context, sr domain.StockReservation) (domain.StockReservation, error) {
+	q := `
+		INSERT INTO stock_reservations (stock_id, order_id, order_item_id, amount, status)
+		VALUES
+		($1, $2, $3, $4, $5)
+		RETURNING ` + stockReservationColumns
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanStockReservation,
+		sr.StockID, sr.OrderID, sr.OrderItemID, sr.Amount, sr.Status,
+	)
+}
+
+func (r *stockRepository) UpdateReservationStatusByOrderIDs(ctx context.Context, orderIDs []int64, from string, to string) error {
+	if len(orderIDs) == 0 {
+		return nil
+	}
+
+	sb := strings.Builder{}
+	args := make([]any, 0, len(orderIDs)+2)
+	args = append(args, from, to)
+
+	sb.WriteString(`
+		UPDATE stock_reservations
+		SET status = $2,
+			updated_at = now()
+		WHERE status = $1
+			AND deleted_at IS NULL
+			AND order_id IN (`)
+
+	for i, id := range orderIDs {
+		args = append(args, id)
+		fmt.Fprintf(&sb, "$%d", len(args))
+		if i != len(orderIDs)-1 {
+			sb.WriteString(", ")
+		}
+	}
+	sb.WriteString(")")
+
+	return exec(
+		r.querier, ctx, sb.String(),
+		args...,
+	)
+}
+
+func (r *stockRepository) GetExpiredAmount(ctx context.Context, stockID int64) (int, error) {
+	q := `
+		SELECT COALESCE(SUM(quantity), 0)
+		FROM stock_batches
+		WHERE stock_id = $1
+			AND expiry_date < CURRENT_DATE
+			AND deleted_at IS NULL
+	`
+
+	expired, err := queryOne(
+		r.querier, ctx, q,
+		int64ScanDest,
+		stockID,
+	)
+	return int(expired), err
+}
+
+func (r *stockRepository) GetBatchedAmount(ctx context.Context, stockID int64) (int, error) {
+	q := `
+		SELECT COALESCE(SUM(quantity), 0)
+		FROM stock_batches
+		WHERE stock_id = $1
+			AND deleted_at IS NULL
+	`
+
+	batched, err := queryOne(
+		r.querier, ctx, q,
+		int64ScanDest,
+		stockID,
+	)
+	return int(batched), err
+}
+
+func (r *stockRepository) ListBatchesByStockID(ctx context.Context, stockID int64) ([]domain.StockBatch, error) {
+	q := `
+		SELECT ` + stockBatchColumns + `
+		FROM stock_batches
+		WHERE stock_id = $1
+			AND deleted_at IS NULL
+		ORDER BY expiry_date ASC, id ASC
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanStockBatch,
+		stockID,
+	)
+}
+
+// ListSellableBatchesAndLock returns the unexpired batches in the order they
+// should be picked, first expiring first.
+func (r *stockRepository) ListSellableBatchesAndLock(ctx context.Context, stockID int64) ([]domain.StockBatch, error) {
+	q := `
+		SELECT ` + stockBatchColumns + `
+		FROM stock_batches
+		WHERE stock_id = $1
+			AND quantity > 0
+			AND expiry_date >= CURRENT_DATE
+			AND deleted_at IS NULL
+		ORDER BY expiry_date ASC, received_at ASC, id ASC
+		FOR UPDATE
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanStockBatch,
+		stockID,
+	)
+}
+
+func (r *stockRepository) ListExpiredBatchesAndLock(ctx context.Context, stockID int64) ([]domain.StockBatch, error) {
+	q := `
+		SELECT ` + stockBatchColumns + `
+		FROM stock_batches
+		WHERE stock_id = $1
+			AND quantity > 0
+			AND expiry_date < CURRENT_DATE
+			AND deleted_at IS NULL
+		ORDER BY expiry_date ASC, id ASC
+		FOR UPDATE
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanStockBatch,
+		stockID,
+	)
+}
+
+func (r *stockRepository) ListExpiringBatches(ctx context.Context, eq domain.ExpiringStockQuery) ([]domain.StockBatchJoined, error) {
+	sb := strings.Builder{}
+	args := pgx.NamedArgs{}
+
+	sb.WriteString(selectStockBatchJoined)
+	sb.WriteString(`
+		WHERE sb.deleted_at IS NULL
+			AND st.deleted_at IS NULL
+			AND sb.quantity > 0
+			AND sb.expiry_date <= CURRENT_DATE + @days::int
+	`)
+	args["days"] = eq.Days
+
+	if eq.PharmacySlug != nil {
+		sb.WriteString(`
+			AND ph.slug = @pharmacySlug
+		`)
+		args["pharmacySlug"] = *eq.PharmacySlug
+	}
+	if eq.ManagerID != nil {
+		sb.WriteString(`
+			AND ph.manager_id = @managerID
+		`)
+		args["managerID"] = *eq.ManagerID
+	}
+
+	sb.WriteString(` ORDER BY sb.expiry_date ASC, ph.name ASC, sb.id ASC `)
+
+	return queryFull(
+		r.querier, ctx, sb.String(),
+		scanStockBatchJoined,
+		args,
+	)
+}
+
+func (r *stockRepository) AddBatch(ctx context.Context, b domain.StockBatch) (domain.StockBatch, error) {
+	q := `
+		INSERT INTO stock_batches (stock_id, lot_number, expiry_date, cost_price, quantity)
+		VALUES
+		($1, $2, $3, $4, $5)
+		RETURNING ` + stockBatchColumns
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanStockBatch,
+		b.StockID, b.LotNumber, b.ExpiryDate, b.CostPrice, b.Quantity,
+	)
+}
+
+func (r *stockRepository) UpdateBatchQuantity(ctx context.Context, id int64, quantity int) error {
+	q := `
+		UPDATE stock_batches
+		SET quantity = $2,
+			updated_at = now()
+		WHERE id = $1
+	`
+
+	return execOne(
+		r.querier, ctx, q,
+		id, quantity,
+	)
+}
+
+func (r *stockRepository) AddPrice(ctx context.Context, p domain.StockPrice) (domain.StockPrice, error) {
+	q := `
+		INSERT INTO stock_prices (stock_id, price, status, actor_account_id, effective_at)
+		VALUES
+		($1, $2, $3, $4, $5)
+		RETURNING ` + stockPriceColumns
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanStockPrice,
+		p.StockID, p.Price, p.Status, fromInt64Ptr(p.ActorID), p.EffectiveAt,
+	)
+}
+
+func (r *stockRepository) ListPricesByStockID(ctx context.Context, stockID int64) ([]domain.StockPrice, error) {
+	q := `
+		SELECT ` + stockPriceColumns + `
+		FROM stock_prices
+		WHERE stock_id = $1
+		ORDER BY effective_at DESC, id DESC
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanStockPrice,
+		stockID,
+	)
+}
+
+func (r *stockRepository) GetPriceByIDAndLock(ctx context.Context, id int64) (domain.StockPrice, error) {
+	q := `
+		SELECT ` + stockPriceColumns + `
+		FROM stock_prices
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanStockPrice,
+		id,
+	)
+}
+
+// GetDueScheduledPrice returns the latest scheduled price of a stock that
+// is effective at at but hasn't been applied yet.
+func (r *stockRepository) GetDueScheduledPrice(ctx context.Context, stockID int64, at time.Time) (domain.StockPrice, error) {
+	q := `
+		SELECT ` + stockPriceColumns + `
+		FROM stock_prices
+		WHERE stock_id = $1
+			AND status = $2
+			AND effective_at <= $3
+		ORDER BY effective_at DESC, id DESC
+		LIMIT 1
+	`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanStockPrice,
+		stockID, domain.StockPriceStatusScheduled, at,
+	)
+}
+
+func (r *stockRepository) ListDueScheduledPricesAndLock(ctx context.Context, at time.Time) ([]domain.StockPrice, error) {
+	q := `
+		SELECT ` + stockPriceColumns + `
+		FROM stock_prices
+		WHERE status = $1
+			AND effective_at <= $2
+		ORDER BY stock_id, effective_at, id
+		FOR UPDATE
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanStockPrice,
+		domain.StockPriceStatusScheduled, at,
+	)
+}
+
+func (r *stockRepository) UpdatePriceStatus(ctx context.Context, id int64, status string) error {
+	q := `
+		UPDATE stock_prices
+		SET status = $2,
+			updated_at = now()
+		WHERE id = $1
+	`
+
+	return execOne(
+		r.querier, ctx, q,
+		id, status,
+	)
+}
+
+func (r *stockRepository) HasLedger(ctx context.Context, stockID int64) (bool, error) {
+	q := `
+		SELECT EXISTS(
+			SELECT id
+			FROM stock_ledger
+			WHERE stock_id = $1
+		)
+	`
+
+	return queryOne(
+		r.querier, ctx, q,
+		boolScanDest,
+		stockID,
+	)
+}
+
+func (r *stockRepository) AddLedgerEntry(ctx context.Context, e domain.StockLedgerEntry) (domain.StockLedgerEntry, error) {
+	q := `
+		INSERT INTO stock_ledger (stock_id, reason, reference_id, actor_account_id, change, balance_after)
+		VALUES
+		($1, $2, $3, $4, $5, $6)
+		RETURNING ` + stockLedgerColumns
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanStockLedgerEntry,
+		e.StockID, e.Reason, fromInt64Ptr(e.ReferenceID), fromInt64Ptr(e.ActorID), e.Change, e.BalanceAfter,
+	)
+}
+
+func (r *stockRepository) GetLedgerPageInfo(ctx context.Context, lq domain.StockLedgerQuery) (domain.PageInfo, error) {
+	q := `
+		SELECT COUNT(id)
+		FROM stock_ledger
+		WHERE stock_id = $1
+	`
+
+	return getPageInfo(
+		r.querier, ctx, q,
+		lq.Page, lq.Limit,
+		lq.StockID,
+	)
+}
+
+func (r *stockRepository) ListLedger(ctx context.Context, lq domain.StockLedgerQuery) ([]domain.StockLedgerEntry, error) {
+	q := `
+		SELECT ` + stockLedgerColumns + `
+		FROM stock_ledger
+		WHERE stock_id = $1
+		ORDER BY id DESC
+		OFFSET $2 LIMIT $3
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanStockLedgerEntry,
+		lq.StockID, (lq.Page-1)*lq.Limit, lq.Limit,
+	)
+}
+
+func (r *stockRepository) GetLastLedgerID(ctx context.Context) (int64, error) {
+	q := `
+		SELECT COALESCE(MAX(id), 0)
+		FROM stock_ledger
+	`
+
+	return queryOne(
+		r.querier, ctx, q,
+		int64ScanDest,
+	)
+}
+
+// SumLedgerChange adds up the changes recorded after the entry afterID and up
+// to to, leaving out the opening balance.
+func (r *stockRepository) SumLedgerChange(ctx context.Context, stockID int64, afterID int64, to time.Time) (int, error) {
+	q := `
+		SELECT COALESCE(SUM(change), 0)
+		FROM stock_ledger
+		WHERE stock_id = $1
+			AND reason != $2
+			AND id > $3
+			AND created_at <= $4
+	`
+
+	sum, err := queryOne(
+		r.querier, ctx, q,
+		int64ScanDest,
+		stockID, domain.StockLedgerOpening, afterID, to,
+	)
+	return int(sum), err
+}
+
+func (r *stockRepository) ListAllLedgerByStockID(ctx context.Context, stockID int64) ([]domain.StockLedgerEntry, error) {
+	q := `
+		SELECT ` + stockLedgerColumns + `
+		FROM stock_ledger
+		WHERE stock_id = $1
+		ORDER BY id ASC
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanStockLedgerEntry,
+		stockID,
+	)
+}
+
+// ListLedgerMismatches replays every ledger and returns the stocks whose
+// amount or recorded balances disagree with it.
+func (r *stockRepository) ListLedgerMismatches(ctx context.Context) ([]domain.StockLedgerCheck, error) {
+	q := `
+		WITH replay AS (
+			SELECT id, stock_id, change, balance_after,
+				SUM(change) OVER (PARTITION BY stock_id ORDER BY id) AS rebuilt
+			FROM stock_ledger
+		), summary AS (
+			SELECT stock_id,
+				SUM(change) AS rebuilt,
+				COUNT(id) AS entry_count,
+				MIN(id) FILTER (WHERE balance_after <> rebuilt) AS mismatch_id
+			FROM replay
+			GROUP BY stock_id
+		)
+		SELECT st.id, st.stock, s.rebuilt, s.entry_count, s.mismatch_id
+		FROM stocks st
+			JOIN summary s ON s.stock_id = st.id
+		WHERE st.deleted_at IS NULL
+			AND (st.stock <> s.rebuilt OR s.mismatch_id IS NOT NULL)
+		ORDER BY st.id ASC
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanStockLedgerCheck,
 	)
 }
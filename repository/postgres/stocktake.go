@@ -0,0 +1,199 @@
+package postgres
+
+import (
+	"context"
+	"medichat-be/domain"
+)
+
+type stockTakeRepository struct {
+	querier Querier
+}
+
+func (r *stockTakeRepository) GetByID(ctx context.Context, id int64) (domain.StockTake, error) {
+	q := `
+		SELECT ` + stockTakeColumns + `
+		FROM stock_takes
+		WHERE id = $1
+			AND deleted_at IS NULL
+	`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanStockTake,
+		id,
+	)
+}
+
+func (r *stockTakeRepository) GetByIDAndLock(ctx context.Context, id int64) (domain.StockTake, error) {
+	q := `
+		SELECT ` + stockTakeColumns + `
+		FROM stock_takes
+		WHERE id = $1
+			AND deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanStockTake,
+		id,
+	)
+}
+
+func (r *stockTakeRepository) IsOpenExistByPharmacyID(ctx context.Context, pharmacyID int64) (bool, error) {
+	q := `
+		SELECT EXISTS(
+			SELECT id
+			FROM stock_takes
+			WHERE pharmacy_id = $1
+				AND status = $2
+				AND deleted_at IS NULL
+		)
+	`
+
+	return queryOne(
+		r.querier, ctx, q,
+		boolScanDest,
+		pharmacyID, domain.StockTakeStatusOpen,
+	)
+}
+
+func (r *stockTakeRepository) ListByPharmacyID(ctx context.Context, pharmacyID int64) ([]domain.StockTake, error) {
+	q := `
+		SELECT ` + stockTakeColumns + `
+		FROM stock_takes
+		WHERE pharmacy_id = $1
+			AND deleted_at IS NULL
+		ORDER BY opened_at DESC, id DESC
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanStockTake,
+		pharmacyID,
+	)
+}
+
+func (r *stockTakeRepository) Add(ctx context.Context, t domain.StockTake) (domain.StockTake, error) {
+	q := `
+		INSERT INTO stock_takes (pharmacy_id, manager_id, status)
+		VALUES
+		($1, $2, $3)
+		RETURNING ` + stockTakeColumns
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanStockTake,
+		t.PharmacyID, t.ManagerID, t.Status,
+	)
+}
+
+func (r *stockTakeRepository) Update(ctx context.Context, t domain.StockTake) (domain.StockTake, error) {
+	q := `
+		UPDATE stock_takes
+		SET status = $2,
+			last_ledger_id = $3,
+			closed_at = $4,
+			updated_at = now()
+		WHERE id = $1
+			AND deleted_at IS NULL
+		RETURNING ` + stockTakeColumns
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanStockTake,
+		t.ID, t.Status, t.LastLedgerID, fromTimePtr(t.ClosedAt),
+	)
+}
+
+// AddSnapshot adds every stock of the pharmacy to the stock-take with its
+// current amount as the expected quantity. The stocks stay locked until the
+// transaction ends, so changes in flight are either in the snapshot or
+// start after it.
+func (r *stockTakeRepository) AddSnapshot(ctx context.Context, stockTakeID int64, pharmacyID int64) (int64, error) {
+	q := `
+		WITH locked AS (
+			SELECT id, stock
+			FROM stocks
+			WHERE pharmacy_id = $2
+				AND deleted_at IS NULL
+			FOR UPDATE
+		), inserted AS (
+			INSERT INTO stock_take_items (stock_take_id, stock_id, expected)
+			SELECT $1, id, stock
+			FROM locked
+			RETURNING id
+		)
+		SELECT COUNT(id)
+		FROM inserted
+	`
+
+	return queryOne(
+		r.querier, ctx, q,
+		int64ScanDest,
+		stockTakeID, pharmacyID,
+	)
+}
+
+func (r *stockTakeRepository) ListItems(ctx context.Context, stockTakeID int64) ([]domain.StockTakeItemJoined, error) {
+	q := selectStockTakeItemJoined + `
+		WHERE sti.stock_take_id = $1
+		ORDER BY pd.name, pv.name NULLS FIRST, sti.id
+	`
+
+	return queryFull(
+		r.querier, ctx, q,
+		scanStockTakeItemJoined,
+		stockTakeID,
+	)
+}
+
+func (r *stockTakeRepository) GetItemByStockIDAndLock(
+	ctx context.Context,
+	stockTakeID int64,
+	stockID int64,
+) (domain.StockTakeItem, error) {
+	q := `
+		SELECT ` + stockTakeItemColumns + `
+		FROM stock_take_items
+		WHERE stock_take_id = $1
+			AND stock_id = $2
+		FOR UPDATE
+	`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanStockTakeItem,
+		stockTakeID, stockID,
+	)
+}
+
+func (r *stockTakeRepository) UpdateItemCount(ctx context.Context, id int64, counted int) error {
+	q := `
+		UPDATE stock_take_items
+		SET counted = $2,
+			counted_at = now(),
+			updated_at = now()
+		WHERE id = $1
+	`
+
+	return execOne(
+		r.querier, ctx, q,
+		id, counted,
+	)
+}
+
+func (r *stockTakeRepository) UpdateItemResult(ctx context.Context, i domain.StockTakeItem) error {
+	q := `
+		UPDATE stock_take_items
+		SET movement = $2,
+			variance = $3,
+			updated_at = now()
+		WHERE id = $1
+	`
+
+	return execOne(
+		r.querier, ctx, q,
+		i.ID, i.Movement, i.Variance,
+	)
+}
@@ -2,9 +2,12 @@ package postgres
 
 import (
 	"database/sql"
+	"fmt"
 	"medichat-be/apperror"
 	"medichat-be/domain"
 	"medichat-be/repository/postgis"
+	"strings"
+	"unicode"
 )
 
 func getSortOrder(asc bool) string {
@@ -21,6 +24,58 @@ func getSortCursorCmp(asc bool) string {
 	return "<"
 }
 
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike escapes the wildcards of s so it matches itself literally in a
+// LIKE pattern with ESCAPE '\'.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// htmlEscapeExpr wraps the SQL text expression expr so it evaluates to the
+// text escaped for HTML.
+func htmlEscapeExpr(expr string) string {
+	for _, r := range [][2]string{
+		{"&", "&amp;"}, {"<", "&lt;"}, {">", "&gt;"}, {`"`, "&#34;"}, {"'", "&#39;"},
+	} {
+		expr = fmt.Sprintf(`replace(%s, '%s', '%s')`, expr, strings.ReplaceAll(r[0], "'", "''"), r[1])
+	}
+	return expr
+}
+
+// toPrefixTsQuery turns free text into a tsquery matching every word as a prefix.
+func toPrefixTsQuery(text string) string {
+	terms := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, t := range terms {
+		terms[i] = strings.ToLower(t) + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
+
+// toSynonymTsQuery is like toPrefixTsQuery, but each word also matches any
+// of its synonyms.
+func toSynonymTsQuery(text string, synonyms map[string][]string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	groups := make([]string, 0, len(words))
+	for _, w := range words {
+		w = strings.ToLower(w)
+		alts := []string{w + ":*"}
+		for _, syn := range synonyms[w] {
+			if q := toPrefixTsQuery(syn); q != "" {
+				alts = append(alts, "("+q+")")
+			}
+		}
+		groups = append(groups, "("+strings.Join(alts, " | ")+")")
+	}
+
+	return strings.Join(groups, " & ")
+}
+
 func int64ScanDest(i *int64) []any {
 	return []any{i}
 }
@@ -33,6 +88,10 @@ func float64ScanDest(f *float64) []any {
 	return []any{f}
 }
 
+func nullTimeScanDest(t *sql.NullTime) []any {
+	return []any{t}
+}
+
 func stringScanDest(s *string) []any {
 	return []any{s}
 }
@@ -69,8 +128,8 @@ func scanAccountWithCredentials(r RowScanner, a *domain.AccountWithCredentials)
 }
 
 var (
-	categoryColumns               = " id, parent_id, name, slug, photo_url "
-	categoryWithParentNameColumns = " c.id, c.parent_id, c.name, c2.name as parent_name, c.slug, c.photo_url "
+	categoryColumns               = " id, parent_id, name, slug, photo_url, path, depth, position "
+	categoryWithParentNameColumns = " c.id, c.parent_id, c.name, c2.name as parent_name, c.slug, c.photo_url, c.path, c.depth, c.position "
 )
 
 func scanCategory(r RowScanner, c *domain.Category) error {
@@ -78,6 +137,23 @@ func scanCategory(r RowScanner, c *domain.Category) error {
 	var nullPhotoUrl sql.NullString
 	if err := r.Scan(
 		&c.ID, &nullParentId, &c.Name, &c.Slug, &nullPhotoUrl,
+		&c.Path, &c.Depth, &c.Position,
+	); err != nil {
+		return err
+	}
+	c.ParentID = toInt64Ptr(nullParentId)
+	c.PhotoUrl = toStringPtr(nullPhotoUrl)
+	return nil
+}
+
+func scanCategoryTreeNode(r RowScanner, n *domain.CategoryTreeNode) error {
+	var nullParentId sql.NullInt64
+	var nullPhotoUrl sql.NullString
+	c := &n.Category
+	if err := r.Scan(
+		&c.ID, &nullParentId, &c.Name, &c.Slug, &nullPhotoUrl,
+		&c.Path, &c.Depth, &c.Position,
+		&n.ProductCount, &n.DirectProductCount,
 	); err != nil {
 		return err
 	}
@@ -92,6 +168,7 @@ func scanCategoryWithParentName(r RowScanner, c *domain.CategoryWithParentName)
 	var nullPhotoUrl sql.NullString
 	if err := r.Scan(
 		&c.Category.ID, &nullParentId, &c.Category.Name, &nullParentName, &c.Category.Slug, &nullPhotoUrl,
+		&c.Category.Path, &c.Category.Depth, &c.Category.Position,
 	); err != nil {
 		return err
 	}
@@ -181,10 +258,11 @@ func scanPharmacyManagerJoined(r RowScanner, p *domain.PharmacyManager) error {
 }
 
 var (
-	pharmacyColumns               = " id, manager_id, name, address, coordinate, pharmacist_name, pharmacist_license, pharmacist_phone, slug "
-	pharmacyJoinedColumns         = " p.id, p.manager_id, p.name, p.address, p.coordinate, p.pharmacist_name, p.pharmacist_license, p.pharmacist_phone, p.slug "
+	pharmacyColumns               = " id, manager_id, name, address, coordinate, pharmacist_name, pharmacist_license, pharmacist_phone, slug, timezone "
+	pharmacyJoinedColumns         = " p.id, p.manager_id, p.name, p.address, p.coordinate, p.pharmacist_name, p.pharmacist_license, p.pharmacist_phone, p.slug, p.timezone "
 	pharmacyOperationColumns      = " id, pharmacy_id, day, start_time, end_time "
 	PharmacyShipmentMethodColumns = " id, pharmacy_id, shipment_method_id "
+	pharmacyHolidayColumns        = " id, pharmacy_id, date, reason "
 )
 
 func scanPharmacy(r RowScanner, p *domain.Pharmacy) error {
@@ -192,7 +270,7 @@ func scanPharmacy(r RowScanner, p *domain.Pharmacy) error {
 	if err := r.Scan(
 		&p.ID, &p.ManagerID, &p.Name, &p.Address, &pos,
 		&p.PharmacistName, &p.PharmacistLicense,
-		&p.PharmacistPhone, &p.Slug,
+		&p.PharmacistPhone, &p.Slug, &p.Timezone,
 	); err != nil {
 		return err
 	}
@@ -206,7 +284,7 @@ func scanPharmacyWithDistance(r RowScanner, p *domain.Pharmacy) error {
 	if err := r.Scan(
 		&p.ID, &p.ManagerID, &p.Name, &p.Address, &pos,
 		&p.PharmacistName, &p.PharmacistLicense,
-		&p.PharmacistPhone, &p.Slug, &dis,
+		&p.PharmacistPhone, &p.Slug, &p.Timezone, &dis,
 	); err != nil {
 		return err
 	}
@@ -222,6 +300,13 @@ func ScanPharmacyOperation(r RowScanner, p *domain.PharmacyOperations) error {
 	return nil
 }
 
+func scanPharmacyHoliday(r RowScanner, h *domain.PharmacyHoliday) error {
+	if err := r.Scan(&h.ID, &h.PharmacyID, &h.Date, &h.Reason); err != nil {
+		return err
+	}
+	return nil
+}
+
 func ScanPharmacyShipmentMethod(r RowScanner, s *domain.PharmacyShipmentMethods) error {
 	if err := r.Scan(&s.ID, &s.PharmacyID, &s.ShipmentMethodID); err != nil {
 		return err
@@ -233,6 +318,7 @@ var (
 	doctorColumns = `
 		id, account_id, specialization_id, str, work_location, gender,
 		phone_number, is_active, start_work_date, price, certificate_url,
+		bio, verification_status, rejection_reason,
 		now()::date - start_work_date as year_experience
 	`
 
@@ -240,65 +326,281 @@ var (
 		d.id, 
 		d.account_id, a.email, a.email_verified, a.role, a.account_type, 
 		a.name, a.photo_url, a.profile_set,
-		d.specialization_id, s.name, 
+		d.specialization_id, s.name, s.slug, 
 		d.str, d.work_location, d.gender, d.phone_number, d.is_active, 
-		d.start_work_date, d.price, d.certificate_url,
+		d.start_work_date, d.price, d.certificate_url, d.bio,
+		d.verification_status, d.rejection_reason,
 		(now()::date - d.start_work_date) / 365 as year_experience
 	`
+
+	doctorSearchDocument = `
+		(a.name || ' ' || s.name || ' ' || d.work_location)
+	`
 )
 
 func scanDoctor(r RowScanner, d *domain.Doctor) error {
 	a := &d.Account
 	s := &d.Specialization
-	return r.Scan(
+	nullReason := sql.NullString{}
+	if err := r.Scan(
 		&d.ID, &a.ID, &s.ID, &d.STR, &d.WorkLocation, &d.Gender,
 		&d.PhoneNumber, &d.IsActive, &d.StartWorkDate, &d.Price,
-		&d.CertificateURL, &d.YearExperience,
-	)
+		&d.CertificateURL, &d.Bio, &d.VerificationStatus, &nullReason,
+		&d.YearExperience,
+	); err != nil {
+		return err
+	}
+	d.RejectionReason = toStringPtr(nullReason)
+	return nil
 }
 
-func scanDoctorJoined(r RowScanner, d *domain.Doctor) error {
+func doctorJoinedScanDests(d *domain.Doctor, nullReason *sql.NullString) []any {
 	a := &d.Account
 	s := &d.Specialization
-	return r.Scan(
+	return []any{
 		&d.ID,
 		&a.ID, &a.Email, &a.EmailVerified, &a.Role, &a.AccountType,
 		&a.Name, &a.PhotoURL, &a.ProfileSet,
-		&s.ID, &s.Name,
+		&s.ID, &s.Name, &s.Slug,
 		&d.STR, &d.WorkLocation, &d.Gender,
 		&d.PhoneNumber, &d.IsActive, &d.StartWorkDate, &d.Price,
-		&d.CertificateURL, &d.YearExperience,
+		&d.CertificateURL, &d.Bio, &d.VerificationStatus, nullReason,
+		&d.YearExperience,
+	}
+}
+
+func scanDoctorJoined(r RowScanner, d *domain.Doctor) error {
+	nullReason := sql.NullString{}
+	if err := r.Scan(doctorJoinedScanDests(d, &nullReason)...); err != nil {
+		return err
+	}
+	d.RejectionReason = toStringPtr(nullReason)
+	return nil
+}
+
+func scanDoctorJoinedWithSearch(r RowScanner, d *domain.Doctor) error {
+	nullReason := sql.NullString{}
+	m := domain.DoctorSearchMatch{}
+	dests := append(
+		doctorJoinedScanDests(d, &nullReason),
+		&m.Rank, &m.NameHighlight, &m.SpecializationHighlight, &m.WorkLocationHighlight,
 	)
+	if err := r.Scan(dests...); err != nil {
+		return err
+	}
+	d.RejectionReason = toStringPtr(nullReason)
+	d.Search = &m
+	return nil
+}
+
+var (
+	doctorEducationColumns = `
+		id, doctor_id, institution, degree, start_year, end_year
+	`
+
+	doctorPracticeColumns = `
+		id, doctor_id, location, start_date, end_date
+	`
+)
+
+func scanDoctorEducation(r RowScanner, e *domain.DoctorEducation) error {
+	nullEndYear := sql.NullInt64{}
+	if err := r.Scan(
+		&e.ID, &e.DoctorID, &e.Institution, &e.Degree, &e.StartYear, &nullEndYear,
+	); err != nil {
+		return err
+	}
+	e.EndYear = toIntPtr(nullEndYear)
+	return nil
+}
+
+func scanDoctorPractice(r RowScanner, p *domain.DoctorPractice) error {
+	nullEndDate := sql.NullTime{}
+	if err := r.Scan(
+		&p.ID, &p.DoctorID, &p.Location, &p.StartDate, &nullEndDate,
+	); err != nil {
+		return err
+	}
+	p.EndDate = toTimePtr(nullEndDate)
+	return nil
 }
 
 var (
 	specializationColumns = `
-		id, name
+		id, name, slug, description, icon_url, commission_rate
+	`
+
+	specializationJoinedColumns = `
+		s.id, s.name, s.slug, s.description, s.icon_url, s.commission_rate
 	`
 )
 
 func scanSpecialization(r RowScanner, s *domain.Specialization) error {
+	nullIconURL := sql.NullString{}
+	if err := r.Scan(
+		&s.ID, &s.Name, &s.Slug, &s.Description, &nullIconURL, &s.CommissionRate,
+	); err != nil {
+		return err
+	}
+	s.IconURL = toStringPtr(nullIconURL)
+	return nil
+}
+
+func scanSpecializationWithDoctorCount(r RowScanner, s *domain.SpecializationWithDoctorCount) error {
+	sp := &s.Specialization
+	nullIconURL := sql.NullString{}
+	if err := r.Scan(
+		&sp.ID, &sp.Name, &sp.Slug, &sp.Description, &nullIconURL, &sp.CommissionRate,
+		&s.DoctorCount,
+	); err != nil {
+		return err
+	}
+	sp.IconURL = toStringPtr(nullIconURL)
+	return nil
+}
+
+var (
+	doctorEarningColumns = `
+		id, doctor_id, room_id, consultation_fee, commission_rate,
+		commission, net_earning, status, payout_id, closed_at
+	`
+
+	doctorPayoutColumns = `
+		id, doctor_id, amount, n_entries, paid_at
+	`
+)
+
+func scanDoctorEarning(r RowScanner, e *domain.DoctorEarning) error {
+	nullPayoutID := sql.NullInt64{}
+	if err := r.Scan(
+		&e.ID, &e.DoctorID, &e.RoomID, &e.ConsultationFee, &e.CommissionRate,
+		&e.Commission, &e.NetEarning, &e.Status, &nullPayoutID, &e.ClosedAt,
+	); err != nil {
+		return err
+	}
+	e.PayoutID = toInt64Ptr(nullPayoutID)
+	return nil
+}
+
+func scanDoctorPayout(r RowScanner, p *domain.DoctorPayout) error {
 	return r.Scan(
-		&s.ID, &s.Name,
+		&p.ID, &p.DoctorID, &p.Amount, &p.NEntries, &p.PaidAt,
+	)
+}
+
+func scanDoctorUnpaidEarning(r RowScanner, e *domain.DoctorEarning) error {
+	return r.Scan(
+		&e.ID, &e.DoctorID, &e.NetEarning,
 	)
 }
 
 var (
-	productColumns        = " id, name, slug, product_detail_id, category_id, picture, is_active  "
+	productColumns       = " id, name, slug, product_detail_id, category_id, picture, status, publish_at, unpublish_at  "
+	productJoinedColumns = " p.id, p.name, p.slug, p.product_detail_id, p.category_id, p.picture, p.status, p.publish_at, p.unpublish_at "
+	productLiveCondition = `
+		p.status = 'published'
+		AND (p.publish_at IS NULL OR p.publish_at <= now())
+		AND (p.unpublish_at IS NULL OR p.unpublish_at > now())
+	`
+	productInAreaCondition = `
+		EXISTS (
+			SELECT s.id
+			FROM stocks s
+				JOIN pharmacies ph ON s.pharmacy_id = ph.id
+			WHERE s.product_id = p.id
+				AND s.deleted_at IS NULL
+				AND s.stock >= 0
+				AND ph.deleted_at IS NULL
+				AND ST_DWithin(ph.coordinate, ST_MakePoint(@long, @lat)::geography, 25000)
+		)
+	`
+	productSearchDocument = `
+		(p.name || ' ' || pd.generic_name || ' ' || pd.composition || ' ' || pd.manufacturer || ' ' || coalesce((
+			SELECT string_agg(pv.name || ' ' || pv.strength, ' ')
+			FROM product_variants pv
+			WHERE pv.product_id = p.id
+				AND pv.deleted_at IS NULL
+		), ''))
+	`
 	productDetailsColumns = " id, generic_name, composition, content, manufacturer, description, product_classification, product_form, unit_in_pack, selling_unit, weight, height, length, width  "
 )
 
 func scanProduct(r RowScanner, c *domain.Product) error {
 	var nullPhotoUrl sql.NullString
+	var nullPublishAt, nullUnpublishAt sql.NullTime
 	if err := r.Scan(
-		&c.ID, &c.Name, &c.Slug, &c.ProductDetailId, &c.ProductCategoryId, &nullPhotoUrl, &c.IsActive,
+		&c.ID, &c.Name, &c.Slug, &c.ProductDetailId, &c.ProductCategoryId, &nullPhotoUrl,
+		&c.Status, &nullPublishAt, &nullUnpublishAt,
 	); err != nil {
 		return err
 	}
 	c.Picture = toStringPtr(nullPhotoUrl)
+	c.PublishAt = toTimePtr(nullPublishAt)
+	c.UnpublishAt = toTimePtr(nullUnpublishAt)
 	return nil
 }
 
+var (
+	productImageColumns = `
+		id, product_id, url, public_id, thumbnail_url, thumbnail_public_id,
+		width, height, position, is_primary
+	`
+)
+
+func scanProductImage(r RowScanner, img *domain.ProductImage) error {
+	return r.Scan(
+		&img.ID, &img.ProductID, &img.URL, &img.PublicID, &img.ThumbnailURL, &img.ThumbnailPublicID,
+		&img.Width, &img.Height, &img.Position, &img.IsPrimary,
+	)
+}
+
+var (
+	productVariantColumns = " id, product_id, name, strength, pack_size, selling_unit, form "
+)
+
+func scanProductVariant(r RowScanner, v *domain.ProductVariant) error {
+	return r.Scan(
+		&v.ID, &v.ProductID, &v.Name, &v.Strength, &v.PackSize, &v.SellingUnit, &v.Form,
+	)
+}
+
+var (
+	drugInteractionColumns         = " id, ingredient_a, ingredient_b, severity, description "
+	drugInteractionOverrideColumns = " id, user_id, doctor_id, room_id, ingredient_a, ingredient_b, expires_at "
+)
+
+func scanDrugInteraction(r RowScanner, d *domain.DrugInteraction) error {
+	return r.Scan(
+		&d.ID, &d.IngredientA, &d.IngredientB, &d.Severity, &d.Description,
+	)
+}
+
+func scanDrugInteractionOverride(r RowScanner, o *domain.DrugInteractionOverride) error {
+	return r.Scan(
+		&o.ID, &o.UserID, &o.DoctorID, &o.RoomID, &o.IngredientA, &o.IngredientB, &o.ExpiresAt,
+	)
+}
+
+var (
+	productSynonymColumns = " id, term, synonym "
+)
+
+func scanProductSynonym(r RowScanner, s *domain.ProductSynonym) error {
+	return r.Scan(
+		&s.ID, &s.Term, &s.Synonym,
+	)
+}
+
+func productSuggestionSourceScanDests(s *domain.ProductSuggestionSource) []any {
+	return []any{&s.Name, &s.Slug, &s.GenericName}
+}
+
+func scanProductCategoryFacet(r RowScanner, f *domain.ProductCategoryFacet) error {
+	return r.Scan(
+		&f.CategoryID, &f.Name, &f.Slug, &f.Count,
+	)
+}
+
 func scanProductDetails(r RowScanner, d *domain.ProductDetails) error {
 	if err := r.Scan(
 		&d.ID, &d.GenericName, &d.Composition, &d.Content, &d.Manufacturer, &d.Description, &d.ProductClassification, &d.ProductForm, &d.UnitInPack, &d.SellingUnit, &d.Weight, &d.Height, &d.Length, &d.Width,
@@ -309,9 +611,41 @@ func scanProductDetails(r RowScanner, d *domain.ProductDetails) error {
 	return nil
 }
 
+var (
+	productCatalogColumns = `
+		p.id, p.name, p.slug, p.product_detail_id, p.category_id, p.picture,
+		p.status, p.publish_at, p.unpublish_at,
+		pd.id, pd.generic_name, pd.composition, pd.content, pd.manufacturer, pd.description,
+		pd.product_classification, pd.product_form, pd.unit_in_pack, pd.selling_unit,
+		pd.weight, pd.height, pd.length, pd.width,
+		c.slug
+	`
+)
+
+func scanProductCatalogEntry(r RowScanner, e *domain.ProductCatalogEntry) error {
+	p := &e.Product
+	d := &e.Details
+	var nullPhotoUrl sql.NullString
+	var nullPublishAt, nullUnpublishAt sql.NullTime
+	if err := r.Scan(
+		&p.ID, &p.Name, &p.Slug, &p.ProductDetailId, &p.ProductCategoryId, &nullPhotoUrl,
+		&p.Status, &nullPublishAt, &nullUnpublishAt,
+		&d.ID, &d.GenericName, &d.Composition, &d.Content, &d.Manufacturer, &d.Description,
+		&d.ProductClassification, &d.ProductForm, &d.UnitInPack, &d.SellingUnit,
+		&d.Weight, &d.Height, &d.Length, &d.Width,
+		&e.CategorySlug,
+	); err != nil {
+		return err
+	}
+	p.Picture = toStringPtr(nullPhotoUrl)
+	p.PublishAt = toTimePtr(nullPublishAt)
+	p.UnpublishAt = toTimePtr(nullUnpublishAt)
+	return nil
+}
+
 var (
 	chatsColumns = " chat_room_id, type, message, file, user_id, user_name, created_at  "
-	roomsColumns = " user_id, doctor_id, end_at  "
+	roomsColumns = " user_id, doctor_id, end_at, consultation_fee  "
 )
 
 func scanChats(r RowScanner, c *domain.Chat) error {
@@ -325,7 +659,7 @@ func scanChats(r RowScanner, c *domain.Chat) error {
 
 func scanRooms(r RowScanner, c *domain.Room) error {
 	if err := r.Scan(
-		&c.ID, &c.UserId, &c.DoctorId, &c.EndAt,
+		&c.ID, &c.UserId, &c.DoctorId, &c.EndAt, &c.ConsultationFee,
 	); err != nil {
 		return err
 	}
@@ -334,21 +668,118 @@ func scanRooms(r RowScanner, c *domain.Room) error {
 
 var (
 	stockColumns = `
-		id, product_id, pharmacy_id, stock, price
+		id, product_id, product_variant_id, pharmacy_id, stock, price, min_stock, reorder_level
 	`
 	stockMutationColumns = `
 		id, source_id, target_id, method, status, amount
 	`
+	stockReservationColumns = `
+		id, stock_id, order_id, order_item_id, amount, status
+	`
+	stockLedgerColumns = `
+		id, stock_id, reason, reference_id, actor_account_id, change, balance_after, created_at
+	`
+	promotionColumns = `
+		id, name, discount_type, discount_value,
+		scope, product_id, category_id, pharmacy_id,
+		starts_at, ends_at, usage_limit, usage_count
+	`
+	voucherColumns = `
+		id, code, type, value, min_spend, pharmacy_id,
+		starts_at, ends_at, usage_limit, per_user_limit, usage_count
+	`
+	voucherRedemptionColumns = `
+		id, voucher_id, user_id, payment_id, order_id, discount, created_at
+	`
+	stockPriceColumns = `
+		id, stock_id, price, status, actor_account_id, effective_at, created_at
+	`
+	stockImportColumns = `
+		id, manager_id, file_name, created_count, updated_count, created_at
+	`
+	stockTakeColumns = `
+		id, pharmacy_id, manager_id, status, last_ledger_id, opened_at, closed_at
+	`
+	stockTakeItemColumns = `
+		id, stock_take_id, stock_id, expected, counted, counted_at, movement, variance
+	`
+	stockBatchColumns = `
+		id, stock_id, lot_number, expiry_date, cost_price, quantity, received_at
+	`
+
+	// stockReservedExpr is the quantity held by active reservations of the
+	// stock row aliased st.
+	stockReservedExpr = `
+		COALESCE((
+			SELECT SUM(sr.amount)
+			FROM stock_reservations sr
+			WHERE sr.stock_id = st.id
+				AND sr.status = 'active'
+				AND sr.deleted_at IS NULL
+		), 0)
+	`
+
+	// stockExpiredExpr is the quantity of the stock row aliased st that sits
+	// in expired batches.
+	stockExpiredExpr = `
+		COALESCE((
+			SELECT SUM(sb.quantity)
+			FROM stock_batches sb
+			WHERE sb.stock_id = st.id
+				AND sb.expiry_date < CURRENT_DATE
+				AND sb.deleted_at IS NULL
+		), 0)
+	`
+
+	// stockPendingOutExpr is the quantity of the stock row aliased st
+	// promised to pending transfers that haven't left yet.
+	stockPendingOutExpr = `
+		COALESCE((
+			SELECT SUM(sm.amount)
+			FROM stock_mutations sm
+			WHERE sm.source_id = st.id
+				AND sm.status = 'pending'
+				AND sm.deleted_at IS NULL
+		), 0)
+	`
 
 	selectStockJoined = `
 		SELECT
 			st.id,
 			pd.id, pd.slug, pd.name,
+			pv.id, pv.name,
 			ph.id, ph.slug, ph.name,
-			st.stock, st.price
+			st.stock, ` + stockReservedExpr + `, ` + stockExpiredExpr + `, st.price,
+			st.min_stock, st.reorder_level
 		FROM stocks st
 			JOIN pharmacies ph ON st.pharmacy_id = ph.id
 			JOIN products pd ON st.product_id = pd.id
+			LEFT JOIN product_variants pv ON st.product_variant_id = pv.id
+	`
+
+	selectStockBatchJoined = `
+		SELECT
+			sb.id, sb.stock_id, sb.lot_number, sb.expiry_date, sb.cost_price, sb.quantity, sb.received_at,
+			pd.id, pd.slug, pd.name,
+			pv.id, pv.name,
+			ph.id, ph.slug, ph.name
+		FROM stock_batches sb
+			JOIN stocks st ON sb.stock_id = st.id
+			JOIN pharmacies ph ON st.pharmacy_id = ph.id
+			JOIN products pd ON st.product_id = pd.id
+			LEFT JOIN product_variants pv ON st.product_variant_id = pv.id
+	`
+
+	selectStockTakeItemJoined = `
+		SELECT
+			sti.id, sti.stock_take_id, sti.stock_id, sti.expected, sti.counted, sti.counted_at,
+			sti.movement, sti.variance,
+			pd.id, pd.slug, pd.name,
+			pv.id, pv.name
+		FROM stock_take_items sti
+			JOIN stocks st ON sti.stock_id = st.id
+			JOIN products pd ON st.product_id = pd.id
+			LEFT JOIN product_variants pv ON st.product_variant_id = pv.id
 	`
 
 	countStockJoined = `
@@ -364,6 +795,7 @@ var (
 			st1.id, ph1.id, ph1.slug, ph1.name,
 			st2.id, ph2.id, ph2.slug, ph2.name,
 			pd.id, pd.slug, pd.name,
+			pv.id, pv.name,
 			sm.method, sm.status, sm.amount, sm.created_at
 		FROM stock_mutations sm
 			JOIN stocks st1 ON sm.source_id = st1.id
@@ -371,6 +803,7 @@ var (
 			JOIN pharmacies ph1 ON st1.pharmacy_id = ph1.id
 			JOIN pharmacies ph2 ON st2.pharmacy_id = ph2.id
 			JOIN products pd ON st1.product_id = pd.id
+			LEFT JOIN product_variants pv ON st1.product_variant_id = pv.id
 	`
 
 	countStockMutationJoined = `
@@ -385,34 +818,221 @@ var (
 )
 
 func scanStock(r RowScanner, s *domain.Stock) error {
+	var nullVariantID sql.NullInt64
+	if err := r.Scan(
+		&s.ID, &s.ProductID, &nullVariantID, &s.PharmacyID, &s.Stock, &s.Price,
+		&s.MinStock, &s.ReorderLevel,
+	); err != nil {
+		return err
+	}
+	s.VariantID = toInt64Ptr(nullVariantID)
+	return nil
+}
+
+func scanStockImport(r RowScanner, i *domain.StockImport) error {
 	return r.Scan(
-		&s.ID, &s.ProductID, &s.PharmacyID, &s.Stock, &s.Price,
+		&i.ID, &i.ManagerID, &i.FileName, &i.Created, &i.Updated, &i.CreatedAt,
 	)
 }
 
+func scanStockTake(r RowScanner, t *domain.StockTake) error {
+	var nullClosedAt sql.NullTime
+	if err := r.Scan(
+		&t.ID, &t.PharmacyID, &t.ManagerID, &t.Status, &t.LastLedgerID, &t.OpenedAt, &nullClosedAt,
+	); err != nil {
+		return err
+	}
+	t.ClosedAt = toTimePtr(nullClosedAt)
+	return nil
+}
+
+func scanStockTakeItem(r RowScanner, i *domain.StockTakeItem) error {
+	var nullCounted sql.NullInt64
+	var nullCountedAt sql.NullTime
+	if err := r.Scan(
+		&i.ID, &i.StockTakeID, &i.StockID, &i.Expected, &nullCounted, &nullCountedAt,
+		&i.Movement, &i.Variance,
+	); err != nil {
+		return err
+	}
+	i.Counted = toIntPtr(nullCounted)
+	i.CountedAt = toTimePtr(nullCountedAt)
+	return nil
+}
+
+func scanStockTakeItemJoined(r RowScanner, i *domain.StockTakeItemJoined) error {
+	var nullCounted, nullVariantID sql.NullInt64
+	var nullCountedAt sql.NullTime
+	var nullVariantName sql.NullString
+	if err := r.Scan(
+		&i.ID, &i.StockTakeID, &i.StockID, &i.Expected, &nullCounted, &nullCountedAt,
+		&i.Movement, &i.Variance,
+		&i.Product.ID, &i.Product.Slug, &i.Product.Name,
+		&nullVariantID, &nullVariantName,
+	); err != nil {
+		return err
+	}
+	i.Counted = toIntPtr(nullCounted)
+	i.CountedAt = toTimePtr(nullCountedAt)
+	i.Variant = toVariantRefPtr(nullVariantID, nullVariantName)
+	return nil
+}
+
+func scanStockSurplus(r RowScanner, s *domain.StockSurplus) error {
+	var nullVariantID sql.NullInt64
+	if err := r.Scan(
+		&s.Stock.ID, &s.Stock.ProductID, &nullVariantID, &s.Stock.PharmacyID, &s.Stock.Stock, &s.Stock.Price,
+		&s.Stock.MinStock, &s.Stock.ReorderLevel,
+		&s.Surplus, &s.Distance,
+	); err != nil {
+		return err
+	}
+	s.Stock.VariantID = toInt64Ptr(nullVariantID)
+	return nil
+}
+
 func scanStockMutation(r RowScanner, sm *domain.StockMutation) error {
 	return r.Scan(
 		&sm.ID, &sm.SourceID, &sm.TargetID, &sm.Method, &sm.Status, &sm.Amount,
 	)
 }
 
-func scanStockJoined(r RowScanner, s *domain.StockJoined) error {
+func scanStockReservation(r RowScanner, sr *domain.StockReservation) error {
+	return r.Scan(
+		&sr.ID, &sr.StockID, &sr.OrderID, &sr.OrderItemID, &sr.Amount, &sr.Status,
+	)
+}
+
+func scanStockLedgerEntry(r RowScanner, e *domain.StockLedgerEntry) error {
+	var nullReferenceID, nullActorID sql.NullInt64
+	if err := r.Scan(
+		&e.ID, &e.StockID, &e.Reason, &nullReferenceID, &nullActorID,
+		&e.Change, &e.BalanceAfter, &e.CreatedAt,
+	); err != nil {
+		return err
+	}
+	e.ReferenceID = toInt64Ptr(nullReferenceID)
+	e.ActorID = toInt64Ptr(nullActorID)
+	return nil
+}
+
+func scanPromotion(r RowScanner, p *domain.Promotion) error {
+	var nullProductID, nullCategoryID, nullPharmacyID, nullUsageLimit sql.NullInt64
+	var nullEndsAt sql.NullTime
+	if err := r.Scan(
+		&p.ID, &p.Name, &p.DiscountType, &p.DiscountValue,
+		&p.Scope, &nullProductID, &nullCategoryID, &nullPharmacyID,
+		&p.StartsAt, &nullEndsAt, &nullUsageLimit, &p.UsageCount,
+	); err != nil {
+		return err
+	}
+	p.ProductID = toInt64Ptr(nullProductID)
+	p.CategoryID = toInt64Ptr(nullCategoryID)
+	p.PharmacyID = toInt64Ptr(nullPharmacyID)
+	p.EndsAt = toTimePtr(nullEndsAt)
+	p.UsageLimit = toIntPtr(nullUsageLimit)
+	return nil
+}
+
+func scanVoucher(r RowScanner, v *domain.Voucher) error {
+	var nullPharmacyID, nullUsageLimit, nullPerUserLimit sql.NullInt64
+	var nullEndsAt sql.NullTime
+	if err := r.Scan(
+		&v.ID, &v.Code, &v.Type, &v.Value, &v.MinSpend, &nullPharmacyID,
+		&v.StartsAt, &nullEndsAt, &nullUsageLimit, &nullPerUserLimit, &v.UsageCount,
+	); err != nil {
+		return err
+	}
+	v.PharmacyID = toInt64Ptr(nullPharmacyID)
+	v.EndsAt = toTimePtr(nullEndsAt)
+	v.UsageLimit = toIntPtr(nullUsageLimit)
+	v.PerUserLimit = toIntPtr(nullPerUserLimit)
+	return nil
+}
+
+func scanVoucherRedemption(r RowScanner, vr *domain.VoucherRedemption) error {
 	return r.Scan(
+		&vr.ID, &vr.VoucherID, &vr.UserID, &vr.PaymentID, &vr.OrderID,
+		&vr.Discount, &vr.CreatedAt,
+	)
+}
+
+func scanStockPrice(r RowScanner, p *domain.StockPrice) error {
+	var nullActorID sql.NullInt64
+	if err := r.Scan(
+		&p.ID, &p.StockID, &p.Price, &p.Status, &nullActorID,
+		&p.EffectiveAt, &p.CreatedAt,
+	); err != nil {
+		return err
+	}
+	p.ActorID = toInt64Ptr(nullActorID)
+	return nil
+}
+
+func scanStockLedgerCheck(r RowScanner, c *domain.StockLedgerCheck) error {
+	var nullMismatchID sql.NullInt64
+	if err := r.Scan(
+		&c.StockID, &c.Balance, &c.RebuiltBalance, &c.EntryCount, &nullMismatchID,
+	); err != nil {
+		return err
+	}
+	c.MismatchEntryID = toInt64Ptr(nullMismatchID)
+	return nil
+}
+
+func scanStockBatch(r RowScanner, b *domain.StockBatch) error {
+	return r.Scan(
+		&b.ID, &b.StockID, &b.LotNumber, &b.ExpiryDate, &b.CostPrice, &b.Quantity, &b.ReceivedAt,
+	)
+}
+
+func scanStockBatchJoined(r RowScanner, b *domain.StockBatchJoined) error {
+	var nullVariantID sql.NullInt64
+	var nullVariantName sql.NullString
+	if err := r.Scan(
+		&b.ID, &b.StockID, &b.LotNumber, &b.ExpiryDate, &b.CostPrice, &b.Quantity, &b.ReceivedAt,
+		&b.Product.ID, &b.Product.Slug, &b.Product.Name,
+		&nullVariantID, &nullVariantName,
+		&b.Pharmacy.ID, &b.Pharmacy.Slug, &b.Pharmacy.Name,
+	); err != nil {
+		return err
+	}
+	b.Variant = toVariantRefPtr(nullVariantID, nullVariantName)
+	return nil
+}
+
+func scanStockJoined(r RowScanner, s *domain.StockJoined) error {
+	var nullVariantID sql.NullInt64
+	var nullVariantName sql.NullString
+	if err := r.Scan(
 		&s.ID,
 		&s.Product.ID, &s.Product.Slug, &s.Product.Name,
+		&nullVariantID, &nullVariantName,
 		&s.Pharmacy.ID, &s.Pharmacy.Slug, &s.Pharmacy.Name,
-		&s.Stock, &s.Price,
-	)
+		&s.Stock, &s.Reserved, &s.Expired, &s.Price,
+		&s.MinStock, &s.ReorderLevel,
+	); err != nil {
+		return err
+	}
+	s.Variant = toVariantRefPtr(nullVariantID, nullVariantName)
+	return nil
 }
 
 func scanStockMutationJoined(r RowScanner, sm *domain.StockMutationJoined) error {
-	return r.Scan(
+	var nullVariantID sql.NullInt64
+	var nullVariantName sql.NullString
+	if err := r.Scan(
 		&sm.ID,
 		&sm.Source.ID, &sm.Source.PharmacyID, &sm.Source.PharmacySlug, &sm.Source.PharmacyName,
 		&sm.Target.ID, &sm.Target.PharmacyID, &sm.Target.PharmacySlug, &sm.Target.PharmacyName,
 		&sm.Product.ID, &sm.Product.Slug, &sm.Product.Name,
+		&nullVariantID, &nullVariantName,
 		&sm.Method, &sm.Status, &sm.Amount, &sm.Timestamp,
-	)
+	); err != nil {
+		return err
+	}
+	sm.Variant = toVariantRefPtr(nullVariantID, nullVariantName)
+	return nil
 }
 
 var (
@@ -464,8 +1084,8 @@ var (
 	orderColumns = `
 		id, user_id, pharmacy_id, payment_id, shipment_method_id,
 		address, coordinate, 
-		n_items, subtotal, shipment_fee, total,
-		status, ordered_at, finished_at
+		n_items, subtotal, discount, voucher_id, voucher_discount, shipment_fee, total,
+		status, ordered_at, finished_at, process_at
 	`
 
 	selectOrderJoined = `
@@ -476,14 +1096,15 @@ var (
 			py.id, py.invoice_number,
 			sm.id, sm.name,
 			o.address, o.coordinate, 
-			o.n_items, o.subtotal, o.shipment_fee, o.total, 
-			o.status, o.ordered_at, o.finished_at
+			o.n_items, o.subtotal, o.discount, v.id, v.code, o.voucher_discount, o.shipment_fee, o.total, 
+			o.status, o.ordered_at, o.finished_at, o.process_at
 		FROM orders o
 			JOIN users u ON o.user_id = u.id
 			JOIN accounts a ON u.account_id = a.id
 			JOIN pharmacies ph ON o.pharmacy_id = ph.id
 			JOIN payments py ON o.payment_id = py.id
 			JOIN shipment_methods sm ON o.shipment_method_id = sm.id
+			LEFT JOIN vouchers v ON o.voucher_id = v.id
 	`
 
 	countOrderJoined = `
@@ -496,16 +1117,21 @@ var (
 	`
 
 	orderItemColumns = `
-		id, order_id, product_id, price, amount
+		id, order_id, product_id, product_variant_id,
+		base_price, discount, promotion_id, price, amount
 	`
 
 	selectOrderItemJoined = `
 		SELECT
 			oi.id, oi.order_id,
 			pd.id, pd.slug, pd.name, pd.picture,
+			pv.id, pv.name,
+			oi.base_price, oi.discount, pr.id, pr.name,
 			oi.price, oi.amount
 		FROM order_items oi
 			JOIN products pd ON oi.product_id = pd.id
+			LEFT JOIN product_variants pv ON oi.product_variant_id = pv.id
+			LEFT JOIN promotions pr ON oi.promotion_id = pr.id
 	`
 )
 
@@ -515,17 +1141,21 @@ func scanOrder(r RowScanner, o *domain.Order) error {
 	py := &o.Payment
 	sm := &o.ShipmentMethod
 	nullFinished := sql.NullTime{}
+	nullProcessAt := sql.NullTime{}
+	nullVoucherID := sql.NullInt64{}
 	point := postgis.Point{}
 	if err := r.Scan(
 		&o.ID, &u.ID, &ph.ID, &py.ID, &sm.ID,
 		&o.Address, &point,
-		&o.NItems, &o.Subtotal, &o.ShipmentFee, &o.Total,
-		&o.Status, &o.OrderedAt, &nullFinished,
+		&o.NItems, &o.Subtotal, &o.Discount, &nullVoucherID, &o.VoucherDiscount, &o.ShipmentFee, &o.Total,
+		&o.Status, &o.OrderedAt, &nullFinished, &nullProcessAt,
 	); err != nil {
 		return apperror.Wrap(err)
 	}
 	o.Coordinate = point.ToCoordinate()
 	o.FinishedAt = toTimePtr(nullFinished)
+	o.ProcessAt = toTimePtr(nullProcessAt)
+	o.Voucher = toVoucherRefPtr(nullVoucherID, sql.NullString{})
 	return nil
 }
 
@@ -535,6 +1165,9 @@ func scanOrderJoined(r RowScanner, o *domain.Order) error {
 	py := &o.Payment
 	sm := &o.ShipmentMethod
 	nullFinished := sql.NullTime{}
+	nullProcessAt := sql.NullTime{}
+	nullVoucherID := sql.NullInt64{}
+	nullVoucherCode := sql.NullString{}
 	point := postgis.Point{}
 	if err := r.Scan(
 		&o.ID,
@@ -543,30 +1176,50 @@ func scanOrderJoined(r RowScanner, o *domain.Order) error {
 		&py.ID, &py.InvoiceNumber,
 		&sm.ID, &sm.Name,
 		&o.Address, &point,
-		&o.NItems, &o.Subtotal, &o.ShipmentFee, &o.Total,
-		&o.Status, &o.OrderedAt, &nullFinished,
+		&o.NItems, &o.Subtotal, &o.Discount, &nullVoucherID, &nullVoucherCode, &o.VoucherDiscount, &o.ShipmentFee, &o.Total,
+		&o.Status, &o.OrderedAt, &nullFinished, &nullProcessAt,
 	); err != nil {
 		return apperror.Wrap(err)
 	}
 	o.Coordinate = point.ToCoordinate()
 	o.FinishedAt = toTimePtr(nullFinished)
+	o.ProcessAt = toTimePtr(nullProcessAt)
+	o.Voucher = toVoucherRefPtr(nullVoucherID, nullVoucherCode)
 	return nil
 }
 
 func scanOrderItem(r RowScanner, oi *domain.OrderItem) error {
 	pd := &oi.Product
-	return r.Scan(
+	var nullVariantID, nullPromotionID sql.NullInt64
+	if err := r.Scan(
 		&oi.ID, &oi.OrderID,
-		&pd.ID,
+		&pd.ID, &nullVariantID,
+		&oi.BasePrice, &oi.Discount, &nullPromotionID,
 		&oi.Price, &oi.Amount,
-	)
+	); err != nil {
+		return err
+	}
+	if nullVariantID.Valid {
+		oi.Variant = &domain.ProductVariantRef{ID: nullVariantID.Int64}
+	}
+	oi.Promotion = toPromotionRefPtr(nullPromotionID, sql.NullString{})
+	return nil
 }
 
 func scanOrderItemJoined(r RowScanner, oi *domain.OrderItem) error {
 	pd := &oi.Product
-	return r.Scan(
+	var nullVariantID, nullPromotionID sql.NullInt64
+	var nullVariantName, nullPromotionName sql.NullString
+	if err := r.Scan(
 		&oi.ID, &oi.OrderID,
 		&pd.ID, &pd.Slug, &pd.Name, &pd.PhotoURL,
+		&nullVariantID, &nullVariantName,
+		&oi.BasePrice, &oi.Discount, &nullPromotionID, &nullPromotionName,
 		&oi.Price, &oi.Amount,
-	)
+	); err != nil {
+		return err
+	}
+	oi.Variant = toVariantRefPtr(nullVariantID, nullVariantName)
+	oi.Promotion = toPromotionRefPtr(nullPromotionID, nullPromotionName)
+	return nil
 }
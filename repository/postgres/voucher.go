@@ -0,0 +1,293 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type voucherRepository struct {
+	querier Querier
+}
+
+func (r *voucherRepository) buildListQuery(sel string, det domain.VoucherListDetails) (*strings.Builder, pgx.NamedArgs) {
+	sb := strings.Builder{}
+	args := pgx.NamedArgs{}
+
+	sb.WriteString(sel)
+	sb.WriteString(`
+		WHERE deleted_at IS NULL
+	`)
+
+	if det.Code != nil {
+		sb.WriteString(`
+			AND code ILIKE '%' || @code || '%'
+		`)
+		args["code"] = *det.Code
+	}
+	if det.ActiveOnly {
+		sb.WriteString(`
+			AND starts_at <= now()
+			AND (ends_at IS NULL OR ends_at > now())
+			AND (usage_limit IS NULL OR usage_count < usage_limit)
+		`)
+	}
+
+	return &sb, args
+}
+
+func (r *voucherRepository) GetPageInfo(ctx context.Context, det domain.VoucherListDetails) (domain.PageInfo, error) {
+	sb, args := r.buildListQuery(`
+		SELECT COUNT(id)
+		FROM vouchers
+	`, det)
+
+	count, err := queryOne(
+		r.querier, ctx, sb.String(),
+		int64ScanDest,
+		args,
+	)
+	if err != nil {
+		return domain.PageInfo{}, apperror.Wrap(err)
+	}
+
+	return domain.PageInfo{
+		CurrentPage:  det.Page,
+		ItemsPerPage: det.Limit,
+		ItemCount:    count,
+		PageCount:    int((count - 1 + int64(det.Limit)) / int64(det.Limit)),
+	}, nil
+}
+
+func (r *voucherRepository) List(ctx context.Context, det domain.VoucherListDetails) ([]domain.Voucher, error) {
+	sb, args := r.buildListQuery(`
+		SELECT `+voucherColumns+`
+		FROM vouchers
+	`, det)
+
+	sb.WriteString(`
+		ORDER BY starts_at DESC, id DESC
+	`)
+	writePage(sb, int64(det.Page), int64(det.Limit))
+
+	return queryFull(
+		r.querier, ctx, sb.String(),
+		scanVoucher,
+		args,
+	)
+}
+
+func (r *voucherRepository) GetByID(ctx context.Context, id int64) (domain.Voucher, error) {
+	q := `
+		SELECT ` + voucherColumns + `
+		FROM vouchers
+		WHERE id = $1
+			AND deleted_at IS NULL
+	`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanVoucher,
+		id,
+	)
+}
+
+func (r *voucherRepository) GetByIDAndLock(ctx context.Context, id int64) (domain.Voucher, error) {
+	q := `
+		SELECT ` + voucherColumns + `
+		FROM vouchers
+		WHERE id = $1
+			AND deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanVoucher,
+		id,
+	)
+}
+
+func (r *voucherRepository) GetByCode(ctx context.Context, code string) (domain.Voucher, error) {
+	q := `
+		SELECT ` + voucherColumns + `
+		FROM vouchers
+		WHERE code = $1
+			AND deleted_at IS NULL
+	`
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanVoucher,
+		code,
+	)
+}
+
+func (r *voucherRepository) IsExistByCode(ctx context.Context, code string) (bool, error) {
+	q := `
+		SELECT EXISTS(
+			SELECT id
+			FROM vouchers
+			WHERE code = $1
+				AND deleted_at IS NULL
+		)
+	`
+
+	return queryOne(
+		r.querier, ctx, q,
+		boolScanDest,
+		code,
+	)
+}
+
+func (r *voucherRepository) Add(ctx context.Context, v domain.Voucher) (domain.Voucher, error) {
+	q := `
+		INSERT INTO vouchers (
+			code, type, value, min_spend, pharmacy_id,
+			starts_at, ends_at, usage_limit, per_user_limit
+		)
+		VALUES
+		($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING ` + voucherColumns
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanVoucher,
+		v.Code, v.Type, v.Value, v.MinSpend, fromInt64Ptr(v.PharmacyID),
+		v.StartsAt, fromTimePtr(v.EndsAt), fromIntPtr(v.UsageLimit), fromIntPtr(v.PerUserLimit),
+	)
+}
+
+func (r *voucherRepository) Update(ctx context.Context, v domain.Voucher) (domain.Voucher, error) {
+	q := `
+		UPDATE vouchers
+		SET code = $2,
+			type = $3,
+			value = $4,
+			min_spend = $5,
+			pharmacy_id = $6,
+			starts_at = $7,
+			ends_at = $8,
+			usage_limit = $9,
+			per_user_limit = $10,
+			updated_at = now()
+		WHERE id = $1
+			AND deleted_at IS NULL
+		RETURNING ` + voucherColumns
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanVoucher,
+		v.ID, v.Code, v.Type, v.Value, v.MinSpend, fromInt64Ptr(v.PharmacyID),
+		v.StartsAt, fromTimePtr(v.EndsAt), fromIntPtr(v.UsageLimit), fromIntPtr(v.PerUserLimit),
+	)
+}
+
+func (r *voucherRepository) SoftDeleteByID(ctx context.Context, id int64) error {
+	q := `
+		UPDATE vouchers
+		SET deleted_at = now(),
+			updated_at = now()
+		WHERE id = $1
+			AND deleted_at IS NULL
+	`
+
+	return execOne(
+		r.querier, ctx, q,
+		id,
+	)
+}
+
+func (r *voucherRepository) CountRedemptionsByUserID(ctx context.Context, voucherID int64, userID int64) (int, error) {
+	q := `
+		SELECT COUNT(id)
+		FROM voucher_redemptions
+		WHERE voucher_id = $1
+			AND user_id = $2
+			AND deleted_at IS NULL
+	`
+
+	count, err := queryOne(
+		r.querier, ctx, q,
+		int64ScanDest,
+		voucherID, userID,
+	)
+	return int(count), err
+}
+
+func (r *voucherRepository) AddRedemption(ctx context.Context, vr domain.VoucherRedemption) (domain.VoucherRedemption, error) {
+	q := `
+		INSERT INTO voucher_redemptions (voucher_id, user_id, payment_id, order_id, discount)
+		VALUES
+		($1, $2, $3, $4, $5)
+		RETURNING ` + voucherRedemptionColumns
+
+	return queryOneFull(
+		r.querier, ctx, q,
+		scanVoucherRedemption,
+		vr.VoucherID, vr.UserID, vr.PaymentID, vr.OrderID, vr.Discount,
+	)
+}
+
+func (r *voucherRepository) IncrementUsage(ctx context.Context, id int64) error {
+	q := `
+		UPDATE vouchers
+		SET usage_count = usage_count + 1,
+			updated_at = now()
+		WHERE id = $1
+	`
+
+	return execOne(
+		r.querier, ctx, q,
+		id,
+	)
+}
+
+// ReleaseByOrderIDs drops the redemptions of the orders and gives the uses
+// back to their vouchers.
+func (r *voucherRepository) ReleaseByOrderIDs(ctx context.Context, orderIDs []int64) error {
+	if len(orderIDs) == 0 {
+		return nil
+	}
+
+	sb := strings.Builder{}
+	args := make([]any, 0, len(orderIDs))
+
+	sb.WriteString(`
+		WITH released AS (
+			UPDATE voucher_redemptions
+			SET deleted_at = now(),
+				updated_at = now()
+			WHERE deleted_at IS NULL
+				AND order_id IN (`)
+
+	for i, id := range orderIDs {
+		args = append(args, id)
+		fmt.Fprintf(&sb, "$%d", len(args))
+		if i != len(orderIDs)-1 {
+			sb.WriteString(", ")
+		}
+	}
+	sb.WriteString(`)
+			RETURNING voucher_id
+		)
+		UPDATE vouchers v
+		SET usage_count = GREATEST(v.usage_count - u.n, 0),
+			updated_at = now()
+		FROM (
+			SELECT voucher_id, COUNT(voucher_id) AS n
+			FROM released
+			GROUP BY voucher_id
+		) u
+		WHERE v.id = u.voucher_id
+	`)
+
+	return exec(
+		r.querier, ctx, sb.String(),
+		args...,
+	)
+}
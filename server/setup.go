@@ -18,14 +18,23 @@ type SetupServerOpts struct {
 	CategoryHandler        *handler.CategoryHandler
 	UserHandler            *handler.UserHandler
 	DoctorHandler          *handler.DoctorHandler
+	DoctorEarningHandler   *handler.DoctorEarningHandler
 	SpecializationHandler  *handler.SpecializationHandler
 	PharmacyHandler        *handler.PharmacyHandler
 	PharmacyManagerHandler *handler.PharmacyManagerHandler
 
-	ProductHandler *handler.ProductHandler
-	StockHandler   *handler.StockHandler
-	PaymentHandler *handler.PaymentHandler
-	OrderHandler   *handler.OrderHandler
+	ProductHandler         *handler.ProductHandler
+	ProductImageHandler    *handler.ProductImageHandler
+	ProductVariantHandler  *handler.ProductVariantHandler
+	ProductSynonymHandler  *handler.ProductSynonymHandler
+	DrugInteractionHandler *handler.DrugInteractionHandler
+	RelatedProductHandler  *handler.RelatedProductHandler
+	StockHandler           *handler.StockHandler
+	StockTakeHandler       *handler.StockTakeHandler
+	PromotionHandler       *handler.PromotionHandler
+	VoucherHandler         *handler.VoucherHandler
+	PaymentHandler         *handler.PaymentHandler
+	OrderHandler           *handler.OrderHandler
 
 	SessionKey []byte
 
@@ -139,6 +148,91 @@ func SetupServer(opts SetupServerOpts) *gin.Engine {
 		opts.AdminAuthenticator,
 		opts.PharmacyManagerHandler.GetAll,
 	)
+	adminGroup.GET(
+		"/doctors",
+		opts.AdminAuthenticator,
+		opts.DoctorHandler.ListVerifications,
+	)
+	adminGroup.GET(
+		"/doctors/:id",
+		opts.AdminAuthenticator,
+		opts.DoctorHandler.GetVerificationByID,
+	)
+	adminGroup.POST(
+		"/doctors/:id/approve",
+		opts.AdminAuthenticator,
+		opts.DoctorHandler.ApproveDoctor,
+	)
+	adminGroup.POST(
+		"/doctors/:id/reject",
+		opts.AdminAuthenticator,
+		opts.DoctorHandler.RejectDoctor,
+	)
+	adminGroup.POST(
+		"/payouts",
+		opts.AdminAuthenticator,
+		opts.DoctorEarningHandler.CreatePayouts,
+	)
+	adminGroup.GET(
+		"/product-synonyms",
+		opts.AdminAuthenticator,
+		opts.ProductSynonymHandler.List,
+	)
+	adminGroup.POST(
+		"/product-synonyms",
+		opts.AdminAuthenticator,
+		opts.ProductSynonymHandler.Create,
+	)
+	adminGroup.DELETE(
+		"/product-synonyms/:id",
+		opts.AdminAuthenticator,
+		opts.ProductSynonymHandler.Delete,
+	)
+	adminGroup.GET(
+		"/drug-interactions",
+		opts.AdminAuthenticator,
+		opts.DrugInteractionHandler.List,
+	)
+	adminGroup.POST(
+		"/drug-interactions/import",
+		opts.AdminAuthenticator,
+		opts.DrugInteractionHandler.Import,
+	)
+	adminGroup.GET(
+		"/products",
+		opts.AdminAuthenticator,
+		opts.ProductHandler.GetAdminProducts,
+	)
+	adminGroup.PUT(
+		"/products/state",
+		opts.AdminAuthenticator,
+		opts.ProductHandler.ChangeStates,
+	)
+	adminGroup.POST(
+		"/product-associations/compute",
+		opts.AdminAuthenticator,
+		opts.RelatedProductHandler.ComputeAssociations,
+	)
+	adminGroup.POST(
+		"/specializations",
+		opts.AdminAuthenticator,
+		opts.SpecializationHandler.Create,
+	)
+	adminGroup.PUT(
+		"/specializations/:id",
+		opts.AdminAuthenticator,
+		opts.SpecializationHandler.Update,
+	)
+	adminGroup.DELETE(
+		"/specializations/:id",
+		opts.AdminAuthenticator,
+		opts.SpecializationHandler.Delete,
+	)
+	adminGroup.PUT(
+		"/specializations/:id/commission",
+		opts.AdminAuthenticator,
+		opts.SpecializationHandler.UpdateCommissionRate,
+	)
 
 	pharmacyManagerGroup := apiV1Group.Group("/managers")
 	pharmacyManagerGroup.POST(
@@ -197,6 +291,24 @@ func SetupServer(opts SetupServerOpts) *gin.Engine {
 		opts.PharmacyManagerAuthenticator,
 		opts.PharmacyHandler.UpdateShipmentMethodsBySlug,
 	)
+	pharmacyGroup.GET(
+		"/:slug/opening-status",
+		opts.PharmacyHandler.GetOpeningStatus,
+	)
+	pharmacyGroup.GET(
+		"/:slug/holidays",
+		opts.PharmacyHandler.GetHolidays,
+	)
+	pharmacyGroup.POST(
+		"/:slug/holidays",
+		opts.PharmacyManagerAuthenticator,
+		opts.PharmacyHandler.AddHoliday,
+	)
+	pharmacyGroup.DELETE(
+		"/:slug/holidays/:id",
+		opts.PharmacyManagerAuthenticator,
+		opts.PharmacyHandler.DeleteHoliday,
+	)
 
 	googleGroup := apiV1Group.Group("/google")
 	googleGroup.GET(
@@ -273,6 +385,14 @@ func SetupServer(opts SetupServerOpts) *gin.Engine {
 		"/active-status",
 		opts.DoctorHandler.SetActiveStatus,
 	)
+	doctorProfileGroup.GET(
+		"/earnings",
+		opts.DoctorEarningHandler.GetStatement,
+	)
+	doctorProfileGroup.GET(
+		"/earnings/export",
+		opts.DoctorEarningHandler.ExportStatement,
+	)
 
 	specializationGroup := apiV1Group.Group(
 		"/specializations",
@@ -281,6 +401,10 @@ func SetupServer(opts SetupServerOpts) *gin.Engine {
 		".",
 		opts.SpecializationHandler.GetAll,
 	)
+	specializationGroup.GET(
+		"/:slug",
+		opts.SpecializationHandler.GetBySlug,
+	)
 
 	router.NoRoute(func(ctx *gin.Context) {
 		ctx.Error(apperror.NewAppError(
@@ -294,19 +418,35 @@ func SetupServer(opts SetupServerOpts) *gin.Engine {
 	categoryGroup := apiV1Group.Group("/categories")
 	categoryGroup.GET(".", opts.CategoryHandler.GetCategories)
 	categoryGroup.GET("/hierarchy", opts.CategoryHandler.GetCategoriesHierarchy)
+	categoryGroup.GET("/tree", opts.CategoryHandler.GetCategoryTree)
 	categoryGroup.GET("/:slug", opts.Authenticator, opts.CategoryHandler.GetCategoryBySlug)
 	categoryGroup.POST(".", opts.AdminAuthenticator, opts.CategoryHandler.CreateCategoryLevelOne)
 	categoryGroup.POST("/:slug", opts.AdminAuthenticator, opts.CategoryHandler.CreateCategoryLevelTwo)
 	categoryGroup.PATCH("/:slug", opts.AdminAuthenticator, opts.CategoryHandler.UpdateCategory)
+	categoryGroup.PUT("/:slug/move", opts.AdminAuthenticator, opts.CategoryHandler.MoveCategory)
+	categoryGroup.PUT("/order", opts.AdminAuthenticator, opts.CategoryHandler.ReorderCategories)
 	categoryGroup.DELETE("/:slug", opts.AdminAuthenticator, opts.CategoryHandler.DeleteCategory)
 
 	productGroup := apiV1Group.Group("/product")
 	productGroup.GET(".", opts.Authenticator, opts.ProductHandler.GetProductsFromArea)
 	productGroup.GET("/list", opts.ProductHandler.GetProducts)
+	productGroup.GET("/suggest", opts.ProductHandler.Suggest)
+	productGroup.GET("/export", opts.AdminAuthenticator, opts.ProductHandler.ExportCatalog)
+	productGroup.POST("/import", opts.AdminAuthenticator, opts.ProductHandler.ImportProducts)
 	productGroup.GET("/:slug", opts.Authenticator, opts.ProductHandler.GetProductBySlug)
+	productGroup.GET("/:slug/related", opts.Authenticator, opts.RelatedProductHandler.GetRelated)
 	productGroup.POST(".", opts.AdminAuthenticator, opts.ProductHandler.CreateProduct)
 	productGroup.PATCH(".", opts.AdminAuthenticator, opts.ProductHandler.UpdateProduct)
 	productGroup.DELETE("/:slug", opts.AdminAuthenticator, opts.ProductHandler.DeleteProduct)
+	productGroup.GET("/:slug/images", opts.ProductImageHandler.List)
+	productGroup.POST("/:slug/images", opts.AdminAuthenticator, opts.ProductImageHandler.Add)
+	productGroup.PUT("/:slug/images/order", opts.AdminAuthenticator, opts.ProductImageHandler.Reorder)
+	productGroup.PUT("/:slug/images/:id/primary", opts.AdminAuthenticator, opts.ProductImageHandler.SetPrimary)
+	productGroup.DELETE("/:slug/images/:id", opts.AdminAuthenticator, opts.ProductImageHandler.Delete)
+	productGroup.GET("/:slug/variants", opts.ProductVariantHandler.List)
+	productGroup.POST("/:slug/variants", opts.AdminAuthenticator, opts.ProductVariantHandler.Create)
+	productGroup.PUT("/:slug/variants/:id", opts.AdminAuthenticator, opts.ProductVariantHandler.Update)
+	productGroup.DELETE("/:slug/variants/:id", opts.AdminAuthenticator, opts.ProductVariantHandler.Delete)
 
 	stockGroup := apiV1Group.Group("/stocks")
 	stockGroup.GET(
@@ -334,6 +474,61 @@ func SetupServer(opts SetupServerOpts) *gin.Engine {
 		opts.PharmacyManagerAuthenticator,
 		opts.StockHandler.DeleteStock,
 	)
+	stockGroup.POST(
+		"/import",
+		opts.PharmacyManagerAuthenticator,
+		opts.StockHandler.ImportStocks,
+	)
+	stockGroup.GET(
+		"/batches/expiring",
+		opts.ManagerOrAdminAuthenticator,
+		opts.StockHandler.ListExpiringBatches,
+	)
+	stockGroup.GET(
+		"/:id/batches",
+		opts.ManagerOrAdminAuthenticator,
+		opts.StockHandler.ListBatches,
+	)
+	stockGroup.POST(
+		"/:id/batches",
+		opts.PharmacyManagerAuthenticator,
+		opts.StockHandler.ReceiveBatch,
+	)
+	stockGroup.POST(
+		"/:id/write-off",
+		opts.PharmacyManagerAuthenticator,
+		opts.StockHandler.WriteOffExpired,
+	)
+	stockGroup.GET(
+		"/ledger/check",
+		opts.AdminAuthenticator,
+		opts.StockHandler.CheckAllLedgers,
+	)
+	stockGroup.GET(
+		"/:id/ledger",
+		opts.ManagerOrAdminAuthenticator,
+		opts.StockHandler.ListLedger,
+	)
+	stockGroup.GET(
+		"/:id/ledger/check",
+		opts.ManagerOrAdminAuthenticator,
+		opts.StockHandler.CheckLedger,
+	)
+	stockGroup.GET(
+		"/:id/prices",
+		opts.ManagerOrAdminAuthenticator,
+		opts.StockHandler.ListPrices,
+	)
+	stockGroup.POST(
+		"/:id/prices",
+		opts.PharmacyManagerAuthenticator,
+		opts.StockHandler.SchedulePrice,
+	)
+	stockGroup.POST(
+		"/:id/prices/:price_id/cancel",
+		opts.PharmacyManagerAuthenticator,
+		opts.StockHandler.CancelScheduledPrice,
+	)
 
 	mutationGroup := stockGroup.Group("/mutations")
 	mutationGroup.GET(
@@ -362,6 +557,92 @@ func SetupServer(opts SetupServerOpts) *gin.Engine {
 		opts.StockHandler.CancelTransfer,
 	)
 
+	stockTakeGroup := apiV1Group.Group("/stock-takes")
+	stockTakeGroup.GET(
+		".",
+		opts.PharmacyManagerAuthenticator,
+		opts.StockTakeHandler.List,
+	)
+	stockTakeGroup.POST(
+		".",
+		opts.PharmacyManagerAuthenticator,
+		opts.StockTakeHandler.Open,
+	)
+	stockTakeGroup.GET(
+		"/:id",
+		opts.PharmacyManagerAuthenticator,
+		opts.StockTakeHandler.GetReport,
+	)
+	stockTakeGroup.POST(
+		"/:id/counts",
+		opts.PharmacyManagerAuthenticator,
+		opts.StockTakeHandler.SubmitCounts,
+	)
+	stockTakeGroup.POST(
+		"/:id/close",
+		opts.PharmacyManagerAuthenticator,
+		opts.StockTakeHandler.Close,
+	)
+	stockTakeGroup.POST(
+		"/:id/cancel",
+		opts.PharmacyManagerAuthenticator,
+		opts.StockTakeHandler.Cancel,
+	)
+
+	promotionGroup := apiV1Group.Group("/promotions")
+	promotionGroup.GET(
+		".",
+		opts.AdminAuthenticator,
+		opts.PromotionHandler.List,
+	)
+	promotionGroup.GET(
+		"/:id",
+		opts.AdminAuthenticator,
+		opts.PromotionHandler.GetByID,
+	)
+	promotionGroup.POST(
+		".",
+		opts.AdminAuthenticator,
+		opts.PromotionHandler.Create,
+	)
+	promotionGroup.PUT(
+		"/:id",
+		opts.AdminAuthenticator,
+		opts.PromotionHandler.Update,
+	)
+	promotionGroup.DELETE(
+		"/:id",
+		opts.AdminAuthenticator,
+		opts.PromotionHandler.Delete,
+	)
+
+	voucherGroup := apiV1Group.Group("/vouchers")
+	voucherGroup.GET(
+		".",
+		opts.AdminAuthenticator,
+		opts.VoucherHandler.List,
+	)
+	voucherGroup.GET(
+		"/:id",
+		opts.AdminAuthenticator,
+		opts.VoucherHandler.GetByID,
+	)
+	voucherGroup.POST(
+		".",
+		opts.AdminAuthenticator,
+		opts.VoucherHandler.Create,
+	)
+	voucherGroup.PUT(
+		"/:id",
+		opts.AdminAuthenticator,
+		opts.VoucherHandler.Update,
+	)
+	voucherGroup.DELETE(
+		"/:id",
+		opts.AdminAuthenticator,
+		opts.VoucherHandler.Delete,
+	)
+
 	paymentGroup := apiV1Group.Group("/payments")
 	paymentGroup.GET(
 		".",
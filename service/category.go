@@ -15,85 +15,189 @@ import (
 type categoryService struct {
 	dataRepository domain.DataRepository
 	cloud          util.CloudinaryProvider
+	suggestSrv     domain.ProductSuggestService
 }
 
 type CategoryServiceOpts struct {
 	DataRepository domain.DataRepository
 	Cloud          util.CloudinaryProvider
+	SuggestSrv     domain.ProductSuggestService
 }
 
 func NewCategoryService(opts CategoryServiceOpts) *categoryService {
 	return &categoryService{
 		dataRepository: opts.DataRepository,
 		cloud:          opts.Cloud,
+		suggestSrv:     opts.SuggestSrv,
 	}
 }
 
-func (s *categoryService) CreateCategoryLevelOne(ctx context.Context, category domain.Category, file *multipart.File) (domain.Category, error) {
-	categoryRepo := s.dataRepository.CategoryRepository()
-	category.Name = strings.TrimSpace(strings.ToLower(category.Name))
-	category.Slug = util.GenerateSlug(category.Name)
+func (s *categoryService) invalidateSuggestions() {
+	if s.suggestSrv != nil {
+		s.suggestSrv.Invalidate()
+	}
+}
 
-	c, err := categoryRepo.GetByName(ctx, category.Name)
-	if err != nil && !apperror.IsErrorCode(err, apperror.CodeNotFound) {
-		return domain.Category{}, apperror.Wrap(err)
+func categoryPath(parent *domain.Category, id int64) string {
+	prefix := "/"
+	if parent != nil {
+		prefix = parent.Path
 	}
+	return fmt.Sprintf("%s%d/", prefix, id)
+}
 
-	if c.Name == category.Name {
-		return domain.Category{}, apperror.NewAlreadyExists("category")
+func categoryDepth(parent *domain.Category) int64 {
+	if parent == nil {
+		return 1
 	}
+	return parent.Depth + 1
+}
 
-	if file != nil {
-		res, err := s.cloud.UploadImage(ctx, *file, uploader.UploadParams{})
-		if err == nil {
-			category.PhotoUrl = &res.SecureURL
+func categoryParentID(parent *domain.Category) *int64 {
+	if parent == nil {
+		return nil
+	}
+	return &parent.ID
+}
+
+func (s *categoryService) getCategoryBySlug(ctx context.Context, dr domain.DataRepository, slug string) (domain.Category, error) {
+	c, err := dr.CategoryRepository().GetBySlug(ctx, slug)
+	if err != nil {
+		if apperror.IsErrorCode(err, apperror.CodeNotFound) {
+			return domain.Category{}, apperror.NewEntityNotFound(fmt.Sprintf("category with slug %s", slug))
 		}
+		return domain.Category{}, apperror.Wrap(err)
 	}
+	return c, nil
+}
 
-	savedCategory, err := categoryRepo.Add(ctx, category)
+// getCategoryBySlugAndLock locks the category for the rest of the
+// transaction. Its path can't change until then, as moving any of its
+// ancestors rewrites its row too.
+func (s *categoryService) getCategoryBySlugAndLock(ctx context.Context, dr domain.DataRepository, slug string) (domain.Category, error) {
+	c, err := dr.CategoryRepository().GetBySlugAndLock(ctx, slug)
 	if err != nil {
+		if apperror.IsErrorCode(err, apperror.CodeNotFound) {
+			return domain.Category{}, apperror.NewEntityNotFound(fmt.Sprintf("category with slug %s", slug))
+		}
 		return domain.Category{}, apperror.Wrap(err)
 	}
+	return c, nil
+}
 
-	return savedCategory, nil
+func (s *categoryService) checkCategoryName(ctx context.Context, dr domain.DataRepository, name string) error {
+	c, err := dr.CategoryRepository().GetByName(ctx, name)
+	if err != nil && !apperror.IsErrorCode(err, apperror.CodeNotFound) {
+		return apperror.Wrap(err)
+	}
+
+	if c.Name == name {
+		return apperror.NewAlreadyExists("category")
+	}
+	return nil
 }
 
-func (s *categoryService) CreateCategoryLevelTwo(ctx context.Context, category domain.Category, parentSlug string) (domain.CategoryWithParentName, error) {
-	categoryRepo := s.dataRepository.CategoryRepository()
+// AddCategoryClosure adds category under the category with parentSlug, or at
+// the top level when parentSlug is nil. The parent is locked so it can't be
+// moved or deleted while its path is copied.
+func (s *categoryService) AddCategoryClosure(
+	ctx context.Context,
+	category domain.Category,
+	parentSlug *string,
+) domain.AtomicFunc[domain.CategoryWithParentName] {
+	return func(dr domain.DataRepository) (domain.CategoryWithParentName, error) {
+		categoryRepo := dr.CategoryRepository()
+
+		var parent *domain.Category
+		if parentSlug != nil {
+			p, err := s.getCategoryBySlugAndLock(ctx, dr, *parentSlug)
+			if err != nil {
+				return domain.CategoryWithParentName{}, err
+			}
+			parent = &p
+		}
+
+		category.ParentID = categoryParentID(parent)
+		category.Depth = categoryDepth(parent)
+
+		position, err := categoryRepo.GetNextPosition(ctx, category.ParentID)
+		if err != nil {
+			return domain.CategoryWithParentName{}, apperror.Wrap(err)
+		}
+		category.Position = position
+
+		savedCategory, err := categoryRepo.Add(ctx, category)
+		if err != nil {
+			return domain.CategoryWithParentName{}, apperror.Wrap(err)
+		}
+
+		savedCategory.Path = categoryPath(parent, savedCategory.ID)
+		err = categoryRepo.UpdatePath(ctx, savedCategory.ID, savedCategory.Path)
+		if err != nil {
+			return domain.CategoryWithParentName{}, apperror.Wrap(err)
+		}
+
+		res := domain.CategoryWithParentName{Category: savedCategory}
+		if parent != nil {
+			res.ParentName = &parent.Name
+		}
+		return res, nil
+	}
+}
+
+func (s *categoryService) CreateCategoryLevelOne(ctx context.Context, category domain.Category, file *multipart.File) (domain.Category, error) {
 	category.Name = strings.TrimSpace(strings.ToLower(category.Name))
 	category.Slug = util.GenerateSlug(category.Name)
 
-	c, err := categoryRepo.GetByName(ctx, category.Name)
-	if err != nil && !apperror.IsErrorCode(err, apperror.CodeNotFound) {
-		return domain.CategoryWithParentName{}, apperror.Wrap(err)
+	err := s.checkCategoryName(ctx, s.dataRepository, category.Name)
+	if err != nil {
+		return domain.Category{}, err
 	}
 
-	if c.Name == category.Name {
-		return domain.CategoryWithParentName{}, apperror.NewAlreadyExists("category")
+	if file != nil {
+		res, err := s.cloud.UploadImage(ctx, *file, uploader.UploadParams{})
+		if err == nil {
+			category.PhotoUrl = &res.SecureURL
+		}
 	}
 
-	c, err = categoryRepo.GetBySlug(ctx, parentSlug)
+	savedCategory, err := domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.AddCategoryClosure(ctx, category, nil),
+	)
 	if err != nil {
-		if apperror.IsErrorCode(err, apperror.CodeNotFound) {
-			return domain.CategoryWithParentName{}, apperror.NewEntityNotFound(fmt.Sprintf(`category with slug %s`, parentSlug))
-		}
-		return domain.CategoryWithParentName{}, apperror.Wrap(err)
+		return domain.Category{}, err
 	}
 
-	if c.ParentID != nil {
-		return domain.CategoryWithParentName{}, apperror.NewCreateCategoryParentRestrict()
+	s.invalidateSuggestions()
+
+	return savedCategory.Category, nil
+}
+
+// CreateCategoryLevelTwo creates a child of the category with parentSlug. The
+// parent may sit at any depth of the tree.
+func (s *categoryService) CreateCategoryLevelTwo(ctx context.Context, category domain.Category, parentSlug string) (domain.CategoryWithParentName, error) {
+	category.Name = strings.TrimSpace(strings.ToLower(category.Name))
+	category.Slug = util.GenerateSlug(category.Name)
+
+	err := s.checkCategoryName(ctx, s.dataRepository, category.Name)
+	if err != nil {
+		return domain.CategoryWithParentName{}, err
 	}
 
-	category.ParentID = &c.ID
-	savedCategory, err := categoryRepo.Add(ctx, category)
+	savedCategory, err := domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.AddCategoryClosure(ctx, category, &parentSlug),
+	)
 	if err != nil {
-		return domain.CategoryWithParentName{}, apperror.Wrap(err)
+		return domain.CategoryWithParentName{}, err
 	}
 
-	return domain.CategoryWithParentName{
-		Category:   savedCategory,
-		ParentName: &c.Name,
-	}, nil
+	s.invalidateSuggestions()
+
+	return savedCategory, nil
 
 }
 
@@ -146,79 +250,251 @@ func (s *categoryService) GetCategoryBySlug(ctx context.Context, slug string) (d
 	return categories, nil
 }
 
-func (s *categoryService) DeleteCategory(ctx context.Context, slug string) error {
+func (s *categoryService) GetCategoryTree(ctx context.Context) ([]domain.CategoryTreeNode, error) {
 	categoryRepo := s.dataRepository.CategoryRepository()
 
-	c, err := categoryRepo.GetBySlug(ctx, slug)
+	nodes, err := categoryRepo.GetTreeWithProductCounts(ctx)
 	if err != nil {
-		return apperror.Wrap(err)
+		return nil, apperror.Wrap(err)
 	}
 
-	if c.ParentID != nil {
-		err = categoryRepo.SoftDeleteBySlug(ctx, slug)
-		if err != nil {
-			return apperror.Wrap(err)
+	return buildCategoryTree(nodes), nil
+}
+
+// buildCategoryTree nests the flat node list, which is ordered by depth and
+// position, under each node's parent.
+func buildCategoryTree(nodes []domain.CategoryTreeNode) []domain.CategoryTreeNode {
+	childIdx := map[int64][]int{}
+	rootIdx := []int{}
+	for i := 0; i < len(nodes); i++ {
+		parentID := nodes[i].Category.ParentID
+		if parentID == nil {
+			rootIdx = append(rootIdx, i)
+			continue
 		}
-		return nil
+		childIdx[*parentID] = append(childIdx[*parentID], i)
 	}
 
-	q := domain.DefaultCategoriesQuery()
-	q.ParentSlug = slug
-	childs, err := categoryRepo.GetCategoriesWithParentName(ctx, q)
-	if err != nil {
-		return apperror.Wrap(err)
+	var build func(i int) domain.CategoryTreeNode
+	build = func(i int) domain.CategoryTreeNode {
+		n := nodes[i]
+		idx := childIdx[n.Category.ID]
+		n.Children = make([]domain.CategoryTreeNode, len(idx))
+		for j := 0; j < len(idx); j++ {
+			n.Children[j] = build(idx[j])
+		}
+		return n
+	}
+
+	res := make([]domain.CategoryTreeNode, len(rootIdx))
+	for i := 0; i < len(rootIdx); i++ {
+		res[i] = build(rootIdx[i])
 	}
+	return res
+}
 
-	slugs := make([]string, len(childs)+1)
-	slugs[0] = slug
-	for i := 0; i < len(childs); i++ {
-		slugs[i+1] = childs[i].Category.Slug
+func (s *categoryService) DeleteCategory(ctx context.Context, slug string) error {
+	categoryRepo := s.dataRepository.CategoryRepository()
+
+	c, err := categoryRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return apperror.Wrap(err)
 	}
 
-	err = categoryRepo.BulkSoftDeleteBySlug(ctx, slugs)
+	err = categoryRepo.SoftDeleteSubtree(ctx, c.Path)
 	if err != nil {
 		return apperror.Wrap(err)
 	}
+
+	s.invalidateSuggestions()
 	return nil
 }
 
-func (s *categoryService) UpdateCategory(ctx context.Context, category domain.Category, file *multipart.File) (domain.Category, error) {
-	categoryRepo := s.dataRepository.CategoryRepository()
+// moveCategory moves c and its whole subtree under parent, or to the top
+// level when parent is nil. The moved category is placed last among its new
+// siblings.
+func (s *categoryService) moveCategory(ctx context.Context, dr domain.DataRepository, c domain.Category, parent *domain.Category) (domain.Category, error) {
+	categoryRepo := dr.CategoryRepository()
+
+	if parent != nil && strings.HasPrefix(parent.Path, c.Path) {
+		return domain.Category{}, apperror.NewCategoryMoveCycle()
+	}
 
-	c, err := categoryRepo.GetBySlug(ctx, category.Slug)
+	parentID := categoryParentID(parent)
+	if (parentID == nil && c.ParentID == nil) ||
+		(parentID != nil && c.ParentID != nil && *parentID == *c.ParentID) {
+		return c, nil
+	}
+
+	position, err := categoryRepo.GetNextPosition(ctx, parentID)
 	if err != nil {
-		if apperror.IsErrorCode(err, apperror.CodeNotFound) {
-			return domain.Category{}, apperror.NewEntityNotFound(fmt.Sprintf("category with slug %s", category.Slug))
-		}
 		return domain.Category{}, apperror.Wrap(err)
 	}
 
-	if category.Name == "" {
-		category.Name = c.Name
+	err = categoryRepo.MoveSubtree(ctx, c, parentID, categoryPath(parent, c.ID), categoryDepth(parent), position)
+	if err != nil {
+		return domain.Category{}, apperror.Wrap(err)
+	}
+
+	moved, err := categoryRepo.GetById(ctx, c.ID)
+	if err != nil {
+		return domain.Category{}, apperror.Wrap(err)
 	}
 
-	if c.ParentID == nil {
-		category.ParentID = nil
+	return moved, nil
+}
+
+func (s *categoryService) MoveCategoryClosure(
+	ctx context.Context,
+	slug string,
+	parentSlug *string,
+) domain.AtomicFunc[domain.Category] {
+	return func(dr domain.DataRepository) (domain.Category, error) {
+		// Both ends of the move are locked, so the cycle check sees paths no
+		// concurrent move can change before this one commits.
+		c, err := s.getCategoryBySlugAndLock(ctx, dr, slug)
+		if err != nil {
+			return domain.Category{}, err
+		}
+
+		var parent *domain.Category
+		if parentSlug != nil {
+			p, err := s.getCategoryBySlugAndLock(ctx, dr, *parentSlug)
+			if err != nil {
+				return domain.Category{}, err
+			}
+			parent = &p
+		}
+
+		return s.moveCategory(ctx, dr, c, parent)
 	}
+}
 
-	if category.ParentID == nil && c.ParentID != nil {
-		category.ParentID = c.ParentID
+func (s *categoryService) MoveCategory(ctx context.Context, slug string, parentSlug *string) (domain.Category, error) {
+	category, err := domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.MoveCategoryClosure(ctx, slug, parentSlug),
+	)
+	if err != nil {
+		return domain.Category{}, err
 	}
 
-	if category.ParentID != nil && c.ParentID != nil {
-		cParent, err := categoryRepo.GetById(ctx, *category.ParentID)
+	s.invalidateSuggestions()
+
+	return category, nil
+}
+
+func (s *categoryService) ReorderCategoriesClosure(
+	ctx context.Context,
+	parentSlug *string,
+	slugs []string,
+) domain.AtomicFunc[[]domain.Category] {
+	return func(dr domain.DataRepository) ([]domain.Category, error) {
+		categoryRepo := dr.CategoryRepository()
+
+		var parentID *int64
+		if parentSlug != nil {
+			parent, err := s.getCategoryBySlug(ctx, dr, *parentSlug)
+			if err != nil {
+				return nil, err
+			}
+			parentID = &parent.ID
+		}
+
+		children, err := categoryRepo.GetChildren(ctx, parentID)
 		if err != nil {
-			if apperror.IsErrorCode(err, apperror.CodeNotFound) {
-				return domain.Category{}, apperror.NewEntityNotFound(fmt.Sprintf("category with id %d", *category.ParentID))
+			return nil, apperror.Wrap(err)
+		}
+
+		if len(children) != len(slugs) {
+			return nil, apperror.NewInvalidCategoryOrder()
+		}
+
+		ids := map[string]int64{}
+		for i := 0; i < len(children); i++ {
+			ids[children[i].Slug] = children[i].ID
+		}
+
+		for i := 0; i < len(slugs); i++ {
+			id, ok := ids[slugs[i]]
+			if !ok {
+				return nil, apperror.NewInvalidCategoryOrder()
+			}
+			delete(ids, slugs[i])
+
+			err = categoryRepo.UpdatePosition(ctx, id, int64(i+1))
+			if err != nil {
+				return nil, apperror.Wrap(err)
 			}
-			return domain.Category{}, apperror.Wrap(err)
 		}
 
-		if cParent.ParentID != nil {
-			return domain.Category{}, apperror.NewUpdateCategoryParentRestrict()
+		children, err = categoryRepo.GetChildren(ctx, parentID)
+		if err != nil {
+			return nil, apperror.Wrap(err)
 		}
+
+		return children, nil
 	}
+}
+
+func (s *categoryService) ReorderCategories(ctx context.Context, parentSlug *string, slugs []string) ([]domain.Category, error) {
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.ReorderCategoriesClosure(ctx, parentSlug, slugs),
+	)
+}
+
+func (s *categoryService) UpdateCategoryClosure(
+	ctx context.Context,
+	category domain.Category,
+	c domain.Category,
+) domain.AtomicFunc[domain.Category] {
+	return func(dr domain.DataRepository) (domain.Category, error) {
+		categoryRepo := dr.CategoryRepository()
+
+		if category.ParentID != nil && (c.ParentID == nil || *category.ParentID != *c.ParentID) {
+			c, err := s.getCategoryBySlugAndLock(ctx, dr, c.Slug)
+			if err != nil {
+				return domain.Category{}, err
+			}
+
+			parent, err := categoryRepo.GetByIdAndLock(ctx, *category.ParentID)
+			if err != nil {
+				if apperror.IsErrorCode(err, apperror.CodeNotFound) {
+					return domain.Category{}, apperror.NewEntityNotFound(fmt.Sprintf("category with id %d", *category.ParentID))
+				}
+				return domain.Category{}, apperror.Wrap(err)
+			}
 
+			_, err = s.moveCategory(ctx, dr, c, &parent)
+			if err != nil {
+				return domain.Category{}, err
+			}
+		}
+
+		category.ID = c.ID
+		updatedCategory, err := categoryRepo.Update(ctx, category)
+		if err != nil {
+			return domain.Category{}, apperror.Wrap(err)
+		}
+
+		return updatedCategory, nil
+	}
+}
+
+func (s *categoryService) UpdateCategory(ctx context.Context, category domain.Category, file *multipart.File) (domain.Category, error) {
+	c, err := s.getCategoryBySlug(ctx, s.dataRepository, category.Slug)
+	if err != nil {
+		return domain.Category{}, err
+	}
+
+	if category.Name == "" {
+		category.Name = c.Name
+	}
+
+	category.PhotoUrl = c.PhotoUrl
 	if file != nil && c.ParentID == nil {
 		res, err := s.cloud.UploadImage(ctx, *file, uploader.UploadParams{})
 		if err == nil {
@@ -226,13 +502,18 @@ func (s *categoryService) UpdateCategory(ctx context.Context, category domain.Ca
 		}
 	}
 
-	category.ID = c.ID
 	category.Name = strings.TrimSpace(strings.ToLower(category.Name))
 	category.Slug = util.GenerateSlug(category.Name)
-	updatedCategory, err := categoryRepo.Update(ctx, category)
+	updatedCategory, err := domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.UpdateCategoryClosure(ctx, category, c),
+	)
 	if err != nil {
-		return domain.Category{}, apperror.Wrap(err)
+		return domain.Category{}, err
 	}
 
+	s.invalidateSuggestions()
+
 	return updatedCategory, nil
 }
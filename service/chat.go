@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"html/template"
+	"medichat-be/apperror"
 	"medichat-be/constants"
 	"medichat-be/domain"
 	"medichat-be/dto"
@@ -23,7 +24,7 @@ type ChatService interface {
 	CreateRoom(doctorId int,ctx *gin.Context) (error)
 	CloseRoom(roomId string,ctx *gin.Context) (error)
 	CreateNote(userId int64, roomId,message string,ctx *gin.Context) (error)
-	Prescribe(req *dto.ChatPrescription,roomId string,ctx *gin.Context) (error)
+	Prescribe(req *dto.ChatPrescription,roomId string,ctx *gin.Context) ([]domain.DrugInteractionWarning, error)
 }
 
 type chatService struct {
@@ -54,32 +55,48 @@ func NewChatService(opts ChatServiceOpts) *chatService {
 	}
 }
 
-func (u *chatService) Prescribe(req *dto.ChatPrescription,roomId string,ctx *gin.Context) (error) {
+func (u *chatService) Prescribe(req *dto.ChatPrescription,roomId string,ctx *gin.Context) ([]domain.DrugInteractionWarning, error) {
 
 	doctorRepository := u.dataRepository.DoctorRepository()
 	productRepository := u.dataRepository.ProductRepository()
+	productDetailRepository := u.dataRepository.ProductDetailsRepository()
+	chatRepository := u.dataRepository.ChatRepository()
 
 	doctorId,err := util.GetAccountIDFromContext(ctx);
 	if err!= nil {
-		return err
+		return nil, err
     }
 	doctor,err:= doctorRepository.GetByAccountID(ctx,doctorId)
 	if err!= nil {
-		return err
+		return nil, err
     }
 
+	room_id,err := strconv.Atoi(roomId)
 	if err!= nil {
-        return err
-    }
+		return nil, apperror.NewBadRequest(err)
+	}
+	room,err := chatRepository.GetRoomByID(ctx,int64(room_id))
+	if err!= nil {
+		return nil, err
+	}
+	if room.DoctorId != doctor.ID {
+		return nil, apperror.NewForbidden(nil)
+	}
 	now := time.Now()
 
 	var drugs []map[string]interface{}
+	var interactionProducts []domain.DrugInteractionProduct
 
 	for i := 0; i < len(req.Drugs); i++ {
 		prod,err:= productRepository.GetById(ctx,int64(req.Drugs[i].ProductId))
 		if err!= nil {
-            return err
+            return nil, err
         }
+		detail,err := productDetailRepository.GetById(ctx,prod.ProductDetailId)
+		if err!= nil {
+			return nil, err
+		}
+		interactionProducts = append(interactionProducts, newDrugInteractionProduct(prod,detail))
 		drugs = append(drugs, map[string]interface{}{
 			"id": req.Drugs[i].ProductId,
 			"name": prod.Name,
@@ -90,14 +107,45 @@ func (u *chatService) Prescribe(req *dto.ChatPrescription,roomId string,ctx *gin
 		})
 	}
 
+	warnings,err := checkDrugInteractions(ctx,u.dataRepository,&room.UserId,interactionProducts)
+	if err!= nil {
+		return nil, err
+	}
+
+	blocking := blockingDrugInteractions(warnings)
+	if len(blocking) > 0 && !req.OverrideInteractions {
+		return nil, newSevereDrugInteractionError(blocking)
+	}
+
+	// The overrides are only saved once the prescription is sent, a failed
+	// prescription must not clear the interactions for later checks.
+	overrides := make([]domain.DrugInteractionOverride, 0, len(blocking))
+	overridden := map[[2]string]bool{}
+	for i := range blocking {
+		overrides = append(overrides, domain.DrugInteractionOverride{
+			UserID: room.UserId,
+			DoctorID: doctor.ID,
+			RoomID: room.ID,
+			IngredientA: blocking[i].IngredientA,
+			IngredientB: blocking[i].IngredientB,
+			ExpiresAt: now.Add(constants.DrugInteractionOverrideDuration),
+		})
+		overridden[[2]string{blocking[i].IngredientA, blocking[i].IngredientB}] = true
+	}
+	for i := range warnings {
+		if overridden[[2]string{warnings[i].IngredientA, warnings[i].IngredientB}] {
+			warnings[i].Overridden = true
+		}
+	}
 
 	prescription := map[string]interface{}{
 		"drugs":drugs,
+		"warnings":util.MapSlice(warnings, dto.NewDrugInteractionWarningResponse),
 	}
 
 	json,err := json.Marshal(prescription)
 	if err!= nil {
-        return err
+        return nil, err
     }
 
 	colRef := u.client.Collection("rooms");
@@ -111,9 +159,18 @@ func (u *chatService) Prescribe(req *dto.ChatPrescription,roomId string,ctx *gin
 	}
 	_,_,err = colRef.Doc(roomId).Collection("chats").Add(ctx, content)
 	if err!= nil {
-        return err
+        return nil, err
     }
-	return nil
+
+	_, err = domain.RunAtomic(
+		u.dataRepository,
+		ctx,
+		addDrugInteractionOverridesClosure(ctx, overrides),
+	)
+	if err!= nil {
+		return nil, err
+	}
+	return warnings, nil
 }
 
 func (u *chatService) CreateNote(userId int64, roomId,message string,ctx *gin.Context) (error){
@@ -236,6 +293,9 @@ func (u *chatService) CreateRoom(doctorId int,ctx *gin.Context) (error) {
 	if err != nil {
 		return err
 	}
+	if doctor.VerificationStatus != domain.DoctorVerificationApproved {
+		return apperror.NewEntityNotFound("doctor")
+	}
 	req.DoctorName = doctor.Account.Name
 
 
@@ -250,6 +310,7 @@ func (u *chatService) CreateRoom(doctorId int,ctx *gin.Context) (error) {
 		req.UserId,
 		req.DoctorId,
 		req.End,
+		doctor.Price,
 	)
 	if err != nil{
 		return err
@@ -333,6 +394,15 @@ func (u *chatService) CloseRoom(roomId string,ctx *gin.Context) (error) {
         return err
     }
 
+	_, err = domain.RunAtomic(
+		u.dataRepository,
+		ctx,
+		recordDoctorEarningClosure(ctx, int64(room_id)),
+	)
+	if err != nil {
+		return err
+	}
+
 	return nil
 
 }
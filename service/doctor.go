@@ -4,7 +4,9 @@ import (
 	"context"
 	"medichat-be/apperror"
 	"medichat-be/domain"
+	"medichat-be/logger"
 	"medichat-be/util"
+	"strings"
 
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 )
@@ -12,17 +14,26 @@ import (
 type doctorService struct {
 	dataRepository domain.DataRepository
 	cloudProvider  util.CloudinaryProvider
+	appEmail       util.AppEmail
+	emailProvider  util.EmailProvider
+	logger         logger.Logger
 }
 
 type DoctorServiceOpts struct {
 	DataRepository domain.DataRepository
 	CloudProvider  util.CloudinaryProvider
+	AppEmail       util.AppEmail
+	EmailProvider  util.EmailProvider
+	Logger         logger.Logger
 }
 
 func NewDoctorService(opts DoctorServiceOpts) *doctorService {
 	return &doctorService{
 		dataRepository: opts.DataRepository,
 		cloudProvider:  opts.CloudProvider,
+		appEmail:       opts.AppEmail,
+		emailProvider:  opts.EmailProvider,
+		logger:         opts.Logger,
 	}
 }
 
@@ -50,10 +61,92 @@ func (s *doctorService) GetByID(
 	if err != nil {
 		return domain.Doctor{}, apperror.Wrap(err)
 	}
+	if doctor.VerificationStatus != domain.DoctorVerificationApproved {
+		return domain.Doctor{}, apperror.NewEntityNotFound("doctor")
+	}
+
+	doctor, err = s.loadProfileDetails(ctx, s.dataRepository, doctor)
+	if err != nil {
+		return domain.Doctor{}, apperror.Wrap(err)
+	}
 
 	return doctor, err
 }
 
+func (s *doctorService) loadProfileDetails(
+	ctx context.Context,
+	dr domain.DataRepository,
+	doctor domain.Doctor,
+) (domain.Doctor, error) {
+	profileRepo := dr.DoctorProfileRepository()
+
+	educations, err := profileRepo.ListEducationsByDoctorID(ctx, doctor.ID)
+	if err != nil {
+		return domain.Doctor{}, apperror.Wrap(err)
+	}
+	doctor.Educations = educations
+
+	practices, err := profileRepo.ListPracticesByDoctorID(ctx, doctor.ID)
+	if err != nil {
+		return domain.Doctor{}, apperror.Wrap(err)
+	}
+	doctor.Practices = practices
+
+	languages, err := profileRepo.ListLanguagesByDoctorID(ctx, doctor.ID)
+	if err != nil {
+		return domain.Doctor{}, apperror.Wrap(err)
+	}
+	doctor.Languages = languages
+
+	return doctor, nil
+}
+
+func (s *doctorService) saveProfileDetails(
+	ctx context.Context,
+	dr domain.DataRepository,
+	doctor domain.Doctor,
+	dets domain.DoctorUpdateDetails,
+) (domain.Doctor, error) {
+	profileRepo := dr.DoctorProfileRepository()
+
+	if dets.Educations != nil {
+		educations, err := profileRepo.ReplaceEducations(ctx, doctor.ID, *dets.Educations)
+		if err != nil {
+			return domain.Doctor{}, apperror.Wrap(err)
+		}
+		doctor.Educations = educations
+	}
+
+	if dets.Practices != nil {
+		practices, err := profileRepo.ReplacePractices(ctx, doctor.ID, *dets.Practices)
+		if err != nil {
+			return domain.Doctor{}, apperror.Wrap(err)
+		}
+		doctor.Practices = practices
+	}
+
+	if dets.Languages != nil {
+		languages := make([]string, 0, len(*dets.Languages))
+		seen := make(map[string]bool)
+		for _, l := range *dets.Languages {
+			key := strings.ToLower(l)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			languages = append(languages, l)
+		}
+
+		languages, err := profileRepo.ReplaceLanguages(ctx, doctor.ID, languages)
+		if err != nil {
+			return domain.Doctor{}, apperror.Wrap(err)
+		}
+		doctor.Languages = languages
+	}
+
+	return doctor, nil
+}
+
 func (s *doctorService) CreateClosure(
 	ctx context.Context,
 	dets domain.DoctorCreateDetails,
@@ -104,6 +197,8 @@ func (s *doctorService) CreateClosure(
 			IsActive:      dets.IsActive,
 			StartWorkDate: dets.StartWorkDate,
 			Price:         dets.Price,
+
+			VerificationStatus: domain.DoctorVerificationPending,
 		}
 
 		if dets.Certificate == nil {
@@ -196,6 +291,14 @@ func (s *doctorService) UpdateClosure(
 
 		doctor.ApplyUpdate(dets)
 
+		if dets.Certificate != nil {
+			res, err := s.cloudProvider.UploadImage(ctx, dets.Certificate, uploader.UploadParams{})
+			if err != nil {
+				return domain.Doctor{}, apperror.Wrap(err)
+			}
+			doctor.CertificateURL = res.SecureURL
+		}
+
 		account, err = accountRepo.Update(ctx, account)
 		if err != nil {
 			return domain.Doctor{}, apperror.Wrap(err)
@@ -206,6 +309,21 @@ func (s *doctorService) UpdateClosure(
 			return domain.Doctor{}, apperror.Wrap(err)
 		}
 
+		doctor, err = s.saveProfileDetails(ctx, dr, doctor, dets)
+		if err != nil {
+			return domain.Doctor{}, apperror.Wrap(err)
+		}
+
+		if dets.Certificate != nil {
+			doctor.VerificationStatus = domain.DoctorVerificationPending
+			doctor.RejectionReason = nil
+
+			doctor, err = doctorRepo.UpdateVerification(ctx, doctor)
+			if err != nil {
+				return domain.Doctor{}, apperror.Wrap(err)
+			}
+		}
+
 		return doctor, nil
 	}
 }
@@ -234,6 +352,11 @@ func (s *doctorService) GetProfile(ctx context.Context) (domain.Doctor, error) {
 		return domain.Doctor{}, apperror.Wrap(err)
 	}
 
+	doctor, err = s.loadProfileDetails(ctx, s.dataRepository, doctor)
+	if err != nil {
+		return domain.Doctor{}, apperror.Wrap(err)
+	}
+
 	return doctor, nil
 }
 
@@ -287,3 +410,131 @@ func (s *doctorService) SetActiveStatus(
 	)
 	return err
 }
+
+func (s *doctorService) ListVerifications(
+	ctx context.Context,
+	dets domain.DoctorVerificationListDetails,
+) ([]domain.Doctor, domain.PageInfo, error) {
+	doctorRepo := s.dataRepository.DoctorRepository()
+
+	page, err := doctorRepo.GetVerificationPageInfo(ctx, dets)
+	if err != nil {
+		return nil, domain.PageInfo{}, apperror.Wrap(err)
+	}
+
+	doctors, err := doctorRepo.ListVerifications(ctx, dets)
+	if err != nil {
+		return nil, domain.PageInfo{}, apperror.Wrap(err)
+	}
+
+	return doctors, page, nil
+}
+
+func (s *doctorService) GetVerificationByID(
+	ctx context.Context,
+	id int64,
+) (domain.Doctor, error) {
+	doctorRepo := s.dataRepository.DoctorRepository()
+
+	doctor, err := doctorRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Doctor{}, apperror.Wrap(err)
+	}
+
+	return doctor, nil
+}
+
+func (s *doctorService) VerifyClosure(
+	ctx context.Context,
+	id int64,
+	status string,
+	reason *string,
+) domain.AtomicFunc[domain.Doctor] {
+	return func(dr domain.DataRepository) (domain.Doctor, error) {
+		accountRepo := dr.AccountRepository()
+		doctorRepo := dr.DoctorRepository()
+
+		accountID, err := util.GetAccountIDFromContext(ctx)
+		if err != nil {
+			return domain.Doctor{}, apperror.Wrap(err)
+		}
+
+		account, err := accountRepo.GetByID(ctx, accountID)
+		if err != nil {
+			return domain.Doctor{}, apperror.Wrap(err)
+		}
+		if account.Role != domain.AccountRoleAdmin {
+			return domain.Doctor{}, apperror.NewForbidden(nil)
+		}
+
+		doctor, err := doctorRepo.GetByIDAndLock(ctx, id)
+		if err != nil {
+			return domain.Doctor{}, apperror.Wrap(err)
+		}
+		if doctor.VerificationStatus != domain.DoctorVerificationPending {
+			return domain.Doctor{}, apperror.NewNotPending(nil)
+		}
+
+		doctor.VerificationStatus = status
+		doctor.RejectionReason = reason
+
+		doctor, err = doctorRepo.UpdateVerification(ctx, doctor)
+		if err != nil {
+			return domain.Doctor{}, apperror.Wrap(err)
+		}
+
+		return doctor, nil
+	}
+}
+
+// verify saves the verification result and then tells the doctor. The
+// email is only sent once the result is committed, so failing to send it
+// is logged rather than failing a verification that already happened.
+func (s *doctorService) verify(
+	ctx context.Context,
+	id int64,
+	status string,
+	reason *string,
+) error {
+	doctor, err := domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.VerifyClosure(ctx, id, status, reason),
+	)
+	if err != nil {
+		return err
+	}
+
+	reasonStr := ""
+	if reason != nil {
+		reasonStr = *reason
+	}
+
+	err = s.emailProvider.SendEmail(
+		doctor.Account.Email,
+		s.appEmail.NewDoctorVerificationEmail(
+			doctor.Account.Name,
+			status == domain.DoctorVerificationApproved,
+			reasonStr,
+		),
+	)
+	if err != nil {
+		s.logger.Errorf("sending verification email of doctor %d: %v", id, err)
+	}
+
+	return nil
+}
+
+func (s *doctorService) Approve(
+	ctx context.Context,
+	id int64,
+) error {
+	return s.verify(ctx, id, domain.DoctorVerificationApproved, nil)
+}
+
+func (s *doctorService) Reject(
+	ctx context.Context,
+	dets domain.DoctorRejectDetails,
+) error {
+	return s.verify(ctx, dets.ID, domain.DoctorVerificationRejected, &dets.Reason)
+}
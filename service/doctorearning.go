@@ -0,0 +1,356 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"medichat-be/apperror"
+	"medichat-be/constants"
+	"medichat-be/domain"
+	"medichat-be/logger"
+	"medichat-be/util"
+	"strconv"
+	"time"
+)
+
+type doctorEarningService struct {
+	dataRepository    domain.DataRepository
+	pdfProvider       util.PDFProvider
+	logger            logger.Logger
+	statementTemplate *template.Template
+}
+
+type DoctorEarningServiceOpts struct {
+	DataRepository domain.DataRepository
+	PDFProvider    util.PDFProvider
+	Logger         logger.Logger
+}
+
+func NewDoctorEarningService(opts DoctorEarningServiceOpts) (*doctorEarningService, error) {
+	statementTemplate, err := template.ParseFiles("templates/earning-statement.html")
+	if err != nil {
+		return nil, err
+	}
+
+	return &doctorEarningService{
+		dataRepository:    opts.DataRepository,
+		pdfProvider:       opts.PDFProvider,
+		logger:            opts.Logger,
+		statementTemplate: statementTemplate,
+	}, nil
+}
+
+func (s *doctorEarningService) getStatement(
+	ctx context.Context,
+	month time.Time,
+) (domain.Doctor, domain.DoctorEarningStatement, error) {
+	doctorRepo := s.dataRepository.DoctorRepository()
+	earningRepo := s.dataRepository.DoctorEarningRepository()
+
+	accountID, err := util.GetAccountIDFromContext(ctx)
+	if err != nil {
+		return domain.Doctor{}, domain.DoctorEarningStatement{}, apperror.Wrap(err)
+	}
+
+	doctor, err := doctorRepo.GetByAccountID(ctx, accountID)
+	if err != nil {
+		return domain.Doctor{}, domain.DoctorEarningStatement{}, apperror.Wrap(err)
+	}
+
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	end := start.AddDate(0, 1, 0)
+
+	entries, err := earningRepo.ListByDoctorID(ctx, doctor.ID, start, end)
+	if err != nil {
+		return domain.Doctor{}, domain.DoctorEarningStatement{}, apperror.Wrap(err)
+	}
+
+	return doctor, domain.NewDoctorEarningStatement(doctor.ID, start, entries), nil
+}
+
+func (s *doctorEarningService) GetStatement(
+	ctx context.Context,
+	month time.Time,
+) (domain.DoctorEarningStatement, error) {
+	_, statement, err := s.getStatement(ctx, month)
+	if err != nil {
+		return domain.DoctorEarningStatement{}, apperror.Wrap(err)
+	}
+
+	return statement, nil
+}
+
+func (s *doctorEarningService) ExportStatement(
+	ctx context.Context,
+	month time.Time,
+	format string,
+) (domain.ExportFile, error) {
+	doctor, statement, err := s.getStatement(ctx, month)
+	if err != nil {
+		return domain.ExportFile{}, apperror.Wrap(err)
+	}
+
+	name := fmt.Sprintf("earning-statement-%s.%s", statement.Month.Format("2006-01"), format)
+
+	switch format {
+	case constants.ExportFormatCSV:
+		content, err := s.statementToCSV(statement)
+		if err != nil {
+			return domain.ExportFile{}, apperror.Wrap(err)
+		}
+		return domain.ExportFile{
+			Name:        name,
+			ContentType: constants.MimeCSV,
+			Content:     content,
+		}, nil
+
+	case constants.ExportFormatPDF:
+		content, err := s.statementToPDF(doctor, statement)
+		if err != nil {
+			return domain.ExportFile{}, apperror.Wrap(err)
+		}
+		return domain.ExportFile{
+			Name:        name,
+			ContentType: constants.MimePDF,
+			Content:     content,
+		}, nil
+
+	default:
+		return domain.ExportFile{}, apperror.NewBadRequest(
+			fmt.Errorf("unsupported export format: %s", format),
+		)
+	}
+}
+
+func (s *doctorEarningService) statementToCSV(
+	statement domain.DoctorEarningStatement,
+) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	records := [][]string{
+		{"date", "room_id", "consultation_fee", "commission_rate", "commission", "net_earning", "status"},
+	}
+	for _, e := range statement.Entries {
+		records = append(records, []string{
+			e.ClosedAt.Format("2006-01-02"),
+			strconv.FormatInt(e.RoomID, 10),
+			strconv.Itoa(e.ConsultationFee),
+			strconv.FormatFloat(e.CommissionRate, 'f', -1, 64),
+			strconv.Itoa(e.Commission),
+			strconv.Itoa(e.NetEarning),
+			e.Status,
+		})
+	}
+	records = append(records, []string{
+		"total", "",
+		strconv.Itoa(statement.TotalFee),
+		"",
+		strconv.Itoa(statement.TotalCommission),
+		strconv.Itoa(statement.TotalNet),
+		"",
+	})
+
+	err := w.WriteAll(records)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s *doctorEarningService) statementToPDF(
+	doctor domain.Doctor,
+	statement domain.DoctorEarningStatement,
+) ([]byte, error) {
+	type entry struct {
+		Date       string
+		RoomID     int64
+		Fee        int
+		Commission int
+		Net        int
+		Status     string
+	}
+
+	entries := util.MapSlice(statement.Entries, func(e domain.DoctorEarning) entry {
+		return entry{
+			Date:       e.ClosedAt.Format("2006-01-02"),
+			RoomID:     e.RoomID,
+			Fee:        e.ConsultationFee,
+			Commission: e.Commission,
+			Net:        e.NetEarning,
+			Status:     e.Status,
+		}
+	})
+
+	var body bytes.Buffer
+	err := s.statementTemplate.Execute(&body, struct {
+		DoctorName      string
+		Month           string
+		Entries         []entry
+		TotalFee        int
+		TotalCommission int
+		TotalNet        int
+		PaidNet         int
+		UnpaidNet       int
+	}{
+		DoctorName:      doctor.Account.Name,
+		Month:           statement.Month.Format("January 2006"),
+		Entries:         entries,
+		TotalFee:        statement.TotalFee,
+		TotalCommission: statement.TotalCommission,
+		TotalNet:        statement.TotalNet,
+		PaidNet:         statement.PaidNet,
+		UnpaidNet:       statement.UnpaidNet,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.pdfProvider.ConvertHTML(body.String())
+}
+
+func (s *doctorEarningService) CreatePayoutsClosure(
+	ctx context.Context,
+	dets domain.DoctorPayoutCreateDetails,
+) domain.AtomicFunc[[]domain.DoctorPayout] {
+	return func(dr domain.DataRepository) ([]domain.DoctorPayout, error) {
+		accountRepo := dr.AccountRepository()
+		earningRepo := dr.DoctorEarningRepository()
+
+		accountID, err := util.GetAccountIDFromContext(ctx)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		account, err := accountRepo.GetByID(ctx, accountID)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+		if account.Role != domain.AccountRoleAdmin {
+			return nil, apperror.NewForbidden(nil)
+		}
+
+		summaries, err := earningRepo.GetUnpaidSummaries(ctx, dets)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		payouts := make([]domain.DoctorPayout, 0, len(summaries))
+		for _, summary := range summaries {
+			payout, err := earningRepo.AddPayout(ctx, summary)
+			if err != nil {
+				return nil, apperror.Wrap(err)
+			}
+
+			n, err := earningRepo.MarkPaid(ctx, payout.ID, summary.EarningIDs)
+			if err != nil {
+				return nil, apperror.Wrap(err)
+			}
+			if n != payout.NEntries {
+				return nil, apperror.NewInternalFmt(
+					"payout %d: marked %d earnings paid, expected %d", payout.ID, n, payout.NEntries,
+				)
+			}
+			payout.EarningIDs = summary.EarningIDs
+
+			payouts = append(payouts, payout)
+		}
+
+		return payouts, nil
+	}
+}
+
+func (s *doctorEarningService) CreatePayouts(
+	ctx context.Context,
+	dets domain.DoctorPayoutCreateDetails,
+) ([]domain.DoctorPayout, error) {
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.CreatePayoutsClosure(ctx, dets),
+	)
+}
+
+// RecordEndedRooms adds the ledger entries of the rooms that ran past their
+// end without being closed and returns how many it added. Each room is
+// recorded in its own transaction, a room that fails is logged and retried
+// on the next run without holding back the others.
+func (s *doctorEarningService) RecordEndedRooms(ctx context.Context) (int, error) {
+	roomIDs, err := s.dataRepository.DoctorEarningRepository().ListUnrecordedRoomIDs(ctx, time.Now())
+	if err != nil {
+		return 0, apperror.Wrap(err)
+	}
+
+	recorded := 0
+	for _, roomID := range roomIDs {
+		_, err = domain.RunAtomic(
+			s.dataRepository,
+			ctx,
+			recordDoctorEarningClosure(ctx, roomID),
+		)
+		if err != nil {
+			s.logger.Errorf("recording earning of room %d: %v", roomID, err)
+			continue
+		}
+		recorded++
+	}
+
+	return recorded, nil
+}
+
+// recordDoctorEarningClosure adds the ledger entry of an ended consultation
+// room. The room stays locked until the entry is added, so a room closed
+// more than once, or closed while the expiry job records it, gets one entry.
+func recordDoctorEarningClosure(
+	ctx context.Context,
+	roomID int64,
+) domain.AtomicFunc[any] {
+	return func(dr domain.DataRepository) (any, error) {
+		chatRepo := dr.ChatRepository()
+		doctorRepo := dr.DoctorRepository()
+		specializationRepo := dr.SpecializationRepository()
+		earningRepo := dr.DoctorEarningRepository()
+
+		room, err := chatRepo.GetRoomByIDAndLock(ctx, roomID)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		exists, err := earningRepo.IsExistByRoomID(ctx, roomID)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+		if exists {
+			return nil, nil
+		}
+
+		doctor, err := doctorRepo.GetByID(ctx, room.DoctorId)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		specialization, err := specializationRepo.GetByID(ctx, doctor.Specialization.ID)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		// A room closed before its end is dated when it was closed.
+		earning := domain.NewDoctorEarning(
+			doctor.ID, room.ID, room.ConsultationFee, specialization.CommissionRate,
+		)
+		earning.ClosedAt = time.Now()
+		if room.EndAt.Before(earning.ClosedAt) {
+			earning.ClosedAt = room.EndAt
+		}
+
+		_, err = earningRepo.Add(ctx, earning)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		return nil, nil
+	}
+}
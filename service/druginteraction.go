@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"medichat-be/util"
+	"sort"
+)
+
+var drugInteractionSeverityRank = map[string]int{
+	domain.DrugInteractionSeverityMinor:    1,
+	domain.DrugInteractionSeverityModerate: 2,
+	domain.DrugInteractionSeveritySevere:   3,
+}
+
+func newDrugInteractionProduct(p domain.Product, d domain.ProductDetails) domain.DrugInteractionProduct {
+	return domain.DrugInteractionProduct{
+		ID:          p.ID,
+		Slug:        p.Slug,
+		Name:        p.Name,
+		Ingredients: util.ExtractIngredients(d.GenericName, d.Composition),
+	}
+}
+
+func orderedIngredients(a, b string) (string, string) {
+	if a > b {
+		return b, a
+	}
+	return a, b
+}
+
+// checkDrugInteractions compares every pair of distinct products against the
+// interaction knowledge base. Warnings covered by an active doctor override for
+// userID are marked as overridden.
+func checkDrugInteractions(
+	ctx context.Context,
+	dr domain.DataRepository,
+	userID *int64,
+	products []domain.DrugInteractionProduct,
+) ([]domain.DrugInteractionWarning, error) {
+	interactionRepo := dr.DrugInteractionRepository()
+
+	warnings := []domain.DrugInteractionWarning{}
+
+	seenProducts := map[int64]bool{}
+	unique := []domain.DrugInteractionProduct{}
+	for _, p := range products {
+		if !seenProducts[p.ID] {
+			seenProducts[p.ID] = true
+			unique = append(unique, p)
+		}
+	}
+	products = unique
+
+	if len(products) < 2 {
+		return warnings, nil
+	}
+
+	ingredientSet := map[string]bool{}
+	ingredients := []string{}
+	for _, p := range products {
+		for _, ing := range p.Ingredients {
+			if !ingredientSet[ing] {
+				ingredientSet[ing] = true
+				ingredients = append(ingredients, ing)
+			}
+		}
+	}
+
+	interactions, err := interactionRepo.ListByIngredients(ctx, ingredients)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+	if len(interactions) == 0 {
+		return warnings, nil
+	}
+
+	overridden := map[[2]string]bool{}
+	if userID != nil {
+		overrides, err := interactionRepo.ListActiveOverridesByUserID(ctx, *userID)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+		for _, o := range overrides {
+			a, b := orderedIngredients(o.IngredientA, o.IngredientB)
+			overridden[[2]string{a, b}] = true
+		}
+	}
+
+	contains := func(p domain.DrugInteractionProduct, ing string) bool {
+		for _, i := range p.Ingredients {
+			if i == ing {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := 0; i < len(products); i++ {
+		for j := i + 1; j < len(products); j++ {
+			pa, pb := products[i], products[j]
+
+			for _, in := range interactions {
+				if !(contains(pa, in.IngredientA) && contains(pb, in.IngredientB)) &&
+					!(contains(pa, in.IngredientB) && contains(pb, in.IngredientA)) {
+					continue
+				}
+
+				a, b := orderedIngredients(in.IngredientA, in.IngredientB)
+				warnings = append(warnings, domain.DrugInteractionWarning{
+					ProductA:    domain.DrugInteractionProductRef{ID: pa.ID, Slug: pa.Slug, Name: pa.Name},
+					ProductB:    domain.DrugInteractionProductRef{ID: pb.ID, Slug: pb.Slug, Name: pb.Name},
+					IngredientA: in.IngredientA,
+					IngredientB: in.IngredientB,
+					Severity:    in.Severity,
+					Description: in.Description,
+					Overridden:  overridden[[2]string{a, b}],
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return drugInteractionSeverityRank[warnings[i].Severity] > drugInteractionSeverityRank[warnings[j].Severity]
+	})
+
+	return warnings, nil
+}
+
+// blockingDrugInteractions returns the severe warnings no doctor has overridden.
+func blockingDrugInteractions(warnings []domain.DrugInteractionWarning) []domain.DrugInteractionWarning {
+	ret := []domain.DrugInteractionWarning{}
+	for _, w := range warnings {
+		if w.Severity == domain.DrugInteractionSeveritySevere && !w.Overridden {
+			ret = append(ret, w)
+		}
+	}
+	return ret
+}
+
+// addDrugInteractionOverridesClosure saves the overrides of a prescription
+// together, so either all of its interactions are overridden or none.
+func addDrugInteractionOverridesClosure(
+	ctx context.Context,
+	overrides []domain.DrugInteractionOverride,
+) domain.AtomicFunc[any] {
+	return func(dr domain.DataRepository) (any, error) {
+		interactionRepo := dr.DrugInteractionRepository()
+
+		for _, o := range overrides {
+			_, err := interactionRepo.AddOverride(ctx, o)
+			if err != nil {
+				return nil, apperror.Wrap(err)
+			}
+		}
+
+		return nil, nil
+	}
+}
+
+func newSevereDrugInteractionError(warnings []domain.DrugInteractionWarning) error {
+	pairs := make([]string, len(warnings))
+	for i, w := range warnings {
+		pairs[i] = fmt.Sprintf("%s and %s (%s + %s)", w.ProductA.Name, w.ProductB.Name, w.IngredientA, w.IngredientB)
+	}
+	return apperror.NewSevereDrugInteraction(pairs)
+}
+
+type drugInteractionService struct {
+	dataRepository domain.DataRepository
+}
+
+type DrugInteractionServiceOpts struct {
+	DataRepository domain.DataRepository
+}
+
+func NewDrugInteractionService(opts DrugInteractionServiceOpts) *drugInteractionService {
+	return &drugInteractionService{
+		dataRepository: opts.DataRepository,
+	}
+}
+
+func (s *drugInteractionService) List(
+	ctx context.Context,
+) ([]domain.DrugInteraction, error) {
+	interactionRepo := s.dataRepository.DrugInteractionRepository()
+
+	interactions, err := interactionRepo.List(ctx)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	return interactions, nil
+}
+
+func (s *drugInteractionService) ImportClosure(
+	ctx context.Context,
+	interactions []domain.DrugInteraction,
+) domain.AtomicFunc[any] {
+	return func(dr domain.DataRepository) (any, error) {
+		interactionRepo := dr.DrugInteractionRepository()
+
+		err := interactionRepo.ReplaceAll(ctx, interactions)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		return nil, nil
+	}
+}
+
+// Import replaces the whole knowledge base with rows. Nothing is written if
+// any row is invalid, so a bad file never leaves a partial knowledge base.
+func (s *drugInteractionService) Import(
+	ctx context.Context,
+	rows []domain.DrugInteractionImportRow,
+) (domain.DrugInteractionImportResult, error) {
+	result := domain.DrugInteractionImportResult{
+		Errors: []domain.DrugInteractionImportRowError{},
+	}
+
+	seen := map[[2]string]int{}
+	interactions := make([]domain.DrugInteraction, 0, len(rows))
+
+	for _, row := range rows {
+		in := row.Interaction
+		a, b := orderedIngredients(
+			util.NormalizeIngredient(in.IngredientA),
+			util.NormalizeIngredient(in.IngredientB),
+		)
+		in.IngredientA, in.IngredientB = a, b
+
+		if line, ok := seen[[2]string{a, b}]; ok {
+			result.Errors = append(result.Errors, domain.DrugInteractionImportRowError{
+				Line:    row.Line,
+				Message: fmt.Sprintf("duplicate of line %d", line),
+			})
+			continue
+		}
+		seen[[2]string{a, b}] = row.Line
+
+		interactions = append(interactions, in)
+	}
+
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	_, err := domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.ImportClosure(ctx, interactions),
+	)
+	if err != nil {
+		return domain.DrugInteractionImportResult{}, apperror.Wrap(err)
+	}
+
+	result.Imported = len(interactions)
+	return result, nil
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"medichat-be/logger"
+	"time"
+)
+
+// RunPeriodically runs job right away and then every interval until ctx is
+// cancelled. Failures are logged and the job is retried on the next tick.
+func RunPeriodically(
+	ctx context.Context,
+	log logger.Logger,
+	name string,
+	interval time.Duration,
+	job func(context.Context) error,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job(ctx); err != nil {
+			log.Errorf("running job %s: %v", name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"medichat-be/apperror"
+	"medichat-be/constants"
 	"medichat-be/domain"
 	"medichat-be/util"
 	"time"
@@ -115,6 +116,11 @@ func (s *orderService) getOrders(dr domain.DataRepository, ctx context.Context,
 	var orderID int64 = 1
 	var itemID int64 = 1
 
+	interactionProducts := []domain.DrugInteractionProduct{}
+	now := time.Now()
+	promotions := newPromotionPicker(dr, now)
+	vouchers := newVoucherApplier(dr, user.ID, now)
+
 	for _, det := range dets {
 		pharmacy, err := pharmacyRepo.GetBySlug(ctx, det.PharmacySlug)
 		if err != nil {
@@ -126,6 +132,11 @@ func (s *orderService) getOrders(dr domain.DataRepository, ctx context.Context,
 			return domain.Orders{}, apperror.Wrap(err)
 		}
 
+		processAt, err := orderProcessAt(ctx, dr, pharmacy, now)
+		if err != nil {
+			return domain.Orders{}, apperror.Wrap(err)
+		}
+
 		order := domain.Order{
 			ID: orderID,
 			User: struct {
@@ -151,11 +162,14 @@ func (s *orderService) getOrders(dr domain.DataRepository, ctx context.Context,
 			Coordinate:     det.Coordinate,
 			NItems:         0,
 			Subtotal:       0,
+			Discount:       0,
+			Voucher:        nil,
 			ShipmentFee:    0,
 			Total:          0,
 			Status:         domain.OrderStatusWaitingPayment,
-			OrderedAt:      time.Now(),
+			OrderedAt:      now,
 			FinishedAt:     nil,
+			ProcessAt:      processAt,
 			Items:          []domain.OrderItem{},
 		}
 
@@ -165,19 +179,52 @@ func (s *orderService) getOrders(dr domain.DataRepository, ctx context.Context,
 				return domain.Orders{}, apperror.Wrap(err)
 			}
 
+			if !product.IsLive(time.Now()) {
+				return domain.Orders{}, apperror.NewProductNotAvailable(product.Name)
+			}
+
 			productDetail, err := productDetailRepo.GetById(ctx, product.ProductDetailId)
 			if err != nil {
 				return domain.Orders{}, apperror.Wrap(err)
 			}
 
-			stock, err := stockRepo.GetByPharmacyAndProduct(ctx, pharmacy.ID, product.ID)
+			variant, err := resolveVariant(ctx, dr, product.ID, it.VariantID)
 			if err != nil {
 				return domain.Orders{}, apperror.Wrap(err)
 			}
-			if stock.Stock < it.Amount {
+
+			stock, err := stockRepo.GetByPharmacyAndProduct(ctx, pharmacy.ID, product.ID, variantIDOf(variant))
+			if err != nil {
+				return domain.Orders{}, apperror.Wrap(err)
+			}
+			sellable, err := sellableAmount(ctx, dr, stock)
+			if err != nil {
+				return domain.Orders{}, apperror.Wrap(err)
+			}
+			if sellable < it.Amount {
 				return domain.Orders{}, apperror.NewStockNotEnough(nil)
 			}
-			price := stock.Price
+
+			basePrice, err := stockPriceAt(ctx, dr, stock, order.OrderedAt)
+			if err != nil {
+				return domain.Orders{}, apperror.Wrap(err)
+			}
+			promotion, err := promotions.pick(ctx, domain.PromotionTarget{
+				ProductID:  product.ID,
+				CategoryID: product.ProductCategoryId,
+				PharmacyID: pharmacy.ID,
+			}, basePrice)
+			if err != nil {
+				return domain.Orders{}, apperror.Wrap(err)
+			}
+			discount := 0
+			var promotionRef *domain.PromotionRef
+			if promotion != nil {
+				discount = promotion.Discount(basePrice)
+				promotionRef = &domain.PromotionRef{ID: promotion.ID, Name: promotion.Name}
+			}
+
+			interactionProducts = append(interactionProducts, newDrugInteractionProduct(product, productDetail))
 
 			picture := ""
 			if product.Picture != nil {
@@ -200,11 +247,16 @@ func (s *orderService) getOrders(dr domain.DataRepository, ctx context.Context,
 					PhotoURL:       picture,
 					Classification: productDetail.ProductClassification,
 				},
-				Price:  price,
-				Amount: it.Amount,
+				Variant:   variantRef(variant),
+				BasePrice: basePrice,
+				Discount:  discount,
+				Promotion: promotionRef,
+				Price:     basePrice - discount,
+				Amount:    it.Amount,
 			})
 
-			order.Subtotal += price * it.Amount
+			order.Subtotal += basePrice * it.Amount
+			order.Discount += discount * it.Amount
 			order.NItems += it.Amount
 			itemID++
 		}
@@ -224,14 +276,32 @@ func (s *orderService) getOrders(dr domain.DataRepository, ctx context.Context,
 		}
 
 		order.ShipmentFee = shipmentFee
-		order.Total = order.Subtotal + order.ShipmentFee
 
+		if det.VoucherCode != nil {
+			voucher, discount, err := vouchers.apply(ctx, *det.VoucherCode, order)
+			if err != nil {
+				return domain.Orders{}, apperror.Wrap(err)
+			}
+			order.Voucher = &domain.VoucherRef{ID: voucher.ID, Code: voucher.Code}
+			order.VoucherDiscount = discount
+		}
+
+		order.Total = order.Subtotal - order.Discount - order.VoucherDiscount + order.ShipmentFee
+
+		orders.Discount += order.Discount
+		orders.VoucherDiscount += order.VoucherDiscount
 		orders.Total += order.Total
 		orders.Orders = append(orders.Orders, order)
 
 		orderID++
 	}
 
+	warnings, err := checkDrugInteractions(ctx, dr, &user.ID, interactionProducts)
+	if err != nil {
+		return domain.Orders{}, apperror.Wrap(err)
+	}
+	orders.Warnings = warnings
+
 	return orders, nil
 }
 
@@ -246,6 +316,7 @@ func (s *orderService) AddOrdersClosure(
 	return func(dr domain.DataRepository) (domain.Orders, error) {
 		orderRepo := dr.OrderRepository()
 		paymentRepo := dr.PaymentRepository()
+		promotionRepo := dr.PromotionRepository()
 
 		if len(dets) == 0 {
 			return domain.Orders{}, nil
@@ -256,6 +327,10 @@ func (s *orderService) AddOrdersClosure(
 			return domain.Orders{}, apperror.Wrap(err)
 		}
 
+		if blocking := blockingDrugInteractions(orders.Warnings); len(blocking) > 0 {
+			return domain.Orders{}, newSevereDrugInteractionError(blocking)
+		}
+
 		payment := domain.Payment{
 			InvoiceNumber: util.GenerateInvoiceNumber(),
 			User:          orders.Orders[0].User,
@@ -282,6 +357,14 @@ func (s *orderService) AddOrdersClosure(
 
 			order.ID = newOrder.ID
 
+			// Redeemed with the payment, so a failed checkout uses nothing.
+			if order.Voucher != nil {
+				err = redeemVoucher(ctx, dr, *order)
+				if err != nil {
+					return domain.Orders{}, apperror.Wrap(err)
+				}
+			}
+
 			for j := range order.Items {
 				item := &order.Items[j]
 
@@ -293,6 +376,21 @@ func (s *orderService) AddOrdersClosure(
 				}
 
 				item.ID = newItem.ID
+
+				if item.Promotion != nil {
+					err = promotionRepo.IncrementUsage(ctx, item.Promotion.ID)
+					if apperror.IsErrorCode(err, apperror.CodeNotFound) {
+						return domain.Orders{}, apperror.NewPromotionExhausted(item.Promotion.Name)
+					}
+					if err != nil {
+						return domain.Orders{}, apperror.Wrap(err)
+					}
+				}
+
+				err = s.reserveStockForOrderItem(ctx, dr, order.Pharmacy.ID, *item)
+				if err != nil {
+					return domain.Orders{}, apperror.Wrap(err)
+				}
 			}
 		}
 
@@ -356,6 +454,16 @@ func (s *orderService) SendOrderClosure(
 			return nil, apperror.Wrap(err)
 		}
 
+		// The reservations are committed first so the stock they held
+		// counts as available to this order's own deduction.
+		err = dr.StockRepository().UpdateReservationStatusByOrderIDs(
+			ctx, []int64{id},
+			domain.StockReservationStatusActive, domain.StockReservationStatusCommitted,
+		)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
 		for _, item := range items {
 			err = s.updateStockForOrderItem(ctx, dr, order.Pharmacy.ID, item)
 			if err != nil {
@@ -372,10 +480,42 @@ func (s *orderService) SendOrderClosure(
 	}
 }
 
-func (s *orderService) updateStockForOrderItem(ctx context.Context, dr domain.DataRepository, pharmacyID int64, item domain.OrderItem) error {
+// orderProcessAt is when an order placed at the pharmacy at t can be
+// processed, nil when the pharmacy is open. A pharmacy without an opening
+// coming up takes no orders.
+func orderProcessAt(
+	ctx context.Context,
+	dr domain.DataRepository,
+	pharmacy domain.Pharmacy,
+	t time.Time,
+) (*time.Time, error) {
+	oh, err := pharmacyOpeningHours(ctx, dr, pharmacy, t)
+	if err != nil {
+		return nil, err
+	}
+
+	next, ok := oh.NextOpening(t)
+	if !ok {
+		return nil, apperror.NewPharmacyClosed(pharmacy.Name)
+	}
+	if !next.After(t) {
+		return nil, nil
+	}
+	return &next, nil
+}
+
+// reserveStockForOrderItem holds the item's amount on the pharmacy's stock.
+// The stock row is locked so concurrent orders can't both take the last
+// units.
+func (s *orderService) reserveStockForOrderItem(ctx context.Context, dr domain.DataRepository, pharmacyID int64, item domain.OrderItem) error {
 	stockRepo := dr.StockRepository()
 
-	stock, err := stockRepo.GetByPharmacyAndProduct(ctx, pharmacyID, item.Product.ID)
+	var variantID *int64
+	if item.Variant != nil {
+		variantID = &item.Variant.ID
+	}
+
+	stock, err := stockRepo.GetByPharmacyAndProduct(ctx, pharmacyID, item.Product.ID, variantID)
 	if err != nil {
 		return err
 	}
@@ -385,33 +525,62 @@ func (s *orderService) updateStockForOrderItem(ctx context.Context, dr domain.Da
 		return err
 	}
 
-	if stock.Stock < item.Amount {
-		source, err := stockRepo.GetNearestStockWithProduct(ctx, stock.PharmacyID, stock.ProductID, item.Amount)
-		if err != nil {
-			return err
-		}
+	sellable, err := sellableAmount(ctx, dr, stock)
+	if err != nil {
+		return err
+	}
+	if sellable < item.Amount {
+		return apperror.NewStockNotEnough(nil)
+	}
 
-		mutation := domain.StockMutation{
-			SourceID: source.ID,
-			TargetID: stock.ID,
-			Method:   domain.StockMutationAutomatic,
-			Status:   domain.StockMutationStatusApproved,
-			Amount:   item.Amount,
-		}
+	_, err = stockRepo.AddReservation(ctx, domain.StockReservation{
+		StockID:     stock.ID,
+		OrderID:     item.OrderID,
+		OrderItemID: item.ID,
+		Amount:      item.Amount,
+		Status:      domain.StockReservationStatusActive,
+	})
+	return err
+}
 
-		mutation, err = stockRepo.AddMutation(ctx, mutation)
-		if err != nil {
-			return err
-		}
+func (s *orderService) updateStockForOrderItem(ctx context.Context, dr domain.DataRepository, pharmacyID int64, item domain.OrderItem) error {
+	stockRepo := dr.StockRepository()
+
+	var variantID *int64
+	if item.Variant != nil {
+		variantID = &item.Variant.ID
+	}
+
+	stock, err := stockRepo.GetByPharmacyAndProduct(ctx, pharmacyID, item.Product.ID, variantID)
+	if err != nil {
+		return err
+	}
 
-		source, stock, err = transferStock(dr, ctx, source.ID, stock.ID, item.Amount)
+	stock, err = stockRepo.GetByIDAndLock(ctx, stock.ID)
+	if err != nil {
+		return err
+	}
+
+	// Other orders' reservations have to survive the deduction, orders
+	// placed before reservations existed top up from elsewhere instead.
+	sellable, err := sellableAmount(ctx, dr, stock)
+	if err != nil {
+		return err
+	}
+
+	if sellable < item.Amount {
+		stock, err = fulfillShortfall(ctx, dr, stock, item.Amount-sellable)
 		if err != nil {
 			return err
 		}
 	}
 
-	stock.Stock -= item.Amount
-	stock, err = stockRepo.Update(ctx, stock)
+	_, err = pickBatches(ctx, dr, stock.ID, item.Amount)
+	if err != nil {
+		return err
+	}
+
+	_, err = changeStock(ctx, dr, stock, -item.Amount, domain.StockLedgerSale, &item.OrderID)
 	if err != nil {
 		return err
 	}
@@ -553,6 +722,24 @@ func (s *orderService) CancelOrderClosure(
 			return nil, apperror.Wrap(err)
 		}
 
+		err = dr.StockRepository().UpdateReservationStatusByOrderIDs(
+			ctx, []int64{id},
+			domain.StockReservationStatusActive, domain.StockReservationStatusReleased,
+		)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		err = dr.PromotionRepository().ReleaseUsageByOrderIDs(ctx, []int64{id})
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		err = dr.VoucherRepository().ReleaseByOrderIDs(ctx, []int64{id})
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
 		return nil, nil
 	}
 }
@@ -568,3 +755,48 @@ func (s *orderService) CancelOrder(
 	)
 	return err
 }
+
+func (s *orderService) CancelExpiredOrdersClosure(
+	ctx context.Context,
+	before time.Time,
+) domain.AtomicFunc[int] {
+	return func(dr domain.DataRepository) (int, error) {
+		orderRepo := dr.OrderRepository()
+		stockRepo := dr.StockRepository()
+
+		ids, err := orderRepo.CancelWaitingPaymentBefore(ctx, before)
+		if err != nil {
+			return 0, apperror.Wrap(err)
+		}
+
+		err = stockRepo.UpdateReservationStatusByOrderIDs(
+			ctx, ids,
+			domain.StockReservationStatusActive, domain.StockReservationStatusReleased,
+		)
+		if err != nil {
+			return 0, apperror.Wrap(err)
+		}
+
+		err = dr.PromotionRepository().ReleaseUsageByOrderIDs(ctx, ids)
+		if err != nil {
+			return 0, apperror.Wrap(err)
+		}
+
+		err = dr.VoucherRepository().ReleaseByOrderIDs(ctx, ids)
+		if err != nil {
+			return 0, apperror.Wrap(err)
+		}
+
+		return len(ids), nil
+	}
+}
+
+// CancelExpiredOrders cancels orders whose payment proof wasn't uploaded in
+// time and releases the stock, promotion uses and vouchers they took.
+func (s *orderService) CancelExpiredOrders(ctx context.Context) (int, error) {
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.CancelExpiredOrdersClosure(ctx, time.Now().Add(-constants.OrderPaymentTimeout)),
+	)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"medichat-be/mocks/domainmocks"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// hoursPharmacyRepository hands out fixed opening hours.
+type hoursPharmacyRepository struct {
+	domain.PharmacyRepository
+
+	operations []domain.PharmacyOperations
+	holidays   []domain.PharmacyHoliday
+}
+
+func (r *hoursPharmacyRepository) GetPharmacyOperationsByPharmacyId(
+	ctx context.Context,
+	id int64,
+) ([]domain.PharmacyOperations, error) {
+	return r.operations, nil
+}
+
+func (r *hoursPharmacyRepository) GetHolidaysByPharmacyId(
+	ctx context.Context,
+	id int64,
+	from time.Time,
+	to *time.Time,
+) ([]domain.PharmacyHoliday, error) {
+	return r.holidays, nil
+}
+
+func Test_orderProcessAt(t *testing.T) {
+	loc, _ := time.LoadLocation(domain.PharmacyTimezoneWITA)
+	clock := func(s string) time.Time {
+		c, _ := time.Parse("15:04", s)
+		return c
+	}
+	monday := domain.PharmacyOperations{Day: "Monday", StartTime: clock("08:00"), EndTime: clock("17:00")}
+	tuesday := domain.PharmacyOperations{Day: "Tuesday", StartTime: clock("08:00"), EndTime: clock("17:00")}
+	holiday := domain.PharmacyHoliday{Date: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)}
+	tuesdayOpening := time.Date(2026, 10, 20, 8, 0, 0, 0, loc)
+
+	tests := []struct {
+		name string
+
+		operations []domain.PharmacyOperations
+		holidays   []domain.PharmacyHoliday
+		at         time.Time
+
+		want    *time.Time
+		wantErr bool
+	}{
+		{
+			name: "should process right away when open",
+
+			operations: []domain.PharmacyOperations{monday, tuesday},
+			at:         time.Date(2026, 10, 19, 10, 0, 0, 0, loc),
+
+			want: nil,
+		},
+		{
+			name: "should defer to the next opening after a holiday",
+
+			operations: []domain.PharmacyOperations{monday, tuesday},
+			holidays:   []domain.PharmacyHoliday{holiday},
+			at:         time.Date(2026, 10, 19, 10, 0, 0, 0, loc),
+
+			want: &tuesdayOpening,
+		},
+		{
+			name: "should block when there is no opening coming up",
+
+			at: time.Date(2026, 10, 19, 10, 0, 0, 0, loc),
+
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			dataRepo := new(domainmocks.DataRepository)
+			dataRepo.On("PharmacyRepository").Return(&hoursPharmacyRepository{
+				operations: tt.operations,
+				holidays:   tt.holidays,
+			})
+			pharmacy := domain.Pharmacy{ID: 1, Name: "Sehat", Timezone: domain.PharmacyTimezoneWITA}
+
+			// when
+			got, err := orderProcessAt(context.Background(), dataRepo, pharmacy, tt.at)
+
+			// then
+			if tt.wantErr {
+				assert.True(t, apperror.IsErrorCode(err, apperror.CodeBadRequest))
+				return
+			}
+			assert.NoError(t, err)
+			if tt.want == nil {
+				assert.Nil(t, got)
+			} else {
+				assert.True(t, tt.want.Equal(*got), "want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
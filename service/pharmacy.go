@@ -163,6 +163,8 @@ func (s *pharmacyService) GetPharmaciesByProductSlug(ctx context.Context, query
 	productRepo := s.dataRepository.ProductRepository()
 	stockRepo := s.dataRepository.StockRepository()
 
+	variantRepo := s.dataRepository.ProductVariantRepository()
+
 	var product domain.Product
 	var newP []domain.PharmacyStock
 
@@ -174,6 +176,21 @@ func (s *pharmacyService) GetPharmaciesByProductSlug(ctx context.Context, query
 
 		query.ProductId = &productDet.ID
 		product = productDet
+
+		if query.VariantID == nil {
+			variants, err := variantRepo.ListByProductID(ctx, product.ID)
+			if err != nil {
+				return []domain.PharmacyStock{}, domain.PageInfo{}, apperror.Wrap(err)
+			}
+			if len(variants) > 0 {
+				query.VariantID = &variants[0].ID
+			}
+		} else {
+			_, err := resolveVariant(ctx, s.dataRepository, product.ID, query.VariantID)
+			if err != nil {
+				return []domain.PharmacyStock{}, domain.PageInfo{}, apperror.Wrap(err)
+			}
+		}
 	}
 
 	p, err := pharmacyRepo.GetPharmacies(ctx, query)
@@ -192,6 +209,7 @@ func (s *pharmacyService) GetPharmaciesByProductSlug(ctx context.Context, query
 			PharmacistName:    v.PharmacistName,
 			PharmacistLicense: v.PharmacistLicense,
 			PharmacistPhone:   v.PharmacistPhone,
+			Timezone:          v.Timezone,
 
 			Distance: v.Distance,
 		})
@@ -215,7 +233,7 @@ func (s *pharmacyService) GetPharmaciesByProductSlug(ctx context.Context, query
 			sh[i].Name = &shDetail.Name
 		}
 
-		s, err := stockRepo.GetByPharmacyAndProduct(ctx, v.ID, product.ID)
+		s, err := stockRepo.GetByPharmacyAndProduct(ctx, v.ID, product.ID, query.VariantID)
 		if err != nil {
 			return []domain.PharmacyStock{}, domain.PageInfo{}, apperror.Wrap(err)
 		}
@@ -297,6 +315,9 @@ func (s *pharmacyService) CreatePharmacy(ctx context.Context, pharmacy domain.Ph
 	}
 
 	pharmacy.ManagerID = manager.ID
+	if pharmacy.Timezone == "" {
+		pharmacy.Timezone = domain.PharmacyTimezoneWIB
+	}
 
 	p, err := pharmacyRepo.Add(ctx, pharmacy)
 	if err != nil {
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"time"
+)
+
+func (s *pharmacyService) GetOpeningStatus(
+	ctx context.Context,
+	slug string,
+	at time.Time,
+) (domain.PharmacyOpeningStatus, error) {
+	pharmacy, err := s.dataRepository.PharmacyRepository().GetBySlug(ctx, slug)
+	if err != nil {
+		return domain.PharmacyOpeningStatus{}, apperror.Wrap(err)
+	}
+
+	oh, err := pharmacyOpeningHours(ctx, s.dataRepository, pharmacy, at)
+	if err != nil {
+		return domain.PharmacyOpeningStatus{}, apperror.Wrap(err)
+	}
+
+	status := domain.PharmacyOpeningStatus{
+		IsOpen:   oh.IsOpenAt(at),
+		At:       at.In(oh.Location()),
+		Timezone: pharmacy.Timezone,
+	}
+	if next, ok := oh.NextOpening(at); ok {
+		status.NextOpening = &next
+	}
+
+	return status, nil
+}
+
+// GetHolidaysBySlug lists the pharmacy's holidays from today on.
+func (s *pharmacyService) GetHolidaysBySlug(ctx context.Context, slug string) ([]domain.PharmacyHoliday, error) {
+	pharmacyRepo := s.dataRepository.PharmacyRepository()
+
+	pharmacy, err := pharmacyRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	today, err := pharmacyToday(pharmacy, time.Now())
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	holidays, err := pharmacyRepo.GetHolidaysByPharmacyId(ctx, pharmacy.ID, today, nil)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	return holidays, nil
+}
+
+func (s *pharmacyService) AddHolidayClosure(
+	ctx context.Context,
+	slug string,
+	holiday domain.PharmacyHoliday,
+) domain.AtomicFunc[domain.PharmacyHoliday] {
+	return func(dr domain.DataRepository) (domain.PharmacyHoliday, error) {
+		pharmacyRepo := dr.PharmacyRepository()
+
+		_, pharmacy, err := managedPharmacyBySlug(ctx, dr, slug)
+		if err != nil {
+			return domain.PharmacyHoliday{}, apperror.Wrap(err)
+		}
+
+		today, err := pharmacyToday(pharmacy, time.Now())
+		if err != nil {
+			return domain.PharmacyHoliday{}, apperror.Wrap(err)
+		}
+		if holiday.Date.Format("2006-01-02") < today.Format("2006-01-02") {
+			return domain.PharmacyHoliday{}, apperror.NewPharmacyHolidayInPast()
+		}
+
+		exists, err := pharmacyRepo.IsHolidayExist(ctx, pharmacy.ID, holiday.Date)
+		if err != nil {
+			return domain.PharmacyHoliday{}, apperror.Wrap(err)
+		}
+		if exists {
+			return domain.PharmacyHoliday{}, apperror.NewPharmacyHolidayExists(holiday.Date.Format("2006-01-02"))
+		}
+
+		holiday.PharmacyID = pharmacy.ID
+		holiday, err = pharmacyRepo.AddHoliday(ctx, holiday)
+		if err != nil {
+			return domain.PharmacyHoliday{}, apperror.Wrap(err)
+		}
+
+		return holiday, nil
+	}
+}
+
+// AddHoliday closes the pharmacy for a date. Orders placed before then
+// aren't moved.
+func (s *pharmacyService) AddHoliday(
+	ctx context.Context,
+	slug string,
+	holiday domain.PharmacyHoliday,
+) (domain.PharmacyHoliday, error) {
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.AddHolidayClosure(ctx, slug, holiday),
+	)
+}
+
+func (s *pharmacyService) DeleteHoliday(ctx context.Context, slug string, id int64) error {
+	pharmacyRepo := s.dataRepository.PharmacyRepository()
+
+	_, pharmacy, err := managedPharmacyBySlug(ctx, s.dataRepository, slug)
+	if err != nil {
+		return apperror.Wrap(err)
+	}
+
+	holiday, err := pharmacyRepo.GetHolidayByID(ctx, id)
+	if err != nil {
+		return apperror.Wrap(err)
+	}
+	if holiday.PharmacyID != pharmacy.ID {
+		return apperror.NewEntityNotFound("holiday")
+	}
+
+	err = pharmacyRepo.SoftDeleteHolidayByID(ctx, id)
+	if err != nil {
+		return apperror.Wrap(err)
+	}
+
+	return nil
+}
+
+// pharmacyOpeningHours loads the opening hours of the pharmacy with the
+// holidays that can matter from at until the end of the lookahead.
+func pharmacyOpeningHours(
+	ctx context.Context,
+	dr domain.DataRepository,
+	pharmacy domain.Pharmacy,
+	at time.Time,
+) (domain.OpeningHours, error) {
+	pharmacyRepo := dr.PharmacyRepository()
+
+	operations, err := pharmacyRepo.GetPharmacyOperationsByPharmacyId(ctx, pharmacy.ID)
+	if err != nil {
+		return domain.OpeningHours{}, err
+	}
+
+	today, err := pharmacyToday(pharmacy, at)
+	if err != nil {
+		return domain.OpeningHours{}, err
+	}
+	from := today.AddDate(0, 0, -1)
+	to := today.AddDate(0, 0, domain.OpeningHoursLookaheadDays)
+
+	holidays, err := pharmacyRepo.GetHolidaysByPharmacyId(ctx, pharmacy.ID, from, &to)
+	if err != nil {
+		return domain.OpeningHours{}, err
+	}
+
+	return domain.NewOpeningHours(pharmacy.Timezone, operations, holidays)
+}
+
+// pharmacyToday is the local date at the pharmacy at t.
+func pharmacyToday(pharmacy domain.Pharmacy, t time.Time) (time.Time, error) {
+	loc, err := time.LoadLocation(pharmacy.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc), nil
+}
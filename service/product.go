@@ -2,41 +2,88 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"medichat-be/apperror"
+	"medichat-be/constants"
 	"medichat-be/domain"
 	"medichat-be/util"
 	"mime/multipart"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type productService struct {
 	dataRepository domain.DataRepository
 	cloud          util.CloudinaryProvider
+	suggestSrv     domain.ProductSuggestService
 }
 
 type ProductServiceOpts struct {
 	DataRepository domain.DataRepository
 	Cloud          util.CloudinaryProvider
+	SuggestSrv     domain.ProductSuggestService
 }
 
 func NewProductService(opts ProductServiceOpts) *productService {
 	return &productService{
 		dataRepository: opts.DataRepository,
 		cloud:          opts.Cloud,
+		suggestSrv:     opts.SuggestSrv,
 	}
 }
 
+func (s *productService) invalidateSuggestions() {
+	if s.suggestSrv != nil {
+		s.suggestSrv.Invalidate()
+	}
+}
+
+func validateProductSchedule(publishAt, unpublishAt *time.Time) error {
+	if publishAt != nil && unpublishAt != nil && !publishAt.Before(*unpublishAt) {
+		return apperror.NewInvalidProductSchedule()
+	}
+	return nil
+}
+
+// canViewProduct hides drafts and products outside their publishing window
+// from everyone but admins. Discontinued products stay visible so they can
+// still be looked up from order history.
+func canViewProduct(ctx context.Context, dr domain.DataRepository, product domain.Product) (bool, error) {
+	if product.IsLive(time.Now()) || product.Status == domain.ProductStatusDiscontinued {
+		return true, nil
+	}
+
+	accountID, err := util.GetAccountIDFromContext(ctx)
+	if err != nil {
+		return false, nil
+	}
+
+	account, err := dr.AccountRepository().GetByID(ctx, accountID)
+	if err != nil {
+		return false, apperror.Wrap(err)
+	}
+
+	return account.Role == domain.AccountRoleAdmin, nil
+}
+
 func (s *productService) CreateProduct(ctx context.Context, request domain.AddProductRequest, file *multipart.File) (domain.Product, error) {
-	productRepo := s.dataRepository.ProductRepository()
 	categoryRepo := s.dataRepository.CategoryRepository()
-	detailRepo := s.dataRepository.ProductDetailsRepository()
 
 	product := domain.Product{}
 
-	product.IsActive = true
+	product.Status = request.Status
+	if product.Status == "" {
+		product.Status = domain.ProductStatusPublished
+	}
+	if err := validateProductSchedule(request.PublishAt, request.UnpublishAt); err != nil {
+		return domain.Product{}, err
+	}
+	product.PublishAt = request.PublishAt
+	product.UnpublishAt = request.UnpublishAt
 	product.Name = request.Name
 
 	product.KeyWord = product.Name + " " + request.Manufacturer + " " + request.Composition
@@ -48,13 +95,39 @@ func (s *productService) CreateProduct(ctx context.Context, request domain.AddPr
 
 	product.ProductCategoryId = cat.ID
 
+	var uploaded *domain.ProductImage
 	if file != nil {
-		res, err := s.cloud.UploadImage(ctx, *file, uploader.UploadParams{})
-		if err == nil {
-			product.Picture = &res.SecureURL
+		img, err := uploadProductImage(ctx, s.cloud, *file)
+		if err != nil {
+			return domain.Product{}, apperror.Wrap(err)
+		}
+		uploaded = &img
+		product.Picture = &img.URL
+	}
+
+	prod, err := s.createProduct(ctx, product, request, uploaded)
+	if err != nil {
+		if uploaded != nil {
+			_ = deleteProductImageFiles(ctx, s.cloud, *uploaded)
 		}
+		return domain.Product{}, apperror.Wrap(err)
 	}
 
+	s.invalidateSuggestions()
+
+	return prod, nil
+}
+
+func (s *productService) createProduct(
+	ctx context.Context,
+	product domain.Product,
+	request domain.AddProductRequest,
+	uploaded *domain.ProductImage,
+) (domain.Product, error) {
+	productRepo := s.dataRepository.ProductRepository()
+	detailRepo := s.dataRepository.ProductDetailsRepository()
+	imageRepo := s.dataRepository.ProductImageRepository()
+
 	detail := domain.ProductDetails{
 		GenericName:           request.GenericName,
 		Content:               request.Content,
@@ -87,6 +160,19 @@ func (s *productService) CreateProduct(ctx context.Context, request domain.AddPr
 		return domain.Product{}, apperror.Wrap(err)
 	}
 
+	if uploaded != nil {
+		img := *uploaded
+		img.ProductID = prod.ID
+		img.Position = 0
+		img.IsPrimary = true
+
+		img, err = imageRepo.Add(ctx, img)
+		if err != nil {
+			return domain.Product{}, apperror.Wrap(err)
+		}
+		prod.Images = []domain.ProductImage{img}
+	}
+
 	return prod, nil
 }
 
@@ -100,6 +186,14 @@ func (s *productService) GetProduct(ctx context.Context, slug string) (domain.Pr
 		return domain.Product{}, domain.ProductDetails{}, domain.CategoryWithParentName{}, apperror.Wrap(err)
 	}
 
+	visible, err := canViewProduct(ctx, s.dataRepository, products)
+	if err != nil {
+		return domain.Product{}, domain.ProductDetails{}, domain.CategoryWithParentName{}, apperror.Wrap(err)
+	}
+	if !visible {
+		return domain.Product{}, domain.ProductDetails{}, domain.CategoryWithParentName{}, apperror.NewEntityNotFound("product")
+	}
+
 	productDetail, err := productDetailRepo.GetById(ctx, products.ProductDetailId)
 	if err != nil {
 		return domain.Product{}, domain.ProductDetails{}, domain.CategoryWithParentName{}, apperror.Wrap(err)
@@ -115,30 +209,74 @@ func (s *productService) GetProduct(ctx context.Context, slug string) (domain.Pr
 		return domain.Product{}, domain.ProductDetails{}, domain.CategoryWithParentName{}, apperror.Wrap(err)
 	}
 
+	images, err := s.dataRepository.ProductImageRepository().ListByProductID(ctx, products.ID)
+	if err != nil {
+		return domain.Product{}, domain.ProductDetails{}, domain.CategoryWithParentName{}, apperror.Wrap(err)
+	}
+	products.Images = images
+
+	variants, err := s.dataRepository.ProductVariantRepository().ListByProductID(ctx, products.ID)
+	if err != nil {
+		return domain.Product{}, domain.ProductDetails{}, domain.CategoryWithParentName{}, apperror.Wrap(err)
+	}
+	products.Variants = variants
+
 	return products, productDetail, category, nil
 }
 
-func (s *productService) GetProductLocation(ctx context.Context, query domain.ProductsQuery) ([]domain.Product, domain.PageInfo, error) {
+func (s *productService) expandSynonyms(ctx context.Context, query domain.ProductsQuery) (domain.ProductsQuery, error) {
+	synonymRepo := s.dataRepository.ProductSynonymRepository()
+
+	words := util.SplitSearchWords(query.Term)
+	if len(words) == 0 {
+		return query, nil
+	}
+
+	synonyms, err := synonymRepo.GetByWords(ctx, words)
+	if err != nil {
+		return domain.ProductsQuery{}, apperror.Wrap(err)
+	}
+
+	query.TermSynonyms = map[string][]string{}
+	for _, syn := range synonyms {
+		query.TermSynonyms[syn.Term] = append(query.TermSynonyms[syn.Term], syn.Synonym)
+		query.TermSynonyms[syn.Synonym] = append(query.TermSynonyms[syn.Synonym], syn.Term)
+	}
+
+	return query, nil
+}
+
+func (s *productService) GetProductLocation(ctx context.Context, query domain.ProductsQuery) ([]domain.Product, []domain.ProductCategoryFacet, domain.PageInfo, error) {
 	productRepo := s.dataRepository.ProductRepository()
 	categoryRepo := s.dataRepository.CategoryRepository()
 
 	if query.CategorySlug != nil {
 		category, err := categoryRepo.GetBySlug(ctx, *query.CategorySlug)
 		if err != nil {
-			return nil, domain.PageInfo{}, apperror.Wrap(err)
+			return nil, nil, domain.PageInfo{}, apperror.Wrap(err)
 		}
 
 		query.CategoryID = &category.ID
 	}
 
+	query, err := s.expandSynonyms(ctx, query)
+	if err != nil {
+		return nil, nil, domain.PageInfo{}, apperror.Wrap(err)
+	}
+
 	products, err := productRepo.GetProductsFromArea(ctx, query)
 	if err != nil {
-		return nil, domain.PageInfo{}, apperror.Wrap(err)
+		return nil, nil, domain.PageInfo{}, apperror.Wrap(err)
+	}
+
+	facets, err := productRepo.GetCategoryFacetsFromArea(ctx, query)
+	if err != nil {
+		return nil, nil, domain.PageInfo{}, apperror.Wrap(err)
 	}
 
 	pageInfo, err := productRepo.GetPageInfoFromArea(ctx, query)
 	if err != nil {
-		return nil, domain.PageInfo{}, apperror.Wrap(err)
+		return nil, nil, domain.PageInfo{}, apperror.Wrap(err)
 	}
 
 	pageInfo.ItemsPerPage = int(query.Limit)
@@ -152,30 +290,40 @@ func (s *productService) GetProductLocation(ctx context.Context, query domain.Pr
 		pageInfo.PageCount = (int(pageInfo.ItemCount) + pageInfo.ItemsPerPage - 1) / pageInfo.ItemsPerPage
 	}
 
-	return products, pageInfo, nil
+	return products, facets, pageInfo, nil
 }
 
-func (s *productService) GetProducts(ctx context.Context, query domain.ProductsQuery) ([]domain.Product, domain.PageInfo, error) {
+func (s *productService) GetProducts(ctx context.Context, query domain.ProductsQuery) ([]domain.Product, []domain.ProductCategoryFacet, domain.PageInfo, error) {
 	productRepo := s.dataRepository.ProductRepository()
 	categoryRepo := s.dataRepository.CategoryRepository()
 
 	if query.CategorySlug != nil {
 		category, err := categoryRepo.GetBySlug(ctx, *query.CategorySlug)
 		if err != nil {
-			return nil, domain.PageInfo{}, apperror.Wrap(err)
+			return nil, nil, domain.PageInfo{}, apperror.Wrap(err)
 		}
 
 		query.CategoryID = &category.ID
 	}
 
+	query, err := s.expandSynonyms(ctx, query)
+	if err != nil {
+		return nil, nil, domain.PageInfo{}, apperror.Wrap(err)
+	}
+
 	products, err := productRepo.GetProducts(ctx, query)
 	if err != nil {
-		return nil, domain.PageInfo{}, apperror.Wrap(err)
+		return nil, nil, domain.PageInfo{}, apperror.Wrap(err)
+	}
+
+	facets, err := productRepo.GetCategoryFacets(ctx, query)
+	if err != nil {
+		return nil, nil, domain.PageInfo{}, apperror.Wrap(err)
 	}
 
 	pageInfo, err := productRepo.GetPageInfo(ctx, query)
 	if err != nil {
-		return nil, domain.PageInfo{}, apperror.Wrap(err)
+		return nil, nil, domain.PageInfo{}, apperror.Wrap(err)
 	}
 
 	pageInfo.ItemsPerPage = int(query.Limit)
@@ -189,7 +337,7 @@ func (s *productService) GetProducts(ctx context.Context, query domain.ProductsQ
 		pageInfo.PageCount = (int(pageInfo.ItemCount) + pageInfo.ItemsPerPage - 1) / pageInfo.ItemsPerPage
 	}
 
-	return products, pageInfo, nil
+	return products, facets, pageInfo, nil
 }
 
 func (s *productService) DeleteProducts(ctx context.Context, slug string) error {
@@ -204,6 +352,9 @@ func (s *productService) DeleteProducts(ctx context.Context, slug string) error
 	if err != nil {
 		return apperror.Wrap(err)
 	}
+
+	s.invalidateSuggestions()
+
 	return nil
 }
 
@@ -231,11 +382,14 @@ func (s *productService) UpdateProduct(ctx context.Context, slug string, request
 
 	prod.Name = strings.TrimSpace(strings.ToLower(request.Name))
 
+	var uploaded *domain.ProductImage
 	if file != nil {
-		res, err := s.cloud.UploadImage(ctx, *file, uploader.UploadParams{})
-		if err == nil {
-			prod.Picture = &res.SecureURL
+		img, err := uploadProductImage(ctx, s.cloud, *file)
+		if err != nil {
+			return domain.Product{}, apperror.Wrap(err)
 		}
+		uploaded = &img
+		prod.Picture = &img.URL
 	}
 
 	prod.KeyWord = prod.Name + " " + detail.GenericName
@@ -260,7 +414,318 @@ func (s *productService) UpdateProduct(ctx context.Context, slug string, request
 	prod.ProductDetailId = updatedDetail.ID
 	updatedProduct, err := productRepo.Update(ctx, prod)
 	if err != nil {
+		if uploaded != nil {
+			_ = deleteProductImageFiles(ctx, s.cloud, *uploaded)
+		}
 		return domain.Product{}, apperror.Wrap(err)
 	}
+
+	if uploaded != nil {
+		replaced, err := replacePrimaryImage(ctx, s.dataRepository, updatedProduct.ID, *uploaded)
+		if err != nil {
+			_ = deleteProductImageFiles(ctx, s.cloud, *uploaded)
+			return domain.Product{}, apperror.Wrap(err)
+		}
+
+		if replaced != nil {
+			err = deleteProductImageFiles(ctx, s.cloud, *replaced)
+			if err != nil {
+				return domain.Product{}, apperror.Wrap(err)
+			}
+		}
+	}
+	s.invalidateSuggestions()
+
 	return updatedProduct, nil
 }
+
+// productImportRowError is the error an import batch failed with on the row
+// at line.
+type productImportRowError struct {
+	line int
+	err  error
+}
+
+func (e *productImportRowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.line, e.err)
+}
+
+func (e *productImportRowError) Unwrap() error {
+	return e.err
+}
+
+// productImportRowMessage describes why the database rejected an import row,
+// without the database's own wording. It reports false for failures that
+// aren't about the row's content.
+func productImportRowMessage(err error) (int, string, bool) {
+	var rowErr *productImportRowError
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &rowErr) || !errors.As(rowErr.err, &pgErr) {
+		return 0, "", false
+	}
+
+	switch {
+	case pgErr.Code == "23505":
+		return rowErr.line, "conflicts with an existing product", true
+	case pgErr.Code == "22001":
+		return rowErr.line, "a value is too long", true
+	case strings.HasPrefix(pgErr.Code, "22"):
+		return rowErr.line, "a value is invalid", true
+	case strings.HasPrefix(pgErr.Code, "23"):
+		return rowErr.line, "a value breaks a data constraint", true
+	}
+	return 0, "", false
+}
+
+func (s *productService) importProductsClosure(
+	ctx context.Context,
+	rows []domain.ProductImportRow,
+	categoryIDs map[string]int64,
+) domain.AtomicFunc[domain.ProductImportResult] {
+	return func(dr domain.DataRepository) (domain.ProductImportResult, error) {
+		productRepo := dr.ProductRepository()
+		detailRepo := dr.ProductDetailsRepository()
+
+		result := domain.ProductImportResult{}
+
+		for _, row := range rows {
+			request := row.Product
+			name := strings.TrimSpace(request.Name)
+			slug := util.GenerateSlug(strings.ToLower(name))
+
+			detail := domain.ProductDetails{
+				GenericName:           request.GenericName,
+				Content:               request.Content,
+				Manufacturer:          request.Manufacturer,
+				Composition:           request.Composition,
+				Description:           request.Description,
+				ProductClassification: request.ProductClassification,
+				ProductForm:           request.ProductForm,
+				UnitInPack:            request.UnitInPack,
+				SellingUnit:           request.SellingUnit,
+				Weight:                request.Weight,
+				Height:                request.Height,
+				Length:                request.Length,
+				Width:                 request.Width,
+			}
+
+			prod, err := productRepo.GetBySlug(ctx, slug)
+			if err != nil && !apperror.IsErrorCode(err, apperror.CodeNotFound) {
+				return domain.ProductImportResult{}, &productImportRowError{line: row.Line, err: err}
+			}
+			exists := err == nil
+
+			if exists {
+				detail.ID = prod.ProductDetailId
+				_, err = detailRepo.Update(ctx, detail)
+			} else {
+				detail, err = detailRepo.Add(ctx, detail)
+			}
+			if err != nil {
+				return domain.ProductImportResult{}, &productImportRowError{line: row.Line, err: err}
+			}
+
+			prod.Name = name
+			prod.Slug = slug
+			prod.ProductCategoryId = categoryIDs[row.CategorySlug]
+			prod.ProductDetailId = detail.ID
+			prod.KeyWord = name + " " + request.Manufacturer + " " + request.Composition
+
+			if exists {
+				_, err = productRepo.Update(ctx, prod)
+				result.Updated++
+			} else {
+				prod.Status = domain.ProductStatusPublished
+				_, err = productRepo.Add(ctx, prod)
+				result.Created++
+			}
+			if err != nil {
+				return domain.ProductImportResult{}, &productImportRowError{line: row.Line, err: err}
+			}
+		}
+
+		return result, nil
+	}
+}
+
+func (s *productService) ImportProducts(ctx context.Context, rows []domain.ProductImportRow) (domain.ProductImportResult, error) {
+	categoryRepo := s.dataRepository.CategoryRepository()
+
+	result := domain.ProductImportResult{
+		Errors: []domain.ProductImportRowError{},
+	}
+
+	categoryIDs := map[string]int64{}
+	seenSlugs := map[string]int{}
+	valid := make([]domain.ProductImportRow, 0, len(rows))
+
+	for _, row := range rows {
+		slug := util.GenerateSlug(strings.ToLower(strings.TrimSpace(row.Product.Name)))
+		if line, ok := seenSlugs[slug]; ok {
+			result.Errors = append(result.Errors, domain.ProductImportRowError{
+				Line:    row.Line,
+				Message: fmt.Sprintf("duplicate of product on line %d", line),
+			})
+			continue
+		}
+		seenSlugs[slug] = row.Line
+
+		if _, ok := categoryIDs[row.CategorySlug]; !ok {
+			c, err := categoryRepo.GetBySlug(ctx, row.CategorySlug)
+			if err != nil && !apperror.IsErrorCode(err, apperror.CodeNotFound) {
+				return domain.ProductImportResult{}, apperror.Wrap(err)
+			}
+			if err != nil {
+				result.Errors = append(result.Errors, domain.ProductImportRowError{
+					Line:    row.Line,
+					Message: fmt.Sprintf("category with slug %s not found", row.CategorySlug),
+				})
+				continue
+			}
+			categoryIDs[row.CategorySlug] = c.ID
+		}
+
+		valid = append(valid, row)
+	}
+
+	for start := 0; start < len(valid); start += constants.ProductImportBatchSize {
+		end := start + constants.ProductImportBatchSize
+		if end > len(valid) {
+			end = len(valid)
+		}
+		batch := valid[start:end]
+
+		batchResult, err := domain.RunAtomic(
+			s.dataRepository,
+			ctx,
+			s.importProductsClosure(ctx, batch, categoryIDs),
+		)
+		if err != nil {
+			line, msg, ok := productImportRowMessage(err)
+			if !ok {
+				return domain.ProductImportResult{}, apperror.Wrap(err)
+			}
+
+			for _, row := range batch {
+				rowMsg := fmt.Sprintf("batch rolled back because of line %d", line)
+				if row.Line == line {
+					rowMsg = msg
+				}
+				result.Errors = append(result.Errors, domain.ProductImportRowError{
+					Line:    row.Line,
+					Message: rowMsg,
+				})
+			}
+			continue
+		}
+
+		result.Created += batchResult.Created
+		result.Updated += batchResult.Updated
+	}
+
+	if result.Created+result.Updated > 0 {
+		s.invalidateSuggestions()
+	}
+
+	return result, nil
+}
+
+func (s *productService) ExportCatalog(ctx context.Context, format string) (domain.ExportFile, error) {
+	productRepo := s.dataRepository.ProductRepository()
+
+	entries, err := productRepo.GetCatalog(ctx)
+	if err != nil {
+		return domain.ExportFile{}, apperror.Wrap(err)
+	}
+
+	records := make([][]string, 0, len(entries)+1)
+	records = append(records, constants.ProductCatalogHeader)
+	for _, e := range entries {
+		d := e.Details
+		records = append(records, []string{
+			e.Product.Name,
+			e.CategorySlug,
+			d.GenericName,
+			d.Composition,
+			d.Content,
+			d.Manufacturer,
+			d.Description,
+			d.ProductClassification,
+			d.ProductForm,
+			d.UnitInPack,
+			d.SellingUnit,
+			strconv.FormatFloat(d.Weight, 'f', -1, 64),
+			strconv.FormatFloat(d.Height, 'f', -1, 64),
+			strconv.FormatFloat(d.Length, 'f', -1, 64),
+			strconv.FormatFloat(d.Width, 'f', -1, 64),
+		})
+	}
+
+	content, err := util.WriteSpreadsheet(records, format)
+	if err != nil {
+		return domain.ExportFile{}, apperror.Wrap(err)
+	}
+
+	contentType := constants.MimeCSV
+	if format == constants.ExportFormatXLSX {
+		contentType = constants.MimeXLSX
+	}
+
+	return domain.ExportFile{
+		Name:        "catalog." + format,
+		ContentType: contentType,
+		Content:     content,
+	}, nil
+}
+
+func (s *productService) ChangeStatesClosure(
+	ctx context.Context,
+	change domain.ProductStateChange,
+) domain.AtomicFunc[[]domain.Product] {
+	return func(dr domain.DataRepository) ([]domain.Product, error) {
+		productRepo := dr.ProductRepository()
+
+		ids := make([]int64, 0, len(change.Slugs))
+		seen := map[int64]bool{}
+		for _, slug := range change.Slugs {
+			product, err := productRepo.GetBySlug(ctx, slug)
+			if err != nil {
+				if apperror.IsErrorCode(err, apperror.CodeNotFound) {
+					return nil, apperror.NewEntityNotFound(fmt.Sprintf("products with slug %s", slug))
+				}
+				return nil, apperror.Wrap(err)
+			}
+
+			if !seen[product.ID] {
+				seen[product.ID] = true
+				ids = append(ids, product.ID)
+			}
+		}
+
+		products, err := productRepo.BulkUpdateStatus(ctx, ids, change)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		return products, nil
+	}
+}
+
+func (s *productService) ChangeStates(ctx context.Context, change domain.ProductStateChange) ([]domain.Product, error) {
+	if err := validateProductSchedule(change.PublishAt, change.UnpublishAt); err != nil {
+		return nil, err
+	}
+
+	products, err := domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.ChangeStatesClosure(ctx, change),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateSuggestions()
+
+	return products, nil
+}
@@ -0,0 +1,459 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"medichat-be/apperror"
+	"medichat-be/constants"
+	"medichat-be/domain"
+	"medichat-be/util"
+	"mime/multipart"
+
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+)
+
+func uploadProductImage(
+	ctx context.Context,
+	cloud util.CloudinaryProvider,
+	file multipart.File,
+) (domain.ProductImage, error) {
+	data, info, err := util.ReadImage(file, constants.MaxImageSize)
+	if errors.Is(err, util.ErrImageTooLarge) {
+		return domain.ProductImage{}, apperror.NewImageSizeExceeded("500kb")
+	}
+	if errors.Is(err, util.ErrImageUnsupported) {
+		return domain.ProductImage{}, apperror.NewRestrictredFileType(constants.ImageJpeg, constants.ImagePng)
+	}
+	if err != nil {
+		return domain.ProductImage{}, apperror.Wrap(err)
+	}
+
+	if info.Width < constants.MinImageDimension || info.Width > constants.MaxImageDimension ||
+		info.Height < constants.MinImageDimension || info.Height > constants.MaxImageDimension {
+		return domain.ProductImage{}, apperror.NewInvalidImageDimension(
+			constants.MinImageDimension, constants.MaxImageDimension,
+		)
+	}
+
+	thumbnail, err := util.MakeThumbnail(data, info.ContentType, constants.ThumbnailDimension)
+	if err != nil {
+		return domain.ProductImage{}, apperror.Wrap(err)
+	}
+
+	res, err := cloud.UploadImage(ctx, util.NewMemoryFile(data), uploader.UploadParams{})
+	if err != nil {
+		return domain.ProductImage{}, apperror.Wrap(err)
+	}
+
+	thumbRes, err := cloud.UploadImage(ctx, util.NewMemoryFile(thumbnail), uploader.UploadParams{})
+	if err != nil {
+		_ = cloud.DeleteImage(ctx, res.PublicID)
+		return domain.ProductImage{}, apperror.Wrap(err)
+	}
+
+	return domain.ProductImage{
+		URL:               res.SecureURL,
+		PublicID:          res.PublicID,
+		ThumbnailURL:      thumbRes.SecureURL,
+		ThumbnailPublicID: thumbRes.PublicID,
+		Width:             info.Width,
+		Height:            info.Height,
+	}, nil
+}
+
+func deleteProductImageFiles(
+	ctx context.Context,
+	cloud util.CloudinaryProvider,
+	img domain.ProductImage,
+) error {
+	err := cloud.DeleteImage(ctx, img.PublicID)
+	if err != nil {
+		return apperror.Wrap(err)
+	}
+
+	err = cloud.DeleteImage(ctx, img.ThumbnailPublicID)
+	if err != nil {
+		return apperror.Wrap(err)
+	}
+
+	return nil
+}
+
+// syncProductImages renumbers the remaining images, makes sure exactly one
+// of them is primary and mirrors the primary image into Product.Picture.
+func syncProductImages(
+	ctx context.Context,
+	dr domain.DataRepository,
+	productID int64,
+	primaryID int64,
+) ([]domain.ProductImage, error) {
+	productRepo := dr.ProductRepository()
+	imageRepo := dr.ProductImageRepository()
+
+	images, err := imageRepo.ListByProductID(ctx, productID)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	if len(images) > 0 {
+		found := false
+		for _, img := range images {
+			if img.ID == primaryID {
+				found = true
+			}
+		}
+		if !found {
+			primaryID = images[0].ID
+		}
+	}
+
+	var picture *string
+	for i := range images {
+		img := &images[i]
+		isPrimary := img.ID == primaryID
+		if img.Position == i && img.IsPrimary == isPrimary {
+			if isPrimary {
+				picture = &img.URL
+			}
+			continue
+		}
+
+		img.Position = i
+		img.IsPrimary = isPrimary
+		_, err = imageRepo.Update(ctx, *img)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+		if isPrimary {
+			picture = &img.URL
+		}
+	}
+
+	err = productRepo.UpdatePicture(ctx, productID, picture)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	return images, nil
+}
+
+func primaryImageID(images []domain.ProductImage) int64 {
+	for _, img := range images {
+		if img.IsPrimary {
+			return img.ID
+		}
+	}
+	return 0
+}
+
+type productImageService struct {
+	dataRepository domain.DataRepository
+	cloud          util.CloudinaryProvider
+}
+
+type ProductImageServiceOpts struct {
+	DataRepository domain.DataRepository
+	Cloud          util.CloudinaryProvider
+}
+
+func NewProductImageService(opts ProductImageServiceOpts) *productImageService {
+	return &productImageService{
+		dataRepository: opts.DataRepository,
+		cloud:          opts.Cloud,
+	}
+}
+
+func (s *productImageService) List(
+	ctx context.Context,
+	slug string,
+) ([]domain.ProductImage, error) {
+	productRepo := s.dataRepository.ProductRepository()
+	imageRepo := s.dataRepository.ProductImageRepository()
+
+	product, err := productRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	images, err := imageRepo.ListByProductID(ctx, product.ID)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	return images, nil
+}
+
+func (s *productImageService) AddClosure(
+	ctx context.Context,
+	slug string,
+	img domain.ProductImage,
+) domain.AtomicFunc[domain.ProductImage] {
+	return func(dr domain.DataRepository) (domain.ProductImage, error) {
+		productRepo := dr.ProductRepository()
+		imageRepo := dr.ProductImageRepository()
+
+		// Image changes lock the product, so concurrent uploads take turns
+		// counting the images and can't share a position or both be primary.
+		product, err := productRepo.GetBySlugAndLock(ctx, slug)
+		if err != nil {
+			return domain.ProductImage{}, apperror.Wrap(err)
+		}
+
+		count, err := imageRepo.CountByProductID(ctx, product.ID)
+		if err != nil {
+			return domain.ProductImage{}, apperror.Wrap(err)
+		}
+		if count >= constants.MaxProductImages {
+			return domain.ProductImage{}, apperror.NewProductImageLimitReached(constants.MaxProductImages)
+		}
+
+		img.ProductID = product.ID
+		img.Position = count
+		img.IsPrimary = count == 0
+
+		img, err = imageRepo.Add(ctx, img)
+		if err != nil {
+			return domain.ProductImage{}, apperror.Wrap(err)
+		}
+
+		if img.IsPrimary {
+			err = productRepo.UpdatePicture(ctx, product.ID, &img.URL)
+			if err != nil {
+				return domain.ProductImage{}, apperror.Wrap(err)
+			}
+		}
+
+		return img, nil
+	}
+}
+
+func (s *productImageService) Add(
+	ctx context.Context,
+	slug string,
+	file multipart.File,
+) (domain.ProductImage, error) {
+	uploaded, err := uploadProductImage(ctx, s.cloud, file)
+	if err != nil {
+		return domain.ProductImage{}, apperror.Wrap(err)
+	}
+
+	img, err := domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.AddClosure(ctx, slug, uploaded),
+	)
+	if err != nil {
+		_ = deleteProductImageFiles(ctx, s.cloud, uploaded)
+		return domain.ProductImage{}, apperror.Wrap(err)
+	}
+
+	return img, nil
+}
+
+func (s *productImageService) ReorderClosure(
+	ctx context.Context,
+	slug string,
+	imageIDs []int64,
+) domain.AtomicFunc[[]domain.ProductImage] {
+	return func(dr domain.DataRepository) ([]domain.ProductImage, error) {
+		productRepo := dr.ProductRepository()
+		imageRepo := dr.ProductImageRepository()
+
+		product, err := productRepo.GetBySlugAndLock(ctx, slug)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		images, err := imageRepo.ListByProductID(ctx, product.ID)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		byID := make(map[int64]domain.ProductImage, len(images))
+		for _, img := range images {
+			byID[img.ID] = img
+		}
+		if len(imageIDs) != len(images) {
+			return nil, apperror.NewInvalidProductImageOrder()
+		}
+
+		for i, id := range imageIDs {
+			img, ok := byID[id]
+			if !ok {
+				return nil, apperror.NewInvalidProductImageOrder()
+			}
+			delete(byID, id)
+
+			if img.Position == i {
+				continue
+			}
+			img.Position = i
+			_, err = imageRepo.Update(ctx, img)
+			if err != nil {
+				return nil, apperror.Wrap(err)
+			}
+		}
+
+		return syncProductImages(ctx, dr, product.ID, primaryImageID(images))
+	}
+}
+
+func (s *productImageService) Reorder(
+	ctx context.Context,
+	slug string,
+	imageIDs []int64,
+) ([]domain.ProductImage, error) {
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.ReorderClosure(ctx, slug, imageIDs),
+	)
+}
+
+func (s *productImageService) SetPrimaryClosure(
+	ctx context.Context,
+	slug string,
+	id int64,
+) domain.AtomicFunc[[]domain.ProductImage] {
+	return func(dr domain.DataRepository) ([]domain.ProductImage, error) {
+		productRepo := dr.ProductRepository()
+		imageRepo := dr.ProductImageRepository()
+
+		product, err := productRepo.GetBySlugAndLock(ctx, slug)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		img, err := imageRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+		if img.ProductID != product.ID {
+			return nil, apperror.NewEntityNotFound("product image")
+		}
+
+		return syncProductImages(ctx, dr, product.ID, img.ID)
+	}
+}
+
+func (s *productImageService) SetPrimary(
+	ctx context.Context,
+	slug string,
+	id int64,
+) ([]domain.ProductImage, error) {
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.SetPrimaryClosure(ctx, slug, id),
+	)
+}
+
+func (s *productImageService) DeleteClosure(
+	ctx context.Context,
+	slug string,
+	id int64,
+) domain.AtomicFunc[domain.ProductImage] {
+	return func(dr domain.DataRepository) (domain.ProductImage, error) {
+		productRepo := dr.ProductRepository()
+		imageRepo := dr.ProductImageRepository()
+
+		product, err := productRepo.GetBySlugAndLock(ctx, slug)
+		if err != nil {
+			return domain.ProductImage{}, apperror.Wrap(err)
+		}
+
+		img, err := imageRepo.GetByID(ctx, id)
+		if err != nil {
+			return domain.ProductImage{}, apperror.Wrap(err)
+		}
+		if img.ProductID != product.ID {
+			return domain.ProductImage{}, apperror.NewEntityNotFound("product image")
+		}
+
+		err = imageRepo.SoftDeleteByID(ctx, img.ID)
+		if err != nil {
+			return domain.ProductImage{}, apperror.Wrap(err)
+		}
+
+		primaryID := int64(0)
+		if !img.IsPrimary {
+			images, err := imageRepo.ListByProductID(ctx, product.ID)
+			if err != nil {
+				return domain.ProductImage{}, apperror.Wrap(err)
+			}
+			primaryID = primaryImageID(images)
+		}
+
+		_, err = syncProductImages(ctx, dr, product.ID, primaryID)
+		if err != nil {
+			return domain.ProductImage{}, apperror.Wrap(err)
+		}
+
+		return img, nil
+	}
+}
+
+func (s *productImageService) Delete(
+	ctx context.Context,
+	slug string,
+	id int64,
+) error {
+	img, err := domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.DeleteClosure(ctx, slug, id),
+	)
+	if err != nil {
+		return apperror.Wrap(err)
+	}
+
+	return deleteProductImageFiles(ctx, s.cloud, img)
+}
+
+// replacePrimaryImage swaps the current primary image of a product for img
+// and returns the replaced image, if any, so its files can be removed.
+func replacePrimaryImage(
+	ctx context.Context,
+	dr domain.DataRepository,
+	productID int64,
+	img domain.ProductImage,
+) (*domain.ProductImage, error) {
+	imageRepo := dr.ProductImageRepository()
+
+	images, err := imageRepo.ListByProductID(ctx, productID)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	var replaced *domain.ProductImage
+	img.ProductID = productID
+	img.Position = len(images)
+	for i := range images {
+		if images[i].IsPrimary {
+			replaced = &images[i]
+			img.Position = replaced.Position
+		}
+	}
+
+	if replaced != nil {
+		err = imageRepo.SoftDeleteByID(ctx, replaced.ID)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+	} else if len(images) >= constants.MaxProductImages {
+		return nil, apperror.NewProductImageLimitReached(constants.MaxProductImages)
+	}
+
+	img.IsPrimary = true
+	img, err = imageRepo.Add(ctx, img)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	_, err = syncProductImages(ctx, dr, productID, img.ID)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	return replaced, nil
+}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"medichat-be/logger"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	suggestKindProduct = iota
+	suggestKindCategory
+	suggestKindGenericName
+)
+
+// suggestBucketLen is the longest prefix, in runes, the index keeps a ranked
+// bucket for. Longer prefixes are looked up in the bucket of their start.
+const suggestBucketLen = 3
+
+type suggestEntry struct {
+	key  string
+	full bool
+	kind int
+	ref  int
+}
+
+type suggestIndex struct {
+	entries []suggestEntry
+	// buckets holds the entries by the prefixes of their key up to
+	// suggestBucketLen runes, each ranked best first.
+	buckets map[string][]suggestEntry
+
+	products     []domain.ProductSuggestion
+	categories   []domain.ProductSuggestion
+	genericNames []string
+
+	// expiresAt is the next scheduled publish or unpublish time, after
+	// which the index no longer matches the live products.
+	expiresAt *time.Time
+}
+
+func (idx *suggestIndex) add(text string, kind int, ref int) {
+	lower := strings.ToLower(strings.TrimSpace(text))
+	if lower == "" {
+		return
+	}
+
+	atWordStart := true
+	for i, r := range lower {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && atWordStart {
+			idx.entries = append(idx.entries, suggestEntry{
+				key:  lower[i:],
+				full: i == 0,
+				kind: kind,
+				ref:  ref,
+			})
+		}
+		atWordStart = !isWord
+	}
+}
+
+func (idx *suggestIndex) name(e suggestEntry) string {
+	switch e.kind {
+	case suggestKindProduct:
+		return idx.products[e.ref].Name
+	case suggestKindCategory:
+		return idx.categories[e.ref].Name
+	default:
+		return idx.genericNames[e.ref]
+	}
+}
+
+// less ranks entries matching the whole name first, then shorter names.
+func (idx *suggestIndex) less(a, b suggestEntry) bool {
+	if a.full != b.full {
+		return a.full
+	}
+	an, bn := idx.name(a), idx.name(b)
+	if len(an) != len(bn) {
+		return len(an) < len(bn)
+	}
+	return an < bn
+}
+
+// rank sorts the entries and fills the buckets.
+func (idx *suggestIndex) rank() {
+	sort.SliceStable(idx.entries, func(i, j int) bool {
+		return idx.less(idx.entries[i], idx.entries[j])
+	})
+
+	idx.buckets = map[string][]suggestEntry{}
+	for _, e := range idx.entries {
+		for _, b := range suggestBuckets(e.key) {
+			idx.buckets[b] = append(idx.buckets[b], e)
+		}
+	}
+}
+
+// suggestBuckets returns the prefixes of key up to suggestBucketLen runes.
+func suggestBuckets(key string) []string {
+	var res []string
+	for i := range key {
+		if i > 0 {
+			res = append(res, key[:i])
+		}
+		if len(res) == suggestBucketLen {
+			return res
+		}
+	}
+	return append(res, key)
+}
+
+func (idx *suggestIndex) lookup(prefix string, limit int) domain.ProductSuggestions {
+	ret := domain.ProductSuggestions{
+		Products:     []domain.ProductSuggestion{},
+		Categories:   []domain.ProductSuggestion{},
+		GenericNames: []string{},
+	}
+
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" {
+		return ret
+	}
+
+	bucket := prefix
+	if runes := []rune(prefix); len(runes) > suggestBucketLen {
+		bucket = string(runes[:suggestBucketLen])
+	}
+
+	seen := map[suggestEntry]bool{}
+	for _, m := range idx.buckets[bucket] {
+		if len(ret.Products) >= limit && len(ret.Categories) >= limit && len(ret.GenericNames) >= limit {
+			break
+		}
+		if !strings.HasPrefix(m.key, prefix) {
+			continue
+		}
+
+		key := suggestEntry{kind: m.kind, ref: m.ref}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		switch m.kind {
+		case suggestKindProduct:
+			if len(ret.Products) < limit {
+				ret.Products = append(ret.Products, idx.products[m.ref])
+			}
+		case suggestKindCategory:
+			if len(ret.Categories) < limit {
+				ret.Categories = append(ret.Categories, idx.categories[m.ref])
+			}
+		case suggestKindGenericName:
+			if len(ret.GenericNames) < limit {
+				ret.GenericNames = append(ret.GenericNames, idx.genericNames[m.ref])
+			}
+		}
+	}
+
+	return ret
+}
+
+type productSuggestService struct {
+	dataRepository domain.DataRepository
+	logger         logger.Logger
+
+	indexMu sync.RWMutex
+	index   *suggestIndex
+	buildMu sync.Mutex
+
+	stateMu    sync.Mutex
+	stale      bool
+	rebuilding bool
+}
+
+type ProductSuggestServiceOpts struct {
+	DataRepository domain.DataRepository
+	Logger         logger.Logger
+}
+
+func NewProductSuggestService(opts ProductSuggestServiceOpts) *productSuggestService {
+	return &productSuggestService{
+		dataRepository: opts.DataRepository,
+		logger:         opts.Logger,
+	}
+}
+
+func (s *productSuggestService) Suggest(
+	ctx context.Context,
+	query string,
+	limit int,
+) (domain.ProductSuggestions, error) {
+	idx := s.currentIndex()
+
+	if idx == nil {
+		var err error
+		idx, err = s.buildFirstIndex(ctx)
+		if err != nil {
+			return domain.ProductSuggestions{}, apperror.Wrap(err)
+		}
+	} else if idx.expiresAt != nil && !time.Now().Before(*idx.expiresAt) {
+		// Keep serving the old index while it is rebuilt in the background.
+		s.Invalidate()
+	}
+
+	return idx.lookup(query, limit), nil
+}
+
+func (s *productSuggestService) currentIndex() *suggestIndex {
+	s.indexMu.RLock()
+	defer s.indexMu.RUnlock()
+
+	return s.index
+}
+
+// buildFirstIndex builds the index when there is none to serve yet. Callers
+// arriving while it is built wait for that build instead of starting their
+// own.
+func (s *productSuggestService) buildFirstIndex(ctx context.Context) (*suggestIndex, error) {
+	s.buildMu.Lock()
+	defer s.buildMu.Unlock()
+
+	if idx := s.currentIndex(); idx != nil {
+		return idx, nil
+	}
+
+	err := s.rebuild(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.currentIndex(), nil
+}
+
+func (s *productSuggestService) Rebuild(ctx context.Context) error {
+	s.buildMu.Lock()
+	defer s.buildMu.Unlock()
+
+	return s.rebuild(ctx)
+}
+
+func (s *productSuggestService) rebuild(ctx context.Context) error {
+	productRepo := s.dataRepository.ProductRepository()
+	categoryRepo := s.dataRepository.CategoryRepository()
+
+	sources, err := productRepo.GetSuggestionSources(ctx)
+	if err != nil {
+		return apperror.Wrap(err)
+	}
+
+	categories, err := categoryRepo.GetCategories(ctx, domain.DefaultCategoriesQuery())
+	if err != nil {
+		return apperror.Wrap(err)
+	}
+
+	expiresAt, err := productRepo.GetNextPublishingChange(ctx)
+	if err != nil {
+		return apperror.Wrap(err)
+	}
+
+	idx := &suggestIndex{expiresAt: expiresAt}
+
+	genericNames := map[string]bool{}
+	for _, src := range sources {
+		idx.add(src.Name, suggestKindProduct, len(idx.products))
+		idx.products = append(idx.products, domain.ProductSuggestion{
+			Name: src.Name,
+			Slug: src.Slug,
+		})
+
+		key := strings.ToLower(strings.TrimSpace(src.GenericName))
+		if key != "" && !genericNames[key] {
+			genericNames[key] = true
+			idx.add(src.GenericName, suggestKindGenericName, len(idx.genericNames))
+			idx.genericNames = append(idx.genericNames, src.GenericName)
+		}
+	}
+
+	for _, c := range categories {
+		idx.add(c.Name, suggestKindCategory, len(idx.categories))
+		idx.categories = append(idx.categories, domain.ProductSuggestion{
+			Name: c.Name,
+			Slug: c.Slug,
+		})
+	}
+
+	idx.rank()
+
+	s.indexMu.Lock()
+	s.index = idx
+	s.indexMu.Unlock()
+
+	return nil
+}
+
+func (s *productSuggestService) Invalidate() {
+	s.stateMu.Lock()
+	s.stale = true
+	if s.rebuilding {
+		s.stateMu.Unlock()
+		return
+	}
+	s.rebuilding = true
+	s.stateMu.Unlock()
+
+	go s.rebuildWhileStale()
+}
+
+func (s *productSuggestService) rebuildWhileStale() {
+	for {
+		s.stateMu.Lock()
+		if !s.stale {
+			s.rebuilding = false
+			s.stateMu.Unlock()
+			return
+		}
+		s.stale = false
+		s.stateMu.Unlock()
+
+		if err := s.Rebuild(context.Background()); err != nil {
+			s.logger.Errorf("rebuilding product suggestion index: %v", err)
+		}
+	}
+}
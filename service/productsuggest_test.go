@@ -0,0 +1,106 @@
+package service
+
+import (
+	"medichat-be/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSuggestIndex(products []string, categories []string, genericNames []string) *suggestIndex {
+	idx := &suggestIndex{}
+	for _, name := range products {
+		idx.add(name, suggestKindProduct, len(idx.products))
+		idx.products = append(idx.products, domain.ProductSuggestion{Name: name})
+	}
+	for _, name := range categories {
+		idx.add(name, suggestKindCategory, len(idx.categories))
+		idx.categories = append(idx.categories, domain.ProductSuggestion{Name: name})
+	}
+	for _, name := range genericNames {
+		idx.add(name, suggestKindGenericName, len(idx.genericNames))
+		idx.genericNames = append(idx.genericNames, name)
+	}
+	idx.rank()
+	return idx
+}
+
+func suggestionNames(s []domain.ProductSuggestion) []string {
+	names := []string{}
+	for _, p := range s {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func Test_suggestIndex_lookup(t *testing.T) {
+	idx := newTestSuggestIndex(
+		[]string{"Paracetamol 500 mg", "Panadol Extra", "Obat Batuk Pahit", "Panadol", "Vitamin C"},
+		[]string{"Obat Panas", "Vitamin"},
+		[]string{"Paracetamol"},
+	)
+
+	tests := []struct {
+		name string
+
+		prefix string
+		limit  int
+
+		wantProducts     []string
+		wantCategories   []string
+		wantGenericNames []string
+	}{
+		{
+			name: "should rank names starting with the prefix first, shorter first",
+
+			prefix: "pa",
+			limit:  5,
+
+			wantProducts:     []string{"Panadol", "Panadol Extra", "Paracetamol 500 mg", "Obat Batuk Pahit"},
+			wantCategories:   []string{"Obat Panas"},
+			wantGenericNames: []string{"Paracetamol"},
+		},
+		{
+			name: "should stop at the limit",
+
+			prefix: "p",
+			limit:  2,
+
+			wantProducts:     []string{"Panadol", "Panadol Extra"},
+			wantCategories:   []string{"Obat Panas"},
+			wantGenericNames: []string{"Paracetamol"},
+		},
+		{
+			name: "should narrow a bucket by a longer prefix",
+
+			prefix: "Panadol E",
+			limit:  5,
+
+			wantProducts:     []string{"Panadol Extra"},
+			wantCategories:   []string{},
+			wantGenericNames: []string{},
+		},
+		{
+			name: "should suggest nothing for a blank prefix",
+
+			prefix: "  ",
+			limit:  5,
+
+			wantProducts:     []string{},
+			wantCategories:   []string{},
+			wantGenericNames: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			got := idx.lookup(tt.prefix, tt.limit)
+
+			// then
+			assert.Equal(t, tt.wantProducts, suggestionNames(got.Products))
+			assert.Equal(t, tt.wantCategories, suggestionNames(got.Categories))
+			assert.Equal(t, tt.wantGenericNames, got.GenericNames)
+		})
+	}
+}
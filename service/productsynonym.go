@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"strings"
+)
+
+type productSynonymService struct {
+	dataRepository domain.DataRepository
+}
+
+type ProductSynonymServiceOpts struct {
+	DataRepository domain.DataRepository
+}
+
+func NewProductSynonymService(opts ProductSynonymServiceOpts) *productSynonymService {
+	return &productSynonymService{
+		dataRepository: opts.DataRepository,
+	}
+}
+
+func (s *productSynonymService) List(
+	ctx context.Context,
+) ([]domain.ProductSynonym, error) {
+	synonymRepo := s.dataRepository.ProductSynonymRepository()
+
+	synonyms, err := synonymRepo.List(ctx)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	return synonyms, nil
+}
+
+func (s *productSynonymService) Create(
+	ctx context.Context,
+	synonym domain.ProductSynonym,
+) (domain.ProductSynonym, error) {
+	synonymRepo := s.dataRepository.ProductSynonymRepository()
+
+	synonym.Term = strings.TrimSpace(strings.ToLower(synonym.Term))
+	synonym.Synonym = strings.TrimSpace(strings.ToLower(synonym.Synonym))
+
+	if synonym.Term == synonym.Synonym {
+		return domain.ProductSynonym{}, apperror.NewSynonymSameAsTerm()
+	}
+
+	exists, err := synonymRepo.IsExist(ctx, synonym.Term, synonym.Synonym)
+	if err != nil {
+		return domain.ProductSynonym{}, apperror.Wrap(err)
+	}
+	if exists {
+		return domain.ProductSynonym{}, apperror.NewAlreadyExists("product synonym")
+	}
+
+	synonym, err = synonymRepo.Add(ctx, synonym)
+	if err != nil {
+		return domain.ProductSynonym{}, apperror.Wrap(err)
+	}
+
+	return synonym, nil
+}
+
+func (s *productSynonymService) Delete(
+	ctx context.Context,
+	id int64,
+) error {
+	synonymRepo := s.dataRepository.ProductSynonymRepository()
+
+	err := synonymRepo.SoftDeleteByID(ctx, id)
+	if err != nil {
+		return apperror.Wrap(err)
+	}
+
+	return nil
+}
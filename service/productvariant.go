@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"strings"
+)
+
+// resolveVariant checks that variantID belongs to the product. Products with
+// variants must always be referenced through one of them.
+func resolveVariant(
+	ctx context.Context,
+	dr domain.DataRepository,
+	productID int64,
+	variantID *int64,
+) (*domain.ProductVariant, error) {
+	variantRepo := dr.ProductVariantRepository()
+
+	if variantID == nil {
+		variants, err := variantRepo.ListByProductID(ctx, productID)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+		if len(variants) > 0 {
+			return nil, apperror.NewProductVariantRequired()
+		}
+		return nil, nil
+	}
+
+	variant, err := variantRepo.GetByID(ctx, *variantID)
+	if err != nil {
+		if apperror.IsErrorCode(err, apperror.CodeNotFound) {
+			return nil, apperror.NewEntityNotFound("product variant")
+		}
+		return nil, apperror.Wrap(err)
+	}
+	if variant.ProductID != productID {
+		return nil, apperror.NewEntityNotFound("product variant")
+	}
+
+	return &variant, nil
+}
+
+func variantRef(v *domain.ProductVariant) *domain.ProductVariantRef {
+	if v == nil {
+		return nil
+	}
+	return &domain.ProductVariantRef{ID: v.ID, Name: v.Name}
+}
+
+func variantIDOf(v *domain.ProductVariant) *int64 {
+	if v == nil {
+		return nil
+	}
+	return &v.ID
+}
+
+type productVariantService struct {
+	dataRepository domain.DataRepository
+}
+
+type ProductVariantServiceOpts struct {
+	DataRepository domain.DataRepository
+}
+
+func NewProductVariantService(opts ProductVariantServiceOpts) *productVariantService {
+	return &productVariantService{
+		dataRepository: opts.DataRepository,
+	}
+}
+
+func (s *productVariantService) List(
+	ctx context.Context,
+	slug string,
+) ([]domain.ProductVariant, error) {
+	productRepo := s.dataRepository.ProductRepository()
+	variantRepo := s.dataRepository.ProductVariantRepository()
+
+	product, err := productRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	variants, err := variantRepo.ListByProductID(ctx, product.ID)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	return variants, nil
+}
+
+// CreateClosure adds a variant to the product. Stocks without a variant
+// can't be sold once the product has variants, so the product must not have
+// any left.
+func (s *productVariantService) CreateClosure(
+	ctx context.Context,
+	slug string,
+	v domain.ProductVariant,
+) domain.AtomicFunc[domain.ProductVariant] {
+	return func(dr domain.DataRepository) (domain.ProductVariant, error) {
+		productRepo := dr.ProductRepository()
+		variantRepo := dr.ProductVariantRepository()
+		stockRepo := dr.StockRepository()
+
+		product, err := productRepo.GetBySlugAndLock(ctx, slug)
+		if err != nil {
+			return domain.ProductVariant{}, apperror.Wrap(err)
+		}
+
+		variantless, err := stockRepo.IsExistWithoutVariantByProductID(ctx, product.ID)
+		if err != nil {
+			return domain.ProductVariant{}, apperror.Wrap(err)
+		}
+		if variantless {
+			return domain.ProductVariant{}, apperror.NewProductHasStockWithoutVariant()
+		}
+
+		v.ProductID = product.ID
+		v.Name = strings.TrimSpace(v.Name)
+
+		exists, err := variantRepo.IsExistByProductIDAndName(ctx, product.ID, v.Name)
+		if err != nil {
+			return domain.ProductVariant{}, apperror.Wrap(err)
+		}
+		if exists {
+			return domain.ProductVariant{}, apperror.NewAlreadyExists("product variant")
+		}
+
+		v, err = variantRepo.Add(ctx, v)
+		if err != nil {
+			return domain.ProductVariant{}, apperror.Wrap(err)
+		}
+
+		return v, nil
+	}
+}
+
+func (s *productVariantService) Create(
+	ctx context.Context,
+	slug string,
+	v domain.ProductVariant,
+) (domain.ProductVariant, error) {
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.CreateClosure(ctx, slug, v),
+	)
+}
+
+func (s *productVariantService) UpdateClosure(
+	ctx context.Context,
+	slug string,
+	v domain.ProductVariant,
+) domain.AtomicFunc[domain.ProductVariant] {
+	return func(dr domain.DataRepository) (domain.ProductVariant, error) {
+		productRepo := dr.ProductRepository()
+		variantRepo := dr.ProductVariantRepository()
+
+		product, err := productRepo.GetBySlug(ctx, slug)
+		if err != nil {
+			return domain.ProductVariant{}, apperror.Wrap(err)
+		}
+
+		old, err := resolveVariant(ctx, dr, product.ID, &v.ID)
+		if err != nil {
+			return domain.ProductVariant{}, apperror.Wrap(err)
+		}
+
+		v.ProductID = product.ID
+		v.Name = strings.TrimSpace(v.Name)
+
+		if !strings.EqualFold(old.Name, v.Name) {
+			exists, err := variantRepo.IsExistByProductIDAndName(ctx, product.ID, v.Name)
+			if err != nil {
+				return domain.ProductVariant{}, apperror.Wrap(err)
+			}
+			if exists {
+				return domain.ProductVariant{}, apperror.NewAlreadyExists("product variant")
+			}
+		}
+
+		v, err = variantRepo.Update(ctx, v)
+		if err != nil {
+			return domain.ProductVariant{}, apperror.Wrap(err)
+		}
+
+		return v, nil
+	}
+}
+
+func (s *productVariantService) Update(
+	ctx context.Context,
+	slug string,
+	v domain.ProductVariant,
+) (domain.ProductVariant, error) {
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.UpdateClosure(ctx, slug, v),
+	)
+}
+
+func (s *productVariantService) DeleteClosure(
+	ctx context.Context,
+	slug string,
+	id int64,
+) domain.AtomicFunc[any] {
+	return func(dr domain.DataRepository) (any, error) {
+		productRepo := dr.ProductRepository()
+		variantRepo := dr.ProductVariantRepository()
+		stockRepo := dr.StockRepository()
+
+		product, err := productRepo.GetBySlug(ctx, slug)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		_, err = resolveVariant(ctx, dr, product.ID, &id)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		inUse, err := stockRepo.IsExistByVariantID(ctx, id)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+		if inUse {
+			return nil, apperror.NewProductVariantInUse()
+		}
+
+		err = variantRepo.SoftDeleteByID(ctx, id)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		return nil, nil
+	}
+}
+
+func (s *productVariantService) Delete(
+	ctx context.Context,
+	slug string,
+	id int64,
+) error {
+	_, err := domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.DeleteClosure(ctx, slug, id),
+	)
+	return err
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"time"
+)
+
+type promotionService struct {
+	dataRepository domain.DataRepository
+}
+
+type PromotionServiceOpts struct {
+	DataRepository domain.DataRepository
+}
+
+func NewPromotionService(opts PromotionServiceOpts) *promotionService {
+	return &promotionService{
+		dataRepository: opts.DataRepository,
+	}
+}
+
+func (s *promotionService) List(
+	ctx context.Context,
+	det domain.PromotionListDetails,
+) ([]domain.Promotion, domain.PageInfo, error) {
+	promotionRepo := s.dataRepository.PromotionRepository()
+
+	pageInfo, err := promotionRepo.GetPageInfo(ctx, det)
+	if err != nil {
+		return nil, domain.PageInfo{}, apperror.Wrap(err)
+	}
+
+	promotions, err := promotionRepo.List(ctx, det)
+	if err != nil {
+		return nil, domain.PageInfo{}, apperror.Wrap(err)
+	}
+
+	return promotions, pageInfo, nil
+}
+
+func (s *promotionService) GetByID(ctx context.Context, id int64) (domain.Promotion, error) {
+	promotion, err := s.dataRepository.PromotionRepository().GetByID(ctx, id)
+	if err != nil {
+		return domain.Promotion{}, apperror.Wrap(err)
+	}
+
+	return promotion, nil
+}
+
+func (s *promotionService) Create(ctx context.Context, p domain.Promotion) (domain.Promotion, error) {
+	err := checkPromotion(ctx, s.dataRepository, p)
+	if err != nil {
+		return domain.Promotion{}, apperror.Wrap(err)
+	}
+
+	promotion, err := s.dataRepository.PromotionRepository().Add(ctx, p)
+	if err != nil {
+		return domain.Promotion{}, apperror.Wrap(err)
+	}
+
+	return promotion, nil
+}
+
+func (s *promotionService) UpdateClosure(
+	ctx context.Context,
+	p domain.Promotion,
+) domain.AtomicFunc[domain.Promotion] {
+	return func(dr domain.DataRepository) (domain.Promotion, error) {
+		promotionRepo := dr.PromotionRepository()
+
+		old, err := promotionRepo.GetByIDAndLock(ctx, p.ID)
+		if err != nil {
+			return domain.Promotion{}, apperror.Wrap(err)
+		}
+
+		p.UsageCount = old.UsageCount
+		if p.UsageLimit != nil && *p.UsageLimit < p.UsageCount {
+			return domain.Promotion{}, apperror.NewPromotionLimitBelowUsage(p.UsageCount)
+		}
+
+		err = checkPromotion(ctx, dr, p)
+		if err != nil {
+			return domain.Promotion{}, apperror.Wrap(err)
+		}
+
+		promotion, err := promotionRepo.Update(ctx, p)
+		if err != nil {
+			return domain.Promotion{}, apperror.Wrap(err)
+		}
+
+		return promotion, nil
+	}
+}
+
+// Update replaces the rules of a promotion. Order items it was already
+// applied to keep the discount they got.
+func (s *promotionService) Update(ctx context.Context, p domain.Promotion) (domain.Promotion, error) {
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.UpdateClosure(ctx, p),
+	)
+}
+
+func (s *promotionService) Delete(ctx context.Context, id int64) error {
+	err := s.dataRepository.PromotionRepository().SoftDeleteByID(ctx, id)
+	if err != nil {
+		return apperror.Wrap(err)
+	}
+
+	return nil
+}
+
+// checkPromotion validates the rules of a promotion and that its scope
+// points at an existing product, category or pharmacy.
+func checkPromotion(ctx context.Context, dr domain.DataRepository, p domain.Promotion) error {
+	if p.DiscountType == domain.PromotionDiscountPercentage && p.DiscountValue > 100 {
+		return apperror.NewPromotionPercentageTooHigh()
+	}
+	if p.EndsAt != nil && !p.EndsAt.After(p.StartsAt) {
+		return apperror.NewPromotionEndsBeforeStart()
+	}
+
+	var err error
+	switch p.Scope {
+	case domain.PromotionScopeProduct:
+		if p.ProductID == nil || p.CategoryID != nil || p.PharmacyID != nil {
+			return apperror.NewPromotionScopeTarget(p.Scope)
+		}
+		_, err = dr.ProductRepository().GetById(ctx, *p.ProductID)
+	case domain.PromotionScopeCategory:
+		if p.CategoryID == nil || p.ProductID != nil || p.PharmacyID != nil {
+			return apperror.NewPromotionScopeTarget(p.Scope)
+		}
+		_, err = dr.CategoryRepository().GetById(ctx, *p.CategoryID)
+	case domain.PromotionScopePharmacy:
+		if p.PharmacyID == nil || p.ProductID != nil || p.CategoryID != nil {
+			return apperror.NewPromotionScopeTarget(p.Scope)
+		}
+		_, err = dr.PharmacyRepository().GetByID(ctx, *p.PharmacyID)
+	}
+	if apperror.IsErrorCode(err, apperror.CodeNotFound) {
+		return apperror.NewEntityNotFound(p.Scope)
+	}
+	return err
+}
+
+// promotionPicker finds the best promotion for each item of a cart. It
+// counts the uses it hands out so a nearly exhausted promotion isn't given
+// to more items than it has uses left.
+type promotionPicker struct {
+	dr   domain.DataRepository
+	at   time.Time
+	used map[int64]int
+}
+
+func newPromotionPicker(dr domain.DataRepository, at time.Time) *promotionPicker {
+	return &promotionPicker{
+		dr:   dr,
+		at:   at,
+		used: map[int64]int{},
+	}
+}
+
+// pick returns the promotion giving the largest discount on a unit priced
+// at price, or nil when none applies. Ties go to the older promotion.
+func (pp *promotionPicker) pick(
+	ctx context.Context,
+	t domain.PromotionTarget,
+	price int,
+) (*domain.Promotion, error) {
+	promotions, err := pp.dr.PromotionRepository().ListApplicable(ctx, t, pp.at)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *domain.Promotion
+	for i := range promotions {
+		p := &promotions[i]
+		if !p.IsAvailable(pp.used[p.ID] + 1) {
+			continue
+		}
+		if p.Discount(price) == 0 {
+			continue
+		}
+		if best == nil || p.Discount(price) > best.Discount(price) {
+			best = p
+		}
+	}
+
+	if best != nil {
+		pp.used[best.ID]++
+	}
+	return best, nil
+}
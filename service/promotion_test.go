@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"medichat-be/domain"
+	"medichat-be/mocks/domainmocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// applicablePromotionRepository hands out fixed promotions for every
+// target.
+type applicablePromotionRepository struct {
+	domain.PromotionRepository
+
+	promotions []domain.Promotion
+}
+
+func (r *applicablePromotionRepository) ListApplicable(
+	ctx context.Context,
+	t domain.PromotionTarget,
+	at time.Time,
+) ([]domain.Promotion, error) {
+	return r.promotions, nil
+}
+
+func promotion(id int64, discountType string, value int) domain.Promotion {
+	return domain.Promotion{ID: id, DiscountType: discountType, DiscountValue: value}
+}
+
+func Test_promotionPicker_pick(t *testing.T) {
+	one := 1
+
+	tests := []struct {
+		name string
+
+		promotions []domain.Promotion
+		price      int
+
+		// wantID is 0 when no promotion should be picked.
+		wantID int64
+	}{
+		{
+			name: "should pick nothing without promotions",
+
+			promotions: nil,
+			price:      10000,
+
+			wantID: 0,
+		},
+		{
+			name: "should pick the largest discount",
+
+			promotions: []domain.Promotion{
+				promotion(1, domain.PromotionDiscountFixed, 1500),
+				promotion(2, domain.PromotionDiscountPercentage, 20),
+			},
+			price: 10000,
+
+			wantID: 2,
+		},
+		{
+			name: "should depend on the price which promotion is largest",
+
+			promotions: []domain.Promotion{
+				promotion(1, domain.PromotionDiscountFixed, 1500),
+				promotion(2, domain.PromotionDiscountPercentage, 20),
+			},
+			price: 5000,
+
+			wantID: 1,
+		},
+		{
+			name: "should give a tie to the older promotion",
+
+			promotions: []domain.Promotion{
+				promotion(1, domain.PromotionDiscountFixed, 2000),
+				promotion(2, domain.PromotionDiscountPercentage, 20),
+			},
+			price: 10000,
+
+			wantID: 1,
+		},
+		{
+			name: "should skip a promotion that gives nothing",
+
+			promotions: []domain.Promotion{
+				promotion(1, domain.PromotionDiscountPercentage, 1),
+			},
+			price: 50,
+
+			wantID: 0,
+		},
+		{
+			name: "should skip an exhausted promotion",
+
+			promotions: []domain.Promotion{
+				{ID: 1, DiscountType: domain.PromotionDiscountFixed, DiscountValue: 5000, UsageLimit: &one, UsageCount: 1},
+				promotion(2, domain.PromotionDiscountFixed, 1000),
+			},
+			price: 10000,
+
+			wantID: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			dataRepo := new(domainmocks.DataRepository)
+			dataRepo.On("PromotionRepository").Return(&applicablePromotionRepository{promotions: tt.promotions})
+			pp := newPromotionPicker(dataRepo, time.Now())
+
+			// when
+			got, err := pp.pick(context.Background(), domain.PromotionTarget{}, tt.price)
+
+			// then
+			assert.NoError(t, err)
+			if tt.wantID == 0 {
+				assert.Nil(t, got)
+			} else if assert.NotNil(t, got) {
+				assert.Equal(t, tt.wantID, got.ID)
+			}
+		})
+	}
+
+	t.Run("should stop handing out a promotion once its uses are taken", func(t *testing.T) {
+		// given
+		dataRepo := new(domainmocks.DataRepository)
+		dataRepo.On("PromotionRepository").Return(&applicablePromotionRepository{
+			promotions: []domain.Promotion{
+				{ID: 1, DiscountType: domain.PromotionDiscountFixed, DiscountValue: 5000, UsageLimit: &one},
+				promotion(2, domain.PromotionDiscountFixed, 1000),
+			},
+		})
+		pp := newPromotionPicker(dataRepo, time.Now())
+
+		// when
+		first, err1 := pp.pick(context.Background(), domain.PromotionTarget{}, 10000)
+		second, err2 := pp.pick(context.Background(), domain.PromotionTarget{}, 10000)
+
+		// then
+		assert.NoError(t, err1)
+		assert.NoError(t, err2)
+		assert.Equal(t, int64(1), first.ID)
+		assert.Equal(t, int64(2), second.ID)
+	})
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"medichat-be/apperror"
+	"medichat-be/constants"
+	"medichat-be/domain"
+)
+
+type relatedProductService struct {
+	dataRepository domain.DataRepository
+}
+
+type RelatedProductServiceOpts struct {
+	DataRepository domain.DataRepository
+}
+
+func NewRelatedProductService(opts RelatedProductServiceOpts) *relatedProductService {
+	return &relatedProductService{
+		dataRepository: opts.DataRepository,
+	}
+}
+
+func (s *relatedProductService) GetRelated(
+	ctx context.Context,
+	slug string,
+	query domain.RelatedProductsQuery,
+) (domain.RelatedProducts, error) {
+	productRepo := s.dataRepository.ProductRepository()
+	detailRepo := s.dataRepository.ProductDetailsRepository()
+	relatedRepo := s.dataRepository.RelatedProductRepository()
+
+	product, err := productRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return domain.RelatedProducts{}, apperror.Wrap(err)
+	}
+
+	visible, err := canViewProduct(ctx, s.dataRepository, product)
+	if err != nil {
+		return domain.RelatedProducts{}, apperror.Wrap(err)
+	}
+	if !visible {
+		return domain.RelatedProducts{}, apperror.NewEntityNotFound("product")
+	}
+
+	detail, err := detailRepo.GetById(ctx, product.ProductDetailId)
+	if err != nil {
+		return domain.RelatedProducts{}, apperror.Wrap(err)
+	}
+
+	if query.Limit == 0 {
+		query.Limit = constants.RelatedProductsDefaultLimit
+	}
+
+	together, err := relatedRepo.GetFrequentlyBoughtTogether(ctx, product, query)
+	if err != nil {
+		return domain.RelatedProducts{}, apperror.Wrap(err)
+	}
+
+	alternatives, err := relatedRepo.GetAlternatives(ctx, product, detail.GenericName, query)
+	if err != nil {
+		return domain.RelatedProducts{}, apperror.Wrap(err)
+	}
+
+	bestSellers, err := relatedRepo.GetCategoryBestSellers(ctx, product, query)
+	if err != nil {
+		return domain.RelatedProducts{}, apperror.Wrap(err)
+	}
+
+	return domain.RelatedProducts{
+		FrequentlyBoughtTogether: together,
+		Alternatives:             alternatives,
+		BestSellers:              bestSellers,
+	}, nil
+}
+
+func (s *relatedProductService) ComputeAssociationsClosure(
+	ctx context.Context,
+) domain.AtomicFunc[int64] {
+	return func(dr domain.DataRepository) (int64, error) {
+		relatedRepo := dr.RelatedProductRepository()
+
+		n, err := relatedRepo.ReplaceAssociations(ctx, constants.AssociationMinSupport)
+		if err != nil {
+			return 0, apperror.Wrap(err)
+		}
+
+		return n, nil
+	}
+}
+
+func (s *relatedProductService) ComputeAssociations(ctx context.Context) (int64, error) {
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.ComputeAssociationsClosure(ctx),
+	)
+}
@@ -4,31 +4,278 @@ import (
 	"context"
 	"medichat-be/apperror"
 	"medichat-be/domain"
+	"medichat-be/util"
+	"strings"
+
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 )
 
 type specializationService struct {
 	dataRepository domain.DataRepository
+	cloudProvider  util.CloudinaryProvider
 }
 
 type SpecializationServiceOpts struct {
 	DataRepository domain.DataRepository
+	CloudProvider  util.CloudinaryProvider
 }
 
 func NewSpecializationService(opts SpecializationServiceOpts) *specializationService {
 	return &specializationService{
 		dataRepository: opts.DataRepository,
+		cloudProvider:  opts.CloudProvider,
 	}
 }
 
 func (s *specializationService) GetAll(
 	ctx context.Context,
-) ([]domain.Specialization, error) {
+) ([]domain.SpecializationWithDoctorCount, error) {
 	specializationRepo := s.dataRepository.SpecializationRepository()
 
-	specializations, err := specializationRepo.GetAll(ctx)
+	specializations, err := specializationRepo.GetAllWithDoctorCount(ctx)
 	if err != nil {
 		return nil, apperror.Wrap(err)
 	}
 
 	return specializations, nil
 }
+
+func (s *specializationService) GetBySlug(
+	ctx context.Context,
+	slug string,
+) (domain.Specialization, error) {
+	specializationRepo := s.dataRepository.SpecializationRepository()
+
+	specialization, err := specializationRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return domain.Specialization{}, apperror.Wrap(err)
+	}
+
+	return specialization, nil
+}
+
+func (s *specializationService) checkAdmin(
+	ctx context.Context,
+	dr domain.DataRepository,
+) error {
+	accountRepo := dr.AccountRepository()
+
+	accountID, err := util.GetAccountIDFromContext(ctx)
+	if err != nil {
+		return apperror.Wrap(err)
+	}
+
+	account, err := accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return apperror.Wrap(err)
+	}
+	if account.Role != domain.AccountRoleAdmin {
+		return apperror.NewForbidden(nil)
+	}
+
+	return nil
+}
+
+func (s *specializationService) CreateClosure(
+	ctx context.Context,
+	dets domain.SpecializationCreateDetails,
+) domain.AtomicFunc[domain.Specialization] {
+	return func(dr domain.DataRepository) (domain.Specialization, error) {
+		specializationRepo := dr.SpecializationRepository()
+
+		err := s.checkAdmin(ctx, dr)
+		if err != nil {
+			return domain.Specialization{}, apperror.Wrap(err)
+		}
+
+		specialization := domain.Specialization{
+			Name:           dets.Name,
+			Slug:           util.GenerateSlug(strings.ToLower(dets.Name)),
+			Description:    dets.Description,
+			CommissionRate: dets.CommissionRate,
+		}
+
+		exists, err := specializationRepo.IsExistBySlug(ctx, specialization.Slug)
+		if err != nil {
+			return domain.Specialization{}, apperror.Wrap(err)
+		}
+		if exists {
+			return domain.Specialization{}, apperror.NewAlreadyExists("specialization")
+		}
+
+		if dets.Icon != nil {
+			res, err := s.cloudProvider.UploadImage(ctx, dets.Icon, uploader.UploadParams{})
+			if err != nil {
+				return domain.Specialization{}, apperror.Wrap(err)
+			}
+			specialization.IconURL = &res.SecureURL
+		}
+
+		specialization, err = specializationRepo.Add(ctx, specialization)
+		if err != nil {
+			return domain.Specialization{}, apperror.Wrap(err)
+		}
+
+		return specialization, nil
+	}
+}
+
+func (s *specializationService) Create(
+	ctx context.Context,
+	dets domain.SpecializationCreateDetails,
+) (domain.Specialization, error) {
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.CreateClosure(ctx, dets),
+	)
+}
+
+func (s *specializationService) UpdateClosure(
+	ctx context.Context,
+	dets domain.SpecializationUpdateDetails,
+) domain.AtomicFunc[domain.Specialization] {
+	return func(dr domain.DataRepository) (domain.Specialization, error) {
+		specializationRepo := dr.SpecializationRepository()
+
+		err := s.checkAdmin(ctx, dr)
+		if err != nil {
+			return domain.Specialization{}, apperror.Wrap(err)
+		}
+
+		specialization, err := specializationRepo.GetByIDAndLock(ctx, dets.ID)
+		if err != nil {
+			return domain.Specialization{}, apperror.Wrap(err)
+		}
+
+		if dets.Name != nil && *dets.Name != specialization.Name {
+			slug := util.GenerateSlug(strings.ToLower(*dets.Name))
+			if slug != specialization.Slug {
+				exists, err := specializationRepo.IsExistBySlug(ctx, slug)
+				if err != nil {
+					return domain.Specialization{}, apperror.Wrap(err)
+				}
+				if exists {
+					return domain.Specialization{}, apperror.NewAlreadyExists("specialization")
+				}
+			}
+			specialization.Slug = slug
+		}
+
+		specialization.ApplyUpdate(dets)
+
+		if dets.Icon != nil {
+			res, err := s.cloudProvider.UploadImage(ctx, dets.Icon, uploader.UploadParams{})
+			if err != nil {
+				return domain.Specialization{}, apperror.Wrap(err)
+			}
+			specialization.IconURL = &res.SecureURL
+		}
+
+		specialization, err = specializationRepo.Update(ctx, specialization)
+		if err != nil {
+			return domain.Specialization{}, apperror.Wrap(err)
+		}
+
+		return specialization, nil
+	}
+}
+
+func (s *specializationService) Update(
+	ctx context.Context,
+	dets domain.SpecializationUpdateDetails,
+) (domain.Specialization, error) {
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.UpdateClosure(ctx, dets),
+	)
+}
+
+func (s *specializationService) DeleteClosure(
+	ctx context.Context,
+	id int64,
+) domain.AtomicFunc[any] {
+	return func(dr domain.DataRepository) (any, error) {
+		specializationRepo := dr.SpecializationRepository()
+		doctorRepo := dr.DoctorRepository()
+
+		err := s.checkAdmin(ctx, dr)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		_, err = specializationRepo.GetByIDAndLock(ctx, id)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		inUse, err := doctorRepo.IsExistBySpecializationID(ctx, id)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+		if inUse {
+			return nil, apperror.NewSpecializationInUse(nil)
+		}
+
+		err = specializationRepo.SoftDeleteByID(ctx, id)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		return nil, nil
+	}
+}
+
+func (s *specializationService) Delete(
+	ctx context.Context,
+	id int64,
+) error {
+	_, err := domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.DeleteClosure(ctx, id),
+	)
+	return err
+}
+
+func (s *specializationService) UpdateCommissionRateClosure(
+	ctx context.Context,
+	id int64,
+	rate float64,
+) domain.AtomicFunc[domain.Specialization] {
+	return func(dr domain.DataRepository) (domain.Specialization, error) {
+		specializationRepo := dr.SpecializationRepository()
+
+		err := s.checkAdmin(ctx, dr)
+		if err != nil {
+			return domain.Specialization{}, apperror.Wrap(err)
+		}
+
+		specialization, err := specializationRepo.GetByIDAndLock(ctx, id)
+		if err != nil {
+			return domain.Specialization{}, apperror.Wrap(err)
+		}
+
+		specialization.CommissionRate = rate
+
+		specialization, err = specializationRepo.Update(ctx, specialization)
+		if err != nil {
+			return domain.Specialization{}, apperror.Wrap(err)
+		}
+
+		return specialization, nil
+	}
+}
+
+func (s *specializationService) UpdateCommissionRate(
+	ctx context.Context,
+	id int64,
+	rate float64,
+) (domain.Specialization, error) {
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.UpdateCommissionRateClosure(ctx, id, rate),
+	)
+}
@@ -4,20 +4,24 @@ import (
 	"context"
 	"medichat-be/apperror"
 	"medichat-be/domain"
+	"medichat-be/logger"
 	"medichat-be/util"
 )
 
 type stockService struct {
 	dataRepository domain.DataRepository
+	logger         logger.Logger
 }
 
 type StockServiceOpts struct {
 	DataRepository domain.DataRepository
+	Logger         logger.Logger
 }
 
 func NewStockService(opts StockServiceOpts) *stockService {
 	return &stockService{
 		dataRepository: opts.DataRepository,
+		logger:         opts.Logger,
 	}
 }
 
@@ -99,11 +103,17 @@ func (s *stockService) AddClosure(
 			return domain.Stock{}, apperror.Wrap(err)
 		}
 
-		product, err := productRepo.GetBySlug(ctx, det.ProductSlug)
+		// The product stays locked so a variant can't be added while a
+		// stock without one is.
+		product, err := productRepo.GetBySlugAndLock(ctx, det.ProductSlug)
 		if err != nil {
 			return domain.Stock{}, apperror.Wrap(err)
 		}
 
+		if product.Status == domain.ProductStatusDiscontinued {
+			return domain.Stock{}, apperror.NewProductDiscontinued()
+		}
+
 		pharmacy, err := pharmacyRepo.GetBySlug(ctx, det.PharmacySlug)
 		if err != nil {
 			return domain.Stock{}, apperror.Wrap(err)
@@ -113,11 +123,20 @@ func (s *stockService) AddClosure(
 			return domain.Stock{}, apperror.NewForbidden(nil)
 		}
 
+		variant, err := resolveVariant(ctx, dr, product.ID, det.VariantID)
+		if err != nil {
+			return domain.Stock{}, apperror.Wrap(err)
+		}
+
 		stock := domain.Stock{
 			ProductID:  product.ID,
+			VariantID:  variantIDOf(variant),
 			PharmacyID: pharmacy.ID,
 			Stock:      det.Stock,
 			Price:      det.Price,
+
+			MinStock:     det.MinStock,
+			ReorderLevel: det.ReorderLevel,
 		}
 
 		stock, err = stockRepo.Add(ctx, stock)
@@ -125,6 +144,16 @@ func (s *stockService) AddClosure(
 			return domain.Stock{}, apperror.Wrap(err)
 		}
 
+		err = openStockLedger(ctx, dr, stock)
+		if err != nil {
+			return domain.Stock{}, apperror.Wrap(err)
+		}
+
+		err = recordStockPrice(ctx, dr, stock, nil)
+		if err != nil {
+			return domain.Stock{}, apperror.Wrap(err)
+		}
+
 		return stock, nil
 	}
 }
@@ -173,14 +202,34 @@ func (s *stockService) UpdateClosure(
 			return domain.Stock{}, apperror.NewForbidden(nil)
 		}
 
+		oldPrice := stock.Price
+		change := 0
 		if det.Stock != nil {
-			stock.Stock = *det.Stock
+			err = checkStockAmount(ctx, dr, stock, *det.Stock)
+			if err != nil {
+				return domain.Stock{}, apperror.Wrap(err)
+			}
+			change = *det.Stock - stock.Stock
 		}
 		if det.Price != nil {
 			stock.Price = *det.Price
 		}
+		if det.MinStock != nil {
+			stock.MinStock = *det.MinStock
+		}
+		if det.ReorderLevel != nil {
+			stock.ReorderLevel = *det.ReorderLevel
+		}
+		if stock.ReorderLevel < stock.MinStock {
+			return domain.Stock{}, apperror.NewReorderBelowMinimum()
+		}
+
+		stock, err = changeStock(ctx, dr, stock, change, domain.StockLedgerAdjustment, nil)
+		if err != nil {
+			return domain.Stock{}, apperror.Wrap(err)
+		}
 
-		stock, err = stockRepo.Update(ctx, stock)
+		err = recordStockPrice(ctx, dr, stock, &oldPrice)
 		if err != nil {
 			return domain.Stock{}, apperror.Wrap(err)
 		}
@@ -233,6 +282,20 @@ func (s *stockService) DeleteClosure(
 			return domain.Stock{}, apperror.NewForbidden(nil)
 		}
 
+		reserved, err := stockRepo.GetReservedAmount(ctx, stock.ID)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+		if reserved > 0 {
+			return nil, apperror.NewStockReserved(reserved)
+		}
+
+		// Whatever is left on hand leaves the ledger as a write-off.
+		_, err = changeStock(ctx, dr, stock, -stock.Stock, domain.StockLedgerWriteOff, nil)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
 		err = stockRepo.SoftDeleteByID(ctx, id)
 		if err != nil {
 			return domain.Stock{}, apperror.Wrap(err)
@@ -340,12 +403,17 @@ func (s *stockService) RequestStockTransferClosure(
 			return domain.StockMutation{}, apperror.NewForbidden(nil)
 		}
 
-		source, err := stockRepo.GetByPharmacyAndProduct(ctx, sourcePharma.ID, product.ID)
+		variant, err := resolveVariant(ctx, dr, product.ID, req.VariantID)
+		if err != nil {
+			return domain.StockMutation{}, apperror.Wrap(err)
+		}
+
+		source, err := stockRepo.GetByPharmacyAndProduct(ctx, sourcePharma.ID, product.ID, variantIDOf(variant))
 		if err != nil {
 			return domain.StockMutation{}, apperror.Wrap(err)
 		}
 
-		target, err := stockRepo.GetByPharmacyAndProduct(ctx, targetPharma.ID, product.ID)
+		target, err := stockRepo.GetByPharmacyAndProduct(ctx, targetPharma.ID, product.ID, variantIDOf(variant))
 		if err != nil {
 			return domain.StockMutation{}, apperror.Wrap(err)
 		}
@@ -425,7 +493,7 @@ func (s *stockService) ApproveStockTransferClosure(
 			return domain.StockMutation{}, apperror.Wrap(err)
 		}
 
-		_, _, err = transferStock(dr, ctx, mut.SourceID, mut.TargetID, mut.Amount)
+		_, _, err = transferStock(dr, ctx, mut.SourceID, mut.TargetID, mut.Amount, mut.ID)
 		if err != nil {
 			return domain.StockMutation{}, apperror.Wrap(err)
 		}
@@ -517,12 +585,41 @@ func (s *stockService) CancelStockTransfer(
 	)
 }
 
+// checkStockAmount rejects setting the stock to an amount that would leave
+// fewer units than its batches hold or than reserved orders still need.
+func checkStockAmount(ctx context.Context, dr domain.DataRepository, stock domain.Stock, amount int) error {
+	stockRepo := dr.StockRepository()
+
+	reserved, err := stockRepo.GetReservedAmount(ctx, stock.ID)
+	if err != nil {
+		return err
+	}
+	expired, err := stockRepo.GetExpiredAmount(ctx, stock.ID)
+	if err != nil {
+		return err
+	}
+	batched, err := stockRepo.GetBatchedAmount(ctx, stock.ID)
+	if err != nil {
+		return err
+	}
+
+	if amount < batched {
+		return apperror.NewStockBelowBatched(batched)
+	}
+	if amount-expired < reserved {
+		return apperror.NewStockBelowReserved(reserved)
+	}
+
+	return nil
+}
+
 func transferStock(
 	dr domain.DataRepository,
 	ctx context.Context,
 	sourceID int64,
 	targetID int64,
 	amount int,
+	mutationID int64,
 ) (domain.Stock, domain.Stock, error) {
 	stockRepo := dr.StockRepository()
 
@@ -536,19 +633,33 @@ func transferStock(
 		return domain.Stock{}, domain.Stock{}, apperror.Wrap(err)
 	}
 
-	if source.Stock < amount {
+	sellable, err := sellableAmount(ctx, dr, source)
+	if err != nil {
+		return domain.Stock{}, domain.Stock{}, apperror.Wrap(err)
+	}
+	if sellable < amount {
 		return domain.Stock{}, domain.Stock{}, apperror.NewStockNotEnough(nil)
 	}
 
-	source.Stock -= amount
-	target.Stock += amount
+	// The batches travel with the units so the target keeps their expiry.
+	picked, err := pickBatches(ctx, dr, source.ID, amount)
+	if err != nil {
+		return domain.Stock{}, domain.Stock{}, apperror.Wrap(err)
+	}
+	for _, b := range picked {
+		b.StockID = target.ID
+		_, err = stockRepo.AddBatch(ctx, b)
+		if err != nil {
+			return domain.Stock{}, domain.Stock{}, apperror.Wrap(err)
+		}
+	}
 
-	source, err = stockRepo.Update(ctx, source)
+	source, err = changeStock(ctx, dr, source, -amount, domain.StockLedgerTransferOut, &mutationID)
 	if err != nil {
 		return domain.Stock{}, domain.Stock{}, apperror.Wrap(err)
 	}
 
-	target, err = stockRepo.Update(ctx, target)
+	target, err = changeStock(ctx, dr, target, amount, domain.StockLedgerTransferIn, &mutationID)
 	if err != nil {
 		return domain.Stock{}, domain.Stock{}, apperror.Wrap(err)
 	}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"medichat-be/apperror"
+	"medichat-be/constants"
+	"medichat-be/domain"
+)
+
+// fulfillShortfall brings shortfall units into the stock from other
+// pharmacies, one automatic mutation per pharmacy, and returns the topped
+// up stock.
+func fulfillShortfall(
+	ctx context.Context,
+	dr domain.DataRepository,
+	stock domain.Stock,
+	shortfall int,
+) (domain.Stock, error) {
+	stockRepo := dr.StockRepository()
+
+	sources, err := stockRepo.ListNearestSpareStocksAndLock(ctx, stock, constants.StockAllocationMaxSources)
+	if err != nil {
+		return domain.Stock{}, err
+	}
+
+	allocations, ok := planStockAllocation(shortfall, sources)
+	if !ok {
+		return domain.Stock{}, apperror.NewStockNotEnough(nil)
+	}
+
+	for _, a := range allocations {
+		mutation, err := stockRepo.AddMutation(ctx, domain.StockMutation{
+			SourceID: a.Source.Stock.ID,
+			TargetID: stock.ID,
+			Method:   domain.StockMutationAutomatic,
+			Status:   domain.StockMutationStatusApproved,
+			Amount:   a.Amount,
+		})
+		if err != nil {
+			return domain.Stock{}, err
+		}
+
+		_, stock, err = transferStock(dr, ctx, a.Source.Stock.ID, stock.ID, a.Amount, mutation.ID)
+		if err != nil {
+			return domain.Stock{}, err
+		}
+	}
+
+	return stock, nil
+}
+
+// planStockAllocation picks the sources, given nearest first, whose spare
+// units cover the shortfall with the least total distance, every source
+// being a separate trip. Ties go to fewer sources. Within the picked ones the
+// nearest give all they can and the farthest only what is left, so a source
+// that isn't needed after all is dropped. The second result is false when
+// all of them together can't cover it.
+func planStockAllocation(shortfall int, sources []domain.StockSurplus) ([]domain.StockAllocation, bool) {
+	best := -1
+	bestDistance := 0.0
+	bestCount := 0
+
+	for set := 1; set < 1<<len(sources); set++ {
+		spare, distance, count := 0, 0.0, 0
+		for i, src := range sources {
+			if set&(1<<i) == 0 {
+				continue
+			}
+			spare += src.Surplus
+			distance += src.Distance
+			count++
+		}
+		if spare < shortfall {
+			continue
+		}
+
+		if best < 0 || distance < bestDistance || (distance == bestDistance && count < bestCount) {
+			best, bestDistance, bestCount = set, distance, count
+		}
+	}
+
+	if best < 0 {
+		return nil, false
+	}
+
+	allocations := []domain.StockAllocation{}
+	for i, src := range sources {
+		if shortfall == 0 {
+			break
+		}
+		if best&(1<<i) == 0 {
+			continue
+		}
+
+		amount := src.Surplus
+		if amount > shortfall {
+			amount = shortfall
+		}
+
+		allocations = append(allocations, domain.StockAllocation{
+			Source: src,
+			Amount: amount,
+		})
+		shortfall -= amount
+	}
+
+	return allocations, true
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"medichat-be/apperror"
+	"medichat-be/constants"
+	"medichat-be/domain"
+	"medichat-be/mocks/domainmocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func surplus(id int64, units int, distance float64) domain.StockSurplus {
+	return domain.StockSurplus{
+		Stock:    domain.Stock{ID: id},
+		Surplus:  units,
+		Distance: distance,
+	}
+}
+
+func allocation(src domain.StockSurplus, amount int) domain.StockAllocation {
+	return domain.StockAllocation{Source: src, Amount: amount}
+}
+
+func Test_planStockAllocation(t *testing.T) {
+	near := surplus(1, 3, 100)
+	mid := surplus(2, 4, 200)
+	far := surplus(3, 10, 1000)
+
+	capped := make([]domain.StockSurplus, constants.StockAllocationMaxSources)
+	cappedWant := make([]domain.StockAllocation, constants.StockAllocationMaxSources)
+	for i := range capped {
+		capped[i] = surplus(int64(i+1), 1, float64(100*(i+1)))
+		cappedWant[i] = allocation(capped[i], 1)
+	}
+
+	tests := []struct {
+		name string
+
+		shortfall int
+		sources   []domain.StockSurplus
+
+		want   []domain.StockAllocation
+		wantOk bool
+	}{
+		{
+			name: "should take everything from the nearest source when it covers the shortfall",
+
+			shortfall: 3,
+			sources:   []domain.StockSurplus{near, mid, far},
+
+			want:   []domain.StockAllocation{allocation(near, 3)},
+			wantOk: true,
+		},
+		{
+			name: "should combine near sources when together they are closer than one far source",
+
+			shortfall: 6,
+			sources:   []domain.StockSurplus{near, mid, far},
+
+			want:   []domain.StockAllocation{allocation(near, 3), allocation(mid, 3)},
+			wantOk: true,
+		},
+		{
+			name: "should use the far source when the near ones can't cover the shortfall",
+
+			shortfall: 8,
+			sources:   []domain.StockSurplus{near, mid, far},
+
+			want:   []domain.StockAllocation{allocation(far, 8)},
+			wantOk: true,
+		},
+		{
+			name: "should fail when all sources together can't cover the shortfall",
+
+			shortfall: 18,
+			sources:   []domain.StockSurplus{near, mid, far},
+
+			want:   nil,
+			wantOk: false,
+		},
+		{
+			name: "should fail without sources",
+
+			shortfall: 1,
+			sources:   nil,
+
+			want:   nil,
+			wantOk: false,
+		},
+		{
+			name: "should prefer fewer sources when the distances are equal",
+
+			shortfall: 8,
+			sources: []domain.StockSurplus{
+				surplus(1, 5, 100),
+				surplus(2, 5, 100),
+				surplus(3, 10, 200),
+			},
+
+			want:   []domain.StockAllocation{allocation(surplus(3, 10, 200), 8)},
+			wantOk: true,
+		},
+		{
+			name: "should use every source up to the cap when all of them are needed",
+
+			shortfall: constants.StockAllocationMaxSources,
+			sources:   capped,
+
+			want:   cappedWant,
+			wantOk: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			got, ok := planStockAllocation(tt.shortfall, tt.sources)
+
+			// then
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// spareStockRepository hands out fixed sources and records how many were
+// asked for.
+type spareStockRepository struct {
+	domain.StockRepository
+
+	sources []domain.StockSurplus
+	limit   int
+}
+
+func (r *spareStockRepository) ListNearestSpareStocksAndLock(
+	ctx context.Context,
+	target domain.Stock,
+	limit int,
+) ([]domain.StockSurplus, error) {
+	r.limit = limit
+	return r.sources, nil
+}
+
+func Test_fulfillShortfall(t *testing.T) {
+	t.Run("should only look at the capped number of sources", func(t *testing.T) {
+		// given
+		stockRepo := &spareStockRepository{
+			sources: []domain.StockSurplus{surplus(1, 1, 100)},
+		}
+		dataRepo := new(domainmocks.DataRepository)
+		dataRepo.On("StockRepository").Return(stockRepo)
+
+		// when
+		_, err := fulfillShortfall(context.Background(), dataRepo, domain.Stock{ID: 99}, 5)
+
+		// then
+		assert.Equal(t, constants.StockAllocationMaxSources, stockRepo.limit)
+		assert.True(t, apperror.IsErrorCode(err, apperror.CodeBadRequest))
+	})
+}
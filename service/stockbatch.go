@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"medichat-be/util"
+	"time"
+)
+
+func (s *stockService) ReceiveBatchClosure(
+	ctx context.Context,
+	det domain.StockBatchCreateDetail,
+) domain.AtomicFunc[domain.StockBatch] {
+	return func(dr domain.DataRepository) (domain.StockBatch, error) {
+		stockRepo := dr.StockRepository()
+		pharmacyRepo := dr.PharmacyRepository()
+		managerRepo := dr.PharmacyManagerRepository()
+
+		accountID, err := util.GetAccountIDFromContext(ctx)
+		if err != nil {
+			return domain.StockBatch{}, apperror.Wrap(err)
+		}
+
+		manager, err := managerRepo.GetByAccountID(ctx, accountID)
+		if err != nil {
+			return domain.StockBatch{}, apperror.Wrap(err)
+		}
+
+		stock, err := stockRepo.GetByIDAndLock(ctx, det.StockID)
+		if err != nil {
+			return domain.StockBatch{}, apperror.Wrap(err)
+		}
+
+		pharmacy, err := pharmacyRepo.GetByID(ctx, stock.PharmacyID)
+		if err != nil {
+			return domain.StockBatch{}, apperror.Wrap(err)
+		}
+
+		if pharmacy.ManagerID != manager.ID {
+			return domain.StockBatch{}, apperror.NewForbidden(nil)
+		}
+
+		product, err := dr.ProductRepository().GetById(ctx, stock.ProductID)
+		if err != nil {
+			return domain.StockBatch{}, apperror.Wrap(err)
+		}
+
+		if product.Status == domain.ProductStatusDiscontinued {
+			return domain.StockBatch{}, apperror.NewProductDiscontinued()
+		}
+
+		batch := domain.StockBatch{
+			StockID:    stock.ID,
+			LotNumber:  det.LotNumber,
+			ExpiryDate: det.ExpiryDate,
+			CostPrice:  det.CostPrice,
+			Quantity:   det.Quantity,
+		}
+
+		if batch.IsExpired(time.Now()) {
+			return domain.StockBatch{}, apperror.NewBatchExpired(batch.LotNumber)
+		}
+
+		batch, err = stockRepo.AddBatch(ctx, batch)
+		if err != nil {
+			return domain.StockBatch{}, apperror.Wrap(err)
+		}
+
+		_, err = changeStock(ctx, dr, stock, batch.Quantity, domain.StockLedgerReceipt, &batch.ID)
+		if err != nil {
+			return domain.StockBatch{}, apperror.Wrap(err)
+		}
+
+		return batch, nil
+	}
+}
+
+func (s *stockService) ReceiveBatch(
+	ctx context.Context,
+	det domain.StockBatchCreateDetail,
+) (domain.StockBatch, error) {
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.ReceiveBatchClosure(ctx, det),
+	)
+}
+
+func (s *stockService) ListBatches(
+	ctx context.Context,
+	stockID int64,
+) ([]domain.StockBatch, error) {
+	stockRepo := s.dataRepository.StockRepository()
+
+	// GetByID checks that a manager only sees their own pharmacies.
+	stock, err := s.GetByID(ctx, stockID)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	batches, err := stockRepo.ListBatchesByStockID(ctx, stock.ID)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	return batches, nil
+}
+
+func (s *stockService) ListExpiringBatches(
+	ctx context.Context,
+	q domain.ExpiringStockQuery,
+) ([]domain.StockBatchJoined, error) {
+	stockRepo := s.dataRepository.StockRepository()
+
+	_, prof, err := util.GetProfileFromContext(ctx, s.dataRepository)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	if manager, ok := prof.(domain.PharmacyManager); ok {
+		q.ManagerID = &manager.ID
+	}
+
+	batches, err := stockRepo.ListExpiringBatches(ctx, q)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	return batches, nil
+}
+
+func (s *stockService) WriteOffExpiredClosure(
+	ctx context.Context,
+	stockID int64,
+) domain.AtomicFunc[domain.Stock] {
+	return func(dr domain.DataRepository) (domain.Stock, error) {
+		stockRepo := dr.StockRepository()
+		pharmacyRepo := dr.PharmacyRepository()
+		managerRepo := dr.PharmacyManagerRepository()
+
+		accountID, err := util.GetAccountIDFromContext(ctx)
+		if err != nil {
+			return domain.Stock{}, apperror.Wrap(err)
+		}
+
+		manager, err := managerRepo.GetByAccountID(ctx, accountID)
+		if err != nil {
+			return domain.Stock{}, apperror.Wrap(err)
+		}
+
+		stock, err := stockRepo.GetByIDAndLock(ctx, stockID)
+		if err != nil {
+			return domain.Stock{}, apperror.Wrap(err)
+		}
+
+		pharmacy, err := pharmacyRepo.GetByID(ctx, stock.PharmacyID)
+		if err != nil {
+			return domain.Stock{}, apperror.Wrap(err)
+		}
+
+		if pharmacy.ManagerID != manager.ID {
+			return domain.Stock{}, apperror.NewForbidden(nil)
+		}
+
+		batches, err := stockRepo.ListExpiredBatchesAndLock(ctx, stock.ID)
+		if err != nil {
+			return domain.Stock{}, apperror.Wrap(err)
+		}
+
+		amount := 0
+		for _, b := range batches {
+			err = stockRepo.UpdateBatchQuantity(ctx, b.ID, 0)
+			if err != nil {
+				return domain.Stock{}, apperror.Wrap(err)
+			}
+			amount += b.Quantity
+		}
+
+		if amount == 0 {
+			return domain.Stock{}, apperror.NewNothingToWriteOff()
+		}
+
+		// A write-off leaves the pharmacy, its ledger entry is all the
+		// record it needs.
+		stock, err = changeStock(ctx, dr, stock, -amount, domain.StockLedgerWriteOff, nil)
+		if err != nil {
+			return domain.Stock{}, apperror.Wrap(err)
+		}
+
+		return stock, nil
+	}
+}
+
+func (s *stockService) WriteOffExpired(
+	ctx context.Context,
+	stockID int64,
+) (domain.Stock, error) {
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.WriteOffExpiredClosure(ctx, stockID),
+	)
+}
+
+// sellableAmount is the part of the on hand amount that can be sold, which
+// leaves out reserved and expired units.
+func sellableAmount(ctx context.Context, dr domain.DataRepository, stock domain.Stock) (int, error) {
+	stockRepo := dr.StockRepository()
+
+	reserved, err := stockRepo.GetReservedAmount(ctx, stock.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	expired, err := stockRepo.GetExpiredAmount(ctx, stock.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	return stock.Stock - reserved - expired, nil
+}
+
+// pickBatches takes amount out of the stock's unexpired batches, first
+// expiring first out, and returns what was taken from each. Whatever the
+// batches can't cover comes from quantity that was never batched.
+func pickBatches(ctx context.Context, dr domain.DataRepository, stockID int64, amount int) ([]domain.StockBatch, error) {
+	stockRepo := dr.StockRepository()
+
+	batches, err := stockRepo.ListSellableBatchesAndLock(ctx, stockID)
+	if err != nil {
+		return nil, err
+	}
+
+	picked := []domain.StockBatch{}
+	for _, b := range batches {
+		if amount == 0 {
+			break
+		}
+
+		take := b.Quantity
+		if take > amount {
+			take = amount
+		}
+
+		err = stockRepo.UpdateBatchQuantity(ctx, b.ID, b.Quantity-take)
+		if err != nil {
+			return nil, err
+		}
+
+		b.Quantity = take
+		picked = append(picked, b)
+		amount -= take
+	}
+
+	return picked, nil
+}
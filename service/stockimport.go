@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"medichat-be/util"
+)
+
+type stockImportKey struct {
+	pharmacySlug string
+	productSlug  string
+	variantID    int64
+}
+
+// stockImportPlan is a validated row, ready to be written.
+type stockImportPlan struct {
+	change domain.StockImportChange
+	stock  domain.Stock
+}
+
+func (s *stockService) ImportStocksClosure(
+	ctx context.Context,
+	det domain.StockImportDetail,
+) domain.AtomicFunc[domain.StockImportResult] {
+	return func(dr domain.DataRepository) (domain.StockImportResult, error) {
+		stockRepo := dr.StockRepository()
+		managerRepo := dr.PharmacyManagerRepository()
+
+		accountID, err := util.GetAccountIDFromContext(ctx)
+		if err != nil {
+			return domain.StockImportResult{}, apperror.Wrap(err)
+		}
+
+		manager, err := managerRepo.GetByAccountID(ctx, accountID)
+		if err != nil {
+			return domain.StockImportResult{}, apperror.Wrap(err)
+		}
+
+		result := domain.StockImportResult{
+			Changes: []domain.StockImportChange{},
+			Errors:  []domain.StockImportRowError{},
+		}
+
+		lookup := newStockImportLookup(dr, manager.ID)
+		seen := map[stockImportKey]int{}
+		plans := make([]stockImportPlan, 0, len(det.Rows))
+
+		for _, row := range det.Rows {
+			key := stockImportKey{pharmacySlug: row.PharmacySlug, productSlug: row.ProductSlug}
+			if row.VariantID != nil {
+				key.variantID = *row.VariantID
+			}
+			if line, ok := seen[key]; ok {
+				result.Errors = append(result.Errors, domain.StockImportRowError{
+					Line:    row.Line,
+					Message: fmt.Sprintf("duplicate of line %d", line),
+				})
+				continue
+			}
+			seen[key] = row.Line
+
+			plan, err := planStockImportRow(ctx, dr, lookup, row)
+			if msg, ok := stockImportRowMessage(err); ok {
+				result.Errors = append(result.Errors, domain.StockImportRowError{
+					Line:    row.Line,
+					Message: msg,
+				})
+				continue
+			}
+			if err != nil {
+				return domain.StockImportResult{}, apperror.Wrap(err)
+			}
+
+			c := plan.change
+			switch {
+			case c.StockID == nil:
+				result.Created++
+			case c.OldStock == c.NewStock && c.OldPrice == c.NewPrice:
+				result.Unchanged++
+				continue
+			default:
+				result.Updated++
+			}
+
+			result.Changes = append(result.Changes, c)
+			plans = append(plans, plan)
+		}
+
+		// The file is applied whole or not at all.
+		if det.DryRun || len(result.Errors) > 0 {
+			return result, nil
+		}
+
+		imp, err := stockRepo.AddImport(ctx, domain.StockImport{
+			ManagerID: manager.ID,
+			FileName:  det.FileName,
+			Created:   result.Created,
+			Updated:   result.Updated,
+		})
+		if err != nil {
+			return domain.StockImportResult{}, apperror.Wrap(err)
+		}
+		result.ImportID = &imp.ID
+
+		for _, plan := range plans {
+			err = applyStockImportPlan(ctx, dr, plan, imp.ID)
+			if err != nil {
+				return domain.StockImportResult{}, apperror.Wrap(err)
+			}
+		}
+
+		return result, nil
+	}
+}
+
+// ImportStocks validates every row before writing anything and writes the
+// whole file in one transaction. A dry run, or a file with any invalid row,
+// only reports what would change.
+func (s *stockService) ImportStocks(
+	ctx context.Context,
+	det domain.StockImportDetail,
+) (domain.StockImportResult, error) {
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.ImportStocksClosure(ctx, det),
+	)
+}
+
+func planStockImportRow(
+	ctx context.Context,
+	dr domain.DataRepository,
+	lookup *stockImportLookup,
+	row domain.StockImportRow,
+) (stockImportPlan, error) {
+	stockRepo := dr.StockRepository()
+
+	pharmacy, err := lookup.pharmacy(ctx, row.PharmacySlug)
+	if err != nil {
+		return stockImportPlan{}, err
+	}
+
+	product, err := lookup.product(ctx, row.ProductSlug)
+	if err != nil {
+		return stockImportPlan{}, err
+	}
+
+	variant, err := resolveVariant(ctx, dr, product.ID, row.VariantID)
+	if err != nil {
+		return stockImportPlan{}, err
+	}
+
+	stock, err := stockRepo.GetByPharmacyAndProduct(ctx, pharmacy.ID, product.ID, variantIDOf(variant))
+	if err != nil && !apperror.IsErrorCode(err, apperror.CodeNotFound) {
+		return stockImportPlan{}, err
+	}
+	exists := err == nil
+
+	if exists {
+		stock, err = stockRepo.GetByIDAndLock(ctx, stock.ID)
+		if err != nil {
+			return stockImportPlan{}, err
+		}
+	} else {
+		if row.Mode == domain.StockImportModeAdjust {
+			return stockImportPlan{}, apperror.NewEntityNotFound("stock to adjust")
+		}
+		if product.Status == domain.ProductStatusDiscontinued {
+			return stockImportPlan{}, apperror.NewProductDiscontinued()
+		}
+		stock = domain.Stock{
+			ProductID:  product.ID,
+			VariantID:  variantIDOf(variant),
+			PharmacyID: pharmacy.ID,
+		}
+	}
+
+	change := domain.StockImportChange{
+		Line:         row.Line,
+		PharmacySlug: row.PharmacySlug,
+		ProductSlug:  row.ProductSlug,
+		VariantID:    stock.VariantID,
+		OldStock:     stock.Stock,
+		NewStock:     stock.Stock,
+		OldPrice:     stock.Price,
+		NewPrice:     stock.Price,
+	}
+	if exists {
+		change.StockID = &stock.ID
+	}
+
+	if row.Quantity != nil {
+		change.NewStock = *row.Quantity
+		if row.Mode == domain.StockImportModeAdjust {
+			change.NewStock += stock.Stock
+		}
+	}
+	if row.Price != nil {
+		change.NewPrice = *row.Price
+		if row.Mode == domain.StockImportModeAdjust {
+			change.NewPrice += stock.Price
+		}
+	}
+
+	if change.NewStock < 0 {
+		return stockImportPlan{}, apperror.NewStockImportNegative("quantity")
+	}
+	if change.NewPrice < 0 {
+		return stockImportPlan{}, apperror.NewStockImportNegative("price")
+	}
+
+	if exists && change.NewStock != change.OldStock {
+		err = checkStockAmount(ctx, dr, stock, change.NewStock)
+		if err != nil {
+			return stockImportPlan{}, err
+		}
+	}
+
+	return stockImportPlan{change: change, stock: stock}, nil
+}
+
+func applyStockImportPlan(
+	ctx context.Context,
+	dr domain.DataRepository,
+	plan stockImportPlan,
+	importID int64,
+) error {
+	stockRepo := dr.StockRepository()
+
+	stock := plan.stock
+	stock.Price = plan.change.NewPrice
+
+	// A new stock opens its ledger at zero so the imported quantity shows
+	// up as an import entry like any other.
+	if plan.change.StockID == nil {
+		var err error
+		stock, err = stockRepo.Add(ctx, stock)
+		if err != nil {
+			return err
+		}
+
+		err = openStockLedger(ctx, dr, stock)
+		if err != nil {
+			return err
+		}
+	}
+
+	stock, err := changeStock(
+		ctx, dr, stock,
+		plan.change.NewStock-plan.change.OldStock,
+		domain.StockLedgerImport, &importID,
+	)
+	if err != nil {
+		return err
+	}
+
+	var oldPrice *int
+	if plan.change.StockID != nil {
+		oldPrice = &plan.change.OldPrice
+	}
+	return recordStockPrice(ctx, dr, stock, oldPrice)
+}
+
+// stockImportRowMessage turns an error caused by the row's content into a
+// message for that row. Any other error aborts the import.
+func stockImportRowMessage(err error) (string, bool) {
+	aerr, ok := err.(*apperror.AppError)
+	if !ok {
+		return "", false
+	}
+	if aerr.Code != apperror.CodeBadRequest && aerr.Code != apperror.CodeNotFound {
+		return "", false
+	}
+	return aerr.Message, true
+}
+
+// stockImportLookup caches the pharmacies and products a file names, most
+// files repeat the same few many times.
+type stockImportLookup struct {
+	dr        domain.DataRepository
+	managerID int64
+
+	pharmacies map[string]domain.Pharmacy
+	products   map[string]domain.Product
+}
+
+func newStockImportLookup(dr domain.DataRepository, managerID int64) *stockImportLookup {
+	return &stockImportLookup{
+		dr:         dr,
+		managerID:  managerID,
+		pharmacies: map[string]domain.Pharmacy{},
+		products:   map[string]domain.Product{},
+	}
+}
+
+func (l *stockImportLookup) pharmacy(ctx context.Context, slug string) (domain.Pharmacy, error) {
+	if p, ok := l.pharmacies[slug]; ok {
+		return p, nil
+	}
+
+	p, err := l.dr.PharmacyRepository().GetBySlug(ctx, slug)
+	if apperror.IsErrorCode(err, apperror.CodeNotFound) {
+		return domain.Pharmacy{}, apperror.NewEntityNotFound(fmt.Sprintf("pharmacy with slug %s", slug))
+	}
+	if err != nil {
+		return domain.Pharmacy{}, err
+	}
+	if p.ManagerID != l.managerID {
+		return domain.Pharmacy{}, apperror.NewStockImportNotManaged(slug)
+	}
+
+	l.pharmacies[slug] = p
+	return p, nil
+}
+
+func (l *stockImportLookup) product(ctx context.Context, slug string) (domain.Product, error) {
+	if p, ok := l.products[slug]; ok {
+		return p, nil
+	}
+
+	// Locked so no variant can be added to the product during the import.
+	p, err := l.dr.ProductRepository().GetBySlugAndLock(ctx, slug)
+	if apperror.IsErrorCode(err, apperror.CodeNotFound) {
+		return domain.Product{}, apperror.NewEntityNotFound(fmt.Sprintf("product with slug %s", slug))
+	}
+	if err != nil {
+		return domain.Product{}, err
+	}
+
+	l.products[slug] = p
+	return p, nil
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"medichat-be/util"
+)
+
+func (s *stockService) ListLedger(
+	ctx context.Context,
+	q domain.StockLedgerQuery,
+) ([]domain.StockLedgerEntry, domain.PageInfo, error) {
+	stockRepo := s.dataRepository.StockRepository()
+
+	// GetByID checks that a manager only sees their own pharmacies.
+	_, err := s.GetByID(ctx, q.StockID)
+	if err != nil {
+		return nil, domain.PageInfo{}, apperror.Wrap(err)
+	}
+
+	pageInfo, err := stockRepo.GetLedgerPageInfo(ctx, q)
+	if err != nil {
+		return nil, domain.PageInfo{}, apperror.Wrap(err)
+	}
+
+	entries, err := stockRepo.ListLedger(ctx, q)
+	if err != nil {
+		return nil, domain.PageInfo{}, apperror.Wrap(err)
+	}
+
+	return entries, pageInfo, nil
+}
+
+func (s *stockService) CheckLedger(
+	ctx context.Context,
+	stockID int64,
+) (domain.StockLedgerCheck, error) {
+	stockRepo := s.dataRepository.StockRepository()
+
+	stock, err := s.GetByID(ctx, stockID)
+	if err != nil {
+		return domain.StockLedgerCheck{}, apperror.Wrap(err)
+	}
+
+	entries, err := stockRepo.ListAllLedgerByStockID(ctx, stock.ID)
+	if err != nil {
+		return domain.StockLedgerCheck{}, apperror.Wrap(err)
+	}
+
+	return rebuildLedger(stock, entries), nil
+}
+
+func (s *stockService) CheckAllLedgers(ctx context.Context) ([]domain.StockLedgerCheck, error) {
+	stockRepo := s.dataRepository.StockRepository()
+
+	checks, err := stockRepo.ListLedgerMismatches(ctx)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	return checks, nil
+}
+
+// rebuildLedger replays entries, oldest first, and compares the result with
+// the stock's current amount.
+func rebuildLedger(stock domain.Stock, entries []domain.StockLedgerEntry) domain.StockLedgerCheck {
+	ret := domain.StockLedgerCheck{
+		StockID:    stock.ID,
+		Balance:    stock.Stock,
+		EntryCount: len(entries),
+	}
+
+	for _, e := range entries {
+		ret.RebuiltBalance += e.Change
+		if ret.MismatchEntryID == nil && e.BalanceAfter != ret.RebuiltBalance {
+			id := e.ID
+			ret.MismatchEntryID = &id
+		}
+	}
+
+	return ret
+}
+
+// changeStock moves the stock's amount by change and appends the change to
+// its ledger. Every write to a stock's amount should go through here.
+func changeStock(
+	ctx context.Context,
+	dr domain.DataRepository,
+	stock domain.Stock,
+	change int,
+	reason string,
+	referenceID *int64,
+) (domain.Stock, error) {
+	stockRepo := dr.StockRepository()
+
+	if change != 0 {
+		err := openStockLedger(ctx, dr, stock)
+		if err != nil {
+			return domain.Stock{}, err
+		}
+	}
+
+	stock.Stock += change
+
+	stock, err := stockRepo.Update(ctx, stock)
+	if err != nil {
+		return domain.Stock{}, err
+	}
+
+	if change == 0 {
+		return stock, nil
+	}
+
+	_, err = stockRepo.AddLedgerEntry(ctx, domain.StockLedgerEntry{
+		StockID:      stock.ID,
+		Reason:       reason,
+		ReferenceID:  referenceID,
+		ActorID:      ledgerActor(ctx),
+		Change:       change,
+		BalanceAfter: stock.Stock,
+	})
+	if err != nil {
+		return domain.Stock{}, err
+	}
+
+	return stock, nil
+}
+
+// openStockLedger writes the opening balance of a stock that has no ledger
+// yet, either because it is new or because it predates the ledger.
+func openStockLedger(ctx context.Context, dr domain.DataRepository, stock domain.Stock) error {
+	stockRepo := dr.StockRepository()
+
+	exists, err := stockRepo.HasLedger(ctx, stock.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	_, err = stockRepo.AddLedgerEntry(ctx, domain.StockLedgerEntry{
+		StockID:      stock.ID,
+		Reason:       domain.StockLedgerOpening,
+		ActorID:      ledgerActor(ctx),
+		Change:       stock.Stock,
+		BalanceAfter: stock.Stock,
+	})
+	return err
+}
+
+// ledgerActor is the account making the change, or nil for changes made by
+// background jobs.
+func ledgerActor(ctx context.Context) *int64 {
+	accountID, err := util.GetAccountIDFromContext(ctx)
+	if err != nil {
+		return nil
+	}
+	return &accountID
+}
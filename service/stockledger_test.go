@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"medichat-be/apperror"
+	"medichat-be/constants"
+	"medichat-be/domain"
+	"medichat-be/mocks/domainmocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// memoryStockRepository keeps stocks, reservations and ledger entries in
+// memory. Stocks have no batches and nothing expires.
+type memoryStockRepository struct {
+	domain.StockRepository
+
+	stocks       map[int64]domain.Stock
+	reservations []domain.StockReservation
+	ledger       []domain.StockLedgerEntry
+	nextID       int64
+}
+
+func newMemoryStockRepository(stocks ...domain.Stock) *memoryStockRepository {
+	r := &memoryStockRepository{stocks: map[int64]domain.Stock{}, nextID: 100}
+	for _, s := range stocks {
+		r.stocks[s.ID] = s
+	}
+	return r
+}
+
+func (r *memoryStockRepository) newID() int64 {
+	r.nextID++
+	return r.nextID
+}
+
+func (r *memoryStockRepository) GetByID(ctx context.Context, id int64) (domain.Stock, error) {
+	s, ok := r.stocks[id]
+	if !ok {
+		return domain.Stock{}, apperror.NewEntityNotFound("stock")
+	}
+	return s, nil
+}
+
+func (r *memoryStockRepository) GetByIDAndLock(ctx context.Context, id int64) (domain.Stock, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *memoryStockRepository) GetByPharmacyAndProduct(
+	ctx context.Context,
+	pharmacyID int64,
+	productID int64,
+	variantID *int64,
+) (domain.Stock, error) {
+	for _, s := range r.stocks {
+		if s.PharmacyID == pharmacyID && s.ProductID == productID {
+			return s, nil
+		}
+	}
+	return domain.Stock{}, apperror.NewEntityNotFound("stock")
+}
+
+func (r *memoryStockRepository) Add(ctx context.Context, s domain.Stock) (domain.Stock, error) {
+	s.ID = r.newID()
+	r.stocks[s.ID] = s
+	return s, nil
+}
+
+func (r *memoryStockRepository) Update(ctx context.Context, s domain.Stock) (domain.Stock, error) {
+	r.stocks[s.ID] = s
+	return s, nil
+}
+
+func (r *memoryStockRepository) GetReservedAmount(ctx context.Context, stockID int64) (int, error) {
+	reserved := 0
+	for _, res := range r.reservations {
+		if res.StockID == stockID && res.Status == domain.StockReservationStatusActive {
+			reserved += res.Amount
+		}
+	}
+	return reserved, nil
+}
+
+func (r *memoryStockRepository) AddReservation(
+	ctx context.Context,
+	res domain.StockReservation,
+) (domain.StockReservation, error) {
+	res.ID = r.newID()
+	r.reservations = append(r.reservations, res)
+	return res, nil
+}
+
+func (r *memoryStockRepository) UpdateReservationStatusByOrderIDs(
+	ctx context.Context,
+	orderIDs []int64,
+	from string,
+	to string,
+) error {
+	for i, res := range r.reservations {
+		for _, id := range orderIDs {
+			if res.OrderID == id && res.Status == from {
+				r.reservations[i].Status = to
+			}
+		}
+	}
+	return nil
+}
+
+func (r *memoryStockRepository) GetExpiredAmount(ctx context.Context, stockID int64) (int, error) {
+	return 0, nil
+}
+
+func (r *memoryStockRepository) ListSellableBatchesAndLock(
+	ctx context.Context,
+	stockID int64,
+) ([]domain.StockBatch, error) {
+	return nil, nil
+}
+
+func (r *memoryStockRepository) AddPrice(ctx context.Context, p domain.StockPrice) (domain.StockPrice, error) {
+	p.ID = r.newID()
+	return p, nil
+}
+
+func (r *memoryStockRepository) HasLedger(ctx context.Context, stockID int64) (bool, error) {
+	for _, e := range r.ledger {
+		if e.StockID == stockID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryStockRepository) AddLedgerEntry(
+	ctx context.Context,
+	e domain.StockLedgerEntry,
+) (domain.StockLedgerEntry, error) {
+	e.ID = r.newID()
+	r.ledger = append(r.ledger, e)
+	return e, nil
+}
+
+func (r *memoryStockRepository) ListAllLedgerByStockID(
+	ctx context.Context,
+	stockID int64,
+) ([]domain.StockLedgerEntry, error) {
+	entries := []domain.StockLedgerEntry{}
+	for _, e := range r.ledger {
+		if e.StockID == stockID {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+type memoryOrderRepository struct {
+	domain.OrderRepository
+
+	orders map[int64]domain.Order
+}
+
+func (r *memoryOrderRepository) GetByIDAndLock(ctx context.Context, id int64) (domain.Order, error) {
+	return r.orders[id], nil
+}
+
+func (r *memoryOrderRepository) UpdateStatusByID(ctx context.Context, id int64, status string) error {
+	o := r.orders[id]
+	o.Status = status
+	r.orders[id] = o
+	return nil
+}
+
+type accountUserRepository struct {
+	domain.UserRepository
+
+	user domain.User
+}
+
+func (r *accountUserRepository) GetByAccountID(ctx context.Context, id int64) (domain.User, error) {
+	return r.user, nil
+}
+
+type releasePromotionRepository struct {
+	domain.PromotionRepository
+}
+
+func (r *releasePromotionRepository) ReleaseUsageByOrderIDs(ctx context.Context, orderIDs []int64) error {
+	return nil
+}
+
+type releaseVoucherRepository struct {
+	domain.VoucherRepository
+}
+
+func (r *releaseVoucherRepository) ReleaseByOrderIDs(ctx context.Context, orderIDs []int64) error {
+	return nil
+}
+
+func assertLedgerReconciles(t *testing.T, stockRepo *memoryStockRepository, stockID int64, want int) {
+	t.Helper()
+
+	stock, err := stockRepo.GetByID(context.Background(), stockID)
+	assert.NoError(t, err)
+	entries, err := stockRepo.ListAllLedgerByStockID(context.Background(), stockID)
+	assert.NoError(t, err)
+
+	check := rebuildLedger(stock, entries)
+	assert.Equal(t, want, check.Balance)
+	assert.Equal(t, check.Balance, check.RebuiltBalance)
+	assert.Nil(t, check.MismatchEntryID)
+}
+
+func Test_stockLedger_reconciles(t *testing.T) {
+	// given
+	const (
+		accountID  = int64(1)
+		userID     = int64(2)
+		productID  = int64(10)
+		pharmacyA  = int64(20)
+		pharmacyB  = int64(21)
+		pharmacyC  = int64(22)
+		mutationID = int64(30)
+		importID   = int64(40)
+	)
+	ctx := context.WithValue(context.Background(), constants.ContextAccountID, accountID)
+
+	// Stock 1 predates the ledger.
+	stockRepo := newMemoryStockRepository(
+		domain.Stock{ID: 1, PharmacyID: pharmacyA, ProductID: productID, Stock: 20, Price: 10000},
+		domain.Stock{ID: 2, PharmacyID: pharmacyB, ProductID: productID, Stock: 5, Price: 10000},
+	)
+
+	sold := domain.Order{ID: 50, Status: domain.OrderStatusProcessing}
+	sold.User.ID = userID
+	cancelled := domain.Order{ID: 51, Status: domain.OrderStatusProcessing}
+	cancelled.User.ID = userID
+	orderRepo := &memoryOrderRepository{orders: map[int64]domain.Order{sold.ID: sold, cancelled.ID: cancelled}}
+
+	accountRepo := new(domainmocks.AccountRepository)
+	accountRepo.On("GetByID", mock.Anything, accountID).
+		Return(domain.Account{ID: accountID, Role: domain.AccountRoleUser}, nil)
+
+	dataRepo := new(domainmocks.DataRepository)
+	dataRepo.On("StockRepository").Return(stockRepo)
+	dataRepo.On("OrderRepository").Return(orderRepo)
+	dataRepo.On("AccountRepository").Return(accountRepo)
+	dataRepo.On("UserRepository").Return(&accountUserRepository{user: domain.User{ID: userID}})
+	dataRepo.On("PharmacyManagerRepository").Return(nil)
+	dataRepo.On("PharmacyRepository").Return(nil)
+	dataRepo.On("PromotionRepository").Return(&releasePromotionRepository{})
+	dataRepo.On("VoucherRepository").Return(&releaseVoucherRepository{})
+
+	s := &orderService{dataRepository: dataRepo}
+	item := func(orderID int64, amount int) domain.OrderItem {
+		i := domain.OrderItem{OrderID: orderID, Amount: amount}
+		i.Product.ID = productID
+		return i
+	}
+
+	t.Run("after an order", func(t *testing.T) {
+		// when
+		err := s.reserveStockForOrderItem(ctx, dataRepo, pharmacyA, item(sold.ID, 3))
+		assert.NoError(t, err)
+		err = s.updateStockForOrderItem(ctx, dataRepo, pharmacyA, item(sold.ID, 3))
+
+		// then
+		assert.NoError(t, err)
+		assertLedgerReconciles(t, stockRepo, 1, 17)
+	})
+
+	t.Run("after a cancel", func(t *testing.T) {
+		// when
+		err := s.reserveStockForOrderItem(ctx, dataRepo, pharmacyA, item(cancelled.ID, 2))
+		assert.NoError(t, err)
+		_, err = s.CancelOrderClosure(ctx, cancelled.ID)(dataRepo)
+
+		// then
+		assert.NoError(t, err)
+		assertLedgerReconciles(t, stockRepo, 1, 17)
+	})
+
+	t.Run("after a mutation", func(t *testing.T) {
+		// when
+		_, _, err := transferStock(dataRepo, ctx, 1, 2, 4, mutationID)
+
+		// then
+		assert.NoError(t, err)
+		assertLedgerReconciles(t, stockRepo, 1, 13)
+		assertLedgerReconciles(t, stockRepo, 2, 9)
+	})
+
+	t.Run("after an import", func(t *testing.T) {
+		// given
+		existingID := int64(1)
+		existing := stockRepo.stocks[existingID]
+		updated := stockImportPlan{
+			change: domain.StockImportChange{StockID: &existingID, OldStock: 13, NewStock: 30, OldPrice: 10000, NewPrice: 12000},
+			stock:  existing,
+		}
+		added := stockImportPlan{
+			change: domain.StockImportChange{NewStock: 8, NewPrice: 12000},
+			stock:  domain.Stock{PharmacyID: pharmacyC, ProductID: productID},
+		}
+
+		// when
+		err1 := applyStockImportPlan(ctx, dataRepo, updated, importID)
+		err2 := applyStockImportPlan(ctx, dataRepo, added, importID)
+
+		// then
+		assert.NoError(t, err1)
+		assert.NoError(t, err2)
+		assertLedgerReconciles(t, stockRepo, 1, 30)
+
+		addedStock, err := stockRepo.GetByPharmacyAndProduct(ctx, pharmacyC, productID, nil)
+		assert.NoError(t, err)
+		assertLedgerReconciles(t, stockRepo, addedStock.ID, 8)
+	})
+}
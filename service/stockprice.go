@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"time"
+)
+
+func (s *stockService) ListPrices(
+	ctx context.Context,
+	stockID int64,
+) ([]domain.StockPrice, error) {
+	stockRepo := s.dataRepository.StockRepository()
+
+	// GetByID checks that a manager only sees their own pharmacies.
+	stock, err := s.GetByID(ctx, stockID)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	prices, err := stockRepo.ListPricesByStockID(ctx, stock.ID)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	return prices, nil
+}
+
+// SchedulePrice sets the price a stock will have from EffectiveAt on.
+// Orders placed after that time pay it even before the job writes it to the
+// stock.
+func (s *stockService) SchedulePrice(
+	ctx context.Context,
+	det domain.StockPriceScheduleDetail,
+) (domain.StockPrice, error) {
+	stockRepo := s.dataRepository.StockRepository()
+
+	if !det.EffectiveAt.After(time.Now()) {
+		return domain.StockPrice{}, apperror.NewStockPriceNotInFuture()
+	}
+
+	stock, err := s.GetByID(ctx, det.StockID)
+	if err != nil {
+		return domain.StockPrice{}, apperror.Wrap(err)
+	}
+
+	price, err := stockRepo.AddPrice(ctx, domain.StockPrice{
+		StockID:     stock.ID,
+		Price:       det.Price,
+		Status:      domain.StockPriceStatusScheduled,
+		ActorID:     ledgerActor(ctx),
+		EffectiveAt: det.EffectiveAt,
+	})
+	if err != nil {
+		return domain.StockPrice{}, apperror.Wrap(err)
+	}
+
+	return price, nil
+}
+
+func (s *stockService) CancelScheduledPriceClosure(
+	ctx context.Context,
+	stockID int64,
+	priceID int64,
+) domain.AtomicFunc[domain.StockPrice] {
+	return func(dr domain.DataRepository) (domain.StockPrice, error) {
+		stockRepo := dr.StockRepository()
+
+		price, err := stockRepo.GetPriceByIDAndLock(ctx, priceID)
+		if err != nil {
+			return domain.StockPrice{}, apperror.Wrap(err)
+		}
+		if price.StockID != stockID {
+			return domain.StockPrice{}, apperror.NewEntityNotFound("price")
+		}
+		if price.Status != domain.StockPriceStatusScheduled {
+			return domain.StockPrice{}, apperror.NewStockPriceNotScheduled()
+		}
+
+		err = stockRepo.UpdatePriceStatus(ctx, price.ID, domain.StockPriceStatusCancelled)
+		if err != nil {
+			return domain.StockPrice{}, apperror.Wrap(err)
+		}
+		price.Status = domain.StockPriceStatusCancelled
+
+		return price, nil
+	}
+}
+
+// CancelScheduledPrice withdraws a price that hasn't been applied yet. A
+// price that is already due can still be cancelled until the job applies it.
+func (s *stockService) CancelScheduledPrice(
+	ctx context.Context,
+	stockID int64,
+	priceID int64,
+) (domain.StockPrice, error) {
+	_, err := s.GetByID(ctx, stockID)
+	if err != nil {
+		return domain.StockPrice{}, apperror.Wrap(err)
+	}
+
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.CancelScheduledPriceClosure(ctx, stockID, priceID),
+	)
+}
+
+func (s *stockService) ApplyScheduledPricesClosure(
+	ctx context.Context,
+) domain.AtomicFunc[int] {
+	return func(dr domain.DataRepository) (int, error) {
+		stockRepo := dr.StockRepository()
+
+		prices, err := stockRepo.ListDueScheduledPricesAndLock(ctx, time.Now())
+		if err != nil {
+			return 0, apperror.Wrap(err)
+		}
+
+		// Prices come ordered by stock and time, so when several are due the
+		// stock ends at the latest one.
+		for _, price := range prices {
+			stock, err := stockRepo.GetByIDAndLock(ctx, price.StockID)
+			if apperror.IsErrorCode(err, apperror.CodeNotFound) {
+				err = stockRepo.UpdatePriceStatus(ctx, price.ID, domain.StockPriceStatusCancelled)
+				if err != nil {
+					return 0, apperror.Wrap(err)
+				}
+				continue
+			}
+			if err != nil {
+				return 0, apperror.Wrap(err)
+			}
+
+			stock.Price = price.Price
+			_, err = stockRepo.Update(ctx, stock)
+			if err != nil {
+				return 0, apperror.Wrap(err)
+			}
+
+			err = stockRepo.UpdatePriceStatus(ctx, price.ID, domain.StockPriceStatusApplied)
+			if err != nil {
+				return 0, apperror.Wrap(err)
+			}
+		}
+
+		return len(prices), nil
+	}
+}
+
+// ApplyScheduledPrices writes every due scheduled price to its stock. A
+// price whose stock was deleted in the meantime is cancelled.
+func (s *stockService) ApplyScheduledPrices(ctx context.Context) (int, error) {
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.ApplyScheduledPricesClosure(ctx),
+	)
+}
+
+// recordStockPrice adds the stock's current price to its history when it
+// differs from old. A new stock has no old price.
+func recordStockPrice(
+	ctx context.Context,
+	dr domain.DataRepository,
+	stock domain.Stock,
+	old *int,
+) error {
+	if old != nil && *old == stock.Price {
+		return nil
+	}
+
+	_, err := dr.StockRepository().AddPrice(ctx, domain.StockPrice{
+		StockID:     stock.ID,
+		Price:       stock.Price,
+		Status:      domain.StockPriceStatusApplied,
+		ActorID:     ledgerActor(ctx),
+		EffectiveAt: time.Now(),
+	})
+	return err
+}
+
+// stockPriceAt is the price of a stock at at, a scheduled price that is due
+// but not yet applied by the job included.
+func stockPriceAt(
+	ctx context.Context,
+	dr domain.DataRepository,
+	stock domain.Stock,
+	at time.Time,
+) (int, error) {
+	price, err := dr.StockRepository().GetDueScheduledPrice(ctx, stock.ID, at)
+	if apperror.IsErrorCode(err, apperror.CodeNotFound) {
+		return stock.Price, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return price.Price, nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+)
+
+// ProposeReplenishments looks for stocks below their minimum and asks the
+// nearest pharmacy of the same manager with a surplus for enough units to
+// reach the reorder level. The proposals are pending mutations the manager
+// approves like any other transfer. A stock that fails is logged and left
+// for the next run.
+func (s *stockService) ProposeReplenishments(ctx context.Context) ([]domain.StockMutation, error) {
+	stockRepo := s.dataRepository.StockRepository()
+
+	stocks, err := stockRepo.ListLowStocksToReplenish(ctx)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	ret := []domain.StockMutation{}
+	for _, stock := range stocks {
+		mut, err := domain.RunAtomic(
+			s.dataRepository,
+			ctx,
+			s.ProposeReplenishmentClosure(ctx, stock.ID),
+		)
+		if err != nil {
+			s.logger.Errorf("proposing replenishment of stock %d: %v", stock.ID, err)
+			continue
+		}
+		if mut != nil {
+			ret = append(ret, *mut)
+		}
+	}
+
+	return ret, nil
+}
+
+// ProposeReplenishmentClosure returns nil when the stock no longer needs
+// replenishing, already has a pending transfer or no pharmacy can spare
+// anything.
+func (s *stockService) ProposeReplenishmentClosure(
+	ctx context.Context,
+	stockID int64,
+) domain.AtomicFunc[*domain.StockMutation] {
+	return func(dr domain.DataRepository) (*domain.StockMutation, error) {
+		stockRepo := dr.StockRepository()
+
+		stock, err := stockRepo.GetByIDAndLock(ctx, stockID)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		// Overlapping runs wait on the lock above, so the one that comes
+		// second sees the transfer the first proposed.
+		pending, err := stockRepo.IsPendingMutationExistByTargetID(ctx, stock.ID)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+		if pending {
+			return nil, nil
+		}
+
+		available, err := sellableAmount(ctx, dr, stock)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+		if available >= stock.MinStock {
+			return nil, nil
+		}
+
+		source, err := stockRepo.GetNearestSurplusStock(ctx, stock)
+		if apperror.IsErrorCode(err, apperror.CodeNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		amount := stock.ReorderLevel - available
+		if amount > source.Surplus {
+			amount = source.Surplus
+		}
+
+		mut := domain.StockMutation{
+			SourceID: source.Stock.ID,
+			TargetID: stock.ID,
+			Method:   domain.StockMutationReplenishment,
+			Status:   domain.StockMutationStatusPending,
+			Amount:   amount,
+		}
+
+		mut, err = stockRepo.AddMutation(ctx, mut)
+		if err != nil {
+			return nil, apperror.Wrap(err)
+		}
+
+		return &mut, nil
+	}
+}
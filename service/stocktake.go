@@ -0,0 +1,473 @@
+package service
+
+import (
+	"context"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"medichat-be/util"
+	"time"
+)
+
+type stockTakeService struct {
+	dataRepository domain.DataRepository
+}
+
+type StockTakeServiceOpts struct {
+	DataRepository domain.DataRepository
+}
+
+func NewStockTakeService(opts StockTakeServiceOpts) *stockTakeService {
+	return &stockTakeService{
+		dataRepository: opts.DataRepository,
+	}
+}
+
+func (s *stockTakeService) OpenClosure(
+	ctx context.Context,
+	pharmacySlug string,
+) domain.AtomicFunc[domain.StockTake] {
+	return func(dr domain.DataRepository) (domain.StockTake, error) {
+		takeRepo := dr.StockTakeRepository()
+
+		manager, pharmacy, err := managedPharmacyBySlug(ctx, dr, pharmacySlug)
+		if err != nil {
+			return domain.StockTake{}, apperror.Wrap(err)
+		}
+
+		// Opening stock-takes of the same pharmacy take turns, so only one
+		// of them can be open.
+		_, err = dr.PharmacyRepository().GetByIDAndLock(ctx, pharmacy.ID)
+		if err != nil {
+			return domain.StockTake{}, apperror.Wrap(err)
+		}
+
+		exists, err := takeRepo.IsOpenExistByPharmacyID(ctx, pharmacy.ID)
+		if err != nil {
+			return domain.StockTake{}, apperror.Wrap(err)
+		}
+		if exists {
+			return domain.StockTake{}, apperror.NewStockTakeAlreadyOpen()
+		}
+
+		take, err := takeRepo.Add(ctx, domain.StockTake{
+			PharmacyID: pharmacy.ID,
+			ManagerID:  manager.ID,
+			Status:     domain.StockTakeStatusOpen,
+		})
+		if err != nil {
+			return domain.StockTake{}, apperror.Wrap(err)
+		}
+
+		_, err = takeRepo.AddSnapshot(ctx, take.ID, pharmacy.ID)
+		if err != nil {
+			return domain.StockTake{}, apperror.Wrap(err)
+		}
+
+		// Every change to the snapshotted stocks committed before the
+		// snapshot locked them, later ones get later ledger IDs.
+		take.LastLedgerID, err = dr.StockRepository().GetLastLedgerID(ctx)
+		if err != nil {
+			return domain.StockTake{}, apperror.Wrap(err)
+		}
+
+		take, err = takeRepo.Update(ctx, take)
+		if err != nil {
+			return domain.StockTake{}, apperror.Wrap(err)
+		}
+
+		return take, nil
+	}
+}
+
+func (s *stockTakeService) Open(ctx context.Context, pharmacySlug string) (domain.StockTake, error) {
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.OpenClosure(ctx, pharmacySlug),
+	)
+}
+
+func (s *stockTakeService) List(ctx context.Context, pharmacySlug string) ([]domain.StockTake, error) {
+	takeRepo := s.dataRepository.StockTakeRepository()
+
+	_, pharmacy, err := managedPharmacyBySlug(ctx, s.dataRepository, pharmacySlug)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	takes, err := takeRepo.ListByPharmacyID(ctx, pharmacy.ID)
+	if err != nil {
+		return nil, apperror.Wrap(err)
+	}
+
+	return takes, nil
+}
+
+func (s *stockTakeService) GetReport(ctx context.Context, id int64) (domain.StockTakeReport, error) {
+	takeRepo := s.dataRepository.StockTakeRepository()
+
+	take, err := takeRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.StockTakeReport{}, apperror.Wrap(err)
+	}
+
+	err = checkStockTakeManager(ctx, s.dataRepository, take)
+	if err != nil {
+		return domain.StockTakeReport{}, apperror.Wrap(err)
+	}
+
+	report, err := stockTakeReport(ctx, s.dataRepository, take)
+	if err != nil {
+		return domain.StockTakeReport{}, apperror.Wrap(err)
+	}
+
+	return report, nil
+}
+
+func (s *stockTakeService) SubmitCountsClosure(
+	ctx context.Context,
+	det domain.StockTakeCountDetail,
+) domain.AtomicFunc[domain.StockTake] {
+	return func(dr domain.DataRepository) (domain.StockTake, error) {
+		takeRepo := dr.StockTakeRepository()
+
+		take, err := takeRepo.GetByIDAndLock(ctx, det.StockTakeID)
+		if err != nil {
+			return domain.StockTake{}, apperror.Wrap(err)
+		}
+
+		err = checkStockTakeManager(ctx, dr, take)
+		if err != nil {
+			return domain.StockTake{}, apperror.Wrap(err)
+		}
+
+		if take.Status != domain.StockTakeStatusOpen {
+			return domain.StockTake{}, apperror.NewStockTakeNotOpen()
+		}
+
+		// A later count of the same stock replaces the earlier one.
+		for _, c := range det.Counts {
+			item, err := takeRepo.GetItemByStockIDAndLock(ctx, take.ID, c.StockID)
+			if apperror.IsErrorCode(err, apperror.CodeNotFound) {
+				return domain.StockTake{}, apperror.NewEntityNotFound("stock in this stock-take")
+			}
+			if err != nil {
+				return domain.StockTake{}, apperror.Wrap(err)
+			}
+
+			err = takeRepo.UpdateItemCount(ctx, item.ID, c.Counted)
+			if err != nil {
+				return domain.StockTake{}, apperror.Wrap(err)
+			}
+		}
+
+		return take, nil
+	}
+}
+
+func (s *stockTakeService) SubmitCounts(
+	ctx context.Context,
+	det domain.StockTakeCountDetail,
+) (domain.StockTakeReport, error) {
+	take, err := domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.SubmitCountsClosure(ctx, det),
+	)
+	if err != nil {
+		return domain.StockTakeReport{}, apperror.Wrap(err)
+	}
+
+	report, err := stockTakeReport(ctx, s.dataRepository, take)
+	if err != nil {
+		return domain.StockTakeReport{}, apperror.Wrap(err)
+	}
+
+	return report, nil
+}
+
+func (s *stockTakeService) CloseClosure(
+	ctx context.Context,
+	id int64,
+) domain.AtomicFunc[domain.StockTake] {
+	return func(dr domain.DataRepository) (domain.StockTake, error) {
+		stockRepo := dr.StockRepository()
+		takeRepo := dr.StockTakeRepository()
+
+		take, err := takeRepo.GetByIDAndLock(ctx, id)
+		if err != nil {
+			return domain.StockTake{}, apperror.Wrap(err)
+		}
+
+		err = checkStockTakeManager(ctx, dr, take)
+		if err != nil {
+			return domain.StockTake{}, apperror.Wrap(err)
+		}
+
+		if take.Status != domain.StockTakeStatusOpen {
+			return domain.StockTake{}, apperror.NewStockTakeNotOpen()
+		}
+
+		items, err := takeRepo.ListItems(ctx, take.ID)
+		if err != nil {
+			return domain.StockTake{}, apperror.Wrap(err)
+		}
+
+		for _, item := range items {
+			if item.Counted == nil {
+				continue
+			}
+
+			// Stocks deleted during the count have nothing left to adjust.
+			stock, err := stockRepo.GetByIDAndLock(ctx, item.StockID)
+			if apperror.IsErrorCode(err, apperror.CodeNotFound) {
+				continue
+			}
+			if err != nil {
+				return domain.StockTake{}, apperror.Wrap(err)
+			}
+
+			result, err := reconcileStockTakeItem(ctx, dr, take, item.StockTakeItem)
+			if err != nil {
+				return domain.StockTake{}, apperror.Wrap(err)
+			}
+
+			if result.Variance != 0 {
+				if stock.Stock+result.Variance < 0 {
+					return domain.StockTake{}, apperror.NewStockTakeBelowZero(item.Product.Name)
+				}
+
+				err = adjustStockToCount(ctx, dr, take, stock, result.Variance)
+				if err != nil {
+					return domain.StockTake{}, apperror.Wrap(err)
+				}
+			}
+
+			err = takeRepo.UpdateItemResult(ctx, result)
+			if err != nil {
+				return domain.StockTake{}, apperror.Wrap(err)
+			}
+		}
+
+		now := time.Now()
+		take.Status = domain.StockTakeStatusClosed
+		take.ClosedAt = &now
+
+		take, err = takeRepo.Update(ctx, take)
+		if err != nil {
+			return domain.StockTake{}, apperror.Wrap(err)
+		}
+
+		return take, nil
+	}
+}
+
+// Close applies every counted variance to its stock and ledger. Stocks that
+// weren't counted are left as they are.
+func (s *stockTakeService) Close(ctx context.Context, id int64) (domain.StockTakeReport, error) {
+	take, err := domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.CloseClosure(ctx, id),
+	)
+	if err != nil {
+		return domain.StockTakeReport{}, apperror.Wrap(err)
+	}
+
+	report, err := stockTakeReport(ctx, s.dataRepository, take)
+	if err != nil {
+		return domain.StockTakeReport{}, apperror.Wrap(err)
+	}
+
+	return report, nil
+}
+
+func (s *stockTakeService) CancelClosure(
+	ctx context.Context,
+	id int64,
+) domain.AtomicFunc[domain.StockTake] {
+	return func(dr domain.DataRepository) (domain.StockTake, error) {
+		takeRepo := dr.StockTakeRepository()
+
+		take, err := takeRepo.GetByIDAndLock(ctx, id)
+		if err != nil {
+			return domain.StockTake{}, apperror.Wrap(err)
+		}
+
+		err = checkStockTakeManager(ctx, dr, take)
+		if err != nil {
+			return domain.StockTake{}, apperror.Wrap(err)
+		}
+
+		if take.Status != domain.StockTakeStatusOpen {
+			return domain.StockTake{}, apperror.NewStockTakeNotOpen()
+		}
+
+		now := time.Now()
+		take.Status = domain.StockTakeStatusCancelled
+		take.ClosedAt = &now
+
+		take, err = takeRepo.Update(ctx, take)
+		if err != nil {
+			return domain.StockTake{}, apperror.Wrap(err)
+		}
+
+		return take, nil
+	}
+}
+
+func (s *stockTakeService) Cancel(ctx context.Context, id int64) (domain.StockTake, error) {
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.CancelClosure(ctx, id),
+	)
+}
+
+// reconcileStockTakeItem works out the variance of a counted item. Whatever
+// the ledger recorded between the snapshot and the count, sales included,
+// moves the quantity the count is compared with.
+func reconcileStockTakeItem(
+	ctx context.Context,
+	dr domain.DataRepository,
+	take domain.StockTake,
+	item domain.StockTakeItem,
+) (domain.StockTakeItem, error) {
+	stockRepo := dr.StockRepository()
+
+	if item.Counted == nil {
+		return item, nil
+	}
+
+	movement, err := stockRepo.SumLedgerChange(ctx, item.StockID, take.LastLedgerID, *item.CountedAt)
+	if err != nil {
+		return domain.StockTakeItem{}, err
+	}
+
+	item.Movement = movement
+	item.Variance = *item.Counted - item.ExpectedAtCount()
+	return item, nil
+}
+
+// adjustStockToCount moves the stock by variance, recorded on its ledger
+// against the stock-take. A shortage comes out of the batches first expiring
+// first, like a sale would.
+func adjustStockToCount(
+	ctx context.Context,
+	dr domain.DataRepository,
+	take domain.StockTake,
+	stock domain.Stock,
+	variance int,
+) error {
+	if variance < 0 {
+		_, err := pickBatches(ctx, dr, stock.ID, -variance)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := changeStock(ctx, dr, stock, variance, domain.StockLedgerStockTake, &take.ID)
+	return err
+}
+
+// stockTakeReport lists the items of the stock-take. The variances of an
+// open stock-take are worked out as of now, a closed one keeps those it was
+// closed with.
+func stockTakeReport(
+	ctx context.Context,
+	dr domain.DataRepository,
+	take domain.StockTake,
+) (domain.StockTakeReport, error) {
+	takeRepo := dr.StockTakeRepository()
+
+	items, err := takeRepo.ListItems(ctx, take.ID)
+	if err != nil {
+		return domain.StockTakeReport{}, err
+	}
+
+	report := domain.StockTakeReport{
+		StockTake: take,
+		Items:     items,
+	}
+
+	for i := range report.Items {
+		item := &report.Items[i]
+
+		if item.Counted == nil {
+			report.Uncounted++
+			continue
+		}
+		report.Counted++
+
+		if take.Status == domain.StockTakeStatusOpen {
+			item.StockTakeItem, err = reconcileStockTakeItem(ctx, dr, take, item.StockTakeItem)
+			if err != nil {
+				return domain.StockTakeReport{}, err
+			}
+		}
+
+		if item.Variance > 0 {
+			report.Gain += item.Variance
+		} else {
+			report.Loss -= item.Variance
+		}
+	}
+
+	return report, nil
+}
+
+func checkStockTakeManager(ctx context.Context, dr domain.DataRepository, take domain.StockTake) error {
+	managerRepo := dr.PharmacyManagerRepository()
+	pharmacyRepo := dr.PharmacyRepository()
+
+	accountID, err := util.GetAccountIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	manager, err := managerRepo.GetByAccountID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	pharmacy, err := pharmacyRepo.GetByID(ctx, take.PharmacyID)
+	if err != nil {
+		return err
+	}
+
+	if pharmacy.ManagerID != manager.ID {
+		return apperror.NewForbidden(nil)
+	}
+
+	return nil
+}
+
+func managedPharmacyBySlug(
+	ctx context.Context,
+	dr domain.DataRepository,
+	slug string,
+) (domain.PharmacyManager, domain.Pharmacy, error) {
+	managerRepo := dr.PharmacyManagerRepository()
+	pharmacyRepo := dr.PharmacyRepository()
+
+	accountID, err := util.GetAccountIDFromContext(ctx)
+	if err != nil {
+		return domain.PharmacyManager{}, domain.Pharmacy{}, err
+	}
+
+	manager, err := managerRepo.GetByAccountID(ctx, accountID)
+	if err != nil {
+		return domain.PharmacyManager{}, domain.Pharmacy{}, err
+	}
+
+	pharmacy, err := pharmacyRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return domain.PharmacyManager{}, domain.Pharmacy{}, err
+	}
+
+	if pharmacy.ManagerID != manager.ID {
+		return domain.PharmacyManager{}, domain.Pharmacy{}, apperror.NewForbidden(nil)
+	}
+
+	return manager, pharmacy, nil
+}
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"strings"
+	"time"
+)
+
+type voucherService struct {
+	dataRepository domain.DataRepository
+}
+
+type VoucherServiceOpts struct {
+	DataRepository domain.DataRepository
+}
+
+func NewVoucherService(opts VoucherServiceOpts) *voucherService {
+	return &voucherService{
+		dataRepository: opts.DataRepository,
+	}
+}
+
+func (s *voucherService) List(
+	ctx context.Context,
+	det domain.VoucherListDetails,
+) ([]domain.Voucher, domain.PageInfo, error) {
+	voucherRepo := s.dataRepository.VoucherRepository()
+
+	pageInfo, err := voucherRepo.GetPageInfo(ctx, det)
+	if err != nil {
+		return nil, domain.PageInfo{}, apperror.Wrap(err)
+	}
+
+	vouchers, err := voucherRepo.List(ctx, det)
+	if err != nil {
+		return nil, domain.PageInfo{}, apperror.Wrap(err)
+	}
+
+	return vouchers, pageInfo, nil
+}
+
+func (s *voucherService) GetByID(ctx context.Context, id int64) (domain.Voucher, error) {
+	voucher, err := s.dataRepository.VoucherRepository().GetByID(ctx, id)
+	if err != nil {
+		return domain.Voucher{}, apperror.Wrap(err)
+	}
+
+	return voucher, nil
+}
+
+func (s *voucherService) CreateClosure(
+	ctx context.Context,
+	v domain.Voucher,
+) domain.AtomicFunc[domain.Voucher] {
+	return func(dr domain.DataRepository) (domain.Voucher, error) {
+		voucherRepo := dr.VoucherRepository()
+
+		v.Code = normalizeVoucherCode(v.Code)
+
+		exists, err := voucherRepo.IsExistByCode(ctx, v.Code)
+		if err != nil {
+			return domain.Voucher{}, apperror.Wrap(err)
+		}
+		if exists {
+			return domain.Voucher{}, apperror.NewVoucherCodeTaken(v.Code)
+		}
+
+		v, err = checkVoucher(ctx, dr, v)
+		if err != nil {
+			return domain.Voucher{}, apperror.Wrap(err)
+		}
+
+		voucher, err := voucherRepo.Add(ctx, v)
+		if err != nil {
+			return domain.Voucher{}, apperror.Wrap(err)
+		}
+
+		return voucher, nil
+	}
+}
+
+func (s *voucherService) Create(ctx context.Context, v domain.Voucher) (domain.Voucher, error) {
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.CreateClosure(ctx, v),
+	)
+}
+
+func (s *voucherService) UpdateClosure(
+	ctx context.Context,
+	v domain.Voucher,
+) domain.AtomicFunc[domain.Voucher] {
+	return func(dr domain.DataRepository) (domain.Voucher, error) {
+		voucherRepo := dr.VoucherRepository()
+
+		old, err := voucherRepo.GetByIDAndLock(ctx, v.ID)
+		if err != nil {
+			return domain.Voucher{}, apperror.Wrap(err)
+		}
+
+		v.Code = normalizeVoucherCode(v.Code)
+		if v.Code != old.Code {
+			exists, err := voucherRepo.IsExistByCode(ctx, v.Code)
+			if err != nil {
+				return domain.Voucher{}, apperror.Wrap(err)
+			}
+			if exists {
+				return domain.Voucher{}, apperror.NewVoucherCodeTaken(v.Code)
+			}
+		}
+
+		v.UsageCount = old.UsageCount
+		if v.UsageLimit != nil && *v.UsageLimit < v.UsageCount {
+			return domain.Voucher{}, apperror.NewVoucherLimitBelowUsage(v.UsageCount)
+		}
+
+		v, err = checkVoucher(ctx, dr, v)
+		if err != nil {
+			return domain.Voucher{}, apperror.Wrap(err)
+		}
+
+		voucher, err := voucherRepo.Update(ctx, v)
+		if err != nil {
+			return domain.Voucher{}, apperror.Wrap(err)
+		}
+
+		return voucher, nil
+	}
+}
+
+// Update replaces the rules of a voucher. Orders it was already redeemed
+// on keep the discount they got.
+func (s *voucherService) Update(ctx context.Context, v domain.Voucher) (domain.Voucher, error) {
+	return domain.RunAtomic(
+		s.dataRepository,
+		ctx,
+		s.UpdateClosure(ctx, v),
+	)
+}
+
+func (s *voucherService) Delete(ctx context.Context, id int64) error {
+	err := s.dataRepository.VoucherRepository().SoftDeleteByID(ctx, id)
+	if err != nil {
+		return apperror.Wrap(err)
+	}
+
+	return nil
+}
+
+// normalizeVoucherCode makes codes case insensitive, users type them in by
+// hand.
+func normalizeVoucherCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// checkVoucher validates the rules of a voucher and that its pharmacy
+// exists. A free shipping voucher has no value of its own.
+func checkVoucher(ctx context.Context, dr domain.DataRepository, v domain.Voucher) (domain.Voucher, error) {
+	if v.Type == domain.VoucherTypeFreeShipping {
+		v.Value = 0
+	} else if v.Value < 1 {
+		return domain.Voucher{}, apperror.NewVoucherValueRequired()
+	}
+	if v.Type == domain.VoucherTypePercentage && v.Value > 100 {
+		return domain.Voucher{}, apperror.NewVoucherPercentageTooHigh()
+	}
+	if v.EndsAt != nil && !v.EndsAt.After(v.StartsAt) {
+		return domain.Voucher{}, apperror.NewVoucherEndsBeforeStart()
+	}
+
+	if v.PharmacyID != nil {
+		_, err := dr.PharmacyRepository().GetByID(ctx, *v.PharmacyID)
+		if apperror.IsErrorCode(err, apperror.CodeNotFound) {
+			return domain.Voucher{}, apperror.NewEntityNotFound("pharmacy")
+		}
+		if err != nil {
+			return domain.Voucher{}, err
+		}
+	}
+
+	return v, nil
+}
+
+// checkVoucherUsage reports whether the user can use the voucher n more
+// times without going past either of its limits.
+func checkVoucherUsage(
+	ctx context.Context,
+	dr domain.DataRepository,
+	v domain.Voucher,
+	userID int64,
+	n int,
+) error {
+	if v.UsageLimit != nil && v.UsageCount+n > *v.UsageLimit {
+		return apperror.NewVoucherExhausted(v.Code)
+	}
+
+	if v.PerUserLimit != nil {
+		used, err := dr.VoucherRepository().CountRedemptionsByUserID(ctx, v.ID, userID)
+		if err != nil {
+			return err
+		}
+		if used+n > *v.PerUserLimit {
+			return apperror.NewVoucherUserLimit(v.Code)
+		}
+	}
+
+	return nil
+}
+
+// voucherApplier applies the vouchers of a cart. It counts the uses it
+// hands out so a code entered on several orders is held to its limits
+// across all of them.
+type voucherApplier struct {
+	dr     domain.DataRepository
+	userID int64
+	at     time.Time
+	used   map[int64]int
+}
+
+func newVoucherApplier(dr domain.DataRepository, userID int64, at time.Time) *voucherApplier {
+	return &voucherApplier{
+		dr:     dr,
+		userID: userID,
+		at:     at,
+		used:   map[int64]int{},
+	}
+}
+
+// apply returns the voucher behind code and its discount on the order. The
+// order's items and shipment fee must already be priced.
+//
+// The usage limits are checked without locking the voucher, so a priced cart
+// is only a preview: another checkout can take the last use in between, and
+// redeemVoucher then rejects the order with the same exhausted or user limit
+// error for the client to price the cart again.
+func (va *voucherApplier) apply(
+	ctx context.Context,
+	code string,
+	order domain.Order,
+) (domain.Voucher, int, error) {
+	code = normalizeVoucherCode(code)
+
+	v, err := va.dr.VoucherRepository().GetByCode(ctx, code)
+	if apperror.IsErrorCode(err, apperror.CodeNotFound) {
+		return domain.Voucher{}, 0, apperror.NewVoucherNotFound(code)
+	}
+	if err != nil {
+		return domain.Voucher{}, 0, err
+	}
+
+	if !v.IsActive(va.at) {
+		return domain.Voucher{}, 0, apperror.NewVoucherNotActive(code)
+	}
+	if v.PharmacyID != nil && *v.PharmacyID != order.Pharmacy.ID {
+		return domain.Voucher{}, 0, apperror.NewVoucherWrongPharmacy(code)
+	}
+
+	spend := order.Subtotal - order.Discount
+	if spend < v.MinSpend {
+		return domain.Voucher{}, 0, apperror.NewVoucherMinSpend(code, v.MinSpend)
+	}
+
+	err = checkVoucherUsage(ctx, va.dr, v, va.userID, va.used[v.ID]+1)
+	if err != nil {
+		return domain.Voucher{}, 0, err
+	}
+	va.used[v.ID]++
+
+	return v, v.Discount(spend, order.ShipmentFee), nil
+}
+
+// redeemVoucher records the use of an order's voucher. The voucher stays
+// locked until the checkout commits, so concurrent checkouts see each
+// other's redemptions and can't go past the limits together.
+func redeemVoucher(
+	ctx context.Context,
+	dr domain.DataRepository,
+	order domain.Order,
+) error {
+	voucherRepo := dr.VoucherRepository()
+
+	v, err := voucherRepo.GetByIDAndLock(ctx, order.Voucher.ID)
+	if apperror.IsErrorCode(err, apperror.CodeNotFound) {
+		return apperror.NewVoucherNotFound(order.Voucher.Code)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = checkVoucherUsage(ctx, dr, v, order.User.ID, 1)
+	if err != nil {
+		return err
+	}
+
+	_, err = voucherRepo.AddRedemption(ctx, domain.VoucherRedemption{
+		VoucherID: v.ID,
+		UserID:    order.User.ID,
+		PaymentID: order.Payment.ID,
+		OrderID:   order.ID,
+		Discount:  order.VoucherDiscount,
+	})
+	if err != nil {
+		return err
+	}
+
+	return voucherRepo.IncrementUsage(ctx, v.ID)
+}
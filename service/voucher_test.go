@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"medichat-be/apperror"
+	"medichat-be/domain"
+	"medichat-be/mocks/domainmocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// codeVoucherRepository hands out a fixed voucher for every code.
+type codeVoucherRepository struct {
+	domain.VoucherRepository
+
+	voucher domain.Voucher
+}
+
+func (r *codeVoucherRepository) GetByCode(ctx context.Context, code string) (domain.Voucher, error) {
+	return r.voucher, nil
+}
+
+func Test_voucherApplier_apply(t *testing.T) {
+	voucher := domain.Voucher{
+		ID:       1,
+		Code:     "HEMAT",
+		Type:     domain.VoucherTypeFixed,
+		Value:    5000,
+		MinSpend: 50000,
+		StartsAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name string
+
+		subtotal int
+		discount int
+
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "should apply when the spend is exactly the minimum",
+
+			subtotal: 50000,
+			discount: 0,
+
+			want: 5000,
+		},
+		{
+			name: "should reject a spend just under the minimum",
+
+			subtotal: 49999,
+			discount: 0,
+
+			wantErr: true,
+		},
+		{
+			name: "should count the spend after promotions",
+
+			subtotal: 55000,
+			discount: 5001,
+
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			dataRepo := new(domainmocks.DataRepository)
+			dataRepo.On("VoucherRepository").Return(&codeVoucherRepository{voucher: voucher})
+			va := newVoucherApplier(dataRepo, 1, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC))
+			order := domain.Order{Subtotal: tt.subtotal, Discount: tt.discount}
+
+			// when
+			_, got, err := va.apply(context.Background(), " hemat ", order)
+
+			// then
+			if tt.wantErr {
+				assert.True(t, apperror.IsErrorCode(err, apperror.CodeBadRequest))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
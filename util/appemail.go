@@ -11,11 +11,13 @@ import (
 type AppEmail interface {
 	NewVerifyAccountEmail(fullname, email string, verifyEmailToken string) *gomail.Message
 	NewPasswordResetEmail(email, resetPasswordToken string) *gomail.Message
+	NewDoctorVerificationEmail(fullname string, approved bool, reason string) *gomail.Message
 }
 
 type appEmail struct {
 	verifyAccountTemplate *template.Template
 	passwordResetTemplate *template.Template
+	doctorVerifyTemplate  *template.Template
 	feVerificationURL     string
 	feResetPasswordURL    string
 }
@@ -36,9 +38,15 @@ func NewAppEmail(opts AppEmailOpts) (*appEmail, error) {
 		return nil, err
 	}
 
+	doctorVerifyTemplate, err := template.ParseFiles("templates/doctor-verification-email.html")
+	if err != nil {
+		return nil, err
+	}
+
 	return &appEmail{
 		verifyAccountTemplate: verifyAccountTemplate,
 		passwordResetTemplate: passwordResetTemplate,
+		doctorVerifyTemplate:  doctorVerifyTemplate,
 		feVerificationURL:     opts.FEVerivicationURL,
 		feResetPasswordURL:    opts.FEResetPasswordURL,
 	}, nil
@@ -73,3 +81,24 @@ func (a *appEmail) NewPasswordResetEmail(email, resetPasswordToken string) *goma
 	mailer.SetBody("text/html", body.String())
 	return mailer
 }
+
+func (a *appEmail) NewDoctorVerificationEmail(fullname string, approved bool, reason string) *gomail.Message {
+	var body bytes.Buffer
+	a.doctorVerifyTemplate.Execute(&body, struct {
+		Fullname string
+		Approved bool
+		Reason   string
+	}{
+		Fullname: fullname,
+		Approved: approved,
+		Reason:   reason,
+	})
+	mailer := gomail.NewMessage()
+	if approved {
+		mailer.SetHeader("Subject", "Medichat Doctor Profile Verified")
+	} else {
+		mailer.SetHeader("Subject", "Medichat Doctor Profile Verification Rejected")
+	}
+	mailer.SetBody("text/html", body.String())
+	return mailer
+}
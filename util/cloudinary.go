@@ -14,6 +14,7 @@ import (
 type CloudinaryProvider interface {
 	SendFile(sendFile SendFileOpts) (*uploader.UploadResult, error)
 	UploadImage(ctx context.Context, image multipart.File, params uploader.UploadParams) (*uploader.UploadResult, error)
+	DeleteImage(ctx context.Context, publicID string) error
 }
 
 type cloudinaryProviderImpl struct {
@@ -65,3 +66,10 @@ func (p *cloudinaryProviderImpl) UploadImage(ctx context.Context, image multipar
 	}
 	return res, nil
 }
+
+func (p *cloudinaryProviderImpl) DeleteImage(ctx context.Context, publicID string) error {
+	_, err := p.cloud.Upload.Destroy(ctx, uploader.DestroyParams{
+		PublicID: publicID,
+	})
+	return err
+}
@@ -0,0 +1,145 @@
+package util
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"medichat-be/constants"
+	"mime/multipart"
+	"net/http"
+)
+
+var (
+	ErrImageTooLarge    = errors.New("image is too large")
+	ErrImageUnsupported = errors.New("image type is not supported")
+)
+
+type ImageInfo struct {
+	ContentType string
+	Width       int
+	Height      int
+}
+
+func ReadImage(r io.Reader, maxSize int) ([]byte, ImageInfo, error) {
+	data, err := io.ReadAll(io.LimitReader(r, int64(maxSize)+1))
+	if err != nil {
+		return nil, ImageInfo{}, err
+	}
+	if len(data) > maxSize {
+		return nil, ImageInfo{}, ErrImageTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	if contentType != constants.ImageJpeg && contentType != constants.ImagePng {
+		return nil, ImageInfo{}, ErrImageUnsupported
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ImageInfo{}, ErrImageUnsupported
+	}
+
+	return data, ImageInfo{
+		ContentType: contentType,
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+	}, nil
+}
+
+func MakeThumbnail(data []byte, contentType string, maxDimension int) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > maxDimension || h > maxDimension {
+		if w >= h {
+			h = h * maxDimension / w
+			w = maxDimension
+		} else {
+			w = w * maxDimension / h
+			h = maxDimension
+		}
+		if w < 1 {
+			w = 1
+		}
+		if h < 1 {
+			h = 1
+		}
+	}
+
+	dst := boxResize(src, w, h)
+
+	var buf bytes.Buffer
+	if contentType == constants.ImagePng {
+		err = png.Encode(&buf, dst)
+	} else {
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func boxResize(src image.Image, w, h int) *image.RGBA64 {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dst := image.NewRGBA64(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*sh/h
+		y1 := b.Min.Y + (y+1)*sh/h
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*sw/w
+			x1 := b.Min.X + (x+1)*sw/w
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					bl += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+
+			dst.SetRGBA64(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(bl / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return dst
+}
+
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (memoryFile) Close() error {
+	return nil
+}
+
+func NewMemoryFile(data []byte) multipart.File {
+	return memoryFile{bytes.NewReader(data)}
+}
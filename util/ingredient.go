@@ -0,0 +1,47 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+)
+
+var dosageUnits = map[string]bool{
+	"mg": true, "mcg": true, "µg": true, "g": true, "gr": true, "kg": true,
+	"ml": true, "l": true, "iu": true, "ui": true, "unit": true, "units": true,
+	"tablet": true, "tablets": true, "tab": true, "kapsul": true, "capsule": true,
+}
+
+func NormalizeIngredient(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+func ExtractIngredients(texts ...string) []string {
+	seen := map[string]bool{}
+	ret := []string{}
+
+	for _, text := range texts {
+		parts := strings.FieldsFunc(text, func(r rune) bool {
+			return strings.ContainsRune(",;+/&()\n", r)
+		})
+
+		for _, part := range parts {
+			words := []string{}
+			for _, w := range strings.Fields(strings.ToLower(part)) {
+				w = strings.Trim(w, ".:-")
+				if w == "" || dosageUnits[w] || unicode.IsDigit([]rune(w)[0]) {
+					continue
+				}
+				words = append(words, w)
+			}
+
+			ingredient := strings.Join(words, " ")
+			if ingredient == "" || seen[ingredient] {
+				continue
+			}
+			seen[ingredient] = true
+			ret = append(ret, ingredient)
+		}
+	}
+
+	return ret
+}
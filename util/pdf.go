@@ -0,0 +1,40 @@
+package util
+
+import (
+	"bytes"
+
+	"github.com/pdfcrowd/pdfcrowd-go"
+)
+
+type PDFProvider interface {
+	ConvertHTML(html string) ([]byte, error)
+}
+
+type pdfcrowdProviderImpl struct {
+	username string
+	apiKey   string
+}
+
+type PDFProviderOpts struct {
+	Username string
+	APIKey   string
+}
+
+func NewPdfcrowdProvider(opts PDFProviderOpts) *pdfcrowdProviderImpl {
+	return &pdfcrowdProviderImpl{
+		username: opts.Username,
+		apiKey:   opts.APIKey,
+	}
+}
+
+func (p *pdfcrowdProviderImpl) ConvertHTML(html string) ([]byte, error) {
+	client := pdfcrowd.NewHtmlToPdfClient(p.username, p.apiKey)
+
+	var pdf bytes.Buffer
+	err := client.ConvertStringToStream(html, &pdf)
+	if err != nil {
+		return nil, err
+	}
+
+	return pdf.Bytes(), nil
+}
@@ -0,0 +1,16 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+)
+
+func SplitSearchWords(text string) []string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return words
+}
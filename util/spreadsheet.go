@@ -0,0 +1,114 @@
+package util
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"medichat-be/constants"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const spreadsheetSheetName = "Sheet1"
+
+// csvFormulaChars start a cell that spreadsheet apps would run as a formula.
+const csvFormulaChars = "=+-@\t\r"
+
+// escapeCSVCell keeps a cell from being run as a formula by prefixing it
+// with a quote, which spreadsheet apps read as "this is text".
+func escapeCSVCell(v string) string {
+	if v != "" && strings.ContainsRune(csvFormulaChars, rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+// unescapeCSVCell undoes escapeCSVCell, so exports can be imported again.
+func unescapeCSVCell(v string) string {
+	if len(v) > 1 && v[0] == '\'' && strings.ContainsRune(csvFormulaChars, rune(v[1])) {
+		return v[1:]
+	}
+	return v
+}
+
+func ReadSpreadsheet(r io.Reader, format string) ([][]string, error) {
+	switch format {
+	case constants.ExportFormatCSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		cr.TrimLeadingSpace = true
+		records, err := cr.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			for i := range record {
+				record[i] = unescapeCSVCell(record[i])
+			}
+		}
+		return records, nil
+	case constants.ExportFormatXLSX:
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return [][]string{}, nil
+		}
+		return f.GetRows(sheets[0])
+	default:
+		return nil, fmt.Errorf("unsupported spreadsheet format %q", format)
+	}
+}
+
+func WriteSpreadsheet(records [][]string, format string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	switch format {
+	case constants.ExportFormatCSV:
+		w := csv.NewWriter(&buf)
+		for _, record := range records {
+			escaped := make([]string, len(record))
+			for i, v := range record {
+				escaped[i] = escapeCSVCell(v)
+			}
+			if err := w.Write(escaped); err != nil {
+				return nil, err
+			}
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return nil, err
+		}
+	case constants.ExportFormatXLSX:
+		f := excelize.NewFile()
+		defer f.Close()
+
+		for i, record := range records {
+			cell, err := excelize.CoordinatesToCellName(1, i+1)
+			if err != nil {
+				return nil, err
+			}
+			row := make([]any, len(record))
+			for j, v := range record {
+				row[j] = v
+			}
+			if err := f.SetSheetRow(spreadsheetSheetName, cell, &row); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err := f.WriteTo(&buf); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported spreadsheet format %q", format)
+	}
+
+	return buf.Bytes(), nil
+}